@@ -0,0 +1,39 @@
+package boilcompat
+
+import (
+	"bytes"
+
+	"github.com/sqlbunny/sqlbunny/gen"
+)
+
+const (
+	templatesPackage = "github.com/sqlbunny/sqlbunny/gen/boilcompat"
+)
+
+// Plugin generates methods with the names of sqlboiler's global executor
+// ("G") variants for all models, so code written against sqlboiler models
+// can be migrated incrementally: OneG, AllG, CountG and ExistsG on queries,
+// Find<Model>G and <Model>ExistsG, and InsertG, ReloadG and ReloadAllG on
+// objects, with the columns of InsertG selected by boilcompat.Columns.
+//
+// Update, Delete and DeleteAll aren't shimmed: sqlbunny's return no rows
+// affected count, so the calls need to be changed anyway.
+//
+// The definitions of the models can be translated from the schema printed
+// by a sqlboiler driver with 'introspect --sqlboiler'.
+type Plugin struct {
+}
+
+var _ gen.Plugin = &Plugin{}
+
+func (*Plugin) ConfigItem(ctx *gen.Context) {}
+
+func (p *Plugin) BunnyPlugin() {
+	gen.OnHook("model", p.modelHook(gen.MustLoadTemplate(templatesPackage, "templates/model.tpl")))
+}
+
+func (p *Plugin) modelHook(tpl *gen.TemplateList) gen.HookFunc {
+	return func(buf *bytes.Buffer, data map[string]interface{}, args ...interface{}) {
+		tpl.ExecuteBuf(data, buf)
+	}
+}
@@ -0,0 +1,50 @@
+{{- $modelNameSingular := .Model.Name | singular | titleCase -}}
+{{- $varNameSingular := .Model.Name | singular | camelCase -}}
+{{- $model := .Model -}}
+{{ import "boilcompat" "github.com/sqlbunny/sqlbunny/runtime/boilcompat" }}
+
+// OneG is sqlboiler's name of One.
+func (q {{$varNameSingular}}Query) OneG(ctx context.Context) (*{{$modelNameSingular}}, error) {
+	return q.One(ctx)
+}
+
+// AllG is sqlboiler's name of All.
+func (q {{$varNameSingular}}Query) AllG(ctx context.Context) ({{$modelNameSingular}}Slice, error) {
+	return q.All(ctx)
+}
+
+// CountG is sqlboiler's name of Count.
+func (q {{$varNameSingular}}Query) CountG(ctx context.Context) (int64, error) {
+	return q.Count(ctx)
+}
+
+// ExistsG is sqlboiler's name of Exists.
+func (q {{$varNameSingular}}Query) ExistsG(ctx context.Context) (bool, error) {
+	return q.Exists(ctx)
+}
+
+// Find{{$modelNameSingular}}G is sqlboiler's name of Find{{$modelNameSingular}}.
+func Find{{$modelNameSingular}}G(ctx context.Context{{range .Model.PrimaryKey.Fields}}, {{$f := $model.FindField .}}{{$f.Name | camelCase}} {{goType $f.Type.GoType}}{{end}}, selectCols ...string) (*{{$modelNameSingular}}, error) {
+	return Find{{$modelNameSingular}}(ctx{{range .Model.PrimaryKey.Fields}}, {{$f := $model.FindField .}}{{$f.Name | camelCase}}{{end}}, selectCols...)
+}
+
+// {{$modelNameSingular}}ExistsG is sqlboiler's name of {{$modelNameSingular}}Exists.
+func {{$modelNameSingular}}ExistsG(ctx context.Context{{range .Model.PrimaryKey.Fields}}, {{$f := $model.FindField .}}{{$f.Name | camelCase}} {{goType $f.Type.GoType}}{{end}}) (bool, error) {
+	return {{$modelNameSingular}}Exists(ctx{{range .Model.PrimaryKey.Fields}}, {{$f := $model.FindField .}}{{$f.Name | camelCase}}{{end}})
+}
+
+// InsertG inserts the columns of o selected by columns, like sqlboiler's
+// InsertG. The inferred columns are the ones of Insert.
+func (o *{{$modelNameSingular}}) InsertG(ctx context.Context, columns boilcompat.Columns) error {
+	return o.Insert(ctx, columns.List({{$varNameSingular}}Columns)...)
+}
+
+// ReloadG is sqlboiler's name of Reload.
+func (o *{{$modelNameSingular}}) ReloadG(ctx context.Context) error {
+	return o.Reload(ctx)
+}
+
+// ReloadAllG is sqlboiler's name of ReloadAll.
+func (o *{{$modelNameSingular}}Slice) ReloadAllG(ctx context.Context) error {
+	return o.ReloadAll(ctx)
+}
@@ -13,10 +13,44 @@ type ConfigStruct struct {
 	Items  []ConfigItem
 	Schema *schema.Schema
 
+	// Warnings are the warnings of the schema, printed before running the
+	// commands. With the --strict flag they fail the commands.
+	Warnings []error
+
 	Dialect queries.Dialect
 
 	ModelsPackagePath string
 	ModelsPackageName string
+
+	// GenerateTests generates integration tests of the models in the models
+	// package. They're skipped unless the SQLBUNNY_TEST_DSN environment
+	// variable is the DSN of a Postgres database, like one started with
+	// testcontainers, where they create the tables in a temporary schema.
+	GenerateTests bool
+
+	// Project is the project config file, nil if there's none.
+	Project *ProjectConfig
 }
 
 var Config *ConfigStruct
+
+var (
+	// DialectPostgres is the dialect of the code generated by default.
+	DialectPostgres = queries.Dialect{
+		LQ:                '"',
+		RQ:                '"',
+		IndexPlaceholders: true,
+		UseTopClause:      false,
+		UseReturning:      true,
+	}
+
+	// DialectMySQL generates code for MySQL.
+	DialectMySQL = queries.Dialect{
+		LQ:                '`',
+		RQ:                '`',
+		IndexPlaceholders: false,
+		UseTopClause:      false,
+		UseReturning:      false,
+		UseOnDuplicateKey: true,
+	}
+)
@@ -34,14 +34,31 @@ func (q *taskQueue) Pop() interface{} {
 type Context struct {
 	Schema *schema.Schema
 
-	errors []error
-	queue  taskQueue
+	// Module is the name of the module whose items are being defined, or
+	// empty for the items passed to Run.
+	Module string
+
+	errors      []error
+	warnings    []error
+	queue       taskQueue
+	modules     map[string]bool
+	definitions map[string]string
 }
 
 func (ctx *Context) AddError(message string, args ...interface{}) {
 	ctx.errors = append(ctx.errors, fmt.Errorf(message, args...))
 }
 
+// AddWarning adds a warning, reported without failing the generation.
+func (ctx *Context) AddWarning(message string, args ...interface{}) {
+	ctx.warnings = append(ctx.warnings, fmt.Errorf(message, args...))
+}
+
+// Warnings returns the warnings added to the context.
+func (ctx *Context) Warnings() []error {
+	return ctx.warnings
+}
+
 func (ctx *Context) Enqueue(order int, fn func()) {
 	heap.Push(&ctx.queue, task{order, fn})
 }
@@ -52,6 +69,46 @@ func (ctx *Context) Run() {
 	}
 }
 
+// InModule defines the items of the module with name with fn, unless the
+// module was already defined. Modules imported by several other modules
+// are defined once.
+func (ctx *Context) InModule(name string, fn func()) {
+	if ctx.modules[name] {
+		return
+	}
+	if ctx.modules == nil {
+		ctx.modules = map[string]bool{}
+	}
+	ctx.modules[name] = true
+
+	parent := ctx.Module
+	ctx.Module = name
+	fn()
+	ctx.Module = parent
+}
+
+// Define records the definition of the type or model with name in the
+// current module. Definitions can't be repeated, in the same module or
+// across modules.
+func (ctx *Context) Define(kind string, name string) {
+	if ctx.definitions == nil {
+		ctx.definitions = map[string]string{}
+	}
+	key := kind + " '" + name + "'"
+	if module, ok := ctx.definitions[key]; ok {
+		ctx.AddError("%s is defined multiple times, %s and %s", key, describeModule(module), describeModule(ctx.Module))
+		return
+	}
+	ctx.definitions[key] = ctx.Module
+}
+
+func describeModule(module string) string {
+	if module == "" {
+		return "in the schema"
+	}
+	return "in module '" + module + "'"
+}
+
 func (ctx *Context) GetType(name string, where string) schema.Type {
 	res, ok := ctx.Schema.Types[name]
 	if !ok {
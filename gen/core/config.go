@@ -1,10 +1,25 @@
 package core
 
-import "github.com/sqlbunny/sqlbunny/gen"
+import (
+	"log"
+	"sort"
+
+	"github.com/sqlbunny/sqlbunny/gen"
+	"github.com/sqlbunny/sqlbunny/schema"
+)
 
 type Config struct {
 	ModelsPackagePath string
 	ModelsPackageName string
+
+	// Dialect of the generated code: "postgres" (the default) or "mysql".
+	// MySQL has no RETURNING clause, so the *Returning methods aren't
+	// generated for it. COPY and LISTEN/NOTIFY support stay Postgres only.
+	Dialect string
+
+	// GenerateTests generates integration tests of the models, see
+	// gen.ConfigStruct.GenerateTests.
+	GenerateTests bool
 }
 
 func (c *Config) ConfigItem(ctx *gen.Context) {
@@ -17,4 +32,78 @@ func (c *Config) BunnyConfig(s *gen.ConfigStruct) {
 	if c.ModelsPackageName != "" {
 		s.ModelsPackageName = c.ModelsPackageName
 	}
+	if c.Dialect != "" {
+		d, err := gen.DialectByName(c.Dialect)
+		if err != nil {
+			log.Fatal(err)
+		}
+		s.Dialect = d
+	}
+	if c.GenerateTests {
+		s.GenerateTests = true
+	}
+}
+
+type defTypeOverride struct {
+	name     string
+	override gen.ProjectType
+}
+
+func (d defTypeOverride) ConfigItem(ctx *gen.Context) {
+	// Types are defined by now, and aren't referenced by fields yet.
+	ctx.Enqueue(50, func() {
+		t, ok := ctx.Schema.Types[d.name]
+		if !ok {
+			ctx.AddError("%s overrides unknown type '%s'", gen.ProjectFile, d.name)
+			return
+		}
+		bt, ok := t.(schema.BaseType)
+		if !ok {
+			ctx.AddError("%s overrides type '%s', but it's not a base type", gen.ProjectFile, d.name)
+			return
+		}
+
+		o := &schema.BaseTypeNullable{
+			Name:     d.name,
+			Go:       t.GoType(),
+			Postgres: bt.SQLType(),
+		}
+		if nt, ok := t.(schema.NullableType); ok {
+			o.GoNull = nt.GoTypeNull()
+		}
+		if d.override.Go != "" {
+			o.Go = parseGoType(d.override.Go)
+			o.GoNull = schema.GoType{
+				Pkg:  "github.com/sqlbunny/sqlbunny/types/null",
+				Name: "Val",
+				Args: []schema.GoType{o.Go},
+			}
+		}
+		if d.override.GoNull != "" {
+			o.GoNull = parseGoType(d.override.GoNull)
+		}
+		if d.override.Postgres != "" {
+			o.Postgres.Type = d.override.Postgres
+		}
+		ctx.Schema.Types[d.name] = o
+	})
+}
+
+// projectTypeOverrides returns the items overriding the types in the
+// project config file.
+func projectTypeOverrides(c *gen.ProjectConfig) []gen.ConfigItem {
+	if c == nil {
+		return nil
+	}
+	names := make([]string, 0, len(c.Types))
+	for name := range c.Types {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var res []gen.ConfigItem
+	for _, name := range names {
+		res = append(res, defTypeOverride{name: name, override: c.Types[name]})
+	}
+	return res
 }
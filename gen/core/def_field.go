@@ -3,6 +3,8 @@ package core
 import (
 	"fmt"
 
+	"github.com/sqlbunny/sqlbunny/gen"
+
 	"github.com/sqlbunny/sqlbunny/schema"
 )
 
@@ -11,6 +13,12 @@ type StructFieldContext struct {
 	Field *schema.Field
 }
 
+// AddError adds an error prefixed with the position of the definition of
+// the field.
+func (ctx *StructFieldContext) AddError(message string, args ...interface{}) {
+	addErrorAt(ctx.Context, ctx.Field, message, args...)
+}
+
 type StructFieldItem interface {
 	StructFieldItem(ctx *StructFieldContext)
 }
@@ -20,6 +28,12 @@ type ModelFieldContext struct {
 	Field *schema.Field
 }
 
+// AddError adds an error prefixed with the position of the definition of
+// the field.
+func (ctx *ModelFieldContext) AddError(message string, args ...interface{}) {
+	addErrorAt(ctx.Context, ctx.Field, message, args...)
+}
+
 type ModelFieldItem interface {
 	ModelFieldItem(ctx *ModelFieldContext)
 }
@@ -29,6 +43,12 @@ type ModelRecursiveFieldContext struct {
 	Field *schema.Field
 }
 
+// AddError adds an error prefixed with the position of the definition of
+// the field.
+func (ctx *ModelRecursiveFieldContext) AddError(message string, args ...interface{}) {
+	addErrorAt(ctx.Context, ctx.Field, message, args...)
+}
+
 type ModelRecursiveFieldItem interface {
 	ModelRecursiveFieldItem(ctx *ModelRecursiveFieldContext)
 }
@@ -41,6 +61,7 @@ type defField struct {
 	name     string
 	typeName string
 	items    []FieldItem
+	pos      string
 
 	field *schema.Field // Filled on the ModelItem pass, used in the ModelRecursiveItem pass
 }
@@ -48,9 +69,10 @@ type defField struct {
 func (d *defField) StructItem(ctx *StructContext) {
 	f := &schema.Field{
 		Name: d.name,
-		Type: ctx.GetType(d.typeName, fmt.Sprintf("Struct %s, field %s", ctx.Struct.Name, d.name)),
 		Tags: schema.Tags{},
 	}
+	f.SetExtension(defPosExt{}, d.pos)
+	f.Type = d.getType(ctx.Context, fmt.Sprintf("Struct %s, field %s", ctx.Struct.Name, d.name))
 
 	ctx.Struct.Fields = append(ctx.Struct.Fields, f)
 	d.field = f
@@ -68,7 +90,7 @@ func (d *defField) StructItem(ctx *StructContext) {
 func (d *defField) ModelItem(ctx *ModelContext) {
 	m := ctx.Model
 
-	t := ctx.GetType(d.typeName, fmt.Sprintf("Model '%s' field '%s'", ctx.Model.Name, d.name))
+	t := d.getType(ctx.Context, fmt.Sprintf("Model '%s' field '%s'", ctx.Model.Name, d.name))
 	if t == nil {
 		return
 	}
@@ -79,6 +101,7 @@ func (d *defField) ModelItem(ctx *ModelContext) {
 		Nullable: false,
 		Tags:     schema.Tags{},
 	}
+	f.SetExtension(defPosExt{}, d.pos)
 	m.Fields = append(m.Fields, f)
 	d.field = f
 
@@ -104,7 +127,7 @@ func (d *defField) ModelRecursiveItem(ctx *ModelRecursiveContext) {
 		}
 	}
 
-	t := ctx.GetType(d.typeName, fmt.Sprintf("Model '%s' field '%s'", ctx.Model.Name, appendPath(ctx.Prefix, d.name).SQLName()))
+	t := d.getType(ctx.Context, fmt.Sprintf("Model '%s' field '%s'", ctx.Model.Name, appendPath(ctx.Prefix, d.name).SQLName()))
 	if t == nil {
 		return
 	}
@@ -126,10 +149,20 @@ func (d *defField) ModelRecursiveItem(ctx *ModelRecursiveContext) {
 	}
 }
 
+// getType returns the type of the field, reporting unknown types at the
+// position of the field.
+func (d *defField) getType(ctx *gen.Context, where string) schema.Type {
+	if d.pos != "" {
+		where = d.pos + ": " + where
+	}
+	return ctx.GetType(d.typeName, where)
+}
+
 func Field(name string, typeName string, items ...FieldItem) *defField {
 	return &defField{
 		name:     name,
 		typeName: typeName,
 		items:    items,
+		pos:      callerPos(),
 	}
 }
@@ -1,6 +1,11 @@
 package core
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
+
+	"github.com/sqlbunny/sqlbunny/schema"
+)
 
 type defFieldNull struct{}
 
@@ -49,3 +54,285 @@ var _ StructFieldItem = defFieldTag{}
 func Tag(key string, value string) defFieldTag {
 	return defFieldTag{key: key, value: value}
 }
+
+// Validate sets the validation rules of the field, like "required,email",
+// checked before the generated code writes the model. See bunny.Validate.
+func Validate(rules string) defFieldTag {
+	return Tag("validate", rules)
+}
+
+// UUID storages, see UUIDStorage.
+const (
+	UUIDNative = schema.UUIDNative
+	UUIDBytea  = schema.UUIDBytea
+	UUIDText   = schema.UUIDText
+)
+
+type defFieldUUIDStorage struct {
+	storage schema.UUIDStorage
+}
+
+func (d defFieldUUIDStorage) FieldItem() {}
+
+func (d defFieldUUIDStorage) ModelFieldItem(ctx *ModelFieldContext) {
+	d.apply(ctx, ctx.Field, fmt.Sprintf("Model '%s' field '%s'", ctx.Model.Name, ctx.Field.Name))
+}
+
+func (d defFieldUUIDStorage) StructFieldItem(ctx *StructFieldContext) {
+	d.apply(ctx, ctx.Field, fmt.Sprintf("Struct '%s' field '%s'", ctx.Struct.Name, ctx.Field.Name))
+}
+
+func (d defFieldUUIDStorage) apply(ctx Context, f *schema.Field, where string) {
+	t, ok := f.Type.(*schema.UUID)
+	if !ok {
+		ctx.AddError("%s has UUIDStorage, but its type '%s' isn't a UUID", where, f.Type.GetName())
+		return
+	}
+	// The type is shared with the other fields.
+	t2 := *t
+	t2.Storage = d.storage
+	f.Type = &t2
+}
+
+var _ ModelFieldItem = defFieldUUIDStorage{}
+var _ StructFieldItem = defFieldUUIDStorage{}
+
+// UUIDStorage stores a uuid field in the columns of storage, like the bytea
+// or text columns of legacy tables. The Go type stays uuid.UUID.
+func UUIDStorage(storage schema.UUIDStorage) defFieldUUIDStorage {
+	return defFieldUUIDStorage{storage: storage}
+}
+
+type defFieldEncrypted struct {
+	encryption schema.Encryption
+}
+
+func (d defFieldEncrypted) FieldItem() {}
+
+func (d defFieldEncrypted) ModelFieldItem(ctx *ModelFieldContext) {
+	d.apply(ctx, ctx.Field, fmt.Sprintf("Model '%s' field '%s'", ctx.Model.Name, ctx.Field.Name))
+}
+
+func (d defFieldEncrypted) StructFieldItem(ctx *StructFieldContext) {
+	d.apply(ctx, ctx.Field, fmt.Sprintf("Struct '%s' field '%s'", ctx.Struct.Name, ctx.Field.Name))
+}
+
+func (d defFieldEncrypted) apply(ctx Context, f *schema.Field, where string) {
+	t, ok := f.Type.(schema.BaseType)
+	if !ok {
+		ctx.AddError("%s is encrypted, but its type '%s' is a struct", where, f.Type.GetName())
+		return
+	}
+	switch sqlType := t.SQLType().Type; {
+	case sqlType == "text", sqlType == "citext", sqlType == "bytea", strings.HasPrefix(sqlType, "varchar"):
+	default:
+		ctx.AddError("%s is encrypted, but its type '%s' is stored as %s, it must be stored as text or bytea", where, f.Type.GetName(), sqlType)
+	}
+	if f.Encryption != schema.EncryptionNone {
+		ctx.AddError("%s has encryption defined multiple times", where)
+	}
+	if f.DBDefault != "" {
+		ctx.AddError("%s has a DBDefault, but it's encrypted", where)
+	}
+	f.Encryption = d.encryption
+}
+
+var _ FieldItem = defFieldEncrypted{}
+var _ ModelFieldItem = defFieldEncrypted{}
+var _ StructFieldItem = defFieldEncrypted{}
+
+// Encrypted encrypts the values of a text or bytea field at rest: they're
+// stored in a bytea column, encrypted and decrypted by the generated code
+// with the keys of bunny.SetKeyProvider. Encrypted fields can't be compared
+// in queries, see EncryptedDeterministic. Values written by queries, like
+// with UpdateMapAll, must be bunny.Encrypted.
+var Encrypted = defFieldEncrypted{encryption: schema.EncryptionRandomized}
+
+// EncryptedDeterministic is like Encrypted, but equal values are encrypted
+// to equal bytes, so the field can be unique, indexed and looked up by
+// equality with bunny.Encrypted arguments. It reveals which rows have equal
+// values.
+var EncryptedDeterministic = defFieldEncrypted{encryption: schema.EncryptionDeterministic}
+
+type defFieldPII struct {
+	omitJSON bool
+}
+
+// OmitJSON excludes the field from the JSON marshaling of the generated
+// types, with the "json" tag "-".
+func (d defFieldPII) OmitJSON() defFieldPII {
+	d.omitJSON = true
+	return d
+}
+
+func (d defFieldPII) FieldItem() {}
+
+func (d defFieldPII) ModelFieldItem(ctx *ModelFieldContext) {
+	d.apply(ctx, ctx.Field, fmt.Sprintf("Model '%s' field '%s'", ctx.Model.Name, ctx.Field.Name))
+}
+
+func (d defFieldPII) StructFieldItem(ctx *StructFieldContext) {
+	d.apply(ctx, ctx.Field, fmt.Sprintf("Struct '%s' field '%s'", ctx.Struct.Name, ctx.Field.Name))
+}
+
+func (d defFieldPII) apply(ctx Context, f *schema.Field, where string) {
+	f.PII = true
+	if d.omitJSON {
+		if _, ok := f.Tags["json"]; ok {
+			ctx.AddError("%s has PII().OmitJSON(), but it has a 'json' tag", where)
+		}
+		f.Tags["json"] = "-"
+	}
+}
+
+var _ FieldItem = defFieldPII{}
+var _ ModelFieldItem = defFieldPII{}
+var _ StructFieldItem = defFieldPII{}
+
+// PII marks a field as personally identifiable information: its values are
+// redacted in the generated String methods and in the query logs, and its
+// columns are listed by the "pii" command for compliance review. See
+// OmitJSON to exclude it from JSON too.
+func PII() defFieldPII {
+	return defFieldPII{}
+}
+
+type defFieldDBDefault struct {
+	expr string
+}
+
+func (d defFieldDBDefault) FieldItem() {}
+
+func (d defFieldDBDefault) ModelFieldItem(ctx *ModelFieldContext) {
+	where := fmt.Sprintf("Model '%s' field '%s'", ctx.Model.Name, ctx.Field.Name)
+	if _, ok := ctx.Field.Type.(schema.BaseType); !ok {
+		ctx.AddError("%s has a DBDefault, but its type '%s' is a struct", where, ctx.Field.Type.GetName())
+		return
+	}
+	if ctx.Field.Encryption != schema.EncryptionNone {
+		ctx.AddError("%s has a DBDefault, but it's encrypted", where)
+	}
+	if ctx.Field.DBDefault != "" {
+		ctx.AddError("%s has DBDefault defined multiple times", where)
+	}
+	ctx.Field.DBDefault = d.expr
+}
+
+var _ FieldItem = defFieldDBDefault{}
+var _ ModelFieldItem = defFieldDBDefault{}
+
+// DBDefault sets the default value of the column of a field to the SQL
+// expression expr, like "now()" or "nextval('invoice_number')", which the
+// database sets on inserts. The generated inserts leave the field out
+// unless it's whitelisted, and Insert scans its value back into the model
+// with RETURNING where the dialect supports it. Primary key fields can't have
+// one.
+func DBDefault(expr string) defFieldDBDefault {
+	return defFieldDBDefault{expr: expr}
+}
+
+type defFieldImmutable struct{}
+
+func (d defFieldImmutable) FieldItem() {}
+
+func (d defFieldImmutable) ModelFieldItem(ctx *ModelFieldContext) {
+	ctx.Field.Immutable = true
+}
+
+var _ FieldItem = defFieldImmutable{}
+var _ ModelFieldItem = defFieldImmutable{}
+
+// Immutable makes a field, like created_at or an external ID, only written
+// by inserts. The generated updates and upserts leave it out without a
+// whitelist, and return a bunny.ImmutableColumnError if its columns are
+// whitelisted or set in a patch.
+func Immutable() defFieldImmutable {
+	return defFieldImmutable{}
+}
+
+type defFieldRenamedFrom struct {
+	name string
+}
+
+func (d defFieldRenamedFrom) FieldItem() {}
+
+func (d defFieldRenamedFrom) ModelFieldItem(ctx *ModelFieldContext) {
+	d.apply(ctx, ctx.Field, fmt.Sprintf("Model '%s' field '%s'", ctx.Model.Name, ctx.Field.Name))
+}
+
+func (d defFieldRenamedFrom) StructFieldItem(ctx *StructFieldContext) {
+	d.apply(ctx, ctx.Field, fmt.Sprintf("Struct '%s' field '%s'", ctx.Struct.Name, ctx.Field.Name))
+}
+
+func (d defFieldRenamedFrom) apply(ctx Context, f *schema.Field, where string) {
+	if !identifierRgx.MatchString(d.name) {
+		ctx.AddError("%s is renamed from '%s', which is not a valid name", where, d.name)
+		return
+	}
+	if d.name == f.Name {
+		ctx.AddError("%s is renamed from its own name", where)
+		return
+	}
+	if f.RenamedFrom != "" {
+		ctx.AddError("%s has RenamedFrom defined multiple times", where)
+	}
+	f.RenamedFrom = d.name
+}
+
+var _ FieldItem = defFieldRenamedFrom{}
+var _ ModelFieldItem = defFieldRenamedFrom{}
+var _ StructFieldItem = defFieldRenamedFrom{}
+
+// RenamedFrom marks a field as renamed from name, so the next generated
+// migration renames its columns instead of dropping them and adding new
+// ones. Columns already renamed are left as is, so it can be removed once
+// the migration is generated.
+func RenamedFrom(name string) defFieldRenamedFrom {
+	return defFieldRenamedFrom{name: name}
+}
+
+// Anonymizations, see Anonymize.
+const (
+	AnonymizeHash = schema.AnonymizationHash
+	AnonymizeFake = schema.AnonymizationFake
+	AnonymizeNull = schema.AnonymizationNull
+)
+
+type defFieldAnonymize struct {
+	anonymization schema.Anonymization
+}
+
+func (d defFieldAnonymize) FieldItem() {}
+
+func (d defFieldAnonymize) ModelFieldItem(ctx *ModelFieldContext) {
+	d.apply(ctx, ctx.Field, fmt.Sprintf("Model '%s' field '%s'", ctx.Model.Name, ctx.Field.Name))
+}
+
+func (d defFieldAnonymize) StructFieldItem(ctx *StructFieldContext) {
+	d.apply(ctx, ctx.Field, fmt.Sprintf("Struct '%s' field '%s'", ctx.Struct.Name, ctx.Field.Name))
+}
+
+func (d defFieldAnonymize) apply(ctx Context, f *schema.Field, where string) {
+	if _, ok := f.Type.(schema.BaseType); !ok {
+		ctx.AddError("%s is anonymized, but its type '%s' is a struct, anonymize its fields instead", where, f.Type.GetName())
+		return
+	}
+	if f.Encryption != schema.EncryptionNone && d.anonymization != schema.AnonymizationNull {
+		ctx.AddError("%s is encrypted, it can only be anonymized with AnonymizeNull", where)
+	}
+	f.Anonymization = d.anonymization
+}
+
+var _ FieldItem = defFieldAnonymize{}
+var _ ModelFieldItem = defFieldAnonymize{}
+var _ StructFieldItem = defFieldAnonymize{}
+
+// Anonymize replaces the values of a field in the dumps of the "dump"
+// command, to refresh non-production environments from production data:
+// AnonymizeHash with their salted hash, which keeps equal values equal, like
+// the ones of foreign keys, AnonymizeFake with fake values of their type,
+// and AnonymizeNull with NULL. Text values are cut to the length of bounded
+// columns, like varchar(n), so short hashes of those may collide.
+func Anonymize(anonymization schema.Anonymization) defFieldAnonymize {
+	return defFieldAnonymize{anonymization: anonymization}
+}
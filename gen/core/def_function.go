@@ -0,0 +1,141 @@
+package core
+
+import (
+	"github.com/sqlbunny/sqlbunny/gen"
+	"github.com/sqlbunny/sqlbunny/schema"
+)
+
+// Function defines a SQL function, created by the migrations with the SQL
+// of Body in Language ("sql", the default, or "plpgsql"), and its generated
+// caller Call<Name>. Args are its arguments, with types of the schema. It
+// returns a value of the type Returns, which can be null with ReturnsNull,
+// the rows of the model ReturnsModel, or nothing. Volatility is "VOLATILE",
+// the default, "STABLE" or "IMMUTABLE". DependsOn are the models whose
+// tables it uses, so migrations create it after them:
+//
+//	Function{
+//		Name:        "compute_invoice_total",
+//		Args:        []FunctionArg{{Name: "invoice_id", Type: "invoice_id"}},
+//		Returns:     "int64",
+//		ReturnsNull: true,
+//		Volatility:  "STABLE",
+//		Body:        "SELECT sum(amount) FROM invoice_line WHERE invoice_id = $1",
+//		DependsOn:   []string{"invoice_line"},
+//	}
+//
+// A Procedure has no result, and runs with CALL. Functions are created after
+// the tables and before the views. Migrations drop and recreate functions
+// whose definition changes.
+type Function struct {
+	Name         string
+	Args         []FunctionArg
+	Returns      string
+	ReturnsNull  bool
+	ReturnsModel string
+	Procedure    bool
+	Language     string
+	Volatility   string
+	Body         string
+	DependsOn    []string
+}
+
+// FunctionArg is an argument of a Function, with the name of its type.
+type FunctionArg struct {
+	Name string
+	Type string
+}
+
+func (d Function) ConfigItem(ctx *gen.Context) {
+	ctx.Define("Function", d.Name)
+	if !identifierRgx.MatchString(d.Name) {
+		ctx.AddError("Function '%s' has an invalid name", d.Name)
+	}
+	if d.Body == "" {
+		ctx.AddError("Function '%s' has no body", d.Name)
+	}
+	language := d.Language
+	switch language {
+	case "":
+		language = "sql"
+	case "sql", "plpgsql":
+	default:
+		ctx.AddError("Function '%s' has invalid language '%s', it must be sql or plpgsql", d.Name, d.Language)
+	}
+	volatility := d.Volatility
+	switch volatility {
+	case "":
+		if !d.Procedure {
+			volatility = "VOLATILE"
+		}
+	case "VOLATILE", "STABLE", "IMMUTABLE":
+		if d.Procedure {
+			ctx.AddError("Function '%s' is a procedure, it can't have a volatility", d.Name)
+		}
+	default:
+		ctx.AddError("Function '%s' has invalid volatility '%s', it must be VOLATILE, STABLE or IMMUTABLE", d.Name, d.Volatility)
+	}
+	if d.Procedure && (d.Returns != "" || d.ReturnsModel != "") {
+		ctx.AddError("Function '%s' is a procedure, it can't return a result", d.Name)
+	}
+	if d.Returns != "" && d.ReturnsModel != "" {
+		ctx.AddError("Function '%s' can't have both Returns and ReturnsModel", d.Name)
+	}
+	if d.ReturnsNull && d.Returns == "" {
+		ctx.AddError("Function '%s' has ReturnsNull, but no Returns", d.Name)
+	}
+
+	f := &schema.Function{
+		Name:       d.Name,
+		Procedure:  d.Procedure,
+		Language:   language,
+		Volatility: volatility,
+		Body:       d.Body,
+		DependsOn:  d.DependsOn,
+	}
+	ctx.Schema.Functions[d.Name] = f
+
+	ctx.Enqueue(400, func() {
+		seen := map[string]bool{}
+		for _, a := range d.Args {
+			if !identifierRgx.MatchString(a.Name) || seen[a.Name] {
+				ctx.AddError("Function '%s' has invalid or repeated argument name '%s'", d.Name, a.Name)
+			}
+			seen[a.Name] = true
+			if t := d.baseType(ctx, a.Type, "argument '"+a.Name+"'"); t != nil {
+				f.Args = append(f.Args, &schema.FunctionArg{Name: a.Name, Type: t})
+			}
+		}
+		if d.Returns != "" {
+			f.Returns = d.baseType(ctx, d.Returns, "result")
+			f.ReturnsNull = d.ReturnsNull
+		}
+		if d.ReturnsModel != "" {
+			m, ok := ctx.Schema.Models[d.ReturnsModel]
+			if !ok {
+				ctx.AddError("Function '%s' returns unknown model '%s'", d.Name, d.ReturnsModel)
+			} else if !m.HasTable() {
+				ctx.AddError("Function '%s' returns model '%s', which has no table", d.Name, d.ReturnsModel)
+			} else {
+				f.ReturnsModel = m
+			}
+		}
+		for _, name := range d.DependsOn {
+			if _, ok := ctx.Schema.Models[name]; !ok {
+				ctx.AddError("Function '%s' depends on unknown model '%s'", d.Name, name)
+			}
+		}
+	})
+}
+
+// baseType returns the type with name, which must not be a struct.
+func (d Function) baseType(ctx *gen.Context, name string, what string) schema.BaseType {
+	t := ctx.GetType(name, "Function '"+d.Name+"' "+what)
+	if t == nil {
+		return nil
+	}
+	bt, ok := t.(schema.BaseType)
+	if !ok {
+		ctx.AddError("Function '%s' %s has type '%s', which is a struct", d.Name, what, name)
+	}
+	return bt
+}
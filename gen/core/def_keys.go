@@ -1,6 +1,12 @@
 package core
 
-import "github.com/sqlbunny/sqlbunny/schema"
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/sqlbunny/sqlbunny/schema"
+)
 
 type defModelPrimaryKey struct {
 	names []string
@@ -42,8 +48,58 @@ var PrimaryKey defFieldPrimaryKey = func(names ...string) defModelPrimaryKey {
 	return defModelPrimaryKey{names: names}
 }
 
+type defModelShardKey struct {
+	name string
+}
+
+func (d defModelShardKey) ModelItem(ctx *ModelContext)   {}
+func (d defModelShardKey) StructItem(ctx *StructContext) {}
+func (d defModelShardKey) ModelRecursiveItem(ctx *ModelRecursiveContext) {
+	m := ctx.Model
+	if m.ShardKey != nil {
+		ctx.AddError("Model '%s' has multiple shard key definitions", m.Name)
+	}
+	m.ShardKey = parsePathPrefix(ctx, ctx.Prefix, d.name)
+}
+
+var _ ModelItem = defModelShardKey{}
+var _ StructItem = defModelShardKey{}
+var _ ModelRecursiveItem = defModelShardKey{}
+
+type defFieldShardKey func(string) defModelShardKey
+
+func (d defFieldShardKey) FieldItem() {}
+func (d defFieldShardKey) ModelRecursiveFieldItem(ctx *ModelRecursiveFieldContext) {
+	m := ctx.Model
+	if m.ShardKey != nil {
+		ctx.AddError("Model '%s' has multiple shard key definitions", m.Name)
+	}
+	m.ShardKey = parsePathPrefix(ctx, ctx.Prefix, ctx.Field.Name)
+}
+
+var _ FieldItem = defFieldShardKey(nil)
+var _ ModelRecursiveFieldItem = defFieldShardKey(nil)
+
+// ShardKey shards the model by the field, as a field item or with the name
+// of the field as a model item: its rows are stored in the shard selected by
+// the field's value, see bunny.ContextWithShards. The generated code routes
+// the statements of the objects by their shard key, and Find by its
+// argument if the shard key is in the primary key; the other queries run in
+// the shard of the context or of their qm.ShardKey mod, and AllShards reads
+// from all of them.
+var ShardKey defFieldShardKey = func(name string) defModelShardKey {
+	return defModelShardKey{name: name}
+}
+
 type defModelIndex struct {
-	names []string
+	names   []string
+	storage *Storage
+}
+
+// WithStorage sets the storage parameters and tablespace of the index.
+func (d defModelIndex) WithStorage(s Storage) defModelIndex {
+	d.storage = &s
+	return d
 }
 
 func (d defModelIndex) ModelItem(ctx *ModelContext)   {}
@@ -51,9 +107,30 @@ func (d defModelIndex) StructItem(ctx *StructContext) {}
 
 func (d defModelIndex) ModelRecursiveItem(ctx *ModelRecursiveContext) {
 	m := ctx.Model
-	m.Indexes = append(m.Indexes, &schema.Index{
-		Fields: parsePathsPrefix(ctx, ctx.Prefix, d.names),
-	})
+	idx := &schema.Index{}
+	if d.storage != nil {
+		idx.Storage = d.storage.schemaStorage(ctx.AddError, fmt.Sprintf("Model '%s' index", m.Name))
+	}
+	for i, name := range d.names {
+		name, options := name, ""
+		if j := strings.IndexByte(name, ' '); j != -1 {
+			name, options = name[:j], name[j+1:]
+		}
+		idx.Fields = append(idx.Fields, parsePathPrefix(ctx, ctx.Prefix, name))
+		options, err := parseIndexOptions(options)
+		if err != nil {
+			ctx.AddError("Model '%s' index field '%s' %s", m.Name, name, err)
+		}
+		if options == "" {
+			continue
+		}
+		if idx.Options == nil {
+			idx.Options = make([]string, len(d.names))
+		}
+		idx.Options[i] = options
+	}
+	idx.Fields, idx.Options = expandStructPaths(m, idx.Fields, idx.Options)
+	m.Indexes = append(m.Indexes, idx)
 }
 
 var _ ModelItem = defModelIndex{}
@@ -66,28 +143,113 @@ func (d defFieldIndex) FieldItem() {}
 
 func (d defFieldIndex) ModelRecursiveFieldItem(ctx *ModelRecursiveFieldContext) {
 	m := ctx.Model
+	fields, _ := expandStructPaths(m, []schema.Path{parsePathPrefix(ctx, ctx.Prefix, ctx.Field.Name)}, nil)
 	m.Indexes = append(m.Indexes, &schema.Index{
-		Fields: []schema.Path{parsePathPrefix(ctx, ctx.Prefix, ctx.Field.Name)},
+		Fields: fields,
 	})
 }
 
+// expandStructPaths replaces the paths of struct fields of the model by the
+// paths of their columns, so indexes and uniques can name a whole struct
+// field: the paths of the fields of the struct, recursively, followed by
+// the path of the field itself if it's nullable, whose column tells if it's
+// null. The options of a struct field apply to all its columns.
+func expandStructPaths(m *schema.Model, paths []schema.Path, options []string) ([]schema.Path, []string) {
+	var resPaths []schema.Path
+	var resOptions []string
+	var expand func(p schema.Path, f *schema.Field, option string)
+	expand = func(p schema.Path, f *schema.Field, option string) {
+		if s, ok := f.Type.(*schema.Struct); ok {
+			for _, f2 := range s.Fields {
+				expand(append(p[:len(p):len(p)], f2.Name), f2, option)
+			}
+			if !f.Nullable {
+				return
+			}
+		}
+		resPaths = append(resPaths, p)
+		resOptions = append(resOptions, option)
+	}
+	for i, p := range paths {
+		var option string
+		if options != nil {
+			option = options[i]
+		}
+		if f := m.FindField(p); f != nil {
+			expand(p, f, option)
+		} else {
+			resPaths = append(resPaths, p)
+			resOptions = append(resOptions, option)
+		}
+	}
+	if options == nil {
+		resOptions = nil
+	}
+	return resPaths, resOptions
+}
+
 var _ FieldItem = defFieldIndex(nil)
 var _ ModelRecursiveFieldItem = defFieldIndex(nil)
 
+var opClassRgx = regexp.MustCompile(`^[a-z_][a-z0-9_]*$`)
+
+// parseIndexOptions parses the options of an index field: an operator class,
+// then ASC or DESC, then NULLS FIRST or NULLS LAST, all optional. It returns
+// them with the keywords in upper case.
+func parseIndexOptions(s string) (string, error) {
+	toks := strings.Fields(s)
+	var res []string
+	if len(toks) != 0 && opClassRgx.MatchString(toks[0]) {
+		switch toks[0] {
+		case "asc", "desc", "nulls":
+		default:
+			res = append(res, toks[0])
+			toks = toks[1:]
+		}
+	}
+	if len(toks) != 0 {
+		switch t := strings.ToUpper(toks[0]); t {
+		case "ASC", "DESC":
+			res = append(res, t)
+			toks = toks[1:]
+		}
+	}
+	if len(toks) >= 2 && strings.ToUpper(toks[0]) == "NULLS" {
+		switch t := strings.ToUpper(toks[1]); t {
+		case "FIRST", "LAST":
+			res = append(res, "NULLS", t)
+			toks = toks[2:]
+		}
+	}
+	if len(toks) != 0 {
+		return "", fmt.Errorf("has invalid options '%s', they must be an operator class, ASC or DESC, and NULLS FIRST or NULLS LAST", s)
+	}
+	return strings.Join(res, " "), nil
+}
+
+// Index defines an index on the field, or on the fields with the given names
+// as a model item. Struct fields are indexed by all their columns.
+//
+// Each name can be followed by the B-tree operator class of the field, like
+// Index("name varchar_pattern_ops") for LIKE 'prefix%' queries, and by its
+// order, like Index("created_at DESC NULLS LAST").
 var Index defFieldIndex = func(names ...string) defModelIndex {
 	return defModelIndex{names: names}
 }
 
 type defModelUnique struct {
 	names []string
+	index bool
 }
 
 func (d defModelUnique) ModelItem(ctx *ModelContext)   {}
 func (d defModelUnique) StructItem(ctx *StructContext) {}
 func (d defModelUnique) ModelRecursiveItem(ctx *ModelRecursiveContext) {
 	m := ctx.Model
+	fields, _ := expandStructPaths(m, parsePathsPrefix(ctx, ctx.Prefix, d.names), nil)
 	m.Uniques = append(m.Uniques, &schema.Unique{
-		Fields: parsePathsPrefix(ctx, ctx.Prefix, d.names),
+		Fields: fields,
+		Index:  d.index,
 	})
 }
 
@@ -100,8 +262,9 @@ type defFieldUnique func(...string) defModelUnique
 func (d defFieldUnique) FieldItem() {}
 func (d defFieldUnique) ModelRecursiveFieldItem(ctx *ModelRecursiveFieldContext) {
 	m := ctx.Model
+	fields, _ := expandStructPaths(m, []schema.Path{parsePathPrefix(ctx, ctx.Prefix, ctx.Field.Name)}, nil)
 	m.Uniques = append(m.Uniques, &schema.Unique{
-		Fields: []schema.Path{parsePathPrefix(ctx, ctx.Prefix, ctx.Field.Name)},
+		Fields: fields,
 	})
 }
 
@@ -112,6 +275,29 @@ var Unique defFieldUnique = func(names ...string) defModelUnique {
 	return defModelUnique{names: names}
 }
 
+type defFieldUniqueIndex func(...string) defModelUnique
+
+func (d defFieldUniqueIndex) FieldItem() {}
+func (d defFieldUniqueIndex) ModelRecursiveFieldItem(ctx *ModelRecursiveFieldContext) {
+	m := ctx.Model
+	fields, _ := expandStructPaths(m, []schema.Path{parsePathPrefix(ctx, ctx.Prefix, ctx.Field.Name)}, nil)
+	m.Uniques = append(m.Uniques, &schema.Unique{
+		Fields: fields,
+		Index:  true,
+	})
+}
+
+var _ FieldItem = defFieldUniqueIndex(nil)
+var _ ModelRecursiveFieldItem = defFieldUniqueIndex(nil)
+
+// UniqueIndex is like Unique, but enforces the uniqueness with a unique index
+// instead of a UNIQUE constraint. Both can be upsert conflict targets by
+// their fields, but only constraints can be named with ON CONSTRAINT, so use
+// Unique for those and for the fields referenced by foreign keys.
+var UniqueIndex defFieldUniqueIndex = func(names ...string) defModelUnique {
+	return defModelUnique{names: names, index: true}
+}
+
 type defModelForeignKey struct {
 	foreignModelName   string
 	columnNames        []string
@@ -122,11 +308,20 @@ func (d defModelForeignKey) ModelItem(ctx *ModelContext) {}
 func (d defModelForeignKey) ModelRecursiveItem(ctx *ModelRecursiveContext) {
 	m := ctx.Model
 	m.ForeignKeys = append(m.ForeignKeys, &schema.ForeignKey{
-		LocalFields:  parsePathsPrefix(ctx, ctx.Prefix, d.columnNames),
-		ForeignModel: d.foreignModelName,
+		LocalFields:   parsePathsPrefix(ctx, ctx.Prefix, d.columnNames),
+		ForeignModel:  d.foreignModelName,
+		ForeignFields: parseForeignFields(ctx, d.foreignColumnNames),
 	})
 }
 
+// References makes the foreign key reference the fields of the foreign
+// model, which must be its primary key or one of its uniques, instead of
+// its primary key.
+func (d defModelForeignKey) References(foreignColumnNames ...string) defModelForeignKey {
+	d.foreignColumnNames = foreignColumnNames
+	return d
+}
+
 var _ ModelItem = defModelForeignKey{}
 var _ ModelRecursiveItem = defModelForeignKey{}
 
@@ -134,23 +329,48 @@ func ModelForeignKey(foreignModelName string, columnNames ...string) defModelFor
 	return defModelForeignKey{
 		foreignModelName:   foreignModelName,
 		columnNames:        columnNames,
-		foreignColumnNames: nil, // Autofill with the foreign model's primary key
+		foreignColumnNames: nil, // Autofill with the foreign model's primary key, see References
 	}
 }
 
 type defFieldForeignKey struct {
-	foreignModelName string
+	foreignModelName  string
+	foreignColumnName string
 }
 
 func (d defFieldForeignKey) FieldItem() {}
 func (d defFieldForeignKey) ModelRecursiveFieldItem(ctx *ModelRecursiveFieldContext) {
 	m := ctx.Model
+	var foreignColumnNames []string
+	if d.foreignColumnName != "" {
+		foreignColumnNames = []string{d.foreignColumnName}
+	}
 	m.ForeignKeys = append(m.ForeignKeys, &schema.ForeignKey{
-		LocalFields:  []schema.Path{parsePathPrefix(ctx, ctx.Prefix, ctx.Field.Name)},
-		ForeignModel: d.foreignModelName,
+		LocalFields:   []schema.Path{parsePathPrefix(ctx, ctx.Prefix, ctx.Field.Name)},
+		ForeignModel:  d.foreignModelName,
+		ForeignFields: parseForeignFields(ctx, foreignColumnNames),
 	})
 }
 
+// References makes the foreign key reference the field of the foreign
+// model, which must be its primary key or unique, instead of its primary
+// key:
+//
+//	Field("author_email", "string", ForeignKey("user").References("email"))
+func (d defFieldForeignKey) References(foreignColumnName string) defFieldForeignKey {
+	d.foreignColumnName = foreignColumnName
+	return d
+}
+
+// parseForeignFields returns the paths of the foreign fields of a foreign
+// key, or nil for the primary key of the foreign model.
+func parseForeignFields(ctx Context, names []string) []schema.Path {
+	if names == nil {
+		return nil
+	}
+	return parsePathsPrefix(ctx, nil, names)
+}
+
 var _ FieldItem = defFieldForeignKey{}
 var _ ModelRecursiveFieldItem = defFieldForeignKey{}
 
@@ -1,7 +1,12 @@
 package core
 
 import (
+	"regexp"
+	"sort"
+	"strings"
+
 	"github.com/sqlbunny/sqlbunny/gen"
+	"github.com/sqlbunny/sqlbunny/runtime/strmangle"
 	"github.com/sqlbunny/sqlbunny/schema"
 )
 
@@ -11,6 +16,12 @@ type ModelContext struct {
 	Model *schema.Model
 }
 
+// AddError adds an error prefixed with the position of the definition of
+// the model.
+func (ctx *ModelContext) AddError(message string, args ...interface{}) {
+	addErrorAt(ctx.Context, ctx.Model, message, args...)
+}
+
 type ModelRecursiveContext struct {
 	*gen.Context
 
@@ -19,6 +30,12 @@ type ModelRecursiveContext struct {
 	ForceNullable bool
 }
 
+// AddError adds an error prefixed with the position of the definition of
+// the model.
+func (ctx *ModelRecursiveContext) AddError(message string, args ...interface{}) {
+	addErrorAt(ctx.Context, ctx.Model, message, args...)
+}
+
 type ModelItem interface {
 	ModelItem(ctx *ModelContext)
 }
@@ -30,35 +47,38 @@ type ModelRecursiveItem interface {
 type defModel struct {
 	name  string
 	items []ModelItem
+	pos   string
 }
 
 func (d defModel) ConfigItem(ctx *gen.Context) {
+	ctx.Define("Model", d.name)
 	ctx.Enqueue(200, func() {
-		if _, ok := ctx.Schema.Models[d.name]; ok {
-			ctx.AddError("Model '%s' is defined multiple times", d.name)
-		}
 		model := &schema.Model{
 			Name: d.name,
 		}
+		model.SetExtension(defPosExt{}, d.pos)
 		ctx.Schema.Models[d.name] = model
+		defineModelItems(ctx, model, d.items)
+	})
+}
 
-		for _, i := range d.items {
-			i.ModelItem(&ModelContext{
-				Context: ctx,
-				Model:   model,
-			})
-		}
-
-		ctx.Enqueue(300, func() {
-			for _, i := range d.items {
-				if i, ok := i.(ModelRecursiveItem); ok {
-					i.ModelRecursiveItem(&ModelRecursiveContext{
-						Context: ctx,
-						Model:   model,
-					})
-				}
-			}
+func defineModelItems(ctx *gen.Context, model *schema.Model, items []ModelItem) {
+	for _, i := range items {
+		i.ModelItem(&ModelContext{
+			Context: ctx,
+			Model:   model,
 		})
+	}
+
+	ctx.Enqueue(300, func() {
+		for _, i := range items {
+			if i, ok := i.(ModelRecursiveItem); ok {
+				i.ModelRecursiveItem(&ModelRecursiveContext{
+					Context: ctx,
+					Model:   model,
+				})
+			}
+		}
 	})
 }
 
@@ -66,5 +86,616 @@ func Model(name string, items ...ModelItem) gen.ConfigItem {
 	return defModel{
 		name:  name,
 		items: items,
+		pos:   callerPos(),
+	}
+}
+
+type defExtendModel struct {
+	name  string
+	items []ModelItem
+}
+
+func (d defExtendModel) ConfigItem(ctx *gen.Context) {
+	ctx.Enqueue(210, func() {
+		model, ok := ctx.Schema.Models[d.name]
+		if !ok {
+			ctx.AddError("Model '%s' is extended, but it's not defined", d.name)
+			return
+		}
+		defineModelItems(ctx, model, d.items)
+	})
+}
+
+// ExtendModel adds the items to the model with name, defined in another
+// module, like the fields a service adds to a shared model:
+//
+//	Module("orders",
+//		Module("billing", billing.Schema()...),
+//		ExtendModel("invoice",
+//			Field("order_id", "order_id", ForeignKey("order")),
+//		),
+//	)
+func ExtendModel(name string, items ...ModelItem) gen.ConfigItem {
+	return defExtendModel{
+		name:  name,
+		items: items,
+	}
+}
+
+type defModule struct {
+	name  string
+	items []gen.ConfigItem
+}
+
+func (d defModule) ConfigItem(ctx *gen.Context) {
+	ctx.InModule(d.name, func() {
+		for _, i := range d.items {
+			i.ConfigItem(ctx)
+		}
+	})
+}
+
+// Module groups the types and models of a package, like a schema library
+// shared by several services. A module included more than once, like one
+// imported by two other modules, is defined once. Types and models can't
+// be defined by more than one module; ExtendModel adds items to the models
+// of other modules.
+func Module(name string, items ...gen.ConfigItem) gen.ConfigItem {
+	return defModule{
+		name:  name,
+		items: items,
+	}
+}
+
+type defDefaultScope struct {
+	where string
+}
+
+func (d defDefaultScope) ModelItem(ctx *ModelContext) {
+	if ctx.Model.DefaultScope != "" {
+		ctx.AddError("Model '%s' has DefaultScope defined multiple times", ctx.Model.Name)
 	}
+	ctx.Model.DefaultScope = d.where
+}
+
+// DefaultScope filters the rows of the model seen by the generated queries,
+// finders and eager loads with the where clause, like "deleted_at IS NULL".
+// The clause can't have placeholders, and its columns must be unambiguous
+// in the joins of relationships through join models. The qm.Unscoped mod
+// removes the filter from a query.
+func DefaultScope(where string) ModelItem {
+	return defDefaultScope{
+		where: where,
+	}
+}
+
+type defDefaultOrderBy struct {
+	orderBy string
+}
+
+func (d defDefaultOrderBy) ModelItem(ctx *ModelContext) {
+	if ctx.Model.DefaultOrderBy != "" {
+		ctx.AddError("Model '%s' has DefaultOrderBy defined multiple times", ctx.Model.Name)
+	}
+	ctx.Model.DefaultOrderBy = d.orderBy
+}
+
+// DefaultOrderBy orders the rows of the generated queries and eager loads of
+// the model with the order by clause, like "created_at DESC, id DESC", unless
+// they have an explicit ordering. Counts, and distinct, grouped or aggregated
+// queries aren't ordered. Like DefaultScope, the columns must be unambiguous
+// in the joins of relationships through join models.
+func DefaultOrderBy(orderBy string) ModelItem {
+	return defDefaultOrderBy{
+		orderBy: orderBy,
+	}
+}
+
+var (
+	storageParamRgx = regexp.MustCompile(`^[a-z_][a-z0-9_.]*$`)
+	storageValueRgx = regexp.MustCompile(`^[a-zA-Z0-9_.]+$`)
+	identifierRgx   = regexp.MustCompile(`^[a-z_][a-z0-9_]*$`)
+)
+
+// Storage sets the storage parameters and the tablespace of the table of the
+// model, like the fillfactor and autovacuum settings of append-heavy tables:
+//
+//	Model("event",
+//		Field("id", "event_id", PrimaryKey),
+//		Field("created_at", "time"),
+//		Storage{
+//			Params: map[string]string{
+//				"fillfactor":                     "100",
+//				"autovacuum_vacuum_scale_factor": "0.01",
+//			},
+//			Tablespace: "bulk",
+//		},
+//		Index("created_at").WithStorage(Storage{Tablespace: "fast"}),
+//	)
+//
+// Migrations change the storage with ALTER TABLE and ALTER INDEX after the
+// tables and indexes are created. Parameters removed from Params are reset,
+// and removing Tablespace moves the table back to the default tablespace.
+type Storage struct {
+	Params     map[string]string
+	Tablespace string
+}
+
+func (d Storage) ModelItem(ctx *ModelContext) {
+	if ctx.Model.Storage != nil {
+		ctx.AddError("Model '%s' has Storage defined multiple times", ctx.Model.Name)
+	}
+	ctx.Model.Storage = d.schemaStorage(ctx.AddError, "Model '"+ctx.Model.Name+"'")
+}
+
+func (d Storage) schemaStorage(addError func(string, ...interface{}), desc string) *schema.Storage {
+	for k, v := range d.Params {
+		if !storageParamRgx.MatchString(k) || !storageValueRgx.MatchString(v) {
+			addError("%s has invalid storage parameter '%s = %s'", desc, k, v)
+		}
+	}
+	if d.Tablespace != "" && !identifierRgx.MatchString(d.Tablespace) {
+		addError("%s has invalid tablespace '%s'", desc, d.Tablespace)
+	}
+	return &schema.Storage{
+		Params:     d.Params,
+		Tablespace: d.Tablespace,
+	}
+}
+
+// Trigger defines a trigger on the table of the model, running Timing
+// ("BEFORE", "AFTER" or "INSTEAD OF") the Events ("INSERT", "UPDATE",
+// "DELETE" or "TRUNCATE") for each row, or for each statement with
+// Statement. UpdateFields restricts UPDATE triggers to updates of the
+// fields, and When to the rows matching the condition.
+//
+// The trigger executes Function, a call like "set_updated_at()", or the
+// PL/pgSQL function with Body, created with the trigger:
+//
+//	Trigger{
+//		Name:   "set_updated_at",
+//		Timing: "BEFORE",
+//		Events: []string{"UPDATE"},
+//		Body:   "NEW.updated_at = now(); RETURN NEW;",
+//	}
+//
+// Migrations drop and recreate triggers whose definition changes.
+type Trigger struct {
+	Name         string
+	Timing       string
+	Events       []string
+	UpdateFields []string
+	Statement    bool
+	When         string
+	Function     string
+	Body         string
+}
+
+func (d Trigger) ModelItem(ctx *ModelContext) {}
+
+func (d Trigger) ModelRecursiveItem(ctx *ModelRecursiveContext) {
+	m := ctx.Model
+	if !identifierRgx.MatchString(d.Name) {
+		ctx.AddError("Model '%s' trigger '%s' has an invalid name", m.Name, d.Name)
+	}
+	switch d.Timing {
+	case "BEFORE", "AFTER", "INSTEAD OF":
+	default:
+		ctx.AddError("Model '%s' trigger '%s' has invalid timing '%s', it must be BEFORE, AFTER or INSTEAD OF", m.Name, d.Name, d.Timing)
+	}
+	if len(d.Events) == 0 {
+		ctx.AddError("Model '%s' trigger '%s' has no events", m.Name, d.Name)
+	}
+	update := false
+	for _, e := range d.Events {
+		switch e {
+		case "UPDATE":
+			update = true
+		case "INSERT", "DELETE", "TRUNCATE":
+		default:
+			ctx.AddError("Model '%s' trigger '%s' has invalid event '%s', it must be INSERT, UPDATE, DELETE or TRUNCATE", m.Name, d.Name, e)
+		}
+	}
+	if len(d.UpdateFields) != 0 && !update {
+		ctx.AddError("Model '%s' trigger '%s' has UpdateFields, but no UPDATE event", m.Name, d.Name)
+	}
+	if (d.Function == "") == (d.Body == "") {
+		ctx.AddError("Model '%s' trigger '%s' must have exactly one of Function or Body", m.Name, d.Name)
+	}
+
+	m.Triggers = append(m.Triggers, &schema.Trigger{
+		Name:         d.Name,
+		Timing:       d.Timing,
+		Events:       d.Events,
+		UpdateFields: parsePathsPrefix(ctx, ctx.Prefix, d.UpdateFields),
+		Statement:    d.Statement,
+		When:         d.When,
+		Function:     d.Function,
+		Body:         d.Body,
+	})
+}
+
+// RowLevelSecurity enables the row-level security of the table of the model,
+// so its rows are only visible and changeable through its policies, defined
+// with Policy. With Force, the policies also apply to the owner of the
+// table, which the application usually connects as.
+type RowLevelSecurity struct {
+	Force bool
+}
+
+func (d RowLevelSecurity) ModelItem(ctx *ModelContext) {
+	if ctx.Model.RowLevelSecurity {
+		ctx.AddError("Model '%s' has RowLevelSecurity defined multiple times", ctx.Model.Name)
+	}
+	ctx.Model.RowLevelSecurity = true
+	ctx.Model.ForceRowLevelSecurity = d.Force
+}
+
+// Policy defines a row-level security policy on the table of the model, for
+// Command ("ALL", the default, "SELECT", "INSERT", "UPDATE" or "DELETE")
+// and Roles, or all roles. Using filters the rows the command sees, and
+// WithCheck checks the rows it writes. The expressions usually compare the
+// columns to settings set with bunny.WithSetting:
+//
+//	RowLevelSecurity{Force: true},
+//	Policy{
+//		Name:  "tenant_isolation",
+//		Using: "tenant_id = current_setting('app.tenant_id')",
+//	},
+//
+// A row passes the policies if it passes any of them and all the
+// Restrictive ones. Migrations drop and recreate policies whose definition
+// changes.
+type Policy struct {
+	Name        string
+	Restrictive bool
+	Command     string
+	Roles       []string
+	Using       string
+	WithCheck   string
+}
+
+func (d Policy) ModelItem(ctx *ModelContext) {
+	m := ctx.Model
+	if !identifierRgx.MatchString(d.Name) {
+		ctx.AddError("Model '%s' policy '%s' has an invalid name", m.Name, d.Name)
+	}
+	switch d.Command {
+	case "", "ALL", "SELECT", "INSERT", "UPDATE", "DELETE":
+	default:
+		ctx.AddError("Model '%s' policy '%s' has invalid command '%s', it must be ALL, SELECT, INSERT, UPDATE or DELETE", m.Name, d.Name, d.Command)
+	}
+	if d.Using == "" && d.WithCheck == "" {
+		ctx.AddError("Model '%s' policy '%s' must have Using or WithCheck", m.Name, d.Name)
+	}
+	for _, p := range m.Policies {
+		if p.Name == d.Name {
+			ctx.AddError("Model '%s' policy '%s' is defined multiple times", m.Name, d.Name)
+		}
+	}
+	m.Policies = append(m.Policies, &schema.Policy{
+		Name:        d.Name,
+		Restrictive: d.Restrictive,
+		Command:     d.Command,
+		Roles:       d.Roles,
+		Using:       d.Using,
+		WithCheck:   d.WithCheck,
+	})
+}
+
+type defGrant struct {
+	role       string
+	privileges []string
+}
+
+func (d defGrant) ConfigItem(ctx *gen.Context) {
+	d.check(ctx.AddError, "Grant")
+	ctx.Enqueue(400, func() {
+		for _, m := range ctx.Schema.Models {
+			d.grant(m)
+		}
+	})
+}
+
+func (d defGrant) ModelItem(ctx *ModelContext) {
+	d.check(ctx.AddError, "Model '"+ctx.Model.Name+"' grant")
+	d.grant(ctx.Model)
+}
+
+func (d defGrant) check(addError func(string, ...interface{}), desc string) {
+	if d.role != "PUBLIC" && !identifierRgx.MatchString(d.role) {
+		addError("%s has invalid role '%s'", desc, d.role)
+	}
+	if len(d.privileges) == 0 {
+		addError("%s to '%s' has no privileges", desc, d.role)
+	}
+	for _, p := range d.privileges {
+		switch p {
+		case "ALL", "SELECT", "INSERT", "UPDATE", "DELETE", "TRUNCATE", "REFERENCES", "TRIGGER":
+		default:
+			addError("%s to '%s' has invalid privilege '%s'", desc, d.role, p)
+		}
+	}
+}
+
+func (d defGrant) grant(m *schema.Model) {
+	var g *schema.Grant
+	for _, g2 := range m.Grants {
+		if g2.Role == d.role {
+			g = g2
+		}
+	}
+	if g == nil {
+		g = &schema.Grant{Role: d.role}
+		m.Grants = append(m.Grants, g)
+	}
+	for _, p := range d.privileges {
+		if !strmangle.SetInclude(p, g.Privileges) {
+			g.Privileges = append(g.Privileges, p)
+		}
+	}
+}
+
+// Grant grants the privileges ("ALL", "SELECT", "INSERT", "UPDATE",
+// "DELETE", "TRUNCATE", "REFERENCES" or "TRIGGER") on the table of the
+// model to the role, or "PUBLIC". Outside models it grants them on the
+// tables of all the models, so new tables get them too:
+//
+//	Grant("reporting", "SELECT"),
+//	Model("payment",
+//		Field("id", "payment_id", PrimaryKey),
+//		Grant("billing", "ALL"),
+//	),
+//
+// Migrations grant the new privileges and revoke the removed ones.
+func Grant(role string, privileges ...string) defGrant {
+	return defGrant{
+		role:       role,
+		privileges: privileges,
+	}
+}
+
+// ForeignTable makes the table of the model a foreign table, whose rows are
+// read and written through the foreign data wrapper of Server, like a
+// postgres_fdw link to another database. Options are the options of the
+// wrapper, and Columns maps fields to the names of their columns in the
+// remote table, if they're different:
+//
+//	Model("legacy_user",
+//		ForeignTable{
+//			Server:  "legacy",
+//			Options: map[string]string{"schema_name": "public", "table_name": "users"},
+//			Columns: map[string]string{"name": "full_name"},
+//		},
+//		Field("id", "int64", PrimaryKey),
+//		Field("name", "string"),
+//	),
+//
+// The server and its user mappings are created outside the migrations,
+// like with RawSQL. Foreign tables have no constraints, so the primary key,
+// unique constraints and foreign keys of the model and the foreign keys
+// referencing it are only used by the generated code. Migrations drop and
+// recreate foreign tables whose definition changes.
+type ForeignTable struct {
+	Server  string
+	Options map[string]string
+	Columns map[string]string
+}
+
+func (d ForeignTable) ModelItem(ctx *ModelContext) {
+	m := ctx.Model
+	if m.ForeignTable != nil {
+		ctx.AddError("Model '%s' has ForeignTable defined multiple times", m.Name)
+	}
+	if !identifierRgx.MatchString(d.Server) {
+		ctx.AddError("Model '%s' foreign table has invalid server '%s'", m.Name, d.Server)
+	}
+	for k := range d.Options {
+		if !identifierRgx.MatchString(k) {
+			ctx.AddError("Model '%s' foreign table has invalid option '%s'", m.Name, k)
+		}
+	}
+
+	t := &schema.ForeignTable{
+		Server:  d.Server,
+		Options: d.Options,
+	}
+	fields := make([]string, 0, len(d.Columns))
+	for f := range d.Columns {
+		fields = append(fields, f)
+	}
+	sort.Strings(fields)
+	for _, f := range fields {
+		t.Columns = append(t.Columns, &schema.ForeignColumn{
+			Field:      parsePathPrefix(ctx, nil, f),
+			RemoteName: d.Columns[f],
+		})
+	}
+	m.ForeignTable = t
+}
+
+// View makes the table of the model a view, whose rows are the rows of
+// Query. DependsOn are the models the query reads, so migrations create
+// the view after their tables, and recreate it when they change. A view
+// with CheckOption "LOCAL" or "CASCADED" rejects writes of rows it doesn't
+// show.
+//
+// The generated code writes to the view like to a table, which Postgres
+// supports for simple views of a single table. For other views, Table sets
+// the model whose table is written by generated INSTEAD OF triggers, with
+// Columns mapping fields to its columns if they're named differently:
+//
+//	Model("customer",
+//		View{
+//			Query:   `SELECT id, name AS full_name FROM account`,
+//			Table:   "account",
+//			Columns: map[string]string{"full_name": "name"},
+//		},
+//		Field("id", "account_id", PrimaryKey),
+//		Field("full_name", "string"),
+//	),
+//
+// The triggers write all the fields, and update and delete the rows by the
+// primary key. Views have no constraints, so the primary key, unique
+// constraints and foreign keys are only used by the generated code. Upserts
+// aren't supported on views with triggers. Migrations drop and recreate
+// views whose definition changes.
+type View struct {
+	Query       string
+	CheckOption string
+	DependsOn   []string
+	Table       string
+	Columns     map[string]string
+}
+
+func (d View) ModelItem(ctx *ModelContext) {
+	m := ctx.Model
+	if m.View != nil {
+		ctx.AddError("Model '%s' has View defined multiple times", m.Name)
+	}
+	if d.Query == "" {
+		ctx.AddError("Model '%s' view has no query", m.Name)
+	}
+	switch d.CheckOption {
+	case "", "LOCAL", "CASCADED":
+	default:
+		ctx.AddError("Model '%s' view has invalid check option '%s', it must be LOCAL or CASCADED", m.Name, d.CheckOption)
+	}
+	if len(d.Columns) != 0 && d.Table == "" {
+		ctx.AddError("Model '%s' view has Columns, but no Table", m.Name)
+	}
+
+	v := &schema.View{
+		Query:       d.Query,
+		CheckOption: d.CheckOption,
+		DependsOn:   d.DependsOn,
+		Table:       d.Table,
+	}
+	if d.Table != "" && !strmangle.SetInclude(d.Table, v.DependsOn) {
+		v.DependsOn = append(append([]string(nil), v.DependsOn...), d.Table)
+	}
+	fields := make([]string, 0, len(d.Columns))
+	for f := range d.Columns {
+		fields = append(fields, f)
+	}
+	sort.Strings(fields)
+	for _, f := range fields {
+		v.Columns = append(v.Columns, &schema.ViewColumn{
+			Field:       parsePathPrefix(ctx, nil, f),
+			TableColumn: d.Columns[f],
+		})
+	}
+	m.View = v
+}
+
+type defIDPrefix struct {
+	prefix string
+}
+
+func (d defIDPrefix) ModelItem(ctx *ModelContext) {
+	m := ctx.Model
+	if d.prefix == "" || strings.ContainsAny(d.prefix, "_ ") {
+		ctx.AddError("Model '%s' has invalid ID prefix '%s', it must be non empty and have no underscores", m.Name, d.prefix)
+		return
+	}
+
+	// The primary key is known once the recursive items are defined.
+	ctx.Enqueue(400, func() {
+		var t *schema.ID
+		if m.PrimaryKey != nil && len(m.PrimaryKey.Fields) == 1 {
+			if f := m.FindField(m.PrimaryKey.Fields[0]); f != nil {
+				t, _ = f.Type.(*schema.ID)
+			}
+		}
+		if t == nil {
+			ctx.AddError("Model '%s' has IDPrefix, but its primary key isn't a single PrefixedID field", m.Name)
+			return
+		}
+		if t.Model != "" {
+			ctx.AddError("Models '%s' and '%s' both have the ID type '%s' as primary key, each needs its own", t.Model, m.Name, t.Name)
+			return
+		}
+		t.Model = m.Name
+		t.Prefix = d.prefix
+	})
+}
+
+// IDPrefix sets the prefix of the external form of the IDs of the model,
+// like "user" for "user_kwy423jvf962v3ghe0xa4np90g". The primary key of the
+// model must be a single field of a PrefixedID type, which only this model
+// uses as its primary key. Prefixes must be unique across models.
+func IDPrefix(prefix string) ModelItem {
+	return defIDPrefix{
+		prefix: prefix,
+	}
+}
+
+type defHistory struct{}
+
+func (d defHistory) ModelItem(ctx *ModelContext) {
+	m := ctx.Model
+	if m.History != nil {
+		ctx.AddError("Model '%s' has History defined multiple times", m.Name)
+		return
+	}
+	h := &schema.Model{
+		Name: m.Name + "_history",
+	}
+	h.SetExtension(defPosExt{}, m.GetExtension(defPosExt{}))
+	ctx.Define("Model", h.Name)
+	ctx.Schema.Models[h.Name] = h
+	m.History = h
+
+	ctx.Enqueue(350, func() {
+		for _, f := range m.Fields {
+			f2 := &schema.Field{
+				Name:       f.Name,
+				Type:       f.Type,
+				Nullable:   f.Nullable,
+				Encryption: f.Encryption,
+				Tags:       schema.Tags{},
+			}
+			for k, v := range f.Tags {
+				f2.Tags[k] = v
+			}
+			f2.SetExtension(defPosExt{}, f.GetExtension(defPosExt{}))
+			h.Fields = append(h.Fields, f2)
+		}
+		where := "Model '" + m.Name + "' history"
+		h.Fields = append(h.Fields,
+			&schema.Field{
+				Name: schema.HistoryValidFrom,
+				Type: ctx.GetType("time", where),
+				Tags: schema.Tags{},
+			},
+			&schema.Field{
+				Name:     schema.HistoryValidTo,
+				Type:     ctx.GetType("time", where),
+				Nullable: true,
+				Tags:     schema.Tags{},
+			},
+		)
+		if m.PrimaryKey != nil {
+			h.PrimaryKey = &schema.PrimaryKey{
+				Fields: append(append([]schema.Path(nil), m.PrimaryKey.Fields...), schema.Path{schema.HistoryValidFrom}),
+			}
+		}
+	})
+}
+
+// History makes the migrations create the history table <model>_history,
+// with the fields of the model and valid_from and valid_to times, and a
+// trigger recording in it every version of the rows of the model. The
+// current version of a row has a null valid_to, and previous versions the
+// time the transaction replacing or deleting them started. The history
+// model is generated like other models, and the model gets AsOf helpers
+// reading the rows as they were at a time:
+//
+//	user, err := models.UsersAsOf(t, qm.Where("email = ?", email)).One(ctx)
+//
+// The history table has the primary key of the model with valid_from, and
+// no other constraints, so it keeps the versions of rows referencing
+// deleted rows. It needs the "time" type of the stdtypes plugin.
+func History() ModelItem {
+	return defHistory{}
 }
@@ -0,0 +1,60 @@
+package core
+
+import (
+	"github.com/sqlbunny/sqlbunny/gen"
+	"github.com/sqlbunny/sqlbunny/schema"
+)
+
+type defRawSQL struct {
+	up        string
+	down      string
+	dependsOn []string
+	pos       string
+}
+
+func (d defRawSQL) ConfigItem(ctx *gen.Context) {
+	if d.up == "" {
+		ctx.AddError("%sRawSQL has no up SQL", posPrefixString(d.pos))
+		return
+	}
+	for _, r := range ctx.Schema.RawSQL {
+		if r.Up == d.up {
+			ctx.AddError("%sRawSQL '%s' is defined multiple times", posPrefixString(d.pos), d.up)
+			return
+		}
+	}
+	ctx.Schema.RawSQL = append(ctx.Schema.RawSQL, &schema.RawSQL{
+		Up:        d.up,
+		Down:      d.down,
+		DependsOn: d.dependsOn,
+	})
+	ctx.Enqueue(400, func() {
+		for _, name := range d.dependsOn {
+			if _, ok := ctx.Schema.Models[name]; !ok {
+				ctx.AddError("%sRawSQL '%s' depends on unknown model '%s'", posPrefixString(d.pos), d.up, name)
+			}
+		}
+	})
+}
+
+// RawSQL defines raw SQL for what the definitions can't express, like
+// extensions or exclusion constraints. Migrations run up once, after
+// creating the tables of the models it depends on, and run down before
+// changing it or when it's removed, before the tables are dropped:
+//
+//	RawSQL(
+//		"ALTER TABLE booking ADD CONSTRAINT booking_no_overlap EXCLUDE USING gist (room_id WITH =, during WITH &&)",
+//		"ALTER TABLE booking DROP CONSTRAINT booking_no_overlap",
+//		"booking",
+//	)
+//
+// The up SQL identifies the item, so changing it runs the old down and the
+// new up. Items without down SQL can't be undone, only replaced.
+func RawSQL(up, down string, dependsOn ...string) gen.ConfigItem {
+	return defRawSQL{
+		up:        up,
+		down:      down,
+		dependsOn: dependsOn,
+		pos:       callerPos(),
+	}
+}
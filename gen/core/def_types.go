@@ -1,7 +1,15 @@
 package core
 
 import (
+	"database/sql"
+	"database/sql/driver"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/sqlbunny/sqlbunny/gen"
 	"github.com/sqlbunny/sqlbunny/runtime/strmangle"
@@ -11,6 +19,18 @@ import (
 type TypeContext struct {
 	*gen.Context
 	Name string
+
+	pos string
+}
+
+// AddError adds an error prefixed with the position of the definition of
+// the type.
+func (ctx *TypeContext) AddError(message string, args ...interface{}) {
+	if ctx.pos == "" {
+		ctx.Context.AddError(message, args...)
+		return
+	}
+	ctx.Context.AddError("%s: "+message, append([]interface{}{ctx.pos}, args...)...)
 }
 
 type TypeItem interface {
@@ -20,15 +40,15 @@ type TypeItem interface {
 type defType struct {
 	name string
 	item TypeItem
+	pos  string
 }
 
 func (t defType) ConfigItem(ctx *gen.Context) {
-	if _, ok := ctx.Schema.Types[t.name]; ok {
-		ctx.AddError("Type '%s' is defined multiple times", t.name)
-	}
+	ctx.Define("Type", t.name)
 	ctx.Schema.Types[t.name] = t.item.TypeItem(&TypeContext{
 		Context: ctx,
 		Name:    t.name,
+		pos:     t.pos,
 	})
 }
 
@@ -38,11 +58,14 @@ func Type(name string, t TypeItem) defType {
 	return defType{
 		name: name,
 		item: t,
+		pos:  callerPos(),
 	}
 }
 
 type BaseType struct {
-	Go       string
+	Go string
+	// GoNull is the Go type of nullable fields. If it's empty, null.Val
+	// wraps the Go type.
 	GoNull   string
 	Postgres SQLType
 }
@@ -66,15 +89,14 @@ func parseGoType(s string) schema.GoType {
 }
 
 func (t BaseType) TypeItem(ctx *TypeContext) schema.Type {
-	if t.GoNull == "" {
-		return &schema.BaseTypeNotNullable{
-			Name: ctx.Name,
-			Postgres: schema.SQLType{
-				Type:      t.Postgres.Type,
-				ZeroValue: t.Postgres.ZeroValue,
-			},
-			Go: parseGoType(t.Go),
-		}
+	goType := parseGoType(t.Go)
+	goNull := schema.GoType{
+		Pkg:  "github.com/sqlbunny/sqlbunny/types/null",
+		Name: "Val",
+		Args: []schema.GoType{goType},
+	}
+	if t.GoNull != "" {
+		goNull = parseGoType(t.GoNull)
 	}
 	return &schema.BaseTypeNullable{
 		Name: ctx.Name,
@@ -82,24 +104,40 @@ func (t BaseType) TypeItem(ctx *TypeContext) schema.Type {
 			Type:      t.Postgres.Type,
 			ZeroValue: t.Postgres.ZeroValue,
 		},
-		Go:     parseGoType(t.Go),
-		GoNull: parseGoType(t.GoNull),
+		Go:     goType,
+		GoNull: goNull,
 	}
 }
 
 type enum struct {
 	choices []string
+	text    bool
 }
 
 func (t enum) TypeItem(ctx *TypeContext) schema.Type {
+	if t.text && len(t.choices) == 0 {
+		ctx.AddError("Type '%s' is a text enum without choices", ctx.Name)
+		t.text = false
+	}
 	return &schema.Enum{
 		Name:    ctx.Name,
 		Choices: t.choices,
+		Text:    t.text,
 	}
 }
 
 func Enum(choices ...string) enum {
-	return enum{choices}
+	return enum{choices: choices}
+}
+
+// TextEnum is an enum stored by name in text columns, restricted to the
+// choices by a CHECK constraint, instead of by index in integer columns.
+// Migrations add choices by replacing the constraints, which, unlike
+// altering native enum types, can run in a transaction:
+//
+//	Type("order_status", TextEnum("pending", "paid", "shipped"))
+func TextEnum(choices ...string) enum {
+	return enum{choices: choices, text: true}
 }
 
 type array struct {
@@ -122,3 +160,179 @@ func (t array) TypeItem(ctx *TypeContext) schema.Type {
 func Array(element string) array {
 	return array{element}
 }
+
+// Timestamp is a time type stored with the passed options. Its values are
+// normalized to Location and rounded to Resolution when they're scanned and
+// written, instead of keeping whatever the driver returns:
+//
+//	Type("event_time", Timestamp{TZ: true, Resolution: time.Millisecond})
+type Timestamp struct {
+	// TZ stores the values as timestamptz instead of timestamp. Values of
+	// timestamp columns are wall clock times in Location.
+	TZ bool
+
+	// Resolution is the precision of the values, from time.Microsecond to
+	// time.Second. Zero is the database default, microseconds.
+	Resolution time.Duration
+
+	// Location is the IANA name of the location of the values, UTC if
+	// it's empty.
+	Location string
+}
+
+func (t Timestamp) TypeItem(ctx *TypeContext) schema.Type {
+	precision := -1
+	if t.Resolution != 0 {
+		precision = 6
+		for r := time.Microsecond; r < t.Resolution && precision > 0; r *= 10 {
+			precision--
+		}
+		if t.Resolution != time.Second/time.Duration(math.Pow10(precision)) {
+			ctx.AddError("Type '%s' has resolution %s, it must be a power of ten between 1µs and 1s", ctx.Name, t.Resolution)
+		}
+	}
+
+	location := t.Location
+	if location == "" {
+		location = "UTC"
+	}
+	if _, err := time.LoadLocation(location); err != nil {
+		ctx.AddError("Type '%s' has unknown location '%s'", ctx.Name, location)
+	}
+
+	return &schema.Timestamp{
+		Name:      ctx.Name,
+		TZ:        t.TZ,
+		Precision: precision,
+		Location:  location,
+	}
+}
+
+type goTypeDef struct {
+	typ      reflect.Type
+	postgres string
+}
+
+var (
+	scannerType = reflect.TypeOf((*sql.Scanner)(nil)).Elem()
+	valuerType  = reflect.TypeOf((*driver.Valuer)(nil)).Elem()
+)
+
+func (t goTypeDef) TypeItem(ctx *TypeContext) schema.Type {
+	typ := t.typ
+	if typ == nil || typ.Name() == "" || strings.Contains(typ.Name(), "[") {
+		ctx.AddError("Type '%s' Go type %s must be a named, non generic type", ctx.Name, typ)
+		return &schema.BaseTypeNotNullable{Name: ctx.Name}
+	}
+	if !reflect.PtrTo(typ).Implements(scannerType) || !typ.Implements(valuerType) {
+		ctx.AddError("Type '%s' Go type %s must implement sql.Scanner and driver.Valuer", ctx.Name, typ)
+		return &schema.BaseTypeNotNullable{Name: ctx.Name}
+	}
+
+	goType := typ.Name()
+	if typ.PkgPath() != "" {
+		goType = typ.PkgPath() + "." + goType
+	}
+
+	// The zero value of the type is the default of non nullable columns.
+	zero, err := reflect.Zero(typ).Interface().(driver.Valuer).Value()
+	if err != nil {
+		ctx.AddError("Type '%s' zero value has no SQL value: %v", ctx.Name, err)
+	}
+
+	return BaseType{
+		Go: goType,
+		Postgres: SQLType{
+			Type:      t.postgres,
+			ZeroValue: sqlLiteral(zero),
+		},
+	}.TypeItem(ctx)
+}
+
+// sqlLiteral returns the SQL literal of a driver value, or "" for nil.
+func sqlLiteral(v driver.Value) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	case []byte:
+		return `'\x` + hex.EncodeToString(v) + "'"
+	case time.Time:
+		return "'" + v.Format("2006-01-02 15:04:05.999999-07:00") + "'"
+	}
+	return "'" + strings.Replace(fmt.Sprint(v), "'", "''", -1) + "'"
+}
+
+// TypeFromGo defines a type for an application Go type implementing
+// sql.Scanner and driver.Valuer, stored in columns of the SQL type:
+//
+//	TypeFromGo("money", reflect.TypeOf(money.Amount{}), "numeric(12, 2)")
+//
+// typ is a reflect.Type or a value of the type. The SQL value of the zero
+// value of the type is the default of the columns, and nullable fields use
+// null.Val.
+func TypeFromGo(name string, typ interface{}, postgresType string) defType {
+	t, ok := typ.(reflect.Type)
+	if !ok {
+		t = reflect.TypeOf(typ)
+	}
+	return defType{
+		name: name,
+		item: goTypeDef{
+			typ:      t,
+			postgres: postgresType,
+		},
+		pos: callerPos(),
+	}
+}
+
+// UUID is a uuid.UUID type, stored in uuid columns unless a field sets
+// another storage with UUIDStorage.
+type UUID struct{}
+
+func (t UUID) TypeItem(ctx *TypeContext) schema.Type {
+	return &schema.UUID{
+		Name: ctx.Name,
+	}
+}
+
+// ID storages, see PrefixedID.
+const (
+	IDUUID   = schema.IDUUID
+	IDBigint = schema.IDBigint
+)
+
+type prefixedID struct {
+	storage schema.IDStorage
+}
+
+func (t prefixedID) TypeItem(ctx *TypeContext) schema.Type {
+	return &schema.ID{
+		Name:    ctx.Name,
+		Storage: t.storage,
+	}
+}
+
+// PrefixedID is the ID type of a model, stored in the columns of storage,
+// whose JSON and String forms are prefixed with the prefix the model declares
+// with IDPrefix:
+//
+//	Type("user_id", PrefixedID(IDUUID)),
+//	Model("user",
+//		IDPrefix("user"),
+//		Field("id", "user_id", PrimaryKey),
+//	),
+//
+// The type must be the primary key of exactly one model with IDPrefix; other
+// models reference it in their foreign keys. UUID IDs are generated with
+// New<Type>, bigint ones by the database.
+func PrefixedID(storage schema.IDStorage) TypeItem {
+	return prefixedID{
+		storage: storage,
+	}
+}
@@ -0,0 +1,81 @@
+package core
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/sqlbunny/sqlbunny/gen"
+	"github.com/sqlbunny/sqlbunny/schema"
+)
+
+// loadExport loads the export of the schema at src: a JSON file written by
+// the export command, or a git revision whose schema is exported by running
+// the main package in a temporary worktree.
+func loadExport(src string, mainPkg string) (*schema.Export, error) {
+	data, err := os.ReadFile(src)
+	if err != nil {
+		if data, err = exportAtRevision(src, mainPkg); err != nil {
+			return nil, err
+		}
+	}
+
+	var e schema.Export
+	if err := json.Unmarshal(data, &e); err != nil {
+		return nil, fmt.Errorf("invalid schema export '%s': %w", src, err)
+	}
+	return &e, nil
+}
+
+func exportAtRevision(rev string, mainPkg string) ([]byte, error) {
+	prefix, err := exec.Command("git", "rev-parse", "--show-prefix").Output()
+	if err != nil {
+		return nil, fmt.Errorf("'%s' is not a file, and the working directory isn't in a git repository", rev)
+	}
+
+	dir, err := os.MkdirTemp("", "sqlbunny-diff-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	add := exec.Command("git", "worktree", "add", "--detach", dir, rev)
+	if out, err := add.CombinedOutput(); err != nil {
+		return nil, fmt.Errorf("'%s' is not a file or a git revision: %s", rev, bytes.TrimSpace(out))
+	}
+	defer exec.Command("git", "worktree", "remove", "--force", dir).Run()
+
+	run := exec.Command("go", "run", mainPkg, "export")
+	run.Dir = filepath.Join(dir, strings.TrimSpace(string(prefix)))
+	run.Stderr = os.Stderr
+	out, err := run.Output()
+	if err != nil {
+		return nil, fmt.Errorf("exporting the schema at '%s': %w", rev, err)
+	}
+	return out, nil
+}
+
+func cmdDiff(cmd *cobra.Command, args []string) {
+	mainPkg, _ := cmd.Flags().GetString("main")
+
+	from, err := loadExport(args[0], mainPkg)
+	if err != nil {
+		log.Fatal(err)
+	}
+	to := gen.Config.Schema.Export()
+	if len(args) == 2 {
+		if to, err = loadExport(args[1], mainPkg); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	for _, line := range from.Diff(to) {
+		fmt.Println(line)
+	}
+}
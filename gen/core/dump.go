@@ -0,0 +1,387 @@
+package core
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/binary"
+	"encoding/csv"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	_ "github.com/lib/pq"
+	"github.com/spf13/cobra"
+	"github.com/sqlbunny/sqlbunny/gen"
+	"github.com/sqlbunny/sqlbunny/runtime/bunny"
+	"github.com/sqlbunny/sqlbunny/runtime/strmangle"
+	"github.com/sqlbunny/sqlbunny/schema"
+)
+
+// sqlTypeKind is the kind of values of a column, by its SQL type.
+type sqlTypeKind int
+
+const (
+	kindOther sqlTypeKind = iota
+	kindText
+	kindInt
+	kindFloat
+	kindBool
+	kindTime
+	kindUUID
+	kindBytes
+)
+
+func sqlTypeKindOf(sqlType string) sqlTypeKind {
+	switch {
+	case sqlType == "text" || strings.HasPrefix(sqlType, "varchar") || strings.HasPrefix(sqlType, "character"):
+		return kindText
+	case sqlType == "smallint" || sqlType == "integer" || sqlType == "bigint":
+		return kindInt
+	case sqlType == "real" || sqlType == "double precision" || strings.HasPrefix(sqlType, "numeric"):
+		return kindFloat
+	case sqlType == "boolean":
+		return kindBool
+	case strings.HasPrefix(sqlType, "timestamp") || sqlType == "date":
+		return kindTime
+	case sqlType == "uuid":
+		return kindUUID
+	case sqlType == "bytea":
+		return kindBytes
+	}
+	return kindOther
+}
+
+// textLength returns the maximum length of the values of a text SQL type,
+// like 32 for varchar(32), or 0 if they're unbounded.
+func textLength(sqlType string) int {
+	i := strings.IndexByte(sqlType, '(')
+	if i == -1 || !strings.HasSuffix(sqlType, ")") {
+		return 0
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(sqlType[i+1 : len(sqlType)-1]))
+	if err != nil {
+		return 0
+	}
+	return n
+}
+
+// checkAnonymization checks the anonymized columns of the model can hold
+// the values they're replaced by.
+func checkAnonymization(ctx *gen.Context, m *schema.Model) {
+	for _, c := range m.Columns() {
+		switch c.Anonymization {
+		case schema.AnonymizationNone:
+		case schema.AnonymizationNull:
+			if !c.Nullable {
+				addErrorAt(ctx, m, "Model '%s' column '%s' is anonymized with null, but it's not nullable", m.Name, c.Name)
+			}
+		case schema.AnonymizationHash:
+			switch sqlTypeKindOf(c.SQLType) {
+			case kindText, kindInt, kindUUID, kindBytes:
+			default:
+				addErrorAt(ctx, m, "Model '%s' column '%s' is anonymized with hash, but its type '%s' can't hold hashes", m.Name, c.Name, c.SQLType)
+				continue
+			}
+			if c.Check != "" {
+				addErrorAt(ctx, m, "Model '%s' column '%s' is anonymized with hash, but it has a CHECK constraint", m.Name, c.Name)
+			}
+		case schema.AnonymizationFake:
+			if sqlTypeKindOf(c.SQLType) == kindOther {
+				addErrorAt(ctx, m, "Model '%s' column '%s' is anonymized with fake, but there are no fake values of its type '%s'", m.Name, c.Name, c.SQLType)
+				continue
+			}
+			if c.Check != "" {
+				addErrorAt(ctx, m, "Model '%s' column '%s' is anonymized with fake, but it has a CHECK constraint", m.Name, c.Name)
+			}
+		}
+	}
+}
+
+// anonymizer replaces the values of anonymized columns. The hashes are
+// keyed with the salt, so they can't be reversed by hashing guesses.
+type anonymizer struct {
+	salt []byte
+}
+
+func (a anonymizer) digest(v interface{}) []byte {
+	h := hmac.New(sha256.New, a.salt)
+	switch v := v.(type) {
+	case []byte:
+		h.Write(v)
+	case time.Time:
+		h.Write([]byte(v.UTC().Format(time.RFC3339Nano)))
+	default:
+		fmt.Fprint(h, v)
+	}
+	return h.Sum(nil)
+}
+
+// anonymize returns the value replacing v in column c. NULLs are kept.
+func (a anonymizer) anonymize(c schema.ModelColumn, v interface{}) interface{} {
+	if v == nil || c.Anonymization == schema.AnonymizationNone {
+		return v
+	}
+	if c.Anonymization == schema.AnonymizationNull {
+		return nil
+	}
+
+	d := a.digest(v)
+	n := binary.BigEndian.Uint64(d)
+	switch sqlTypeKindOf(c.SQLType) {
+	case kindText:
+		var s string
+		if c.Anonymization == schema.AnonymizationHash {
+			s = hex.EncodeToString(d)
+		} else if orig, ok := v.(string); ok && strings.Contains(orig, "@") {
+			s = fmt.Sprintf("user%d@example.com", n%1000000)
+		} else {
+			s = fmt.Sprintf("%s %d", strmangle.TitleCase(c.Name), n%1000000)
+		}
+		// The values are ASCII, so they're cut to the length of bounded
+		// columns, like varchar(n), by bytes.
+		if max := textLength(c.SQLType); max != 0 && len(s) > max {
+			s = s[:max]
+		}
+		return s
+	case kindInt:
+		limit := uint64(1<<31 - 1)
+		switch {
+		case c.SQLType == "smallint":
+			limit = 1<<15 - 1
+		case c.SQLType == "bigint" && c.Anonymization == schema.AnonymizationHash:
+			limit = 1<<63 - 1
+		case c.Anonymization == schema.AnonymizationFake:
+			limit = 1000000
+		}
+		return int64(n % limit)
+	case kindFloat:
+		return float64(n%100000) / 100
+	case kindBool:
+		return n%2 == 0
+	case kindTime:
+		base := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+		return base.Add(time.Duration(n%(25*365*24*3600)) * time.Second)
+	case kindUUID:
+		// Set the version and variant of a random UUID.
+		d[6] = d[6]&0x0f | 0x40
+		d[8] = d[8]&0x3f | 0x80
+		return fmt.Sprintf("%x-%x-%x-%x-%x", d[0:4], d[4:6], d[6:8], d[8:10], d[10:16])
+	case kindBytes:
+		return d
+	}
+	return v
+}
+
+func cmdDump(cmd *cobra.Command, args []string) {
+	dsn, _ := cmd.Flags().GetString("dsn")
+	env, _ := cmd.Flags().GetString("env")
+	format, _ := cmd.Flags().GetString("format")
+	dir, _ := cmd.Flags().GetString("dir")
+	salt, _ := cmd.Flags().GetString("salt")
+	if dsn == "" && env != "" {
+		var err error
+		if dsn, err = gen.Config.DSN(env); err != nil {
+			log.Fatal(err)
+		}
+	}
+	if dsn == "" {
+		log.Fatal("The --dsn or --env flag is required.")
+	}
+	if format != "sql" && format != "csv" {
+		log.Fatalf("Unknown format '%s', it must be sql or csv.", format)
+	}
+
+	models, err := dumpModels(args)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	a := anonymizer{salt: []byte(salt)}
+	if salt == "" {
+		a.salt = make([]byte, 32)
+		if _, err := rand.Read(a.salt); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer db.Close()
+
+	ctx := context.Background()
+	for _, m := range models {
+		if format == "sql" {
+			err = dumpModel(ctx, db, m, a, sqlRowWriter(os.Stdout, m))
+		} else {
+			err = dumpModelCSV(ctx, db, m, a, filepath.Join(dir, m.Name+".csv"))
+		}
+		if err != nil {
+			log.Fatalf("Error dumping model '%s': %v", m.Name, err)
+		}
+	}
+}
+
+// dumpModels returns the models with names, or all the models with tables
+// if there are none, sorted by name.
+func dumpModels(names []string) ([]*schema.Model, error) {
+	s := gen.Config.Schema
+	if len(names) == 0 {
+		for name, m := range s.Models {
+			if m.HasTable() {
+				names = append(names, name)
+			}
+		}
+		sort.Strings(names)
+	}
+
+	var res []*schema.Model
+	for _, name := range names {
+		m, ok := s.Models[name]
+		if !ok {
+			return nil, fmt.Errorf("model '%s' not found", name)
+		}
+		if !m.HasTable() {
+			return nil, fmt.Errorf("model '%s' has no table", name)
+		}
+		res = append(res, m)
+	}
+	return res, nil
+}
+
+// dumpModel calls write with the anonymized values of each row of the
+// table of the model, in primary key order.
+func dumpModel(ctx context.Context, db *sql.DB, m *schema.Model, a anonymizer, write func(values []interface{}) error) error {
+	cols := m.Columns()
+	names := make([]string, len(cols))
+	for i, c := range cols {
+		names[i] = c.Name
+	}
+	query := fmt.Sprintf("SELECT %s FROM %s", strings.Join(strmangle.IdentQuoteSlice('"', '"', names), ", "), strmangle.IdentQuote('"', '"', m.Name))
+	if m.PrimaryKey != nil {
+		var pk []string
+		for _, p := range m.PrimaryKey.Fields {
+			pk = append(pk, p.SQLName())
+		}
+		query += " ORDER BY " + strings.Join(strmangle.IdentQuoteSlice('"', '"', pk), ", ")
+	}
+
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	values := make([]interface{}, len(cols))
+	dests := make([]interface{}, len(cols))
+	for i := range dests {
+		dests[i] = &values[i]
+	}
+	for rows.Next() {
+		if err := rows.Scan(dests...); err != nil {
+			return err
+		}
+		for i, c := range cols {
+			// The driver returns the values of types it doesn't decode,
+			// like numeric or uuid, as their text.
+			if b, ok := values[i].([]byte); ok && c.SQLType != "bytea" {
+				values[i] = string(b)
+			}
+			values[i] = a.anonymize(c, values[i])
+		}
+		if err := write(values); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// sqlRowWriter returns a function writing the values of the rows of the
+// model to w as INSERT statements.
+func sqlRowWriter(w io.Writer, m *schema.Model) func(values []interface{}) error {
+	cols := m.Columns()
+	names := make([]string, len(cols))
+	for i, c := range cols {
+		names[i] = c.Name
+	}
+	query := fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s);\n",
+		strmangle.IdentQuote('"', '"', m.Name),
+		strings.Join(strmangle.IdentQuoteSlice('"', '"', names), ", "),
+		strmangle.Placeholders(true, len(cols), 1, 1))
+
+	return func(values []interface{}) error {
+		stmt, err := bunny.InterpolateQuery(query, values)
+		if err != nil {
+			return err
+		}
+		_, err = io.WriteString(w, stmt)
+		return err
+	}
+}
+
+// dumpModelCSV writes the rows of the model to the CSV file at path, with a
+// header of the column names. NULLs are empty, and bytea values are
+// hex-encoded like Postgres does, so COPY ... WITH (FORMAT csv, HEADER) can
+// read them.
+func dumpModelCSV(ctx context.Context, db *sql.DB, m *schema.Model, a anonymizer, path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	cols := m.Columns()
+	w := csv.NewWriter(f)
+	header := make([]string, len(cols))
+	for i, c := range cols {
+		header[i] = c.Name
+	}
+	if err := w.Write(header); err != nil {
+		return err
+	}
+
+	record := make([]string, len(cols))
+	err = dumpModel(ctx, db, m, a, func(values []interface{}) error {
+		for i, v := range values {
+			record[i] = csvValue(v)
+		}
+		return w.Write(record)
+	})
+	if err != nil {
+		return err
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+func csvValue(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case []byte:
+		return `\x` + hex.EncodeToString(v)
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	}
+	return fmt.Sprint(v)
+}
@@ -0,0 +1,41 @@
+package core
+
+import (
+	"encoding/json"
+	"log"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/sqlbunny/sqlbunny/gen"
+)
+
+func cmdExport(cmd *cobra.Command, args []string) {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(gen.Config.Schema.Export()); err != nil {
+		log.Fatal(err)
+	}
+}
+
+type piiColumn struct {
+	Model   string `json:"model"`
+	Column  string `json:"column"`
+	SQLType string `json:"sql_type"`
+}
+
+func cmdPII(cmd *cobra.Command, args []string) {
+	res := []piiColumn{}
+	for _, m := range gen.Config.Schema.Export().Models {
+		for _, c := range m.Columns {
+			if c.PII {
+				res = append(res, piiColumn{Model: m.Name, Column: c.Name, SQLType: c.SQLType})
+			}
+		}
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(res); err != nil {
+		log.Fatal(err)
+	}
+}
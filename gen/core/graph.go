@@ -0,0 +1,204 @@
+package core
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/sqlbunny/sqlbunny/gen"
+	"github.com/sqlbunny/sqlbunny/schema"
+)
+
+type graphColumn struct {
+	schema.ModelColumn
+	pk bool
+	fk bool
+}
+
+// graphColumns returns the columns of the model, with their keys.
+func graphColumns(m *schema.Model) []graphColumn {
+	pk := map[string]bool{}
+	if m.PrimaryKey != nil {
+		for _, p := range m.PrimaryKey.Fields {
+			pk[p.SQLName()] = true
+		}
+	}
+	fk := map[string]bool{}
+	for _, f := range m.ForeignKeys {
+		for _, p := range f.LocalFields {
+			fk[p.SQLName()] = true
+		}
+	}
+
+	var res []graphColumn
+	for _, c := range m.Columns() {
+		res = append(res, graphColumn{ModelColumn: c, pk: pk[c.Name], fk: fk[c.Name]})
+	}
+	return res
+}
+
+// graphCluster returns the cluster of the model, the prefix of its name
+// before the first underscore.
+func graphCluster(m *schema.Model) string {
+	if i := strings.Index(m.Name, "_"); i != -1 {
+		return m.Name[:i]
+	}
+	return m.Name
+}
+
+func sortedModels(s *schema.Schema) []*schema.Model {
+	res := make([]*schema.Model, 0, len(s.Models))
+	for _, m := range s.Models {
+		res = append(res, m)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Name < res[j].Name
+	})
+	return res
+}
+
+// writeDot writes the models, their columns and foreign keys as a Graphviz
+// digraph, with the models with the same cluster in a subgraph if cluster
+// is set.
+func writeDot(w io.Writer, s *schema.Schema, cluster bool) {
+	models := sortedModels(s)
+
+	fmt.Fprintln(w, "digraph schema {")
+	fmt.Fprintln(w, "\trankdir=LR;")
+	fmt.Fprintln(w, "\tnode [shape=plaintext];")
+
+	writeNode := func(indent string, m *schema.Model) {
+		fmt.Fprintf(w, "%s%q [label=<<table border=\"0\" cellborder=\"1\" cellspacing=\"0\">", indent, m.Name)
+		fmt.Fprintf(w, "<tr><td bgcolor=\"lightgrey\"><b>%s</b></td></tr>", m.Name)
+		for _, c := range graphColumns(m) {
+			name := c.Name
+			if c.pk {
+				name = "<u>" + name + "</u>"
+			}
+			typ := c.SQLType
+			if c.Nullable {
+				typ += " null"
+			}
+			fmt.Fprintf(w, "<tr><td port=%q align=\"left\">%s <i>%s</i></td></tr>", c.Name, name, typ)
+		}
+		fmt.Fprintln(w, "</table>>];")
+	}
+
+	if cluster {
+		var clusters []string
+		byCluster := map[string][]*schema.Model{}
+		for _, m := range models {
+			c := graphCluster(m)
+			if _, ok := byCluster[c]; !ok {
+				clusters = append(clusters, c)
+			}
+			byCluster[c] = append(byCluster[c], m)
+		}
+		for _, c := range clusters {
+			fmt.Fprintf(w, "\tsubgraph %q {\n", "cluster_"+c)
+			fmt.Fprintf(w, "\t\tlabel=%q;\n", c)
+			for _, m := range byCluster[c] {
+				writeNode("\t\t", m)
+			}
+			fmt.Fprintln(w, "\t}")
+		}
+	} else {
+		for _, m := range models {
+			writeNode("\t", m)
+		}
+	}
+
+	for _, m := range models {
+		for _, f := range m.ForeignKeys {
+			local := f.LocalFields[0].SQLName()
+			foreign := ""
+			if len(f.ForeignFields) != 0 {
+				foreign = ":" + fmt.Sprintf("%q", f.ForeignFields[0].SQLName())
+			}
+			fmt.Fprintf(w, "\t%q:%q -> %q%s;\n", m.Name, local, f.ForeignModel, foreign)
+		}
+	}
+	fmt.Fprintln(w, "}")
+}
+
+// mermaidType returns typ as a Mermaid attribute type, which can't have
+// spaces or parentheses.
+func mermaidType(typ string) string {
+	return strings.Map(func(r rune) rune {
+		if r == ' ' || r == '(' || r == ')' || r == ',' {
+			return '_'
+		}
+		return r
+	}, typ)
+}
+
+// writeMermaid writes the models, their columns and foreign keys as a
+// Mermaid ER diagram. Mermaid has no clusters, so with cluster set the
+// models are commented with their cluster instead.
+func writeMermaid(w io.Writer, s *schema.Schema, cluster bool) {
+	models := sortedModels(s)
+
+	fmt.Fprintln(w, "erDiagram")
+	lastCluster := ""
+	for _, m := range models {
+		if c := graphCluster(m); cluster && c != lastCluster {
+			fmt.Fprintf(w, "\t%%%% %s\n", c)
+			lastCluster = c
+		}
+		fmt.Fprintf(w, "\t%s {\n", m.Name)
+		for _, c := range graphColumns(m) {
+			var keys []string
+			if c.pk {
+				keys = append(keys, "PK")
+			}
+			if c.fk {
+				keys = append(keys, "FK")
+			}
+			line := mermaidType(c.SQLType) + " " + c.Name
+			if len(keys) != 0 {
+				line += " " + strings.Join(keys, ", ")
+			}
+			if c.Nullable {
+				line += ` "null"`
+			}
+			fmt.Fprintf(w, "\t\t%s\n", line)
+		}
+		fmt.Fprintln(w, "\t}")
+	}
+
+	for _, m := range models {
+		for _, f := range m.ForeignKeys {
+			// Many rows of the model reference zero or one foreign row.
+			card := "|o"
+			allNotNull := true
+			for _, p := range f.LocalFields {
+				if field := m.FindField(p); field != nil && field.Nullable {
+					allNotNull = false
+				}
+			}
+			if allNotNull {
+				card = "||"
+			}
+			fmt.Fprintf(w, "\t%s }o--%s %s : %q\n", m.Name, card, f.ForeignModel, describeIndex(f.LocalFields))
+		}
+	}
+}
+
+func cmdGraph(cmd *cobra.Command, args []string) {
+	format, _ := cmd.Flags().GetString("format")
+	cluster, _ := cmd.Flags().GetBool("cluster")
+
+	s := gen.Config.Schema
+	switch format {
+	case "dot":
+		writeDot(os.Stdout, s, cluster)
+	case "mermaid":
+		writeMermaid(os.Stdout, s, cluster)
+	default:
+		log.Fatalf("Unknown graph format '%s', it must be dot or mermaid.", format)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"log"
 	"os"
 
+	"github.com/spf13/cobra"
 	"github.com/sqlbunny/sqlbunny/gen"
 	"github.com/sqlbunny/sqlbunny/schema"
 )
@@ -14,14 +15,24 @@ const (
 	templatesModelDirectory     = "templates/model"
 	templatesStructDirectory    = "templates/struct"
 	templatesEnumDirectory      = "templates/enum"
+	templatesTimestampDirectory = "templates/timestamp"
+	templatesIDDirectory        = "templates/id"
+	templatesFunctionDirectory  = "templates/function"
 	templatesSingletonDirectory = "templates/singleton"
+	templatesTestDirectory      = "templates/test"
+	templatesTestMainDirectory  = "templates/test_main"
 )
 
 type Plugin struct {
 	ModelTemplates     *gen.TemplateList
 	StructTemplates    *gen.TemplateList
 	EnumTemplates      *gen.TemplateList
+	TimestampTemplates *gen.TemplateList
+	IDTemplates        *gen.TemplateList
+	FunctionTemplates  *gen.TemplateList
 	SingletonTemplates *gen.TemplateList
+	TestTemplates      *gen.TemplateList
+	TestMainTemplates  *gen.TemplateList
 }
 
 var _ gen.Plugin = &Plugin{}
@@ -29,20 +40,76 @@ var _ gen.Plugin = &Plugin{}
 func (*Plugin) ConfigItem(ctx *gen.Context) {}
 
 func (p *Plugin) BunnyPlugin() {
-	schema, err := buildSchema(gen.Config.Items)
+	items := append(gen.Config.Items, projectTypeOverrides(gen.Config.Project)...)
+	schema, warnings, err := buildSchema(items)
 	if err != nil {
+		gen.PrintWarnings(warnings)
 		log.Println(err)
 		os.Exit(1)
 	}
 
 	gen.Config.Schema = schema
+	gen.Config.Warnings = warnings
 
 	p.ModelTemplates = gen.MustLoadTemplates(templatesPackage, templatesModelDirectory)
 	p.StructTemplates = gen.MustLoadTemplates(templatesPackage, templatesStructDirectory)
 	p.EnumTemplates = gen.MustLoadTemplates(templatesPackage, templatesEnumDirectory)
+	p.TimestampTemplates = gen.MustLoadTemplates(templatesPackage, templatesTimestampDirectory)
+	p.IDTemplates = gen.MustLoadTemplates(templatesPackage, templatesIDDirectory)
+	p.FunctionTemplates = gen.MustLoadTemplates(templatesPackage, templatesFunctionDirectory)
 	p.SingletonTemplates = gen.MustLoadTemplates(templatesPackage, templatesSingletonDirectory)
+	p.TestTemplates = gen.MustLoadTemplates(templatesPackage, templatesTestDirectory)
+	p.TestMainTemplates = gen.MustLoadTemplates(templatesPackage, templatesTestMainDirectory)
 
 	gen.OnGen(p.gen)
+
+	graphCmd := &cobra.Command{
+		Use:   "graph",
+		Short: "Print an ER diagram of the models",
+		Run:   cmdGraph,
+	}
+	graphCmd.Flags().String("format", "dot", "Diagram format: dot or mermaid")
+	graphCmd.Flags().Bool("cluster", false, "Group the models by the prefix of their names, before the first underscore")
+	gen.AddCommand(graphCmd)
+
+	gen.AddCommand(&cobra.Command{
+		Use:   "export",
+		Short: "Print the schema as JSON, see schema.Export",
+		Run:   cmdExport,
+	})
+
+	gen.AddCommand(&cobra.Command{
+		Use:   "pii",
+		Short: "Print the PII columns of the models as JSON, for compliance review",
+		Run:   cmdPII,
+	})
+
+	dumpCmd := &cobra.Command{
+		Use:   "dump [MODEL...]",
+		Short: "Dump the rows of models from a database, with their anonymized fields replaced",
+		Long: "Dump the rows of the models, or of all of them, from a Postgres database to load in non-production\n" +
+			"environments, with the values of the fields with Anonymize replaced. The sql format prints INSERT\n" +
+			"statements, the csv format writes a MODEL.csv file per model for COPY ... WITH (FORMAT csv, HEADER).",
+		Run: cmdDump,
+	}
+	dumpCmd.Flags().String("dsn", "", "Postgres connection string of the database")
+	dumpCmd.Flags().String("env", "", "Environment of the database in the project config file, instead of --dsn")
+	dumpCmd.Flags().String("format", "sql", "Dump format: sql or csv")
+	dumpCmd.Flags().String("dir", ".", "Directory of the csv files")
+	dumpCmd.Flags().String("salt", "", "Key of the hashes of the anonymized values, random if empty, set it to get the same values across dumps")
+	gen.AddCommand(dumpCmd)
+
+	diffCmd := &cobra.Command{
+		Use:   "diff FROM [TO]",
+		Short: "Print the changes of the schema between two git revisions or schema exports",
+		Long: "Print the changes of the schema between two git revisions or JSON files written by export.\n" +
+			"The schema of a revision is exported by running the main package in a temporary git worktree.\n" +
+			"Without TO, the changes are up to the current schema.",
+		Args: cobra.RangeArgs(1, 2),
+		Run:  cmdDiff,
+	}
+	diffCmd.Flags().String("main", ".", "Main package defining the schema, run to export revisions")
+	gen.AddCommand(diffCmd)
 }
 
 func (p *Plugin) gen() {
@@ -51,6 +118,7 @@ func (p *Plugin) gen() {
 	}
 
 	data := gen.BaseTemplateData()
+	data["SchemaInfo"] = schemaInfoLiteral(gen.Config.Schema)
 	p.SingletonTemplates.ExecuteSingleton(data)
 
 	for _, t := range gen.Config.Schema.Types {
@@ -59,6 +127,14 @@ func (p *Plugin) gen() {
 			data := gen.BaseTemplateData()
 			data["Enum"] = t
 			p.EnumTemplates.Execute(data, t.Name+".gen.go")
+		case *schema.Timestamp:
+			data := gen.BaseTemplateData()
+			data["Timestamp"] = t
+			p.TimestampTemplates.Execute(data, t.Name+".gen.go")
+		case *schema.ID:
+			data := gen.BaseTemplateData()
+			data["ID"] = t
+			p.IDTemplates.Execute(data, t.Name+".gen.go")
 		case *schema.Struct:
 			data := gen.BaseTemplateData()
 			data["Struct"] = t
@@ -71,4 +147,14 @@ func (p *Plugin) gen() {
 		data["Model"] = model
 		p.ModelTemplates.Execute(data, model.Name+".gen.go")
 	}
+
+	for _, f := range gen.Config.Schema.Functions {
+		data := gen.BaseTemplateData()
+		data["Function"] = f
+		p.FunctionTemplates.Execute(data, "function_"+f.Name+".gen.go")
+	}
+
+	if gen.Config.GenerateTests {
+		p.genTests()
+	}
 }
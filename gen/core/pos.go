@@ -0,0 +1,56 @@
+package core
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/sqlbunny/sqlbunny/gen"
+)
+
+// defPosExt is the extension holding the source position of the
+// definition of a model or a field.
+type defPosExt struct{}
+
+// callerPos returns the file:line of the call of the definition function
+// calling callerPos, relative to the working directory if it's inside it.
+func callerPos() string {
+	_, file, line, ok := runtime.Caller(2)
+	if !ok {
+		return ""
+	}
+	if wd, err := os.Getwd(); err == nil {
+		if rel, err := filepath.Rel(wd, file); err == nil && !strings.HasPrefix(rel, "..") {
+			file = rel
+		}
+	}
+	return fmt.Sprintf("%s:%d", file, line)
+}
+
+type extendable interface {
+	GetExtension(key interface{}) interface{}
+}
+
+// posPrefix returns the "file:line: " prefix of the errors of the model or
+// field, or "" if its position is unknown.
+func posPrefix(e extendable) string {
+	pos, _ := e.GetExtension(defPosExt{}).(string)
+	return posPrefixString(pos)
+}
+
+// posPrefixString returns the "file:line: " prefix of the position, or ""
+// if it's unknown.
+func posPrefixString(pos string) string {
+	if pos == "" {
+		return ""
+	}
+	return pos + ": "
+}
+
+// addErrorAt adds an error prefixed with the position of the definition of
+// the model or field.
+func addErrorAt(ctx *gen.Context, e extendable, message string, args ...interface{}) {
+	ctx.AddError("%s"+message, append([]interface{}{posPrefix(e)}, args...)...)
+}
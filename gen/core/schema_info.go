@@ -0,0 +1,80 @@
+package core
+
+import (
+	"sort"
+
+	"github.com/sanity-io/litter"
+	"github.com/sqlbunny/sqlbunny/runtime/bunny"
+	"github.com/sqlbunny/sqlbunny/schema"
+)
+
+func sqlNames(paths []schema.Path) []string {
+	if len(paths) == 0 {
+		return nil
+	}
+	res := make([]string, len(paths))
+	for i, p := range paths {
+		res[i] = p.SQLName()
+	}
+	return res
+}
+
+// schemaInfo returns the runtime description of the models of s, returned
+// by the generated Schema function.
+func schemaInfo(s *schema.Schema) *bunny.SchemaInfo {
+	res := &bunny.SchemaInfo{}
+	for _, m := range s.Models {
+		mi := &bunny.ModelInfo{
+			Name:     m.Name,
+			HasTable: m.HasTable(),
+		}
+		for _, c := range m.Columns() {
+			mi.Columns = append(mi.Columns, bunny.ColumnInfo{
+				Name:      c.Name,
+				SQLType:   c.SQLType,
+				Nullable:  c.Nullable,
+				PII:       c.PII,
+				Immutable: c.Immutable,
+				DBDefault: c.DBDefault,
+			})
+		}
+		if m.PrimaryKey != nil {
+			mi.PrimaryKey = sqlNames(m.PrimaryKey.Fields)
+		}
+		for _, i := range m.Indexes {
+			mi.Indexes = append(mi.Indexes, sqlNames(i.Fields))
+		}
+		for _, u := range m.Uniques {
+			mi.Uniques = append(mi.Uniques, sqlNames(u.Fields))
+		}
+		for _, fk := range m.ForeignKeys {
+			mi.ForeignKeys = append(mi.ForeignKeys, bunny.ForeignKeyInfo{
+				Columns:        sqlNames(fk.LocalFields),
+				ForeignModel:   fk.ForeignModel,
+				ForeignColumns: sqlNames(fk.ForeignFields),
+			})
+		}
+		for _, r := range m.Relationships {
+			mi.Relationships = append(mi.Relationships, bunny.RelationshipInfo{
+				Name:               r.Name,
+				ForeignModel:       r.ForeignModel,
+				ToMany:             r.ToMany,
+				LocalColumns:       sqlNames(r.LocalFields),
+				ForeignColumns:     sqlNames(r.ForeignFields),
+				JoinModel:          r.JoinModel,
+				JoinLocalColumns:   sqlNames(r.JoinLocalFields),
+				JoinForeignColumns: sqlNames(r.JoinForeignFields),
+			})
+		}
+		res.Models = append(res.Models, mi)
+	}
+	sort.Slice(res.Models, func(i, j int) bool {
+		return res.Models[i].Name < res.Models[j].Name
+	})
+	return res
+}
+
+// schemaInfoLiteral returns the Go expression of the schemaInfo of s.
+func schemaInfoLiteral(s *schema.Schema) string {
+	return litter.Options{HideZeroValues: true}.Sdump(schemaInfo(s))
+}
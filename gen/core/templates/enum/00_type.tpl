@@ -7,13 +7,14 @@ import (
     "bytes"
     "database/sql/driver"
     "encoding/json"
-    "bytes"
+    "fmt"
+    "strconv"
 
     "github.com/sqlbunny/sqlbunny/runtime/bunny"
     "github.com/sqlbunny/sqlbunny/types/null/convert"
 )
 
-// {{$enumName}} is an enum type.
+// {{$enumName}} is an enum type{{if .Enum.Text}}, stored by name in text columns{{end}}.
 type {{$enumName}} int32
 
 
@@ -46,12 +47,33 @@ func (o {{$enumName}}) String() string {
     return {{$enumNameCamel}}Names[o]
 }
 
-func {{$enumName}}FromString(s string) ({{$enumName}}, error) {
+// Parse{{$enumName}} returns the {{$enumName}} named s, or an error if there's none.
+func Parse{{$enumName}}(s string) ({{$enumName}}, error) {
     var o {{$enumName}}
     err := o.UnmarshalText([]byte(s))
     return o, err
 }
 
+// {{$enumName}}FromString is Parse{{$enumName}}.
+func {{$enumName}}FromString(s string) ({{$enumName}}, error) {
+    return Parse{{$enumName}}(s)
+}
+
+// All{{$enumName}}Values returns the {{$enumName}} values, in the order they're defined.
+func All{{$enumName}}Values() []{{$enumName}} {
+    return []{{$enumName}}{
+        {{- range $index, $choice := .Enum.Choices }}
+        {{$enumName}}({{$index}}),
+        {{- end}}
+    }
+}
+
+// IsValid reports whether o is one of the {{$enumName}} values.
+func (o {{$enumName}}) IsValid() bool {
+    _, ok := {{$enumNameCamel}}Names[o]
+    return ok
+}
+
 // MarshalText implements encoding/text TextMarshaler interface.
 func (o {{$enumName}}) MarshalText() ([]byte, error) {
 	return []byte(o.String()), nil
@@ -66,3 +88,46 @@ func (o *{{$enumName}}) UnmarshalText(text []byte) error {
 	*o = val
 	return nil
 }
+
+// MarshalJSON implements json.Marshaler, with the name of the value.
+func (o {{$enumName}}) MarshalJSON() ([]byte, error) {
+	if !o.IsValid() {
+		return nil, &bunny.InvalidEnumError{Value: []byte(strconv.Itoa(int(o))), Type: "{{$enumName}}"}
+	}
+	return json.Marshal(o.String())
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (o *{{$enumName}}) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	return o.UnmarshalText([]byte(s))
+}
+
+// Set implements flag.Value.
+func (o *{{$enumName}}) Set(s string) error {
+	return o.UnmarshalText([]byte(s))
+}
+{{- if .Enum.Text}}
+
+// Value implements driver.Valuer, with the name of the value.
+func (o {{$enumName}}) Value() (driver.Value, error) {
+	if !o.IsValid() {
+		return nil, &bunny.InvalidEnumError{Value: []byte(strconv.Itoa(int(o))), Type: "{{$enumName}}"}
+	}
+	return o.String(), nil
+}
+
+// Scan implements sql.Scanner, from the name of the value.
+func (o *{{$enumName}}) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case string:
+		return o.UnmarshalText([]byte(v))
+	case []byte:
+		return o.UnmarshalText(v)
+	}
+	return fmt.Errorf("cannot scan %T into {{$enumName}}", value)
+}
+{{- end}}
@@ -62,6 +62,11 @@ func (u *Null{{$enumName}}) UnmarshalText(text []byte) error {
 	return err
 }
 
+// Set implements flag.Value. An empty string is null.
+func (u *Null{{$enumName}}) Set(s string) error {
+	return u.UnmarshalText([]byte(s))
+}
+
 // MarshalJSON implements json.Marshaler.
 func (u Null{{$enumName}}) MarshalJSON() ([]byte, error) {
 	if !u.Valid {
@@ -113,7 +118,11 @@ func (u Null{{$enumName}}) Value() (driver.Value, error) {
 	if !u.Valid {
 		return nil, nil
 	}
+	{{- if .Enum.Text}}
+	return u.{{$enumName}}.Value()
+	{{- else}}
 	return int64(u.{{$enumName}}), nil
+	{{- end}}
 }
 
 func (u Null{{$enumName}}) String() string {
@@ -0,0 +1,63 @@
+{{- $f := .Function -}}
+{{- $funcName := $f.Name | titleCase -}}
+{{- $call := printf "%s%s%s(%s)" .LQ $f.Name .RQ (placeholders .Dialect.IndexPlaceholders (len $f.Args) 1 1) -}}
+{{- /* Volatile functions can write, so they don't run on replicas. */ -}}
+{{- $ctx := "ctx" -}}
+{{- if eq $f.Volatility "VOLATILE"}}{{$ctx = "bunny.ForceWriter(ctx)"}}{{end -}}
+{{ import "context" "context" }}
+{{ import "bunny" "github.com/sqlbunny/sqlbunny/runtime/bunny" }}
+{{- if $f.ReturnsModel}}
+{{ import "queries" "github.com/sqlbunny/sqlbunny/runtime/queries" }}
+{{- end}}
+
+{{- define "function_args" -}}
+ctx context.Context{{range .Args}}, {{.Name | camelCase}} {{goType .Type.GoType}}{{end}}
+{{- end}}
+
+{{- define "function_values" -}}
+{{range $i, $a := .Args}}{{if $i}}, {{end}}{{$a.Name | camelCase}}{{end}}
+{{- end}}
+
+{{if $f.Procedure}}
+// Call{{$funcName}} calls the procedure {{$f.Name}}.
+func Call{{$funcName}}({{template "function_args" $f}}) error {
+	ctx = bunny.WithOperation(ctx, "{{$f.Name}}", "call")
+
+	_, err := bunny.Exec(ctx, "CALL {{$call}}"{{range $f.Args}}, {{.Name | camelCase}}{{end}})
+	return err
+}
+{{- else if $f.ReturnsModel}}
+{{- $modelName := $f.ReturnsModel.Name | titleCase}}
+{{- $varNameSingular := $f.ReturnsModel.Name | singular | camelCase}}
+// Call{{$funcName}} calls the function {{$f.Name}}, and returns the {{$f.ReturnsModel.Name}}
+// rows it returns.
+func Call{{$funcName}}({{template "function_args" $f}}) ({{$modelName}}Slice, error) {
+	ctx = bunny.WithOperation(ctx, "{{$f.Name}}", "call")
+
+	var res []*{{$modelName}}
+	q := queries.Raw("SELECT * FROM {{$call}}"{{range $f.Args}}, {{.Name | camelCase}}{{end}})
+	if err := {{$varNameSingular}}Scanner.All({{$ctx}}, q, &res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+{{- else if $f.Returns}}
+{{- $goType := $f.Returns.GoType}}
+{{- if $f.ReturnsNull}}{{$goType = $f.Returns.GoTypeNull}}{{end}}
+// Call{{$funcName}} calls the function {{$f.Name}}, and returns its result.
+func Call{{$funcName}}({{template "function_args" $f}}) ({{goType $goType}}, error) {
+	ctx = bunny.WithOperation(ctx, "{{$f.Name}}", "call")
+
+	var res {{goType $goType}}
+	err := bunny.QueryRowScan({{$ctx}}, "SELECT {{$call}}", []interface{}{ {{- template "function_values" $f -}} }, &res)
+	return res, err
+}
+{{- else}}
+// Call{{$funcName}} calls the function {{$f.Name}}.
+func Call{{$funcName}}({{template "function_args" $f}}) error {
+	ctx = bunny.WithOperation(ctx, "{{$f.Name}}", "call")
+
+	_, err := bunny.Exec(ctx, "SELECT {{$call}}"{{range $f.Args}}, {{.Name | camelCase}}{{end}})
+	return err
+}
+{{- end}}
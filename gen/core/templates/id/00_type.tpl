@@ -0,0 +1,89 @@
+{{- $typeName := .ID.Name | titleCase -}}
+{{- $uuid := eq .ID.SQLType.Type "uuid" -}}
+
+import (
+	"database/sql/driver"
+	"encoding/binary"
+
+	"github.com/gofrs/uuid"
+	"github.com/sqlbunny/sqlbunny/types"
+	"github.com/sqlbunny/sqlbunny/types/null/convert"
+)
+
+// {{$typeName}} is an ID stored as {{.ID.SQLType.Type}}, whose external form
+// is prefixed with "{{.ID.Prefix}}_".
+{{if $uuid -}}
+type {{$typeName}} uuid.UUID
+
+// New{{$typeName}} returns a new random {{$typeName}}.
+func New{{$typeName}}() {{$typeName}} {
+	return {{$typeName}}(uuid.Must(uuid.NewV4()))
+}
+
+func (id {{$typeName}}) bytes() []byte {
+	return id[:]
+}
+
+func (id *{{$typeName}}) setBytes(b []byte) {
+	copy(id[:], b)
+}
+
+// Scan implements the Scanner interface.
+func (id *{{$typeName}}) Scan(value interface{}) error {
+	return (*uuid.UUID)(id).Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (id {{$typeName}}) Value() (driver.Value, error) {
+	return uuid.UUID(id).Value()
+}
+{{- else -}}
+type {{$typeName}} int64
+
+func (id {{$typeName}}) bytes() []byte {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, uint64(id))
+	return b
+}
+
+func (id *{{$typeName}}) setBytes(b []byte) {
+	*id = {{$typeName}}(binary.BigEndian.Uint64(b))
+}
+
+// Scan implements the Scanner interface.
+func (id *{{$typeName}}) Scan(value interface{}) error {
+	return convert.Assign((*int64)(id), value)
+}
+
+// Value implements the driver Valuer interface.
+func (id {{$typeName}}) Value() (driver.Value, error) {
+	return int64(id), nil
+}
+{{- end}}
+
+// Parse{{$typeName}} parses the external form of a {{$typeName}}.
+func Parse{{$typeName}}(s string) ({{$typeName}}, error) {
+	var id {{$typeName}}
+	err := id.UnmarshalText([]byte(s))
+	return id, err
+}
+
+// String returns the external form of id.
+func (id {{$typeName}}) String() string {
+	return types.FormatID("{{.ID.Prefix}}", id.bytes())
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (id {{$typeName}}) MarshalText() ([]byte, error) {
+	return []byte(id.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (id *{{$typeName}}) UnmarshalText(text []byte) error {
+	b := id.bytes()
+	if err := types.ParseID("{{.ID.Prefix}}", string(text), b); err != nil {
+		return err
+	}
+	id.setBytes(b)
+	return nil
+}
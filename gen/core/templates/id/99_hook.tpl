@@ -0,0 +1 @@
+{{ hook . "id" }}
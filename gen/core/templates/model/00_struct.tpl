@@ -26,6 +26,24 @@ type {{$modelName}} struct {
 	L {{$modelNameCamel}}L `json:"-" toml:"-" yaml:"-"`
 }
 
+{{- if hasPII .Model.Fields }}
+
+// String formats the {{$modelName}} like the %+v verb, with the values of its PII
+// fields replaced by bunny.RedactedText.
+func (o {{$modelName}}) String() string {
+	return fmt.Sprintf("{{$modelName}}{ {{- range $i, $field := .Model.Fields }}{{if $i}} {{end}}{{titleCase $field.Name}}:%v{{end -}} }",
+		{{- range $field := .Model.Fields }}
+		{{if $field.PII}}bunny.RedactedText{{else}}o.{{titleCase $field.Name}}{{end}},
+		{{- end }}
+	)
+}
+
+// GoString formats the {{$modelName}} like String, for the %#v verb.
+func (o {{$modelName}}) GoString() string {
+	return o.String()
+}
+{{- end }}
+
 var {{$modelName}}Columns = struct {
 	{{range $name, $column := .Model.Table.Columns -}}
 	{{titleCase $name}} string
@@ -36,11 +54,32 @@ var {{$modelName}}Columns = struct {
 	{{end -}}
 }
 
+type {{$modelNameCamel}}TableColumns struct {
+	{{range tableColumns .Model -}}
+	{{titleCase .Name}} queries.Column[{{goType .GoType}}]
+	{{end -}}
+}
+
+// All returns all the columns, to select them with qm.SelectColumns.
+func (c {{$modelNameCamel}}TableColumns) All() []queries.TableColumn {
+	return []queries.TableColumn{ {{- range $i, $c := tableColumns .Model}}{{if $i}}, {{end}}c.{{titleCase $c.Name}}{{end -}} }
+}
+
+// {{$modelName}}TableColumns are the columns of the table of {{$modelName}}
+// qualified by its name, to join it with qm.InnerJoinOn and select them
+// with qm.SelectColumns.
+var {{$modelName}}TableColumns = {{$modelNameCamel}}TableColumns{
+	{{range tableColumns .Model -}}
+	{{titleCase .Name}}: queries.Column[{{goType .GoType}}]{Table: "{{$.Model.Name}}", Name: "{{.Name}}"},
+	{{end -}}
+}
+
 // {{$modelNameCamel}}R is where relationships are stored.
 type {{$modelNameCamel}}R struct {
 	{{range .Model.Relationships -}}
 	{{- if .ToMany -}}
 	{{ .Name | titleCase }} {{ .ForeignModel | titleCase}}Slice
+	{{ .Name | titleCase }}Count int64
 	{{ else -}}
 	{{ .Name | titleCase }} *{{ .ForeignModel | titleCase}}
 	{{ end -}}
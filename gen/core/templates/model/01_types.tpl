@@ -4,6 +4,10 @@ var (
 	{{$varNameSingular}}Columns               = []string{{"{"}}{{modelColumns      .Model | stringMap .StringFuncs.quoteWrap | join ", "}}{{"}"}}
 	{{$varNameSingular}}PrimaryKeyColumns     = []string{{"{"}}{{modelPKColumns    .Model | stringMap .StringFuncs.quoteWrap | join ", "}}{{"}"}}
 	{{$varNameSingular}}NonPrimaryKeyColumns  = []string{{"{"}}{{modelNonPKColumns .Model | stringMap .StringFuncs.quoteWrap | join ", "}}{{"}"}}
+	{{$varNameSingular}}DBDefaultColumns      = []string{{"{"}}{{dbDefaultColumns  .Model | stringMap .StringFuncs.quoteWrap | join ", "}}{{"}"}}
+	{{$varNameSingular}}InsertColumns         = []string{{"{"}}{{insertColumns     .Model | stringMap .StringFuncs.quoteWrap | join ", "}}{{"}"}}
+	{{$varNameSingular}}ImmutableColumns      = []string{{"{"}}{{immutableColumns  .Model | stringMap .StringFuncs.quoteWrap | join ", "}}{{"}"}}
+	{{$varNameSingular}}UpdateColumns         = []string{{"{"}}{{updateColumns     .Model | stringMap .StringFuncs.quoteWrap | join ", "}}{{"}"}}
 )
 
 type (
@@ -0,0 +1,27 @@
+{{- $modelName := .Model.Name | titleCase -}}
+{{- $varNameSingular := .Model.Name | singular | camelCase -}}
+{{- $dests := scanDests .Model -}}
+// {{$varNameSingular}}Scanner binds the rows of queries into {{$modelName}} objects
+// without reflection.
+var {{$varNameSingular}}Scanner = queries.NewScanner(
+	[]string{ {{- range $i, $d := $dests}}{{if $i}}, {{end}}"{{$d.Column}}"{{end -}} },
+	(*{{$modelName}}).scanRow,
+)
+
+// scanRow scans the current row of rows into o. cols are the indexes of the
+// columns of the row in the columns of {{$varNameSingular}}Scanner, or -1 for the
+// columns to discard.
+func (o *{{$modelName}}) scanRow(rows *sql.Rows, cols []int) error {
+	dests := make([]interface{}, len(cols))
+	for i, c := range cols {
+		switch c {
+		{{- range $i, $d := $dests}}
+		case {{$i}}:
+			dests[i] = {{$d.Dest}}
+		{{- end}}
+		default:
+			dests[i] = new(interface{})
+		}
+	}
+	return rows.Scan(dests...)
+}
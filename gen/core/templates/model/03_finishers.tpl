@@ -3,9 +3,11 @@
 // One returns a single {{$varNameSingular}} record from the query. If the query returns no objects, ErrNoRows is returned. 
 // If the query returns multiple rows, bunny.ErrMultipleRows is returned.
 func (q {{$varNameSingular}}Query) One(ctx context.Context) (*{{$modelNameSingular}}, error) {
+	ctx = bunny.WithOperation(ctx, "{{.Model.Name}}", "one")
+
 	o := &{{$modelNameSingular}}{}
 
-	err := q.Bind(ctx, o)
+	err := {{$varNameSingular}}Scanner.One(ctx, q.Query, o)
 	if err != nil {
 		return nil, errors.Errorf("{{.PkgName}}: failed to execute a one query for {{.Model.Name}}: %w", err)
 	}
@@ -18,11 +20,13 @@ func (q {{$varNameSingular}}Query) One(ctx context.Context) (*{{$modelNameSingul
 // First returns a single {{$varNameSingular}} record from the query. If the query returns no objects, ErrNoRows is returned. 
 // If the query returns multiple objects, the first one is picked (and no error is generated).
 func (q {{$varNameSingular}}Query) First(ctx context.Context) (*{{$modelNameSingular}}, error) {
+	ctx = bunny.WithOperation(ctx, "{{.Model.Name}}", "first")
+
 	o := &{{$modelNameSingular}}{}
 
 	queries.SetLimit(q.Query, 1)
 
-	err := q.Bind(ctx, o)
+	err := {{$varNameSingular}}Scanner.One(ctx, q.Query, o)
 	if err != nil {
 		return nil, errors.Errorf("{{.PkgName}}: failed to execute a one query for {{.Model.Name}}: %w", err)
 	}
@@ -34,9 +38,11 @@ func (q {{$varNameSingular}}Query) First(ctx context.Context) (*{{$modelNameSing
 
 // All returns all {{$modelNameSingular}} records from the query.
 func (q {{$varNameSingular}}Query) All(ctx context.Context) ({{$modelNameSingular}}Slice, error) {
+	ctx = bunny.WithOperation(ctx, "{{.Model.Name}}", "all")
+
 	var o []*{{$modelNameSingular}}
 
-	err := q.Bind(ctx, &o)
+	err := {{$varNameSingular}}Scanner.All(ctx, q.Query, &o)
 	if err != nil {
 		return nil, errors.Errorf("{{.PkgName}}: failed to assign all query results to {{$modelNameSingular}} slice: %w", err)
 	}
@@ -46,14 +52,74 @@ func (q {{$varNameSingular}}Query) All(ctx context.Context) ({{$modelNameSingula
 	return o, nil
 }
 
+{{range $key := mapKeys .Model -}}
+{{- $keyType := goType $key.Field.GoType -}}
+// AllBy{{$key.Name}} returns all {{$modelNameSingular}} records from the query by their {{$key.Path.DotName}}.
+func (q {{$varNameSingular}}Query) AllBy{{$key.Name}}(ctx context.Context) (map[{{$keyType}}]*{{$modelNameSingular}}, error) {
+	o, err := q.All(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return o.By{{$key.Name}}(), nil
+}
+
+// By{{$key.Name}} returns the {{$modelNameSingular}} records of the slice by their {{$key.Path.DotName}}.
+func (o {{$modelNameSingular}}Slice) By{{$key.Name}}() map[{{$keyType}}]*{{$modelNameSingular}} {
+	res := make(map[{{$keyType}}]*{{$modelNameSingular}}, len(o))
+	for _, obj := range o {
+		res[obj.{{$key.Path | titleCasePath}}] = obj
+	}
+	return res
+}
+
+{{end -}}
+{{if .Model.ShardKey -}}
+// AllShards returns the {{$modelNameSingular}} records from the query in all the shards,
+// see bunny.FanOut. Its ordering, limit and offset apply to the records of each shard.
+func (q {{$varNameSingular}}Query) AllShards(ctx context.Context) ({{$modelNameSingular}}Slice, error) {
+	ctx = bunny.WithOperation(ctx, "{{.Model.Name}}", "all_shards")
+
+	var o []*{{$modelNameSingular}}
+
+	err := q.BindShards(ctx, &o)
+	if err != nil {
+		return nil, errors.Errorf("{{.PkgName}}: failed to assign all shards query results to {{$modelNameSingular}} slice: %w", err)
+	}
+
+	{{ hook . "after_select_slice" "o" .Model }}
+
+	return o, nil
+}
+
+{{end -}}
+// Each calls fn for each {{$modelNameSingular}} record from the query. Records are scanned
+// one at a time from the database cursor instead of being loaded in memory all at once,
+// so it's suitable for large result sets. Iteration stops at the first error returned by fn.
+func (q {{$varNameSingular}}Query) Each(ctx context.Context, fn func(*{{$modelNameSingular}}) error) error {
+	ctx = bunny.WithOperation(ctx, "{{.Model.Name}}", "each")
+
+	err := {{$varNameSingular}}Scanner.Each(ctx, q.Query, func(o *{{$modelNameSingular}}) error {
+		{{ hook . "after_select_noreturn" "o" .Model }}
+
+		return fn(o)
+	})
+	if err != nil {
+		return errors.Errorf("{{.PkgName}}: failed to iterate {{$modelNameSingular}} records: %w", err)
+	}
+
+	return nil
+}
+
 // Count returns the count of all {{$modelNameSingular}} records in the query.
 func (q {{$varNameSingular}}Query) Count(ctx context.Context) (int64, error) {
+	ctx = bunny.WithOperation(ctx, "{{.Model.Name}}", "count")
+
 	var count int64
 
 	queries.SetSelect(q.Query, nil)
 	queries.SetCount(q.Query)
 
-	err := q.Query.QueryRow(ctx).Scan(&count)
+	err := q.Query.ScanRow(ctx, &count)
 	if err != nil {
 		return 0, errors.Errorf("{{.PkgName}}: failed to count {{.Model.Name}} rows: %w", err)
 	}
@@ -63,12 +129,14 @@ func (q {{$varNameSingular}}Query) Count(ctx context.Context) (int64, error) {
 
 // Exists checks if the row exists in the model.
 func (q {{$varNameSingular}}Query) Exists(ctx context.Context) (bool, error) {
+	ctx = bunny.WithOperation(ctx, "{{.Model.Name}}", "exists")
+
 	var count int64
 
 	queries.SetCount(q.Query)
 	queries.SetLimit(q.Query, 1)
 
-	err := q.Query.QueryRow(ctx).Scan(&count)
+	err := q.Query.ScanRow(ctx, &count)
 	if err != nil {
 		return false, errors.Errorf("{{.PkgName}}: failed to check if {{.Model.Name}} exists: %w", err)
 	}
@@ -19,9 +19,9 @@ func (o *{{$modelName}}) {{$relationshipName}}(mods ...qm.QueryMod) ({{$foreignM
 	queryMods := []qm.QueryMod{
 		{{if .IsJoinModel -}}
 		qm.InnerJoin("{{.JoinModel | schemaModel }} ON {{joinOnClause $dot.LQ $dot.RQ .JoinModel .JoinForeignFields .ForeignModel .ForeignFields}}"),
-		qm.Where("{{joinWhereClause $dot.LQ $dot.RQ 0 .JoinModel .JoinLocalFields}}" {{range .LocalFields}}, o.{{. | titleCasePath}}{{end}}),
+		qm.Where("{{joinWhereClause $dot.LQ $dot.RQ 0 .JoinModel .JoinLocalFields}}" {{range .LocalFields}}, {{sqlArg ($model.FindField .) (printf "o.%s" (titleCasePath .))}}{{end}}),
 		{{ else }}
-		qm.Where("{{whereClause $dot.LQ $dot.RQ 0 .ForeignFields}}" {{range .LocalFields}}, o.{{. | titleCasePath}}{{end}}),
+		qm.Where("{{whereClause $dot.LQ $dot.RQ 0 .ForeignFields}}" {{range .LocalFields}}, {{sqlArg ($model.FindField .) (printf "o.%s" (titleCasePath .))}}{{end}}),
 		{{- end }}
 		{{if .ForeignWhere -}}
 		{{- $schemaModel := .ForeignModel | schemaModel }}
@@ -36,22 +36,31 @@ func (o *{{$modelName}}) {{$relationshipName}}(mods ...qm.QueryMod) ({{$foreignM
 	query := {{$foreignModelNamePlural}}(queryMods...)
 	queries.SetFrom(query.Query, "{{.ForeignModel | schemaModel}}")
 	if len(queries.GetSelect(query.Query)) == 0 {
-		queries.SetSelect(query.Query, []string{"{{.ForeignModel | schemaModel}}.*"})
+		queries.SetSelect(query.Query, []string{queries.TableRef(query.Query, "{{.ForeignModel | schemaModel}}") + ".*"})
 	}
 
 	return query
 }
 
+// Has{{$relationshipName}} checks if the object has {{$relationshipName}}, filtered
+// by mods, without loading them.
+func (o *{{$modelName}}) Has{{$relationshipName}}(ctx context.Context, mods ...qm.QueryMod) (bool, error) {
+	return o.{{$relationshipName}}(mods...).Exists(ctx)
+}
+
 // Load{{$relationshipName}} allows an eager lookup of values, cached into the
 // loaded structs of the objects.
 func ({{$modelNameCamel}}L) Load{{$relationshipName}}(ctx context.Context, slice []*{{$modelName}}) error {
+	{{- if $foreignModel.ShardKey}}
+	ctx = bunny.RequireShard(ctx)
+	{{- end}}
 	args := make([]interface{}, len(slice)*{{len .LocalFields}})
 	for i, obj := range slice {
 		if obj.R == nil {
 			obj.R = &{{$modelNameCamel}}R{}
 		}
 		{{ range $i, $c := .LocalFields }}
-		args[i*{{len $relationship.LocalFields}} + {{$i}}] = obj.{{$c | titleCasePath}}
+		args[i*{{len $relationship.LocalFields}} + {{$i}}] = {{sqlArg ($model.FindField $c) (printf "obj.%s" (titleCasePath $c))}}
 		{{ end }}
 	}
 
@@ -64,26 +73,35 @@ func ({{$modelNameCamel}}L) Load{{$relationshipName}}(ctx context.Context, slice
 	{{- $joinModelName := .JoinModel | titleCase}}
 	{{- $joinModelNameCamel := .JoinModel | camelCase}}
 
-	where := fmt.Sprintf(
-		"{{ whereInClause $dot.LQ $dot.RQ "j" .JoinLocalFields }} in (%s)",
-		strmangle.Placeholders(dialect.IndexPlaceholders, len(slice)*{{len .LocalFields}}, 1, {{len .LocalFields}}),
-	)
 	query := NewQuery(
 		qm.Select(
 			{{ range $i, $c := $foreignModel.Table.Columns -}}"f.{{$i}}",{{end}}
 			{{ range $i, $c := .JoinLocalFields -}}{{if $i}},{{end}} "j.{{$c}}"{{end}},
 		),
-		qm.From("{{.ForeignModel | schemaModel}} AS f"),
+		qm.From("{{.ForeignModel | schemaModel}}"),
+		qm.As("f"),
 		qm.InnerJoin("{{.JoinModel | schemaModel }} AS j ON {{joinOnClause $dot.LQ $dot.RQ "j" .JoinForeignFields "f" .ForeignFields}}"),
-		qm.Where(where, args...),
+		qm.WhereIn("{{ whereInClause $dot.LQ $dot.RQ "j" .JoinLocalFields }} in ?", args...),
 		{{if .ForeignWhere -}}
 		{{- $schemaModel := .ForeignModel | schemaModel }}
 		qm.Where("{{replaceAll .ForeignWhere "f" $schemaModel}}"),
 		{{- end }}
+		{{if $foreignModel.DefaultScope -}}
+		qm.Where({{printf "%q" $foreignModel.DefaultScope}}),
+		{{- end }}
 		{{if .ForeignOrderBy -}}
 		qm.OrderBy("{{.ForeignOrderBy}}"),
 		{{- end }}
 	)
+	{{- if and $foreignModel.DefaultOrderBy (not .ForeignOrderBy)}}
+	queries.SetDefaultOrderBy(query, {{printf "%q" $foreignModel.DefaultOrderBy}})
+	{{- end}}
+	queries.SetChunked(query)
+	queries.SetContextMods(query, {{.ForeignModel | singular | camelCase}}ContextMods)
+	queries.ApplyLoadMods(ctx, query)
+	{{if .ToMany -}}
+	queries.SetLimitPer(query, "{{ whereInClause $dot.LQ $dot.RQ "j" .JoinLocalFields }}")
+	{{- end}}
 	type joinStruct struct {
 		F {{ $foreignModelName }} `bunny:"f.,bind"`
 		J {{ $joinModelName }} `bunny:"j.,bind"`
@@ -115,25 +133,34 @@ func ({{$modelNameCamel}}L) Load{{$relationshipName}}(ctx context.Context, slice
 		}
 	}
 	{{else}}
-	where := fmt.Sprintf(
-		"{{ whereInClause $dot.LQ $dot.RQ "f" .ForeignFields }} in (%s)",
-		strmangle.Placeholders(dialect.IndexPlaceholders, len(slice)*{{len .LocalFields}}, 1, {{len .LocalFields}}),
-	)
 	query := NewQuery(
 		qm.Select("f.*"),
-		qm.From("{{.ForeignModel | schemaModel}} AS f"),
-		qm.Where(where, args...),
+		qm.From("{{.ForeignModel | schemaModel}}"),
+		qm.As("f"),
+		qm.WhereIn("{{ whereInClause $dot.LQ $dot.RQ "f" .ForeignFields }} in ?", args...),
 		{{if .ForeignWhere -}}
 		{{- $schemaModel := .ForeignModel | schemaModel }}
 		qm.Where("{{replaceAll .ForeignWhere "$foreign" "f"}}"),
 		{{- end }}
+		{{if $foreignModel.DefaultScope -}}
+		qm.Where({{printf "%q" $foreignModel.DefaultScope}}),
+		{{- end }}
 		{{if .ForeignOrderBy -}}
 		qm.OrderBy("{{.ForeignOrderBy}}"),
 		{{- end }}
 	)
+	{{- if and $foreignModel.DefaultOrderBy (not .ForeignOrderBy)}}
+	queries.SetDefaultOrderBy(query, {{printf "%q" $foreignModel.DefaultOrderBy}})
+	{{- end}}
+	queries.SetChunked(query)
+	queries.SetContextMods(query, {{.ForeignModel | singular | camelCase}}ContextMods)
+	queries.ApplyLoadMods(ctx, query)
+	{{if .ToMany -}}
+	queries.SetLimitPer(query, "{{ whereInClause $dot.LQ $dot.RQ "f" .ForeignFields }}")
+	{{- end}}
 
 	var resultSlice []*{{$foreignModelName}}
-	if err := query.Bind(ctx, &resultSlice); err != nil {
+	if err := {{.ForeignModel | singular | camelCase}}Scanner.All(ctx, query, &resultSlice); err != nil {
 		return errors.Errorf("failed to bind eager loaded slice {{$foreignModelName}}: %w", err)
 	}
 
@@ -166,4 +193,128 @@ func ({{$modelNameCamel}}L) Load{{$relationshipName}}(ctx context.Context, slice
 	return nil
 }
 
+{{if .ToMany -}}
+// Load{{$relationshipName}}Count allows an eager lookup of the number of
+// {{$relationshipName}} of the objects, cached into {{$relationshipName}}Count of
+// their loaded structs, without loading the rows.
+func ({{$modelNameCamel}}L) Load{{$relationshipName}}Count(ctx context.Context, slice []*{{$modelName}}) error {
+	{{- if $foreignModel.ShardKey}}
+	ctx = bunny.RequireShard(ctx)
+	{{- end}}
+	args := make([]interface{}, len(slice)*{{len .LocalFields}})
+	for i, obj := range slice {
+		if obj.R == nil {
+			obj.R = &{{$modelNameCamel}}R{}
+		}
+		obj.R.{{$relationshipName}}Count = 0
+		{{ range $i, $c := .LocalFields }}
+		args[i*{{len $relationship.LocalFields}} + {{$i}}] = {{sqlArg ($model.FindField $c) (printf "obj.%s" (titleCasePath $c))}}
+		{{ end }}
+	}
+
+	if len(args) == 0 {
+		return nil
+	}
+
+	{{if .IsJoinModel }}
+	{{ $joinModel := index $dot.Schema.Models .JoinModel }}
+	{{- $joinModelName := .JoinModel | titleCase}}
+	query := NewQuery(
+		qm.Select(
+			{{ range .JoinLocalFields -}}"{{$dot.LQ}}j{{$dot.RQ}}.{{$dot.LQ}}{{.SQLName}}{{$dot.RQ}} AS {{$dot.LQ}}j.{{.SQLName}}{{$dot.RQ}}",{{end}}
+		),
+		qm.Count("*", "count"),
+		qm.From("{{.ForeignModel | schemaModel}}"),
+		qm.As("f"),
+		qm.InnerJoin("{{.JoinModel | schemaModel }} AS j ON {{joinOnClause $dot.LQ $dot.RQ "j" .JoinForeignFields "f" .ForeignFields}}"),
+		qm.WhereIn("{{ whereInClause $dot.LQ $dot.RQ "j" .JoinLocalFields }} in ?", args...),
+		{{if .ForeignWhere -}}
+		qm.Where("{{replaceAll .ForeignWhere "$foreign" "f"}}"),
+		{{- end }}
+		{{if $foreignModel.DefaultScope -}}
+		qm.Where({{printf "%q" $foreignModel.DefaultScope}}),
+		{{- end }}
+		{{ range .JoinLocalFields -}}
+		qm.GroupBy("{{$dot.LQ}}j{{$dot.RQ}}.{{$dot.LQ}}{{.SQLName}}{{$dot.RQ}}"),
+		{{ end -}}
+	)
+	queries.SetChunked(query)
+	queries.SetContextMods(query, {{.ForeignModel | singular | camelCase}}ContextMods)
+	queries.ApplyLoadMods(ctx, query)
+
+	type countStruct struct {
+		J     {{ $joinModelName }} `bunny:"j.,bind"`
+		Count int64 `bunny:"count"`
+	}
+	var resultSlice []*countStruct
+	if err := query.Bind(ctx, &resultSlice); err != nil {
+		return errors.Errorf("failed to bind eager loaded count {{$relationshipName}}: %w", err)
+	}
+
+	for _, local := range slice {
+		for _, counted := range resultSlice {
+			if {{ range $i, $lc := .LocalFields -}}
+				{{- if $i}} && {{end}}
+				{{- $jc := index $relationship.JoinLocalFields $i -}}
+				{{- $lcol := $model.FindField $lc -}}
+				{{- $jcol := $joinModel.FindField $jc -}}
+				{{doCompare (printf "local.%s" ($lc | titleCasePath)) (printf "counted.J.%s" ($jc | titleCasePath)) $lcol $jcol }}
+			{{- end }} {
+				local.R.{{$relationshipName}}Count = counted.Count
+				break
+			}
+		}
+	}
+	{{else}}
+	query := NewQuery(
+		qm.Select(
+			{{ range .ForeignFields -}}"{{$dot.LQ}}f{{$dot.RQ}}.{{$dot.LQ}}{{.SQLName}}{{$dot.RQ}} AS {{$dot.LQ}}f.{{.SQLName}}{{$dot.RQ}}",{{end}}
+		),
+		qm.Count("*", "count"),
+		qm.From("{{.ForeignModel | schemaModel}}"),
+		qm.As("f"),
+		qm.WhereIn("{{ whereInClause $dot.LQ $dot.RQ "f" .ForeignFields }} in ?", args...),
+		{{if .ForeignWhere -}}
+		qm.Where("{{replaceAll .ForeignWhere "$foreign" "f"}}"),
+		{{- end }}
+		{{if $foreignModel.DefaultScope -}}
+		qm.Where({{printf "%q" $foreignModel.DefaultScope}}),
+		{{- end }}
+		{{ range .ForeignFields -}}
+		qm.GroupBy("{{$dot.LQ}}f{{$dot.RQ}}.{{$dot.LQ}}{{.SQLName}}{{$dot.RQ}}"),
+		{{ end -}}
+	)
+	queries.SetChunked(query)
+	queries.SetContextMods(query, {{.ForeignModel | singular | camelCase}}ContextMods)
+	queries.ApplyLoadMods(ctx, query)
+
+	type countStruct struct {
+		F     {{ $foreignModelName }} `bunny:"f.,bind"`
+		Count int64 `bunny:"count"`
+	}
+	var resultSlice []*countStruct
+	if err := query.Bind(ctx, &resultSlice); err != nil {
+		return errors.Errorf("failed to bind eager loaded count {{$relationshipName}}: %w", err)
+	}
+
+	for _, local := range slice {
+		for _, counted := range resultSlice {
+			if {{ range $i, $lc := .LocalFields -}}
+				{{- if $i}} && {{end}}
+				{{- $fc := index $relationship.ForeignFields $i -}}
+				{{- $lcol := $model.FindField $lc -}}
+				{{- $fcol := $foreignModel.FindField $fc -}}
+				{{doCompare (printf "local.%s" ($lc | titleCasePath)) (printf "counted.F.%s" ($fc | titleCasePath)) $lcol $fcol }}
+			{{- end }} {
+				local.R.{{$relationshipName}}Count = counted.Count
+				break
+			}
+		}
+	}
+	{{end}}
+
+	return nil
+}
+{{- end }}
+
 {{ end -}}
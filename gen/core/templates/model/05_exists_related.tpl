@@ -0,0 +1,42 @@
+{{- if .Model.Relationships -}}
+{{- $dot := . -}}
+{{- $model := .Model -}}
+{{- $modelNamePlural := .Model.Name | plural -}}
+{{- $varNameSingular := .Model.Name | singular | camelCase -}}
+// WhereExistsRelated filters the {{$modelNamePlural}} of the query to the ones with
+// rows in the relationship, filtered by mods, with an EXISTS subquery. The
+// relationship is one of the names of the relationships of the model:
+// {{range $i, $r := .Model.Relationships}}{{if $i}}, {{end}}{{$r.Name | titleCase}}{{end}}. Other names make the query fail.
+func (q {{$varNameSingular}}Query) WhereExistsRelated(relationship string, mods ...qm.QueryMod) {{$varNameSingular}}Query {
+	local := queries.TableRef(q.Query, "{{$dot.LQ}}{{$model.Name}}{{$dot.RQ}}")
+
+	switch relationship {
+	{{- range .Model.Relationships}}
+	{{- $relationship := .}}
+	{{- $foreignModelNamePlural := .ForeignModel | plural | titleCase}}
+	case "{{.Name | titleCase}}":
+		sub := {{$foreignModelNamePlural}}(mods...)
+		queries.SetSelect(sub.Query, []string{"1"})
+		{{- if .IsJoinModel}}
+		queries.AppendInnerJoin(sub.Query, "{{.JoinModel | schemaModel}} ON {{joinOnClause $dot.LQ $dot.RQ .JoinModel .JoinForeignFields .ForeignModel .ForeignFields}}")
+		queries.AppendWhere(sub.Query, fmt.Sprintf("{{range $i, $c := .JoinLocalFields}}{{if $i}} AND {{end}}{{$dot.LQ}}{{$relationship.JoinModel}}{{$dot.RQ}}.{{$dot.LQ}}{{$c.SQLName}}{{$dot.RQ}} = %[1]s.{{$dot.LQ}}{{(index $relationship.LocalFields $i).SQLName}}{{$dot.RQ}}{{end}}", local))
+		{{- else if eq .ForeignModel $model.Name}}
+		{{- /* The subquery is aliased, so the table of the query can be referenced. */}}
+		queries.SetAlias(sub.Query, "related")
+		queries.AppendWhere(sub.Query, fmt.Sprintf("{{range $i, $c := .ForeignFields}}{{if $i}} AND {{end}}{{$dot.LQ}}related{{$dot.RQ}}.{{$dot.LQ}}{{$c.SQLName}}{{$dot.RQ}} = %[1]s.{{$dot.LQ}}{{(index $relationship.LocalFields $i).SQLName}}{{$dot.RQ}}{{end}}", local))
+		{{- else}}
+		queries.AppendWhere(sub.Query, fmt.Sprintf("{{range $i, $c := .ForeignFields}}{{if $i}} AND {{end}}{{$dot.LQ}}{{$relationship.ForeignModel}}{{$dot.RQ}}.{{$dot.LQ}}{{$c.SQLName}}{{$dot.RQ}} = %[1]s.{{$dot.LQ}}{{(index $relationship.LocalFields $i).SQLName}}{{$dot.RQ}}{{end}}", local))
+		{{- end}}
+		{{- if and .ForeignWhere (not .IsJoinModel) (eq .ForeignModel $model.Name)}}
+		queries.AppendWhere(sub.Query, "{{replaceAll .ForeignWhere "$foreign" (printf "%srelated%s" $dot.LQ $dot.RQ)}}")
+		{{- else if .ForeignWhere}}
+		queries.AppendWhere(sub.Query, "{{replaceAll .ForeignWhere "$foreign" (.ForeignModel | schemaModel)}}")
+		{{- end}}
+		queries.AppendWhere(q.Query, "EXISTS ?", sub.Query)
+	{{- end}}
+	default:
+		queries.SetError(q.Query, errors.Errorf("{{$dot.PkgName}}: unknown relationship %s of {{$model.Name}}", relationship))
+	}
+	return q
+}
+{{- end -}}
@@ -0,0 +1,61 @@
+{{- $dot := . -}}
+{{- $modelName := .Model.Name | titleCase -}}
+{{- range .Model.Relationships -}}
+{{- if .IsJoinModel -}}
+{{- $relationship := . -}}
+{{- $relationshipName := .Name | titleCase -}}
+{{- $foreignModelName := .ForeignModel | titleCase -}}
+{{- $joinModel := index $dot.Schema.Models .JoinModel -}}
+{{- $joinModelName := .JoinModel | titleCase}}
+
+// Add{{$relationshipName}} relates o to the related {{$foreignModelName}} records, with the rows of
+// {{.JoinModel}} inserted in as few statements as the placeholder limit allows. The rows
+// which already exist are ignored. The loaded relationship of o isn't changed.
+func (o *{{$modelName}}) Add{{$relationshipName}}(ctx context.Context, related ...*{{$foreignModelName}}) error {
+	rows := make({{$joinModelName}}Slice, len(related))
+	for i, rel := range related {
+		rows[i] = &{{$joinModelName}}{}
+		{{- range $i, $f := .JoinLocalFields}}
+		rows[i].{{$f | titleCasePath}} = o.{{index $relationship.LocalFields $i | titleCasePath}}
+		{{- end}}
+		{{- range $i, $f := .JoinForeignFields}}
+		rows[i].{{$f | titleCasePath}} = rel.{{index $relationship.ForeignFields $i | titleCasePath}}
+		{{- end}}
+	}
+
+	if _, err := rows.InsertIgnoreAll(ctx); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Remove{{$relationshipName}} unrelates o from the related {{$foreignModelName}} records, with the rows
+// of {{.JoinModel}} deleted in as few statements as the placeholder limit allows, and
+// their delete hooks run. The loaded relationship of o isn't changed.
+func (o *{{$modelName}}) Remove{{$relationshipName}}(ctx context.Context, related ...*{{$foreignModelName}}) error {
+	chunkSize := queries.MaxPlaceholders / {{len $joinModel.PrimaryKey.Fields}}
+	for start := 0; start < len(related); start += chunkSize {
+		chunk := related[start:]
+		if len(chunk) > chunkSize {
+			chunk = chunk[:chunkSize]
+		}
+
+		rows := make({{$joinModelName}}Slice, len(chunk))
+		for i, rel := range chunk {
+			rows[i] = &{{$joinModelName}}{}
+			{{- range $i, $f := .JoinLocalFields}}
+			rows[i].{{$f | titleCasePath}} = o.{{index $relationship.LocalFields $i | titleCasePath}}
+			{{- end}}
+			{{- range $i, $f := .JoinForeignFields}}
+			rows[i].{{$f | titleCasePath}} = rel.{{index $relationship.ForeignFields $i | titleCasePath}}
+			{{- end}}
+		}
+
+		if err := rows.DeleteAll(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+{{end -}}
+{{- end -}}
@@ -1,8 +1,37 @@
 {{- $modelName := .Model.Name | titleCase -}}
 {{- $modelNamePlural := .Model.Name | plural | titleCase -}}
 {{- $varNameSingular := .Model.Name | singular | camelCase}}
+var {{$varNameSingular}}ContextMods []func(ctx context.Context, q *queries.Query)
+
+// Add{{$modelName}}ContextMod registers fn to add mods to every {{$modelNamePlural}} query
+// from the context it runs with, like a filter by the tenant of the context.
+// It applies to the relationship queries and eager loads of other models too,
+// where the table is aliased, so the mods must refer to it with
+// queries.TableRef. The statements by primary key of Find, Exists, Reload,
+// Update and Delete only take the where clauses of the mods, and fail with
+// other mods. Finds with mods don't use the cache nor batching. Mods must be
+// registered before the queries run, usually in an init function.
+func Add{{$modelName}}ContextMod(fn func(ctx context.Context) []qm.QueryMod) {
+	{{$varNameSingular}}ContextMods = append({{$varNameSingular}}ContextMods, func(ctx context.Context, q *queries.Query) {
+		qm.Apply(q, fn(ctx)...)
+	})
+}
+
 // {{$modelNamePlural}} creates a {{$modelNamePlural}} query with the given mods.
 func {{$modelNamePlural}}(mods ...qm.QueryMod) {{$varNameSingular}}Query {
 	mods = append(mods, qm.From("{{.Model.Name | schemaModel}}"))
-	return {{$varNameSingular}}Query{NewQuery(mods...)}
+	q := NewQuery(mods...)
+	{{- if .Model.ShardKey}}
+	queries.SetSharded(q)
+	{{- end}}
+	{{- if .Model.DefaultScope}}
+	if !queries.IsUnscoped(q) {
+		queries.AppendWhere(q, {{printf "%q" .Model.DefaultScope}})
+	}
+	{{- end}}
+	{{- if .Model.DefaultOrderBy}}
+	queries.SetDefaultOrderBy(q, {{printf "%q" .Model.DefaultOrderBy}})
+	{{- end}}
+	queries.SetContextMods(q, {{$varNameSingular}}ContextMods)
+	return {{$varNameSingular}}Query{q}
 }
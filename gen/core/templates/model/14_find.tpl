@@ -3,10 +3,50 @@
 {{- $model := .Model -}}
 
 // Find{{$modelNameSingular}} retrieves a single record by ID with an executor.
-// If selectCols is empty Find will return all fields.
+// If selectCols is empty Find will return all fields, and the cache set with
+// bunny.SetCache and the batching enabled with bunny.WithBatching are used,
+// unless there are context mods (see Add{{$modelNameSingular}}ContextMod), since
+// the cached and batched rows aren't scoped by them. With a cache, the rows
+// missing from it are read from the primary, not the read replica.
 func Find{{$modelNameSingular}}(ctx context.Context{{range .Model.PrimaryKey.Fields}}, {{$f := $model.FindField .}}{{$f.Name | camelCase}} {{goType $f.Type.GoType}}{{end}}, selectCols ...string) (*{{$modelNameSingular}}, error) {
+	ctx = bunny.WithOperation(ctx, "{{.Model.Name}}", "find")
+	{{- if .Model.ShardKey}}
+	{{- $shardArg := ""}}
+	{{- range .Model.PrimaryKey.Fields}}{{if .Equals $model.ShardKey}}{{$shardArg = ($model.FindField .).Name | camelCase}}{{end}}{{end}}
+	{{- if $shardArg}}
+	ctx = bunny.WithShardKey(ctx, {{$shardArg}})
+	{{- else}}
+	ctx = bunny.RequireShard(ctx)
+	{{- end}}
+	{{- end}}
+
 	{{$varNameSingular}}Obj := &{{$modelNameSingular}}{}
 
+	cached := len(selectCols) == 0 && len({{$varNameSingular}}ContextMods) == 0
+	cacheKey := bunny.CacheKey({{range $i, $p := .Model.PrimaryKey.Fields}}{{if $i}}, {{end}}{{$f := $model.FindField $p}}{{$f.Name | camelCase}}{{end}})
+	if cached && bunny.CacheGet(ctx, "{{.Model.Name}}", cacheKey, {{$varNameSingular}}Obj) {
+		return {{$varNameSingular}}Obj, nil
+	}
+	if cached {
+		ctx = bunny.CacheFillContext(ctx)
+	}
+
+	if cached {
+		obj, batched, err := bunny.BatchLoad(ctx, "{{.Model.Name}}", find{{$modelNameSingular}}Batch{{range .Model.PrimaryKey.Fields}}, {{$f := $model.FindField .}}{{$f.Name | camelCase}}{{end}})
+		if batched {
+			if err != nil {
+				return nil, errors.Errorf("{{.PkgName}}: unable to select from {{.Model.Name}}: %w", err)
+			}
+			if obj == nil {
+				return nil, errors.Errorf("{{.PkgName}}: unable to select from {{.Model.Name}}: %w", sql.ErrNoRows)
+			}
+			// The batch's rows are shared by its lookups.
+			*{{$varNameSingular}}Obj = *obj.(*{{$modelNameSingular}})
+			bunny.CacheSet(ctx, "{{.Model.Name}}", cacheKey, {{$varNameSingular}}Obj)
+			return {{$varNameSingular}}Obj, nil
+		}
+	}
+
 	sel := "*"
 	if len(selectCols) > 0 {
 		sel = strings.Join(strmangle.IdentQuoteSlice(dialect.LQ, dialect.RQ, selectCols), ",")
@@ -14,13 +54,45 @@ func Find{{$modelNameSingular}}(ctx context.Context{{range .Model.PrimaryKey.Fie
 	query := fmt.Sprintf(
 		"SELECT %s FROM {{.Model.Name | schemaModel}} WHERE {{if .Dialect.IndexPlaceholders}}{{whereClause .LQ .RQ 1 .Model.PrimaryKey.Fields}}{{else}}{{whereClause .LQ .RQ 0 .Model.PrimaryKey.Fields}}{{end}}", sel,
 	)
+	{{- if .Model.DefaultScope}}
+	query += {{printf " AND (%s)" .Model.DefaultScope | printf "%q"}}
+	{{- end}}
 
-	q := queries.Raw(query{{range .Model.PrimaryKey.Fields}}, {{$f := $model.FindField .}}{{$f.Name | camelCase}}{{end}})
+	args := []interface{}{ {{- range $i, $p := .Model.PrimaryKey.Fields}}{{if $i}}, {{end}}{{$f := $model.FindField $p}}{{sqlArg $f ($f.Name | camelCase)}}{{end -}} }
+	scope, scopeArgs, err := queries.ContextWhere(ctx, &dialect, {{$varNameSingular}}ContextMods, len(args)+1)
+	if err != nil {
+		return nil, errors.Errorf("{{.PkgName}}: unable to select from {{.Model.Name}}: %w", err)
+	}
+	q := queries.Raw(query+scope, append(args, scopeArgs...)...)
 
-	err := q.Bind(ctx, {{$varNameSingular}}Obj)
+	err = {{$varNameSingular}}Scanner.One(ctx, q, {{$varNameSingular}}Obj)
 	if err != nil {
 		return nil, errors.Errorf("{{.PkgName}}: unable to select from {{.Model.Name}}: %w", err)
 	}
 
+	if cached {
+		bunny.CacheSet(ctx, "{{.Model.Name}}", cacheKey, {{$varNameSingular}}Obj)
+	}
+
 	return {{$varNameSingular}}Obj, nil
 }
+
+// find{{$modelNameSingular}}Batch loads a batch of Find{{$modelNameSingular}} lookups.
+func find{{$modelNameSingular}}Batch(ctx context.Context, keys [][]interface{}) (map[string]interface{}, error) {
+	var args []interface{}
+	for _, key := range keys {
+		args = append(args, {{range $i, $p := .Model.PrimaryKey.Fields}}{{if $i}}, {{end}}{{sqlArg ($model.FindField $p) (printf "key[%d]" $i)}}{{end}})
+	}
+
+	var objs []*{{$modelNameSingular}}
+	err := {{$varNameSingular}}Scanner.All(ctx, {{.Model.Name | plural | titleCase}}(qm.WhereIn("{{if gt (len .Model.PrimaryKey.Fields) 1}}({{end}}{{range $i, $f := .Model.PrimaryKey.Fields}}{{if $i}},{{end}}{{$f.SQLName}}{{end}}{{if gt (len .Model.PrimaryKey.Fields) 1}}){{end}} in ?", args...)).Query, &objs)
+	if err != nil {
+		return nil, err
+	}
+
+	rows := make(map[string]interface{}, len(objs))
+	for _, o := range objs {
+		rows[bunny.CacheKey({{range $i, $f := .Model.PrimaryKey.Fields}}{{if $i}}, {{end}}o.{{$f | titleCasePath}}{{end}})] = o
+	}
+	return rows, nil
+}
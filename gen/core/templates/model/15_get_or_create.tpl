@@ -0,0 +1,45 @@
+{{- $modelNameSingular := .Model.Name | singular | titleCase -}}
+{{- $modelNamePlural := .Model.Name | plural | titleCase -}}
+{{- $model := .Model -}}
+{{- range $key := uniqueKeys .Model}}
+// GetOrCreateBy{{$key.Name}} loads the {{$modelNameSingular}} with the {{range $i, $p := $key.Fields}}{{if $i}}, {{end}}{{$p.DotName}}{{end}} of o into o,
+// or inserts o with InsertIgnore if there's none, and reports whether it was created.
+// Concurrent calls get the same row, which only one of them creates. The row is looked
+// up without the default scope, since it holds the key even if the scope hides it.
+// It fails if o conflicts with another row on another unique key.
+func (o *{{$modelNameSingular}}) GetOrCreateBy{{$key.Name}}(ctx context.Context, whitelist ...string) (bool, error) {
+	ctx = bunny.WithOperation(ctx, "{{$model.Name}}", "get_or_create")
+	{{- if $model.ShardKey}}
+	ctx = bunny.WithShardKey(ctx, o.{{$model.ShardKey | titleCasePath}})
+	{{- end}}
+
+	get := func() (bool, error) {
+		found, err := {{$modelNamePlural}}(qm.Unscoped(), qm.Where("{{whereClause $.LQ $.RQ 0 $key.Fields}}"{{range $key.Fields}}, {{sqlArg ($model.FindField .) (printf "o.%s" (. | titleCasePath))}}{{end}})).One(ctx)
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, nil
+		}
+		if err != nil {
+			return false, err
+		}
+		*o = *found
+		return true, nil
+	}
+
+	if found, err := get(); found || err != nil {
+		return false, err
+	}
+	created, err := o.InsertIgnore(ctx, whitelist...)
+	if created || err != nil {
+		return created, err
+	}
+	// The insert conflicted with a row created since the lookup.
+	found, err := get()
+	if err != nil {
+		return false, err
+	}
+	if !found {
+		return false, errors.New("{{$.PkgName}}: {{$model.Name}} conflicts with another row on another unique key")
+	}
+	return false, nil
+}
+{{end -}}
@@ -3,10 +3,37 @@
 {{- $schemaModel := .Model.Name | schemaModel}}
 // Insert a single record using an executor.
 // Whitelist behavior: If a whitelist is provided, only those fields supplied are inserted
-// No whitelist behavior: Without a whitelist, fields are inferred by the following rules:
-// - All fields without a default value are included (i.e. name, age)
-// - All fields with a default, but non-zero are included (i.e. health = 75)
+// No whitelist behavior: Without a whitelist, all fields are inserted but the ones with
+// a DBDefault, which the database sets.
+{{- if and .Dialect.UseReturning (dbDefaultColumns .Model)}}
+// The values of the fields with a DBDefault which aren't inserted are scanned back into o.
+{{- end}}
 func (o *{{$modelNameSingular}}) Insert(ctx context.Context, whitelist ... string) error {
+	return o.insert(ctx, whitelist, nil)
+}
+
+// Validate checks the fields of the {{.Model.Name}} against their validation rules,
+// with bunny.Validate. Insert, Upsert, Update and CopyFrom call it after the
+// before hooks, and don't write an invalid {{.Model.Name}}.
+func (o *{{$modelNameSingular}}) Validate(ctx context.Context) error {
+	return bunny.Validate(ctx, o)
+}
+
+{{if .Dialect.UseReturning}}
+// InsertReturning inserts the record like Insert, and scans the returning columns
+// of the inserted row back into it, so values set by the database (defaults, triggers)
+// are visible without a Reload. If returning is empty, all columns are returned.
+func (o *{{$modelNameSingular}}) InsertReturning(ctx context.Context, returning []string, whitelist ... string) error {
+	if len(returning) == 0 {
+		returning = {{$varNameSingular}}Columns
+	}
+	return o.insert(ctx, whitelist, returning)
+}
+{{- end}}
+
+func (o *{{$modelNameSingular}}) insert(ctx context.Context, whitelist, returning []string) error {
+	ctx = bunny.WithOperation(ctx, "{{.Model.Name}}", "insert")
+
 	if o == nil {
 		return errors.New("{{.PkgName}}: no {{.Model.Name}} provided for insertion")
 	}
@@ -15,11 +42,24 @@ func (o *{{$modelNameSingular}}) Insert(ctx context.Context, whitelist ... strin
 
 	{{ hook . "before_insert" "o" .Model }}
 
+	if err := o.Validate(ctx); err != nil {
+		return err
+	}
+	{{- if .Model.ShardKey}}
+
+	ctx = bunny.WithShardKey(ctx, o.{{.Model.ShardKey | titleCasePath}})
+	{{- end}}
+
 	if len(whitelist) == 0 {
-		whitelist = {{$varNameSingular}}Columns
+		whitelist = {{$varNameSingular}}InsertColumns
+	}
+	{{- if and .Dialect.UseReturning (dbDefaultColumns .Model)}}
+	if returning == nil {
+		returning = strmangle.SetComplement({{$varNameSingular}}DBDefaultColumns, whitelist)
 	}
+	{{- end}}
 
-	key := makeCacheKey(whitelist)
+	key := makeReturningCacheKey(whitelist, returning)
 	{{$varNameSingular}}InsertCacheMut.RLock()
 	cache, cached := {{$varNameSingular}}InsertCache[key]
 	{{$varNameSingular}}InsertCacheMut.RUnlock()
@@ -35,12 +75,24 @@ func (o *{{$modelNameSingular}}) Insert(ctx context.Context, whitelist ... strin
 		} else {
 			cache.query = "INSERT INTO {{$schemaModel}} DEFAULT VALUES"
 		}
+
+		if len(returning) != 0 {
+			cache.returningMapping, err = queries.BindMapping({{$varNameSingular}}Type, {{$varNameSingular}}Mapping, returning)
+			if err != nil {
+				return err
+			}
+			cache.query += fmt.Sprintf(" RETURNING {{.LQ}}%s{{.RQ}}", strings.Join(returning, "{{.RQ}},{{.LQ}}"))
+		}
 	}
 
 	value := reflect.Indirect(reflect.ValueOf(o))
 	vals := queries.ValuesFromMapping(value, cache.valueMapping)
 
-	_, err = bunny.Exec(ctx, cache.query, vals...)
+	if len(returning) != 0 {
+		err = bunny.QueryRowScan(bunny.ForceWriter(ctx), cache.query, vals, queries.PtrsFromMapping(value, cache.returningMapping)...)
+	} else {
+		_, err = bunny.Exec(ctx, cache.query, vals...)
+	}
 	if err != nil {
 		return errors.Errorf("{{.PkgName}}: unable to insert into {{.Model.Name}}: %w", err)
 	}
@@ -0,0 +1,123 @@
+{{- $modelNameSingular := .Model.Name | singular | titleCase -}}
+{{- $varNameSingular := .Model.Name | singular | camelCase -}}
+{{- $schemaModel := .Model.Name | schemaModel}}
+// InsertIgnore inserts the record like Insert, unless it conflicts with an
+// existing row on a unique key, in which case nothing is written and the
+// after insert hooks don't run. It reports whether the record was inserted,
+// for idempotent ingestion.
+func (o *{{$modelNameSingular}}) InsertIgnore(ctx context.Context, whitelist ...string) (bool, error) {
+	var inserted bool
+	err := o.insertIgnore(ctx, whitelist, &inserted)
+	return inserted, err
+}
+
+func (o *{{$modelNameSingular}}) insertIgnore(ctx context.Context, whitelist []string, inserted *bool) error {
+	ctx = bunny.WithOperation(ctx, "{{.Model.Name}}", "insert_ignore")
+
+	if o == nil {
+		return errors.New("{{.PkgName}}: no {{.Model.Name}} provided for insertion")
+	}
+
+	var err error
+
+	{{ hook . "before_insert" "o" .Model }}
+
+	if err := o.Validate(ctx); err != nil {
+		return err
+	}
+	{{- if .Model.ShardKey}}
+
+	ctx = bunny.WithShardKey(ctx, o.{{.Model.ShardKey | titleCasePath}})
+	{{- end}}
+
+	if len(whitelist) == 0 {
+		whitelist = {{$varNameSingular}}InsertColumns
+	}
+
+	valueMapping, err := queries.BindMapping({{$varNameSingular}}Type, {{$varNameSingular}}Mapping, whitelist)
+	if err != nil {
+		return err
+	}
+	query := queries.BuildInsertIgnoreQuery(dialect, "{{$schemaModel}}", whitelist, 1)
+
+	res, err := bunny.Exec(ctx, query, queries.ValuesFromMapping(reflect.Indirect(reflect.ValueOf(o)), valueMapping)...)
+	if err != nil {
+		return errors.Errorf("{{.PkgName}}: unable to insert into {{.Model.Name}}: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return errors.Errorf("{{.PkgName}}: unable to get rows affected by insert into {{.Model.Name}}: %w", err)
+	}
+	if n == 0 {
+		return nil
+	}
+	*inserted = true
+
+	{{ hook . "after_insert" "o" .Model }}
+
+	return nil
+}
+
+// InsertIgnoreAll inserts the records of the slice like InsertIgnore, with
+// as few statements as the placeholder limit allows, and returns the number
+// of inserted records. Which records were inserted isn't known, so the after
+// insert hooks don't run.
+func (o {{$modelNameSingular}}Slice) InsertIgnoreAll(ctx context.Context, whitelist ...string) (int64, error) {
+	var inserted int64
+	err := o.insertIgnoreAll(ctx, whitelist, &inserted)
+	return inserted, err
+}
+
+func (o {{$modelNameSingular}}Slice) insertIgnoreAll(ctx context.Context, whitelist []string, inserted *int64) error {
+	ctx = bunny.WithOperation(ctx, "{{.Model.Name}}", "insert_ignore_all")
+	{{- if .Model.ShardKey}}
+	ctx = bunny.RequireShard(ctx)
+	{{- end}}
+
+	if len(o) == 0 {
+		return nil
+	}
+
+	{{ hook . "before_insert_slice" "o" .Model }}
+
+	for _, obj := range o {
+		if err := obj.Validate(ctx); err != nil {
+			return err
+		}
+	}
+
+	if len(whitelist) == 0 {
+		whitelist = {{$varNameSingular}}InsertColumns
+	}
+
+	valueMapping, err := queries.BindMapping({{$varNameSingular}}Type, {{$varNameSingular}}Mapping, whitelist)
+	if err != nil {
+		return err
+	}
+
+	chunkSize := queries.MaxPlaceholders / len(whitelist)
+	for start := 0; start < len(o); start += chunkSize {
+		chunk := o[start:]
+		if len(chunk) > chunkSize {
+			chunk = chunk[:chunkSize]
+		}
+
+		var args []interface{}
+		for _, obj := range chunk {
+			args = append(args, queries.ValuesFromMapping(reflect.Indirect(reflect.ValueOf(obj)), valueMapping)...)
+		}
+		query := queries.BuildInsertIgnoreQuery(dialect, "{{$schemaModel}}", whitelist, len(chunk))
+
+		res, err := bunny.Exec(ctx, query, args...)
+		if err != nil {
+			return errors.Errorf("{{.PkgName}}: unable to insert all into {{.Model.Name}}: %w", err)
+		}
+		n, err := res.RowsAffected()
+		if err != nil {
+			return errors.Errorf("{{.PkgName}}: unable to get rows affected by insert all into {{.Model.Name}}: %w", err)
+		}
+		*inserted += n
+	}
+
+	return nil
+}
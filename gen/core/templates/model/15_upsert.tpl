@@ -0,0 +1,149 @@
+{{- $modelNameSingular := .Model.Name | singular | titleCase -}}
+{{- $varNameSingular := .Model.Name | singular | camelCase -}}
+{{- $schemaModel := .Model.Name | schemaModel}}
+// Upsert inserts a single record, or updates the updateColumns of the row it
+// conflicts with on target instead. If updateColumns is empty, the conflicting
+// row is left as is. An empty target is the primary key, a partial unique
+// index needs its predicate:
+//
+//	o.Upsert(ctx, queries.ConflictTarget{Columns: []string{"email"}, Where: "deleted_at IS NULL"}, []string{"name"})
+//
+// The whitelist selects the inserted fields like with Insert. Immutable fields
+// can't be in updateColumns.
+// MySQL conflicts on any unique key, and ignores target.
+func (o *{{$modelNameSingular}}) Upsert(ctx context.Context, target queries.ConflictTarget, updateColumns []string, whitelist ...string) error {
+	ctx = bunny.WithOperation(ctx, "{{.Model.Name}}", "upsert")
+
+	if o == nil {
+		return errors.New("{{.PkgName}}: no {{.Model.Name}} provided for upsert")
+	}
+
+	var err error
+
+	{{ hook . "before_insert" "o" .Model }}
+
+	if err := o.Validate(ctx); err != nil {
+		return err
+	}
+	{{- if .Model.ShardKey}}
+
+	ctx = bunny.WithShardKey(ctx, o.{{.Model.ShardKey | titleCasePath}})
+	{{- end}}
+
+	if len(whitelist) == 0 {
+		whitelist = {{$varNameSingular}}InsertColumns
+	}
+	{{- if immutableColumns .Model}}
+	if err := bunny.CheckImmutable("{{.Model.Name}}", {{$varNameSingular}}ImmutableColumns, updateColumns); err != nil {
+		return err
+	}
+	{{- end}}
+	pkTarget := len(target.Columns) == 0 && target.Constraint == ""
+	if pkTarget {
+		target.Columns = {{$varNameSingular}}PrimaryKeyColumns
+	}
+
+	valueMapping, err := queries.BindMapping({{$varNameSingular}}Type, {{$varNameSingular}}Mapping, whitelist)
+	if err != nil {
+		return err
+	}
+	query := queries.BuildUpsertQuery(dialect, "{{$schemaModel}}", target, updateColumns, whitelist, nil)
+
+	value := reflect.Indirect(reflect.ValueOf(o))
+	if _, err = bunny.Exec(ctx, query, queries.ValuesFromMapping(value, valueMapping)...); err != nil {
+		return errors.Errorf("{{.PkgName}}: unable to upsert into {{.Model.Name}}: %w", err)
+	}
+
+	// A conflict on another target than the primary key updates a row with
+	// another primary key than o, so all the rows of the model are invalidated.
+	{{- if .Dialect.UseOnDuplicateKey}}
+	bunny.CacheInvalidateModel(ctx, "{{.Model.Name}}")
+	{{- else}}
+	if pkTarget {
+		bunny.CacheInvalidate(ctx, "{{.Model.Name}}", bunny.CacheKey({{range $i, $f := .Model.PrimaryKey.Fields}}{{if $i}}, {{end}}o.{{$f | titleCasePath}}{{end}}))
+	} else {
+		bunny.CacheInvalidateModel(ctx, "{{.Model.Name}}")
+	}
+	{{- end}}
+
+	{{ hook . "after_insert" "o" .Model }}
+
+	return nil
+}
+
+// UpsertAll upserts the records of the slice like Upsert, with as few
+// statements as the placeholder limit allows, for syncs reconciling many
+// rows at a time. The records of a statement can't conflict with the same
+// row, so target must be unique among them.
+func (o {{$modelNameSingular}}Slice) UpsertAll(ctx context.Context, target queries.ConflictTarget, updateColumns []string, whitelist ...string) error {
+	ctx = bunny.WithOperation(ctx, "{{.Model.Name}}", "upsert_all")
+	{{- if .Model.ShardKey}}
+	ctx = bunny.RequireShard(ctx)
+	{{- end}}
+
+	if len(o) == 0 {
+		return nil
+	}
+
+	{{ hook . "before_insert_slice" "o" .Model }}
+
+	for _, obj := range o {
+		if err := obj.Validate(ctx); err != nil {
+			return err
+		}
+	}
+
+	if len(whitelist) == 0 {
+		whitelist = {{$varNameSingular}}InsertColumns
+	}
+	{{- if immutableColumns .Model}}
+	if err := bunny.CheckImmutable("{{.Model.Name}}", {{$varNameSingular}}ImmutableColumns, updateColumns); err != nil {
+		return err
+	}
+	{{- end}}
+	pkTarget := len(target.Columns) == 0 && target.Constraint == ""
+	if pkTarget {
+		target.Columns = {{$varNameSingular}}PrimaryKeyColumns
+	}
+
+	valueMapping, err := queries.BindMapping({{$varNameSingular}}Type, {{$varNameSingular}}Mapping, whitelist)
+	if err != nil {
+		return err
+	}
+
+	chunkSize := queries.MaxPlaceholders / len(whitelist)
+	for start := 0; start < len(o); start += chunkSize {
+		chunk := o[start:]
+		if len(chunk) > chunkSize {
+			chunk = chunk[:chunkSize]
+		}
+
+		var args []interface{}
+		for _, obj := range chunk {
+			args = append(args, queries.ValuesFromMapping(reflect.Indirect(reflect.ValueOf(obj)), valueMapping)...)
+		}
+		query := queries.BuildUpsertAllQuery(dialect, "{{$schemaModel}}", target, updateColumns, whitelist, len(chunk))
+
+		if _, err := bunny.Exec(ctx, query, args...); err != nil {
+			return errors.Errorf("{{.PkgName}}: unable to upsert all into {{.Model.Name}}: %w", err)
+		}
+
+		// Conflicts on other targets than the primary key update rows with
+		// other primary keys, like with Upsert.
+		{{- if .Dialect.UseOnDuplicateKey}}
+		bunny.CacheInvalidateModel(ctx, "{{.Model.Name}}")
+		{{- else}}
+		if !pkTarget {
+			bunny.CacheInvalidateModel(ctx, "{{.Model.Name}}")
+			continue
+		}
+		for _, obj := range chunk {
+			bunny.CacheInvalidate(ctx, "{{.Model.Name}}", bunny.CacheKey({{range $i, $f := .Model.PrimaryKey.Fields}}{{if $i}}, {{end}}obj.{{$f | titleCasePath}}{{end}}))
+		}
+		{{- end}}
+	}
+
+	{{ hook . "after_insert_slice" "o" .Model }}
+
+	return nil
+}
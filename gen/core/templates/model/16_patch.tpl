@@ -0,0 +1,49 @@
+{{- $modelNameSingular := .Model.Name | singular | titleCase -}}
+{{- $dot := . -}}
+// {{$modelNameSingular}}Patch is a partial update of a {{$modelNameSingular}}, like the body of a
+// JSON merge patch: only the fields which are set are changed, and null sets a
+// nullable field to null. The primary key can't be patched.
+type {{$modelNameSingular}}Patch struct {
+	{{- range $field := patchFields .Model }}
+	{{titleCase $field.Name}} bunny.Optional[{{goType $field.GoType}}] `json:"{{jsonName $field}}"`
+	{{- end }}
+}
+
+// Columns returns the columns of the fields set in the patch.
+func (p *{{$modelNameSingular}}Patch) Columns() []string {
+	var columns []string
+	{{- range $field := patchFields .Model }}
+	if p.{{titleCase $field.Name}}.Set {
+		columns = append(columns{{range fieldColumns $dot.Model $field}}, "{{.}}"{{end}})
+	}
+	{{- end }}
+	return columns
+}
+
+// ApplyPatch sets the fields set in the patch on o, and returns their columns.
+func (o *{{$modelNameSingular}}) ApplyPatch(p *{{$modelNameSingular}}Patch) []string {
+	{{- range $field := patchFields .Model }}
+	if p.{{titleCase $field.Name}}.Set {
+		o.{{titleCase $field.Name}} = p.{{titleCase $field.Name}}.Value
+	}
+	{{- end }}
+	return p.Columns()
+}
+
+// UpdateFromPatch applies the patch to o, and updates only the columns of the
+// fields set in it. An empty patch updates nothing.
+{{- if immutableColumns .Model}} A patch setting immutable fields
+// returns a bunny.ImmutableColumnError, without changing o.
+{{- end}}
+func (o *{{$modelNameSingular}}) UpdateFromPatch(ctx context.Context, p *{{$modelNameSingular}}Patch) error {
+	{{- if immutableColumns .Model}}
+	if err := bunny.CheckImmutable("{{.Model.Name}}", {{.Model.Name | singular | camelCase}}ImmutableColumns, p.Columns()); err != nil {
+		return err
+	}
+	{{- end}}
+	columns := o.ApplyPatch(p)
+	if len(columns) == 0 {
+		return nil
+	}
+	return o.Update(ctx, columns...)
+}
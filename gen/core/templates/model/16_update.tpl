@@ -6,23 +6,55 @@
 // No whitelist behavior: Without a whitelist, fields are inferred by the following rules:
 // - All fields are inferred to start with
 // - All primary keys are subtracted from this set
+// - All immutable fields are subtracted from this set
+// Whitelisting an immutable field returns a bunny.ImmutableColumnError.
 // Update does not automatically update the record in case of default values. Use .Reload()
 // to refresh the records.
 func (o *{{$modelNameSingular}}) Update(ctx context.Context, whitelist ... string) error {
+	return o.update(ctx, whitelist, nil)
+}
+
+{{if .Dialect.UseReturning}}
+// UpdateReturning updates the record like Update, and scans the returning columns
+// of the updated row back into it, so values changed by the database (triggers)
+// are visible without a Reload. If returning is empty, all columns are returned.
+func (o *{{$modelNameSingular}}) UpdateReturning(ctx context.Context, returning []string, whitelist ... string) error {
+	if len(returning) == 0 {
+		returning = {{$varNameSingular}}Columns
+	}
+	return o.update(ctx, whitelist, returning)
+}
+{{- end}}
+
+func (o *{{$modelNameSingular}}) update(ctx context.Context, whitelist, returning []string) error {
+	ctx = bunny.WithOperation(ctx, "{{.Model.Name}}", "update")
+
 	var err error
 
 	{{ hook . "before_update" "o" .Model }}
 
+	if err := o.Validate(ctx); err != nil {
+		return err
+	}
+	{{- if .Model.ShardKey}}
+
+	ctx = bunny.WithShardKey(ctx, o.{{.Model.ShardKey | titleCasePath}})
+	{{- end}}
+
 	if len(whitelist) == 0 {
-		whitelist = {{$varNameSingular}}NonPrimaryKeyColumns
+		whitelist = {{$varNameSingular}}UpdateColumns
+	}
+	{{- if immutableColumns .Model}} else if err := bunny.CheckImmutable("{{.Model.Name}}", {{$varNameSingular}}ImmutableColumns, whitelist); err != nil {
+		return err
 	}
+	{{- end}}
 
 	if len(whitelist) == 0 {
 		// Nothing to update
 		return nil
 	}
 
-	key := makeCacheKey(whitelist)
+	key := makeReturningCacheKey(whitelist, returning)
 	{{$varNameSingular}}UpdateCacheMut.RLock()
 	cache, cached := {{$varNameSingular}}UpdateCache[key]
 	{{$varNameSingular}}UpdateCacheMut.RUnlock()
@@ -36,15 +68,37 @@ func (o *{{$modelNameSingular}}) Update(ctx context.Context, whitelist ... strin
 		if err != nil {
 			return err
 		}
+
+		if len(returning) != 0 {
+			cache.returningMapping, err = queries.BindMapping({{$varNameSingular}}Type, {{$varNameSingular}}Mapping, returning)
+			if err != nil {
+				return err
+			}
+			cache.returning = fmt.Sprintf(" RETURNING {{.LQ}}%s{{.RQ}}", strings.Join(returning, "{{.RQ}},{{.LQ}}"))
+		}
 	}
 
-	values := queries.ValuesFromMapping(reflect.Indirect(reflect.ValueOf(o)), cache.valueMapping)
+	value := reflect.Indirect(reflect.ValueOf(o))
+	values := queries.ValuesFromMapping(value, cache.valueMapping)
+
+	scope, scopeArgs, err := queries.ContextWhere(ctx, &dialect, {{$varNameSingular}}ContextMods, len(values)+1)
+	if err != nil {
+		return errors.Errorf("{{.PkgName}}: unable to update {{.Model.Name}} row: %w", err)
+	}
+	query := cache.query + scope + cache.returning
+	values = append(values, scopeArgs...)
 
-	_, err = bunny.Exec(ctx, cache.query, values...)
+	if len(returning) != 0 {
+		err = bunny.QueryRowScan(bunny.ForceWriter(ctx), query, values, queries.PtrsFromMapping(value, cache.returningMapping)...)
+	} else {
+		_, err = bunny.Exec(ctx, query, values...)
+	}
 	if err != nil {
 		return errors.Errorf("{{.PkgName}}: unable to update {{.Model.Name}} row: %w", err)
 	}
 
+	bunny.CacheInvalidate(ctx, "{{.Model.Name}}", bunny.CacheKey({{range $i, $f := .Model.PrimaryKey.Fields}}{{if $i}}, {{end}}o.{{$f | titleCasePath}}{{end}}))
+
 	if !cached {
 		{{$varNameSingular}}UpdateCacheMut.Lock()
 		{{$varNameSingular}}UpdateCache[key] = cache
@@ -58,6 +112,8 @@ func (o *{{$modelNameSingular}}) Update(ctx context.Context, whitelist ... strin
 
 // UpdateMapAll updates all rows with the specified field values.
 func (q {{$varNameSingular}}Query) UpdateMapAll(ctx context.Context, cols M) error {
+	ctx = bunny.WithOperation(ctx, "{{.Model.Name}}", "update_all")
+
 	queries.SetUpdate(q.Query, cols)
 
 	_, err := q.Query.Exec(ctx)
@@ -65,5 +121,7 @@ func (q {{$varNameSingular}}Query) UpdateMapAll(ctx context.Context, cols M) err
 		return errors.Errorf("{{.PkgName}}: unable to update all for {{.Model.Name}}: %w", err)
 	}
 
+	bunny.CacheInvalidateModel(ctx, "{{.Model.Name}}")
+
 	return nil
 }
@@ -0,0 +1,37 @@
+{{- $modelNameSingular := .Model.Name | singular | titleCase -}}
+{{- $varNameSingular := .Model.Name | singular | camelCase -}}
+// CopyFrom inserts all the records in the slice using the Postgres COPY protocol, which is
+// much faster than Insert for large amounts of rows. All columns are inserted, so database
+// defaults are not applied.
+func (o {{$modelNameSingular}}Slice) CopyFrom(ctx context.Context) error {
+	{{- if .Model.ShardKey}}
+	ctx = bunny.RequireShard(ctx)
+	{{- end}}
+	if len(o) == 0 {
+		return nil
+	}
+
+	{{ hook . "before_insert_slice" "o" .Model }}
+
+	for _, obj := range o {
+		if err := obj.Validate(ctx); err != nil {
+			return err
+		}
+	}
+
+	mapping, err := queries.BindMapping({{$varNameSingular}}Type, {{$varNameSingular}}Mapping, {{$varNameSingular}}Columns)
+	if err != nil {
+		return err
+	}
+
+	err = bunny.CopyFrom(ctx, "{{.Model.Name}}", {{$varNameSingular}}Columns, len(o), func(i int) []interface{} {
+		return queries.ValuesFromMapping(reflect.Indirect(reflect.ValueOf(o[i])), mapping)
+	})
+	if err != nil {
+		return errors.Errorf("{{.PkgName}}: unable to copy into {{.Model.Name}}: %w", err)
+	}
+
+	{{ hook . "after_insert_slice" "o" .Model }}
+
+	return nil
+}
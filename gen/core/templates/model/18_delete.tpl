@@ -4,20 +4,60 @@
 // Delete deletes a single {{$modelNameSingular}} record with an executor.
 // Delete will match against the primary key field to find the record to delete.
 func (o *{{$modelNameSingular}}) Delete(ctx context.Context) error {
+	return o.delete(ctx, nil)
+}
+
+{{if .Dialect.UseReturning}}
+// DeleteReturning deletes the record like Delete, and scans the returning columns
+// of the deleted row back into it. If returning is empty, all columns are returned.
+func (o *{{$modelNameSingular}}) DeleteReturning(ctx context.Context, returning []string) error {
+	if len(returning) == 0 {
+		returning = {{$varNameSingular}}Columns
+	}
+	return o.delete(ctx, returning)
+}
+{{- end}}
+
+func (o *{{$modelNameSingular}}) delete(ctx context.Context, returning []string) error {
+	ctx = bunny.WithOperation(ctx, "{{.Model.Name}}", "delete")
+
 	if o == nil {
 	return errors.New("{{.PkgName}}: no {{$modelNameSingular}} provided for delete")
 	}
 
 	{{ hook . "before_delete" "o" .Model }}
+	{{- if .Model.ShardKey}}
+	ctx = bunny.WithShardKey(ctx, o.{{.Model.ShardKey | titleCasePath}})
+	{{- end}}
 
-	args := queries.ValuesFromMapping(reflect.Indirect(reflect.ValueOf(o)), {{$varNameSingular}}PrimaryKeyMapping)
+	value := reflect.Indirect(reflect.ValueOf(o))
+	args := queries.ValuesFromMapping(value, {{$varNameSingular}}PrimaryKeyMapping)
 	sql := "DELETE FROM {{$schemaModel}} WHERE {{if .Dialect.IndexPlaceholders}}{{whereClause .LQ .RQ 1 .Model.PrimaryKey.Fields}}{{else}}{{whereClause .LQ .RQ 0 .Model.PrimaryKey.Fields}}{{end}}"
 
-	_, err := bunny.Exec(ctx, sql, args...)
+	scope, scopeArgs, err := queries.ContextWhere(ctx, &dialect, {{$varNameSingular}}ContextMods, len(args)+1)
+	if err != nil {
+		return errors.Errorf("{{.PkgName}}: unable to delete from {{.Model.Name}}: %w", err)
+	}
+	sql += scope
+	args = append(args, scopeArgs...)
+
+	if len(returning) != 0 {
+		var returningMapping []queries.MappedField
+		returningMapping, err = queries.BindMapping({{$varNameSingular}}Type, {{$varNameSingular}}Mapping, returning)
+		if err != nil {
+			return err
+		}
+		sql += fmt.Sprintf(" RETURNING {{.LQ}}%s{{.RQ}}", strings.Join(returning, "{{.RQ}},{{.LQ}}"))
+		err = bunny.QueryRowScan(bunny.ForceWriter(ctx), sql, args, queries.PtrsFromMapping(value, returningMapping)...)
+	} else {
+		_, err = bunny.Exec(ctx, sql, args...)
+	}
 	if err != nil {
 	return errors.Errorf("{{.PkgName}}: unable to delete from {{.Model.Name}}: %w", err)
 	}
 
+	bunny.CacheInvalidate(ctx, "{{.Model.Name}}", bunny.CacheKey({{range $i, $f := .Model.PrimaryKey.Fields}}{{if $i}}, {{end}}o.{{$f | titleCasePath}}{{end}}))
+
 	{{ hook . "after_delete" "o" .Model }}
 
 	return nil
@@ -25,6 +65,8 @@ func (o *{{$modelNameSingular}}) Delete(ctx context.Context) error {
 
 // DeleteAll deletes all matching rows.
 func (q {{$varNameSingular}}Query) DeleteAll(ctx context.Context) error {
+	ctx = bunny.WithOperation(ctx, "{{.Model.Name}}", "delete_all")
+
 	if q.Query == nil {
 	return errors.New("{{.PkgName}}: no {{$varNameSingular}}Query provided for delete all")
 	}
@@ -36,11 +78,18 @@ func (q {{$varNameSingular}}Query) DeleteAll(ctx context.Context) error {
 	return errors.Errorf("{{.PkgName}}: unable to delete all from {{.Model.Name}}: %w", err)
 	}
 
+	bunny.CacheInvalidateModel(ctx, "{{.Model.Name}}")
+
 	return nil
 }
 
 // DeleteAll deletes all rows in the slice, using an executor.
 func (o {{$modelNameSingular}}Slice) DeleteAll(ctx context.Context) error {
+	ctx = bunny.WithOperation(ctx, "{{.Model.Name}}", "delete_all")
+	{{- if .Model.ShardKey}}
+	ctx = bunny.RequireShard(ctx)
+	{{- end}}
+
 	if o == nil {
 		return errors.New("{{.PkgName}}: no {{$modelNameSingular}} slice provided for delete all")
 	}
@@ -60,11 +109,22 @@ func (o {{$modelNameSingular}}Slice) DeleteAll(ctx context.Context) error {
 	sql := "DELETE FROM {{$schemaModel}} WHERE " +
 		strmangle.WhereClauseRepeated(string(dialect.LQ), string(dialect.RQ), {{if .Dialect.IndexPlaceholders}}1{{else}}0{{end}}, {{$varNameSingular}}PrimaryKeyColumns, len(o))
 
-	_, err := bunny.Exec(ctx, sql, args...)
+	scope, scopeArgs, err := queries.ContextWhere(ctx, &dialect, {{$varNameSingular}}ContextMods, len(args)+1)
+	if err != nil {
+		return errors.Errorf("{{.PkgName}}: unable to delete all from {{$varNameSingular}} slice: %w", err)
+	}
+	sql += scope
+	args = append(args, scopeArgs...)
+
+	_, err = bunny.Exec(ctx, sql, args...)
 	if err != nil {
 		return errors.Errorf("{{.PkgName}}: unable to delete all from {{$varNameSingular}} slice: %w", err)
 	}
 
+	for _, obj := range o {
+		bunny.CacheInvalidate(ctx, "{{.Model.Name}}", bunny.CacheKey({{range $i, $f := .Model.PrimaryKey.Fields}}{{if $i}}, {{end}}obj.{{$f | titleCasePath}}{{end}}))
+	}
+
 	{{ hook . "after_delete_slice" "o" .Model }}
 
 	return nil
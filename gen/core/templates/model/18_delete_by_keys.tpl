@@ -0,0 +1,111 @@
+{{- if eq (len .Model.PrimaryKey.Fields) 1 -}}
+{{ import "pq" "github.com/lib/pq" }}
+{{- $modelNamePlural := .Model.Name | plural | titleCase -}}
+{{- $schemaModel := .Model.Name | schemaModel -}}
+{{- $pk := index .Model.PrimaryKey.Fields 0 -}}
+{{- $pkField := .Model.FindField $pk -}}
+{{- $modelNameSingular := .Model.Name | singular | titleCase -}}
+{{- $funcName := printf "Delete%sBy%ss" $modelNamePlural ($pkField.Name | titleCase)}}
+// {{$funcName}} deletes the {{.Model.Name}} rows with the primary keys, with a
+// statement per 10000 keys instead of one per row, and returns the number of
+// deleted rows. The rows aren't loaded, so the before delete hooks don't run.
+{{- if .Dialect.UseReturning}} The after delete slice hooks, like
+// notifications and invalidations, run with the deleted rows, which only have
+// their primary key set.
+{{- else}} Neither do the
+// after delete hooks, like notifications and invalidations, since the
+// deleted rows aren't known without RETURNING.
+{{- end}}
+func {{$funcName}}(ctx context.Context, keys []{{goType $pkField.Type.GoType}}) (int64, error) {
+	ctx = bunny.WithOperation(ctx, "{{.Model.Name}}", "delete_by_keys")
+	{{- if .Model.ShardKey}}
+	ctx = bunny.RequireShard(ctx)
+	{{- end}}
+
+	const chunkSize = 10000
+
+	var deleted int64
+	for start := 0; start < len(keys); start += chunkSize {
+		chunk := keys[start:]
+		if len(chunk) > chunkSize {
+			chunk = chunk[:chunkSize]
+		}
+
+		args := make([]interface{}, len(chunk))
+		for i, key := range chunk {
+			args[i] = {{sqlArg $pkField "key"}}
+		}
+
+		{{if .Dialect.IndexPlaceholders -}}
+		sql := "DELETE FROM {{$schemaModel}} WHERE {{$pk.SQLName | quotes}} = ANY($1)"
+		args = []interface{}{pq.Array(args)}
+		{{- else -}}
+		sql := fmt.Sprintf("DELETE FROM {{$schemaModel}} WHERE {{$pk.SQLName | quotes}} IN (%s)", strmangle.Placeholders(false, len(args), 1, 1))
+		{{- end}}
+		scope, scopeArgs, err := queries.ContextWhere(ctx, &dialect, {{.Model.Name | singular | camelCase}}ContextMods, len(args)+1)
+		if err != nil {
+			return deleted, errors.Errorf("{{.PkgName}}: unable to delete from {{.Model.Name}} by keys: %w", err)
+		}
+		{{- if .Dialect.UseReturning}}
+		var objs {{$modelNameSingular}}Slice
+		err = bunny.AtomicTenant(bunny.ForceWriter(ctx), func(ctx context.Context) error {
+			rows, err := bunny.Query(ctx, sql+scope+" RETURNING {{$pk.SQLName | quotes}}", append(args, scopeArgs...)...)
+			if err != nil {
+				return err
+			}
+			defer rows.Close()
+			for rows.Next() {
+				obj := &{{$modelNameSingular}}{}
+				if err := rows.Scan(&obj.{{$pk | titleCasePath}}); err != nil {
+					return err
+				}
+				objs = append(objs, obj)
+			}
+			return rows.Err()
+		})
+		if err != nil {
+			return deleted, errors.Errorf("{{.PkgName}}: unable to delete from {{.Model.Name}} by keys: %w", err)
+		}
+		deleted += int64(len(objs))
+
+		for _, key := range chunk {
+			bunny.CacheInvalidate(ctx, "{{.Model.Name}}", bunny.CacheKey(key))
+		}
+
+		if err := objs.afterDeleteByKeys(ctx); err != nil {
+			return deleted, err
+		}
+		{{- else}}
+		res, err := bunny.Exec(ctx, sql+scope, append(args, scopeArgs...)...)
+		if err != nil {
+			return deleted, errors.Errorf("{{.PkgName}}: unable to delete from {{.Model.Name}} by keys: %w", err)
+		}
+		n, err := res.RowsAffected()
+		if err != nil {
+			return deleted, errors.Errorf("{{.PkgName}}: unable to get rows affected by delete from {{.Model.Name}}: %w", err)
+		}
+		deleted += n
+
+		for _, key := range chunk {
+			bunny.CacheInvalidate(ctx, "{{.Model.Name}}", bunny.CacheKey(key))
+		}
+		{{- end}}
+	}
+
+	return deleted, nil
+}
+{{- if .Dialect.UseReturning}}
+
+// afterDeleteByKeys runs the after delete slice hooks of the rows deleted by
+// {{$funcName}}.
+func (o {{$modelNameSingular}}Slice) afterDeleteByKeys(ctx context.Context) error {
+	if len(o) == 0 {
+		return nil
+	}
+
+	{{ hook . "after_delete_slice" "o" .Model }}
+
+	return nil
+}
+{{- end}}
+{{- end}}
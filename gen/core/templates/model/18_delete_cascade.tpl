@@ -0,0 +1,62 @@
+{{- $dot := . -}}
+{{- $model := .Model -}}
+{{- $modelNameSingular := .Model.Name | singular | titleCase -}}
+{{- $dependents := .Schema.DependentRelationships .Model -}}
+// DeleteCascade deletes the {{$modelNameSingular}} like Delete, in a transaction
+// with the rows depending on it through foreign keys, which are deleted first,
+// recursively, with their delete hooks. It's for schemas whose foreign keys
+// can't be ON DELETE CASCADE.
+func (o *{{$modelNameSingular}}) DeleteCascade(ctx context.Context) error {
+	ctx = bunny.WithOperation(ctx, "{{.Model.Name}}", "delete_cascade")
+
+	if o == nil {
+		return errors.New("{{.PkgName}}: no {{$modelNameSingular}} provided for delete cascade")
+	}
+	{{- if .Model.ShardKey}}
+	ctx = bunny.WithShardKey(ctx, o.{{.Model.ShardKey | titleCasePath}})
+	{{- end}}
+
+	return bunny.Atomic(ctx, func(ctx context.Context) error {
+		if err := o.deleteDependents(ctx, map[string]struct{}{}); err != nil {
+			return err
+		}
+		return o.Delete(ctx)
+	})
+}
+
+// deleteDependents deletes the rows depending on o, after their own
+// dependents. seen has the rows whose dependents are being deleted, so
+// cycles in the rows end with a foreign key violation instead of recursing
+// forever.
+func (o *{{$modelNameSingular}}) deleteDependents(ctx context.Context, seen map[string]struct{}) error {
+	{{- if not $dependents}}
+	return nil
+	{{- else}}
+	key := "{{.Model.Name}}\x00" + bunny.CacheKey({{range $i, $f := .Model.PrimaryKey.Fields}}{{if $i}}, {{end}}o.{{$f | titleCasePath}}{{end}})
+	if _, ok := seen[key]; ok {
+		return nil
+	}
+	seen[key] = struct{}{}
+	{{range $dependents}}
+	{{- $foreignModelNamePlural := .ForeignModel | plural | titleCase}}
+	{
+		rows, err := {{$foreignModelNamePlural}}(
+			qm.Unscoped(),
+			qm.Where("{{whereClause $dot.LQ $dot.RQ 0 .ForeignFields}}"{{range .LocalFields}}, {{sqlArg ($model.FindField .) (printf "o.%s" (titleCasePath .))}}{{end}}),
+		).All(ctx)
+		if err != nil {
+			return errors.Errorf("{{$dot.PkgName}}: unable to load {{.Name}} of {{$model.Name}} for delete cascade: %w", err)
+		}
+		for _, row := range rows {
+			if err := row.deleteDependents(ctx, seen); err != nil {
+				return err
+			}
+		}
+		if err := rows.DeleteAll(ctx); err != nil {
+			return err
+		}
+	}
+	{{end}}
+	return nil
+	{{- end}}
+}
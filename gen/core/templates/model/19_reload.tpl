@@ -5,6 +5,14 @@
 // Reload refetches the object from the database
 // using the primary keys with an executor.
 func (o *{{$modelNameSingular}}) Reload(ctx context.Context) error {
+	ctx = bunny.WithOperation(ctx, "{{.Model.Name}}", "reload")
+	{{- if .Model.ShardKey}}
+	ctx = bunny.WithShardKey(ctx, o.{{.Model.ShardKey | titleCasePath}})
+	{{- end}}
+
+	// The point of reloading is seeing the current row, not a cached one.
+	bunny.CacheInvalidate(ctx, "{{.Model.Name}}", bunny.CacheKey({{range $i, $f := .Model.PrimaryKey.Fields}}{{if $i}}, {{end}}o.{{$f | titleCasePath}}{{end}}))
+
 	ret, err := Find{{$modelNameSingular}}(ctx {{range .Model.PrimaryKey.Fields}}, o.{{. | titleCasePath}}{{end}})
 	if err != nil {
 		return err
@@ -17,25 +25,51 @@ func (o *{{$modelNameSingular}}) Reload(ctx context.Context) error {
 // ReloadAll refetches every row with matching primary key field values
 // and overwrites the original object slice with the newly updated slice.
 func (o *{{$modelNameSingular}}Slice) ReloadAll(ctx context.Context) error {
+	ctx = bunny.WithOperation(ctx, "{{.Model.Name}}", "reload_all")
+	{{- if .Model.ShardKey}}
+	ctx = bunny.RequireShard(ctx)
+	{{- end}}
+
 	if o == nil || len(*o) == 0 {
 		return nil
 	}
 
-	{{$varNamePlural}} := {{$modelNameSingular}}Slice{}
-	var args []interface{}
-	for _, obj := range *o {
-		pkeyArgs := queries.ValuesFromMapping(reflect.Indirect(reflect.ValueOf(obj)), {{$varNameSingular}}PrimaryKeyMapping)
-		args = append(args, pkeyArgs...)
-	}
+	// The primary keys are looked up with a statement per chunk of them,
+	// to stay under the limit of arguments of a statement.
+	const chunkSize = queries.MaxPlaceholders / {{len .Model.PrimaryKey.Fields}}
 
-	sql := "SELECT {{$schemaModel}}.* FROM {{$schemaModel}} WHERE " +
-		strmangle.WhereClauseRepeated(string(dialect.LQ), string(dialect.RQ), {{if .Dialect.IndexPlaceholders}}1{{else}}0{{end}}, {{$varNameSingular}}PrimaryKeyColumns, len(*o))
+	{{$varNamePlural}} := []*{{$modelNameSingular}}{}
+	for start := 0; start < len(*o); start += chunkSize {
+		chunk := (*o)[start:]
+		if len(chunk) > chunkSize {
+			chunk = chunk[:chunkSize]
+		}
 
-	q := queries.Raw(sql, args...)
+		var args []interface{}
+		for _, obj := range chunk {
+			pkeyArgs := queries.ValuesFromMapping(reflect.Indirect(reflect.ValueOf(obj)), {{$varNameSingular}}PrimaryKeyMapping)
+			args = append(args, pkeyArgs...)
+		}
 
-	err := q.Bind(ctx, &{{$varNamePlural}})
-	if err != nil {
-		return errors.Errorf("{{.PkgName}}: unable to reload all in {{$modelNameSingular}}Slice: %w", err)
+		{{if .Model.DefaultScope -}}
+		sql := "SELECT {{$schemaModel}}.* FROM {{$schemaModel}} WHERE (" +
+			strmangle.WhereClauseRepeated(string(dialect.LQ), string(dialect.RQ), {{if .Dialect.IndexPlaceholders}}1{{else}}0{{end}}, {{$varNameSingular}}PrimaryKeyColumns, len(chunk)) +
+			{{printf ") AND (%s)" .Model.DefaultScope | printf "%q"}}
+		{{- else -}}
+		sql := "SELECT {{$schemaModel}}.* FROM {{$schemaModel}} WHERE " +
+			strmangle.WhereClauseRepeated(string(dialect.LQ), string(dialect.RQ), {{if .Dialect.IndexPlaceholders}}1{{else}}0{{end}}, {{$varNameSingular}}PrimaryKeyColumns, len(chunk))
+		{{- end}}
+
+		scope, scopeArgs, err := queries.ContextWhere(ctx, &dialect, {{$varNameSingular}}ContextMods, len(args)+1)
+		if err != nil {
+			return errors.Errorf("{{.PkgName}}: unable to reload all in {{$modelNameSingular}}Slice: %w", err)
+		}
+		q := queries.Raw(sql+scope, append(args, scopeArgs...)...)
+
+		err = {{$varNameSingular}}Scanner.All(ctx, q, &{{$varNamePlural}})
+		if err != nil {
+			return errors.Errorf("{{.PkgName}}: unable to reload all in {{$modelNameSingular}}Slice: %w", err)
+		}
 	}
 
 	*o = {{$varNamePlural}}
@@ -4,12 +4,30 @@
 
 // {{$modelNameSingular}}Exists checks if the {{$modelNameSingular}} row exists.
 func {{$modelNameSingular}}Exists(ctx context.Context{{range .Model.PrimaryKey.Fields}}, {{$f := $model.FindField .}}{{$f.Name | camelCase}} {{goType $f.Type.GoType}}{{end}}, selectCols ...string) (bool, error) {
+	ctx = bunny.WithOperation(ctx, "{{.Model.Name}}", "exists")
+	{{- if .Model.ShardKey}}
+	{{- $shardArg := ""}}
+	{{- range .Model.PrimaryKey.Fields}}{{if .Equals $model.ShardKey}}{{$shardArg = ($model.FindField .).Name | camelCase}}{{end}}{{end}}
+	{{- if $shardArg}}
+	ctx = bunny.WithShardKey(ctx, {{$shardArg}})
+	{{- else}}
+	ctx = bunny.RequireShard(ctx)
+	{{- end}}
+	{{- end}}
+
 	var exists bool
-	sql := "select exists(select 1 from {{$schemaModel}} where {{if .Dialect.IndexPlaceholders}}{{whereClause .LQ .RQ 1 .Model.PrimaryKey.Fields}}{{else}}{{whereClause .LQ .RQ 0 .Model.PrimaryKey.Columns}}{{end}} limit 1)"
+	sql := "select exists(select 1 from {{$schemaModel}} where {{if .Dialect.IndexPlaceholders}}{{whereClause .LQ .RQ 1 .Model.PrimaryKey.Fields}}{{else}}{{whereClause .LQ .RQ 0 .Model.PrimaryKey.Fields}}{{end}}{{if .Model.DefaultScope}}" +
+		{{printf " and (%s)" .Model.DefaultScope | printf "%q"}}{{else}}"{{end}}
 
-	row := bunny.QueryRow(ctx, sql{{range .Model.PrimaryKey.Fields}}, {{$f := $model.FindField .}}{{$f.Name | camelCase}}{{end}})
+	args := []interface{}{ {{- range $i, $p := .Model.PrimaryKey.Fields}}{{if $i}}, {{end}}{{$f := $model.FindField $p}}{{sqlArg $f ($f.Name | camelCase)}}{{end -}} }
+	scope, scopeArgs, err := queries.ContextWhere(ctx, &dialect, {{.Model.Name | singular | camelCase}}ContextMods, len(args)+1)
+	if err != nil {
+		return false, errors.Errorf("{{.PkgName}}: unable to check if {{.Model.Name}} exists: %w", err)
+	}
+	sql += scope + " limit 1)"
+	args = append(args, scopeArgs...)
 
-	err := row.Scan(&exists)
+	err = bunny.QueryRowScan(ctx, sql, args, &exists)
 	if err != nil {
 		return false, errors.Errorf("{{.PkgName}}: unable to check if {{.Model.Name}} exists: %w", err)
 	}
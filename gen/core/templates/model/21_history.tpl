@@ -0,0 +1,30 @@
+{{- if .Model.History -}}
+{{- $modelNameSingular := .Model.Name | singular | titleCase -}}
+{{- $modelNamePlural := .Model.Name | plural | titleCase -}}
+{{- $varNameSingular := .Model.Name | singular | camelCase -}}
+{{- $model := .Model -}}
+// {{$modelNamePlural}}AsOf creates a {{$modelNamePlural}} query with the given mods,
+// of the rows as they were at t, read from the {{.Model.History.Name}} table.
+func {{$modelNamePlural}}AsOf(t time.Time, mods ...qm.QueryMod) {{$varNameSingular}}Query {
+	mods = append(mods,
+		qm.From("{{.Model.History.Name | schemaModel}}"),
+		qm.Where("{{.LQ}}valid_from{{.RQ}} <= ? AND ({{.LQ}}valid_to{{.RQ}} IS NULL OR {{.LQ}}valid_to{{.RQ}} > ?)", t, t),
+	)
+	{{- if .Model.ShardKey}}
+	q := NewQuery(mods...)
+	queries.SetSharded(q)
+	return {{$varNameSingular}}Query{q}
+	{{- else}}
+	return {{$varNameSingular}}Query{NewQuery(mods...)}
+	{{- end}}
+}
+
+// AsOf returns the version of o at t, read from the {{.Model.History.Name}} table.
+// It returns an error wrapping sql.ErrNoRows if the row didn't exist at t.
+func (o *{{$modelNameSingular}}) AsOf(ctx context.Context, t time.Time) (*{{$modelNameSingular}}, error) {
+	{{- if .Model.ShardKey}}
+	ctx = bunny.WithShardKey(ctx, o.{{.Model.ShardKey | titleCasePath}})
+	{{- end}}
+	return {{$modelNamePlural}}AsOf(t, qm.Where("{{whereClause .LQ .RQ 0 .Model.PrimaryKey.Fields}}"{{range .Model.PrimaryKey.Fields}}, {{sqlArg ($model.FindField .) (printf "o.%s" (. | titleCasePath))}}{{end}})).One(ctx)
+}
+{{- end -}}
@@ -10,6 +10,8 @@ var dialect = queries.Dialect{
 	RQ: 0x{{printf "%x" .Dialect.RQ}},
 	IndexPlaceholders: {{.Dialect.IndexPlaceholders}},
 	UseTopClause: {{.Dialect.UseTopClause}},
+	UseReturning: {{.Dialect.UseReturning}},
+	UseOnDuplicateKey: {{.Dialect.UseOnDuplicateKey}},
 }
 
 // NewQuery initializes a new Query using the passed in QueryMods
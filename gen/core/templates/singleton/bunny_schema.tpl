@@ -0,0 +1,9 @@
+import "github.com/sqlbunny/sqlbunny/runtime/bunny"
+
+var schemaInfo = {{.SchemaInfo}}
+
+// Schema returns the description of the models of the package, with their
+// columns, keys and relationships, for generic tooling like admin interfaces.
+func Schema() *bunny.SchemaInfo {
+	return schemaInfo
+}
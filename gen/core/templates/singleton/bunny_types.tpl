@@ -8,13 +8,16 @@ import (
 type M map[string]interface{}
 
 type insertCache struct {
-	query        string
-	valueMapping []queries.MappedField
+	query            string
+	valueMapping     []queries.MappedField
+	returningMapping []queries.MappedField
 }
 
 type updateCache struct {
-	query        string
-	valueMapping []queries.MappedField
+	query            string
+	returning        string
+	valueMapping     []queries.MappedField
+	returningMapping []queries.MappedField
 }
 
 func makeCacheKey(wl []string) string {
@@ -29,3 +32,10 @@ func makeCacheKey(wl []string) string {
 	strmangle.PutBuffer(buf)
 	return str
 }
+
+func makeReturningCacheKey(wl []string, returning []string) string {
+	if len(returning) == 0 {
+		return makeCacheKey(wl)
+	}
+	return makeCacheKey(wl) + "returning:" + makeCacheKey(returning)
+}
@@ -3,6 +3,7 @@ import (
     "bytes"
     "database/sql/driver"
     "encoding/json"
+    "fmt"
 
     "github.com/sqlbunny/sqlbunny/runtime/bunny"
     "github.com/sqlbunny/sqlbunny/types/null/convert"
@@ -18,3 +19,21 @@ type {{$modelName}} struct {
 	{{titleCase $field.Name}} {{goType $field.GoType}} `{{$field.GenerateTags}}`
 	{{- end -}}
 }
+
+{{- if hasPII .Struct.Fields }}
+
+// String formats the {{$modelName}} like the %+v verb, with the values of its PII
+// fields replaced by bunny.RedactedText.
+func (o {{$modelName}}) String() string {
+	return fmt.Sprintf("{{$modelName}}{ {{- range $i, $field := .Struct.Fields }}{{if $i}} {{end}}{{titleCase $field.Name}}:%v{{end -}} }",
+		{{- range $field := .Struct.Fields }}
+		{{if $field.PII}}bunny.RedactedText{{else}}o.{{titleCase $field.Name}}{{end}},
+		{{- end }}
+	)
+}
+
+// GoString formats the {{$modelName}} like String, for the %#v verb.
+func (o {{$modelName}}) GoString() string {
+	return o.String()
+}
+{{- end }}
@@ -0,0 +1,125 @@
+{{ import "context" "context" }}
+{{ import "sql" "database/sql" }}
+{{ import "testing" "testing" }}
+{{ import "errors" "github.com/sqlbunny/errors" }}
+{{ import "queries" "github.com/sqlbunny/sqlbunny/runtime/queries" }}
+{{ import "qm" "github.com/sqlbunny/sqlbunny/runtime/qm" }}
+
+{{- $model := .Model -}}
+{{- $modelName := .Model.Name | titleCase -}}
+{{- $modelNamePlural := .Model.Name | plural | titleCase -}}
+{{- $varNameSingular := .Model.Name | singular | camelCase -}}
+
+// testNew{{$modelName}} returns a {{.Model.Name}} with zero values, except for
+// the fields whose zero value isn't a valid column value. Nullable fields
+// are null.
+func testNew{{$modelName}}() *{{$modelName}} {
+	return &{{$modelName}}{
+		{{- range .Model.Fields}}{{if not .Nullable}}{{$t := .Type.GoType}}
+		{{- if eq $t.Name "[]byte"}}
+		{{titleCase .Name}}: []byte{},
+		{{- else if and (eq $t.Pkg "github.com/sqlbunny/sqlbunny/types") (eq $t.Name "JSON")}}
+		{{titleCase .Name}}: {{goType $t}}("{}"),
+		{{- end}}
+		{{- end}}{{end}}
+	}
+}
+
+func Test{{$modelName}}CRUD(t *testing.T) {
+	testRun(t, func(ctx context.Context) {
+		o := testNew{{$modelName}}()
+		if err := o.Insert(ctx); err != nil {
+			t.Fatalf("unable to insert: %v", err)
+		}
+		inserted, err := testNew{{$modelName}}().InsertIgnore(ctx)
+		if err != nil {
+			t.Fatalf("unable to insert ignoring conflicts: %v", err)
+		}
+		if inserted {
+			t.Error("InsertIgnore inserted a row with the primary key of another")
+		}
+
+		{{if .Model.DefaultScope -}}
+		found, err := {{$modelNamePlural}}(qm.Unscoped()).One(ctx)
+		{{- else -}}
+		found, err := Find{{$modelName}}(ctx{{range .Model.PrimaryKey.Fields}}, o.{{titleCasePath .}}{{end}})
+		{{- end}}
+		if err != nil {
+			t.Fatalf("unable to find the inserted row: %v", err)
+		}
+		testCheckValues(t, o, found, {{$varNameSingular}}Columns)
+
+		if err := found.Update(ctx); err != nil {
+			t.Fatalf("unable to update: %v", err)
+		}
+		{{- if not .Model.DefaultScope}}
+		if err := found.Reload(ctx); err != nil {
+			t.Fatalf("unable to reload: %v", err)
+		}
+		testCheckValues(t, o, found, {{$varNameSingular}}Columns)
+		{{- end}}
+
+		count, err := {{$modelNamePlural}}(qm.Unscoped()).Count(ctx)
+		if err != nil {
+			t.Fatalf("unable to count: %v", err)
+		}
+		if count != 1 {
+			t.Errorf("expected 1 row, got %d", count)
+		}
+
+		if err := found.Delete(ctx); err != nil {
+			t.Fatalf("unable to delete: %v", err)
+		}
+		exists, err := {{$modelName}}Exists(ctx{{range .Model.PrimaryKey.Fields}}, o.{{titleCasePath .}}{{end}})
+		if err != nil {
+			t.Fatalf("unable to check if the row exists: %v", err)
+		}
+		if exists {
+			t.Error("the row exists after Delete")
+		}
+	})
+}
+
+{{if not .Model.DefaultScope -}}
+func Test{{$modelName}}ContextMods(t *testing.T) {
+	testRun(t, func(ctx context.Context) {
+		o := testNew{{$modelName}}()
+		if err := o.Insert(ctx); err != nil {
+			t.Fatalf("unable to insert: %v", err)
+		}
+
+		defer func(mods []func(ctx context.Context, q *queries.Query)) {
+			{{$varNameSingular}}ContextMods = mods
+		}({{$varNameSingular}}ContextMods)
+		Add{{$modelName}}ContextMod(func(ctx context.Context) []qm.QueryMod {
+			if ctx.Value(testScopeKey{}) == nil {
+				return nil
+			}
+			return []qm.QueryMod{qm.Where("1 = 0")}
+		})
+		scoped := context.WithValue(ctx, testScopeKey{}, true)
+
+		if _, err := Find{{$modelName}}(scoped{{range .Model.PrimaryKey.Fields}}, o.{{titleCasePath .}}{{end}}); !errors.Is(err, sql.ErrNoRows) {
+			t.Errorf("expected no rows for Find out of the scope, got %v", err)
+		}
+		if _, err := Find{{$modelName}}(ctx{{range .Model.PrimaryKey.Fields}}, o.{{titleCasePath .}}{{end}}); err != nil {
+			t.Errorf("unable to find the row in the scope: %v", err)
+		}
+
+		exists, err := {{$modelName}}Exists(scoped{{range .Model.PrimaryKey.Fields}}, o.{{titleCasePath .}}{{end}})
+		if err != nil {
+			t.Fatalf("unable to check if the row exists: %v", err)
+		}
+		if exists {
+			t.Error("the row exists out of the scope")
+		}
+		exists, err = {{$modelName}}Exists(ctx{{range .Model.PrimaryKey.Fields}}, o.{{titleCasePath .}}{{end}})
+		if err != nil {
+			t.Fatalf("unable to check if the row exists: %v", err)
+		}
+		if !exists {
+			t.Error("the row doesn't exist in the scope")
+		}
+	})
+}
+{{- end}}
@@ -0,0 +1,64 @@
+{{- $dot := . -}}
+{{- $model := .Model -}}
+{{- $modelName := .Model.Name | titleCase -}}
+{{- $modelNamePlural := .Model.Name | plural | titleCase -}}
+
+{{- range .Model.Relationships}}
+{{- $foreignModel := index $dot.Schema.Models .ForeignModel}}
+{{- /* The zero rows of the models are related if their keys aren't null. */ -}}
+{{- $testable := and (not .IsJoinModel) (not .ForeignWhere) (ne .ForeignModel $model.Name) (not $foreignModel.DefaultScope)}}
+{{- range .LocalFields}}{{if ($model.FindField .).Nullable}}{{$testable = false}}{{end}}{{end}}
+{{- range .ForeignFields}}{{if ($foreignModel.FindField .).Nullable}}{{$testable = false}}{{end}}{{end}}
+{{- if $testable}}
+{{- $relationshipName := .Name | titleCase}}
+{{- $foreignModelName := .ForeignModel | titleCase}}
+
+func Test{{$modelName}}Load{{$relationshipName}}(t *testing.T) {
+	testRun(t, func(ctx context.Context) {
+		if err := testNew{{$modelName}}().Insert(ctx); err != nil {
+			t.Fatalf("unable to insert the {{$model.Name}}: %v", err)
+		}
+		if err := testNew{{$foreignModelName}}().Insert(ctx); err != nil {
+			t.Fatalf("unable to insert the {{.ForeignModel}}: %v", err)
+		}
+
+		o, err := {{$modelNamePlural}}(qm.Unscoped(), qm.Load("{{$relationshipName}}")).One(ctx)
+		if err != nil {
+			t.Fatalf("unable to load {{$relationshipName}}: %v", err)
+		}
+		{{- if .ToMany}}
+		if o.R == nil || len(o.R.{{$relationshipName}}) != 1 {
+			t.Errorf("expected 1 loaded {{.ForeignModel}}, got %+v", o.R)
+		}
+
+		o, err = {{$modelNamePlural}}(qm.Unscoped(), qm.Load("{{$relationshipName}}Count")).One(ctx)
+		if err != nil {
+			t.Fatalf("unable to load {{$relationshipName}}Count: %v", err)
+		}
+		if o.R == nil || o.R.{{$relationshipName}}Count != 1 {
+			t.Errorf("expected 1 counted {{.ForeignModel}}, got %+v", o.R)
+		}
+		{{- else}}
+		if o.R == nil || o.R.{{$relationshipName}} == nil {
+			t.Error("expected the {{.ForeignModel}} to be loaded")
+		}
+		{{- end}}
+
+		has, err := o.Has{{$relationshipName}}(ctx)
+		if err != nil {
+			t.Fatalf("unable to check {{$relationshipName}}: %v", err)
+		}
+		if !has {
+			t.Error("expected the {{$model.Name}} to have {{$relationshipName}}")
+		}
+		count, err := {{$modelNamePlural}}(qm.Unscoped()).WhereExistsRelated("{{$relationshipName}}").Count(ctx)
+		if err != nil {
+			t.Fatalf("unable to count the {{$model.Name}} with {{$relationshipName}}: %v", err)
+		}
+		if count != 1 {
+			t.Errorf("expected 1 {{$model.Name}} with {{$relationshipName}}, got %d", count)
+		}
+	})
+}
+{{- end}}
+{{- end}}
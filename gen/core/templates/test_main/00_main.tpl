@@ -0,0 +1,141 @@
+{{ import "bytes" "bytes" }}
+{{ import "context" "context" }}
+{{ import "sql" "database/sql" }}
+{{ import "driver" "database/sql/driver" }}
+{{ import "fmt" "fmt" }}
+{{ import "url" "net/url" }}
+{{ import "os" "os" }}
+{{ import "reflect" "reflect" }}
+{{ import "testing" "testing" }}
+{{ import "time" "time" }}
+{{ import "pq" "github.com/lib/pq" }}
+{{ import "bunny" "github.com/sqlbunny/sqlbunny/runtime/bunny" }}
+{{ import "bunnytest" "github.com/sqlbunny/sqlbunny/runtime/bunnytest" }}
+{{ import "queries" "github.com/sqlbunny/sqlbunny/runtime/queries" }}
+
+// testSchemaStatements create the tables of the models, without their
+// foreign keys so the tests can write rows in any order.
+var testSchemaStatements = []string{
+	{{- range .TestSchemaStatements}}
+	{{printf "%q" .}},
+	{{- end}}
+}
+
+var testDB *sql.DB
+
+func TestMain(m *testing.M) {
+	os.Exit(testMain(m))
+}
+
+func testMain(m *testing.M) int {
+	dsn := os.Getenv("SQLBUNNY_TEST_DSN")
+	if dsn == "" {
+		// The tests skip themselves.
+		return m.Run()
+	}
+
+	schemaName := fmt.Sprintf("bunny_test_%d", time.Now().UnixNano())
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if _, err := db.Exec("CREATE SCHEMA " + pq.QuoteIdentifier(schemaName)); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	db.Close()
+
+	// Every connection uses the temporary schema.
+	db, err = sql.Open("postgres", testSearchPathDSN(dsn, schemaName))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	defer db.Close()
+	defer db.Exec("DROP SCHEMA " + pq.QuoteIdentifier(schemaName) + " CASCADE")
+
+	for _, stmt := range testSchemaStatements {
+		if _, err := db.Exec(stmt); err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", stmt, err)
+			return 1
+		}
+	}
+
+	testDB = db
+	return m.Run()
+}
+
+// testSearchPathDSN returns dsn with the search_path run-time parameter,
+// in URL or key=value form.
+func testSearchPathDSN(dsn, schemaName string) string {
+	if u, err := url.Parse(dsn); err == nil && u.Scheme != "" {
+		q := u.Query()
+		q.Set("search_path", schemaName)
+		u.RawQuery = q.Encode()
+		return u.String()
+	}
+	return dsn + " search_path=" + schemaName
+}
+
+// testScopeKey is the context key of the contexts the context mods of the
+// tests scope.
+type testScopeKey struct{}
+
+// testRun runs the test in a transaction rolled back when it ends, with
+// validation disabled since the tests write zero values. Without
+// SQLBUNNY_TEST_DSN the test is skipped.
+func testRun(t *testing.T, fn func(ctx context.Context)) {
+	t.Helper()
+	if testDB == nil {
+		t.Skip("SQLBUNNY_TEST_DSN is not set")
+	}
+
+	bunnytest.WithRollback(t, testDB, func(ctx context.Context) {
+		fn(bunny.WithoutValidation(ctx))
+	})
+}
+
+// testCheckValues fails the test if a column of got has another value than
+// in want, comparing the values written to the database.
+func testCheckValues(t *testing.T, want, got interface{}, columns []string) {
+	t.Helper()
+	wantValues := testValues(t, want, columns)
+	gotValues := testValues(t, got, columns)
+	for i, c := range columns {
+		if !testEqualValue(wantValues[i], gotValues[i]) {
+			t.Errorf("column %s: expected %#v, got %#v", c, wantValues[i], gotValues[i])
+		}
+	}
+}
+
+func testValues(t *testing.T, o interface{}, columns []string) []driver.Value {
+	t.Helper()
+	v := reflect.Indirect(reflect.ValueOf(o))
+	mapping, err := queries.BindMapping(v.Type(), queries.MakeStructMapping(v.Type()), columns)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var res []driver.Value
+	for _, value := range queries.ValuesFromMapping(v, mapping) {
+		dv, err := driver.DefaultParameterConverter.ConvertValue(value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		res = append(res, dv)
+	}
+	return res
+}
+
+func testEqualValue(a, b driver.Value) bool {
+	switch a := a.(type) {
+	case time.Time:
+		b, ok := b.(time.Time)
+		return ok && a.Equal(b)
+	case []byte:
+		b, ok := b.([]byte)
+		return ok && bytes.Equal(a, b)
+	}
+	return a == b
+}
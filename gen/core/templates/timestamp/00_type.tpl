@@ -0,0 +1,58 @@
+{{- $typeName := .Timestamp.Name | titleCase -}}
+{{- $typeNameCamel := .Timestamp.Name | camelCase -}}
+{{- $sqlType := .Timestamp.SQLType.Type -}}
+
+import (
+	"database/sql/driver"
+	"time"
+
+	"github.com/sqlbunny/sqlbunny/types/null/convert"
+)
+
+// {{$typeName}} is a time stored as {{$sqlType}}. Its values are in {{.Timestamp.Location}}
+{{- if ge .Timestamp.Precision 0}}, rounded to {{.Timestamp.Precision}} fractional digits{{end}}.
+type {{$typeName}} struct {
+	time.Time
+}
+
+{{if eq .Timestamp.Location "UTC" -}}
+var {{$typeNameCamel}}Location = time.UTC
+{{- else -}}
+var {{$typeNameCamel}}Location = func() *time.Location {
+	loc, err := time.LoadLocation("{{.Timestamp.Location}}")
+	if err != nil {
+		panic(err)
+	}
+	return loc
+}()
+{{- end}}
+
+{{if ge .Timestamp.Precision 0 -}}
+var {{$typeNameCamel}}Resolution = time.Second / 1e{{.Timestamp.Precision}}
+{{- else -}}
+var {{$typeNameCamel}}Resolution = time.Microsecond
+{{- end}}
+
+// {{$typeName}}From creates a new {{$typeName}} with t in its location and precision.
+func {{$typeName}}From(t time.Time) {{$typeName}} {
+	return {{$typeName}}{t.Round({{$typeNameCamel}}Resolution).In({{$typeNameCamel}}Location)}
+}
+
+// Scan implements the Scanner interface.
+func (t *{{$typeName}}) Scan(value interface{}) error {
+	var v time.Time
+	if err := convert.Assign(&v, value); err != nil {
+		return err
+	}
+	{{- if not .Timestamp.TZ}}
+	// timestamp columns hold the wall clock time in the location.
+	v = time.Date(v.Year(), v.Month(), v.Day(), v.Hour(), v.Minute(), v.Second(), v.Nanosecond(), {{$typeNameCamel}}Location)
+	{{- end}}
+	*t = {{$typeName}}From(v)
+	return nil
+}
+
+// Value implements the driver Valuer interface.
+func (t {{$typeName}}) Value() (driver.Value, error) {
+	return {{$typeName}}From(t.Time).Time, nil
+}
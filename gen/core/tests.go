@@ -0,0 +1,75 @@
+package core
+
+import (
+	"log"
+	"reflect"
+	"sort"
+
+	"github.com/sqlbunny/sqlbunny/gen"
+	"github.com/sqlbunny/sqlbunny/runtime/migration"
+	"github.com/sqlbunny/sqlbunny/schema"
+	"github.com/sqlbunny/sqlschema/diff"
+	"github.com/sqlbunny/sqlschema/operations"
+	sqlschema "github.com/sqlbunny/sqlschema/schema"
+)
+
+// testSchemaStatements returns the statements creating the tables of the
+// models, without their foreign keys so the tests can write rows in any
+// order.
+func testSchemaStatements(s *schema.Schema) []string {
+	db := s.SQLSchema()
+	for _, sc := range db.Schemas {
+		for _, t := range sc.Tables {
+			t.ForeignKeys = map[string]*sqlschema.ForeignKey{}
+		}
+	}
+
+	empty := sqlschema.NewDatabase()
+	empty.Schemas[""] = sqlschema.NewSchema()
+
+	// The diff is in map order. Sort the columns, and the runs of operations
+	// of the same kind, so the statements are the same on every run.
+	var res []string
+	ops := diff.Diff(empty, db)
+	for _, op := range ops {
+		if ct, ok := op.(operations.CreateTable); ok {
+			sort.Slice(ct.Columns, func(i, j int) bool {
+				return ct.Columns[i].Name < ct.Columns[j].Name
+			})
+		}
+	}
+	for i := 0; i < len(ops); {
+		j := i
+		var run []string
+		for ; j < len(ops) && reflect.TypeOf(ops[j]) == reflect.TypeOf(ops[i]); j++ {
+			stmts, err := migration.Postgres.Statements(nil, ops[j:j+1])
+			if err != nil {
+				log.Fatal(err)
+			}
+			run = append(run, stmts...)
+		}
+		sort.Strings(run)
+		res = append(res, run...)
+		i = j
+	}
+	return res
+}
+
+// genTests generates the integration tests of the models: a test of
+// the CRUD methods for each model, and of eager loading for each
+// relationship whose zero rows are related.
+func (p *Plugin) genTests() {
+	if gen.Config.Dialect != gen.DialectPostgres {
+		log.Fatal("The generated tests need the postgres dialect")
+	}
+
+	data := gen.BaseTemplateData()
+	data["TestSchemaStatements"] = testSchemaStatements(gen.Config.Schema)
+	p.TestMainTemplates.Execute(data, "bunny_main.gen_test.go")
+
+	for _, model := range gen.Config.Schema.Models {
+		data := gen.BaseTemplateData()
+		data["Model"] = model
+		p.TestTemplates.Execute(data, model.Name+".gen_test.go")
+	}
+}
@@ -1,13 +1,17 @@
 package core
 
 import (
+	"sort"
 	"strings"
 
 	"github.com/sqlbunny/sqlbunny/gen"
+	"github.com/sqlbunny/sqlbunny/runtime/strmangle"
 	"github.com/sqlbunny/sqlbunny/schema"
 )
 
-func buildSchema(items []gen.ConfigItem) (*schema.Schema, error) {
+// buildSchema builds the schema of the items, returning its warnings, and
+// its errors if it's invalid.
+func buildSchema(items []gen.ConfigItem) (*schema.Schema, []error, error) {
 	ctx := &gen.Context{
 		Schema: schema.New(),
 	}
@@ -24,13 +28,26 @@ func buildSchema(items []gen.ConfigItem) (*schema.Schema, error) {
 		checkIndexes(ctx, m)
 		checkUniques(ctx, m)
 		checkForeignKeys(ctx, m)
+		checkTriggers(ctx, m)
+		checkForeignTable(ctx, m)
+		checkView(ctx, m)
+		checkHistory(ctx, m)
+		checkEncryption(ctx, m)
+		checkAnonymization(ctx, m)
+		checkShardKey(ctx, m)
+		if len(m.Policies) != 0 && !m.RowLevelSecurity {
+			addErrorAt(ctx, m, "Model '%s' has policies, but no RowLevelSecurity", m.Name)
+		}
+		checkIdentifierLengths(ctx, m)
+		checkRedundantIndexes(ctx, m)
 	}
+	checkIDPrefixes(ctx)
 
 	// TODO disallow double underscore.
 	// TODO check FK fields match type (Go type? or just Postgres type?)
 
 	if err := ctx.Error(); err != nil {
-		return nil, err
+		return nil, ctx.Warnings(), err
 	}
 
 	ctx.Schema.CalculateRelationships()
@@ -38,7 +55,7 @@ func buildSchema(items []gen.ConfigItem) (*schema.Schema, error) {
 	// TODO remove this
 	ctx.Schema.SQLSchema()
 
-	return ctx.Schema, nil
+	return ctx.Schema, ctx.Warnings(), nil
 }
 
 type Context interface {
@@ -103,7 +120,7 @@ func checkDuplicateFields(ctx *gen.Context, m *schema.Model) {
 	seen := make(map[string]struct{})
 	for _, f := range m.Fields {
 		if _, ok := seen[f.Name]; ok {
-			ctx.AddError("Model '%s' field '%s' is defined multiple times.", m.Name, f.Name)
+			addErrorAt(ctx, f, "Model '%s' field '%s' is defined multiple times.", m.Name, f.Name)
 		}
 		seen[f.Name] = struct{}{}
 	}
@@ -115,17 +132,47 @@ func describeIndex(fields []schema.Path) string {
 
 func checkPrimaryKey(ctx *gen.Context, m *schema.Model) {
 	if m.PrimaryKey == nil {
-		ctx.AddError("Model '%s' is missing a primary key", m.Name)
+		addErrorAt(ctx, m, "Model '%s' is missing a primary key", m.Name)
 		return
 	}
 
 	for _, p := range m.PrimaryKey.Fields {
 		f := m.FindField(p)
 		if f == nil {
-			ctx.AddError("Model '%s' primary key references unknown field '%s'", m.Name, p.DotName())
+			addErrorAt(ctx, m, "Model '%s' primary key references unknown field '%s'", m.Name, p.DotName())
 		} else if f.Nullable {
-			ctx.AddError("Model '%s' primary key references nullable field '%s'", m.Name, p.DotName())
+			addErrorAt(ctx, f, "Model '%s' primary key references nullable field '%s'", m.Name, p.DotName())
+		} else if f.DBDefault != "" {
+			// Inserts leave the field out, so upserts on the primary key
+			// never conflict.
+			addErrorAt(ctx, f, "Model '%s' primary key references field '%s', which has a DBDefault", m.Name, p.DotName())
+		}
+	}
+}
+
+// checkIDPrefixes checks that every ID type got its prefix from a model, and
+// that no two models have the same prefix.
+func checkIDPrefixes(ctx *gen.Context) {
+	var names []string
+	for name := range ctx.Schema.Types {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	models := map[string]string{}
+	for _, name := range names {
+		t, ok := ctx.Schema.Types[name].(*schema.ID)
+		if !ok {
+			continue
+		}
+		if t.Model == "" {
+			ctx.AddError("Type '%s' is a PrefixedID, but it's not the primary key of a model with IDPrefix", t.Name)
+			continue
+		}
+		if other, ok := models[t.Prefix]; ok {
+			ctx.AddError("Models '%s' and '%s' have the same ID prefix '%s'", other, t.Model, t.Prefix)
 		}
+		models[t.Prefix] = t.Model
 	}
 }
 
@@ -135,14 +182,14 @@ func checkIndexes(ctx *gen.Context, m *schema.Model) {
 		desc := describeIndex(f.Fields)
 
 		if _, ok := seen[desc]; ok {
-			ctx.AddError("Model '%s' index '%s' is defined multiple times.", m.Name, desc)
+			addErrorAt(ctx, m, "Model '%s' index '%s' is defined multiple times.", m.Name, desc)
 		}
 		seen[desc] = struct{}{}
 
 		for _, path := range f.Fields {
 			c := m.FindField(path)
 			if c == nil {
-				ctx.AddError("Model '%s' index '%s' references unknown field '%s'", m.Name, desc, path.DotName())
+				addErrorAt(ctx, m, "Model '%s' index '%s' references unknown field '%s'", m.Name, desc, path.DotName())
 			}
 		}
 	}
@@ -154,17 +201,185 @@ func checkUniques(ctx *gen.Context, m *schema.Model) {
 		desc := describeIndex(f.Fields)
 
 		if _, ok := seen[desc]; ok {
-			ctx.AddError("Model '%s' unique '%s' is defined multiple times.", m.Name, desc)
+			addErrorAt(ctx, m, "Model '%s' unique '%s' is defined multiple times.", m.Name, desc)
 		}
 		seen[desc] = struct{}{}
 
 		for _, path := range f.Fields {
 			c := m.FindField(path)
 			if c == nil {
-				ctx.AddError("Model '%s' unique '%s' references unknown field '%s'", m.Name, desc, path.DotName())
+				addErrorAt(ctx, m, "Model '%s' unique '%s' references unknown field '%s'", m.Name, desc, path.DotName())
+			}
+		}
+	}
+}
+
+func checkTriggers(ctx *gen.Context, m *schema.Model) {
+	seen := make(map[string]struct{})
+	for _, t := range m.Triggers {
+		if _, ok := seen[t.Name]; ok {
+			addErrorAt(ctx, m, "Model '%s' trigger '%s' is defined multiple times.", m.Name, t.Name)
+		}
+		seen[t.Name] = struct{}{}
+
+		for _, path := range t.UpdateFields {
+			f := m.FindField(path)
+			if f == nil {
+				addErrorAt(ctx, m, "Model '%s' trigger '%s' references unknown field '%s'", m.Name, t.Name, path.DotName())
+			} else if _, ok := f.Type.(*schema.Struct); ok {
+				addErrorAt(ctx, m, "Model '%s' trigger '%s' references struct field '%s', it must reference its fields", m.Name, t.Name, path.DotName())
+			}
+		}
+	}
+}
+
+func checkForeignTable(ctx *gen.Context, m *schema.Model) {
+	t := m.ForeignTable
+	if t == nil {
+		return
+	}
+	if len(m.Indexes) != 0 || m.Storage != nil {
+		addErrorAt(ctx, m, "Model '%s' is a foreign table, it can't have indexes or storage", m.Name)
+	}
+	if len(m.Triggers) != 0 || m.RowLevelSecurity {
+		addErrorAt(ctx, m, "Model '%s' is a foreign table, it can't have triggers or row-level security", m.Name)
+	}
+	for _, u := range m.Uniques {
+		if u.Index {
+			addErrorAt(ctx, m, "Model '%s' is a foreign table, it can't have unique indexes", m.Name)
+		}
+	}
+	for _, c := range t.Columns {
+		f := m.FindField(c.Field)
+		if f == nil {
+			addErrorAt(ctx, m, "Model '%s' foreign table maps unknown field '%s'", m.Name, c.Field.DotName())
+		} else if _, ok := f.Type.(*schema.Struct); ok {
+			addErrorAt(ctx, m, "Model '%s' foreign table maps struct field '%s', it must map its fields", m.Name, c.Field.DotName())
+		}
+		if c.RemoteName == "" {
+			addErrorAt(ctx, m, "Model '%s' foreign table maps field '%s' to an empty column name", m.Name, c.Field.DotName())
+		}
+	}
+}
+
+func checkView(ctx *gen.Context, m *schema.Model) {
+	v := m.View
+	if v == nil {
+		return
+	}
+	if m.ForeignTable != nil {
+		addErrorAt(ctx, m, "Model '%s' can't be both a foreign table and a view", m.Name)
+	}
+	if len(m.Indexes) != 0 || m.Storage != nil || m.RowLevelSecurity {
+		addErrorAt(ctx, m, "Model '%s' is a view, it can't have indexes, storage or row-level security", m.Name)
+	}
+	for _, u := range m.Uniques {
+		if u.Index {
+			addErrorAt(ctx, m, "Model '%s' is a view, it can't have unique indexes", m.Name)
+		}
+	}
+	for _, name := range v.DependsOn {
+		if _, ok := ctx.Schema.Models[name]; !ok {
+			addErrorAt(ctx, m, "Model '%s' view depends on unknown model '%s'", m.Name, name)
+		}
+	}
+	if v.Table == "" {
+		return
+	}
+	if m.PrimaryKey == nil {
+		addErrorAt(ctx, m, "Model '%s' view has a Table, but no primary key to write its rows", m.Name)
+	}
+	for _, t := range m.Triggers {
+		if strmangle.SetInclude(t.Name, schema.ViewTriggerNames) {
+			addErrorAt(ctx, m, "Model '%s' trigger '%s' has the name of a trigger of the view", m.Name, t.Name)
+		}
+	}
+	for _, c := range v.Columns {
+		f := m.FindField(c.Field)
+		if f == nil {
+			addErrorAt(ctx, m, "Model '%s' view maps unknown field '%s'", m.Name, c.Field.DotName())
+		} else if _, ok := f.Type.(*schema.Struct); ok {
+			addErrorAt(ctx, m, "Model '%s' view maps struct field '%s', it must map its fields", m.Name, c.Field.DotName())
+		}
+		if c.TableColumn == "" {
+			addErrorAt(ctx, m, "Model '%s' view maps field '%s' to an empty column name", m.Name, c.Field.DotName())
+		}
+	}
+}
+
+func checkHistory(ctx *gen.Context, m *schema.Model) {
+	if m.History == nil {
+		return
+	}
+	if !m.HasTable() {
+		addErrorAt(ctx, m, "Model '%s' has History, but it's a foreign table or a view", m.Name)
+	}
+	if m.PrimaryKey == nil {
+		addErrorAt(ctx, m, "Model '%s' has History, but no primary key to record its rows", m.Name)
+	}
+	for _, f := range m.Fields {
+		if f.Name == schema.HistoryValidFrom || f.Name == schema.HistoryValidTo {
+			addErrorAt(ctx, m, "Model '%s' field '%s' has the name of a field of its history", m.Name, f.Name)
+		}
+	}
+	for _, t := range m.Triggers {
+		if t.Name == schema.HistoryTriggerName {
+			addErrorAt(ctx, m, "Model '%s' trigger '%s' has the name of the trigger of its history", m.Name, t.Name)
+		}
+	}
+}
+
+func checkEncryption(ctx *gen.Context, m *schema.Model) {
+	encryption := func(m *schema.Model, p schema.Path) schema.Encryption {
+		if f := m.FindField(p); f != nil {
+			return f.Encryption
+		}
+		return schema.EncryptionNone
+	}
+
+	if m.PrimaryKey != nil {
+		for _, p := range m.PrimaryKey.Fields {
+			if encryption(m, p) != schema.EncryptionNone {
+				addErrorAt(ctx, m, "Model '%s' primary key field '%s' can't be encrypted", m.Name, p.DotName())
+			}
+		}
+	}
+	for _, fk := range m.ForeignKeys {
+		m2 := ctx.Schema.Models[fk.ForeignModel]
+		for i, p := range fk.LocalFields {
+			if encryption(m, p) != schema.EncryptionNone || (m2 != nil && i < len(fk.ForeignFields) && encryption(m2, fk.ForeignFields[i]) != schema.EncryptionNone) {
+				addErrorAt(ctx, m, "Model '%s' foreign key field '%s' can't be encrypted, or reference an encrypted field", m.Name, p.DotName())
+			}
+		}
+	}
+	for _, i := range m.Indexes {
+		for _, p := range i.Fields {
+			if encryption(m, p) == schema.EncryptionRandomized {
+				addErrorAt(ctx, m, "Model '%s' index field '%s' is encrypted, it must be EncryptedDeterministic", m.Name, p.DotName())
 			}
 		}
 	}
+	for _, u := range m.Uniques {
+		for _, p := range u.Fields {
+			if encryption(m, p) == schema.EncryptionRandomized {
+				addErrorAt(ctx, m, "Model '%s' unique field '%s' is encrypted, it must be EncryptedDeterministic", m.Name, p.DotName())
+			}
+		}
+	}
+}
+
+func checkShardKey(ctx *gen.Context, m *schema.Model) {
+	if m.ShardKey == nil {
+		return
+	}
+	f := m.FindField(m.ShardKey)
+	if f == nil {
+		addErrorAt(ctx, m, "Model '%s' shard key references unknown field '%s'", m.Name, m.ShardKey.DotName())
+	} else if f.Nullable {
+		addErrorAt(ctx, f, "Model '%s' shard key references nullable field '%s'", m.Name, m.ShardKey.DotName())
+	} else if f.IsStruct() {
+		addErrorAt(ctx, f, "Model '%s' shard key references struct field '%s'", m.Name, m.ShardKey.DotName())
+	}
 }
 
 func checkForeignKeys(ctx *gen.Context, m *schema.Model) {
@@ -173,17 +388,17 @@ func checkForeignKeys(ctx *gen.Context, m *schema.Model) {
 		desc := strings.Join(dotNameAll(f.LocalFields), ", ")
 
 		if len(f.LocalFields) == 0 {
-			ctx.AddError("Model '%s' foreign key '%s': local field list is empty", m.Name, desc)
+			addErrorAt(ctx, m, "Model '%s' foreign key '%s': local field list is empty", m.Name, desc)
 		}
 		for _, p := range f.LocalFields {
 			if f := m.FindField(p); f == nil {
-				ctx.AddError("Model '%s' foreign key '%s': local field '%s' does not exist", m.Name, desc, p.DotName())
+				addErrorAt(ctx, m, "Model '%s' foreign key '%s': local field '%s' does not exist", m.Name, desc, p.DotName())
 			}
 		}
 
 		m2, ok := ctx.Schema.Models[f.ForeignModel]
 		if !ok {
-			ctx.AddError("Model '%s' foreign key '%s': foreign model '%s' does not exist", m.Name, desc, f.ForeignModel)
+			addErrorAt(ctx, m, "Model '%s' foreign key '%s': foreign model '%s' does not exist", m.Name, desc, f.ForeignModel)
 			continue
 		}
 		if f.ForeignFields == nil && m2.PrimaryKey != nil {
@@ -191,16 +406,21 @@ func checkForeignKeys(ctx *gen.Context, m *schema.Model) {
 		}
 
 		if len(f.ForeignFields) == 0 {
-			ctx.AddError("Model '%s' foreign key '%s': foreign field list is empty", m.Name, desc)
+			addErrorAt(ctx, m, "Model '%s' foreign key '%s': foreign field list is empty", m.Name, desc)
 		}
+		missing := false
 		for _, p := range f.ForeignFields {
 			if f := m2.FindField(p); f == nil {
-				ctx.AddError("Model '%s' foreign key '%s': foreign field '%s' does not exist", m.Name, desc, p.DotName())
+				addErrorAt(ctx, m, "Model '%s' foreign key '%s': foreign field '%s' does not exist", m.Name, desc, p.DotName())
+				missing = true
 			}
 		}
+		if !missing && len(f.ForeignFields) != 0 && !isUniqueKey(m2, f.ForeignFields) {
+			addErrorAt(ctx, m, "Model '%s' foreign key '%s': foreign fields '%s' are not the primary key or a unique of model '%s'", m.Name, desc, describeIndex(f.ForeignFields), m2.Name)
+		}
 
 		if len(f.LocalFields) != len(f.ForeignFields) {
-			ctx.AddError("Model '%s' foreign key '%s': local (%d) and foreign (%d) field count doesn't match", m.Name, desc, len(f.LocalFields), len(f.ForeignFields))
+			addErrorAt(ctx, m, "Model '%s' foreign key '%s': local (%d) and foreign (%d) field count doesn't match", m.Name, desc, len(f.LocalFields), len(f.ForeignFields))
 			continue // Do not compare types if count doesn't match
 		}
 
@@ -210,14 +430,117 @@ func checkForeignKeys(ctx *gen.Context, m *schema.Model) {
 			if ff == nil || lf == nil {
 				continue // Ignore these errors, they've already been reported before.
 			}
-			if ff.Type != lf.Type {
-				ctx.AddError("Model '%s' foreign key '%s': local field '%s' and foreign field '%s' have different types: %+v %+v", m.Name, desc, f.LocalFields[i], f.ForeignFields[i], lf.Type, ff.Type)
+			if !sameType(ff.Type, lf.Type) {
+				addErrorAt(ctx, lf, "Model '%s' foreign key '%s': local field '%s' and foreign field '%s' have different types: %+v %+v", m.Name, desc, f.LocalFields[i], f.ForeignFields[i], lf.Type, ff.Type)
 			}
 		}
 
 		if _, ok := seen[desc]; ok {
-			ctx.AddError("Model '%s' foreign key '%s' is defined multiple times.", m.Name, describeIndex(f.LocalFields))
+			addErrorAt(ctx, m, "Model '%s' foreign key '%s' is defined multiple times.", m.Name, describeIndex(f.LocalFields))
 		}
 		seen[desc] = struct{}{}
 	}
 }
+
+// isUniqueKey reports whether the fields are the primary key or a unique of
+// the model, in any order, which foreign keys can reference.
+func isUniqueKey(m *schema.Model, fields []schema.Path) bool {
+	same := func(a []schema.Path) bool {
+		if len(a) != len(fields) {
+			return false
+		}
+		for _, p := range a {
+			found := false
+			for _, p2 := range fields {
+				found = found || p.Equals(p2)
+			}
+			if !found {
+				return false
+			}
+		}
+		return true
+	}
+	if m.PrimaryKey != nil && same(m.PrimaryKey.Fields) {
+		return true
+	}
+	for _, u := range m.Uniques {
+		if same(u.Fields) {
+			return true
+		}
+	}
+	return false
+}
+
+// isPathPrefix reports whether a is a prefix of b.
+func isPathPrefix(a, b []schema.Path) bool {
+	if len(a) > len(b) {
+		return false
+	}
+	for i := range a {
+		if !a[i].Equals(b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// checkRedundantIndexes warns about the indexes whose fields are a prefix of
+// the fields of the primary key, a unique or a longer index, which can be
+// used instead. Indexes with options, like operator classes, serve other
+// queries and are never redundant.
+func checkRedundantIndexes(ctx *gen.Context, m *schema.Model) {
+	for i, idx := range m.Indexes {
+		if idx.Options != nil {
+			continue
+		}
+		desc := describeIndex(idx.Fields)
+		if m.PrimaryKey != nil && isPathPrefix(idx.Fields, m.PrimaryKey.Fields) {
+			ctx.AddWarning("%sModel '%s' index '%s' is redundant with the primary key", posPrefix(m), m.Name, desc)
+			continue
+		}
+		redundant := false
+		for _, u := range m.Uniques {
+			if isPathPrefix(idx.Fields, u.Fields) {
+				ctx.AddWarning("%sModel '%s' index '%s' is redundant with unique '%s'", posPrefix(m), m.Name, desc, describeIndex(u.Fields))
+				redundant = true
+				break
+			}
+		}
+		if redundant {
+			continue
+		}
+		for j, idx2 := range m.Indexes {
+			// Equal indexes are reported as duplicates.
+			if i != j && idx2.Options == nil && len(idx.Fields) < len(idx2.Fields) && isPathPrefix(idx.Fields, idx2.Fields) {
+				ctx.AddWarning("%sModel '%s' index '%s' is redundant with index '%s'", posPrefix(m), m.Name, desc, describeIndex(idx2.Fields))
+				break
+			}
+		}
+	}
+}
+
+// checkIdentifierLengths checks the names of the table and the columns of
+// the model fit in Postgres identifiers. Generated constraint names are
+// truncated instead.
+func checkIdentifierLengths(ctx *gen.Context, m *schema.Model) {
+	if len(m.Name) > schema.MaxIdentifierLength {
+		addErrorAt(ctx, m, "Model '%s' name is longer than %d bytes", m.Name, schema.MaxIdentifierLength)
+	}
+	for _, c := range m.Columns() {
+		if len(c.Name) > schema.MaxIdentifierLength {
+			addErrorAt(ctx, m, "Model '%s' column '%s' is longer than %d bytes", m.Name, c.Name, schema.MaxIdentifierLength)
+		}
+	}
+}
+
+// sameType reports whether a and b are the same type. Field options like
+// UUIDStorage copy the type, the copies are the same type if they have
+// the same SQL type.
+func sameType(a, b schema.Type) bool {
+	if a == b {
+		return true
+	}
+	ba, ok1 := a.(schema.BaseType)
+	bb, ok2 := b.(schema.BaseType)
+	return ok1 && ok2 && a.GetName() == b.GetName() && ba.SQLType() == bb.SQLType()
+}
@@ -2,6 +2,7 @@ package gen
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/sqlbunny/sqlbunny/schema"
 )
@@ -34,15 +35,18 @@ func templateTypesGo(t []schema.GoType) []string {
 }
 
 func templateGoType(t schema.GoType) string {
-	if t.Pkg == "" {
-		return t.Name
+	name := t.Name
+	if t.Pkg != "" {
+		pkgName, ok := imports[t.Pkg]
+		if !ok {
+			pkgName = fmt.Sprintf("_import%02d", importCount)
+			imports[t.Pkg] = pkgName
+			importCount++
+		}
+		name = pkgName + "." + t.Name
 	}
-
-	pkgName, ok := imports[t.Pkg]
-	if !ok {
-		pkgName = fmt.Sprintf("_import%02d", importCount)
-		imports[t.Pkg] = pkgName
-		importCount++
+	if len(t.Args) != 0 {
+		name += "[" + strings.Join(templateTypesGo(t.Args), ", ") + "]"
 	}
-	return pkgName + "." + t.Name
+	return name
 }
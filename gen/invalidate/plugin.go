@@ -0,0 +1,55 @@
+package invalidate
+
+import (
+	"bytes"
+
+	"github.com/sqlbunny/sqlbunny/gen"
+	"github.com/sqlbunny/sqlbunny/schema"
+)
+
+const (
+	templatesPackage = "github.com/sqlbunny/sqlbunny/gen/invalidate"
+)
+
+// Plugin generates invalidation callbacks for all models: after every
+// insert, update or delete of a row, the keys of the row are passed to the
+// hooks registered with bunny.OnInvalidate or the generated
+// Add<Model>InvalidateHook, once the write is committed.
+//
+// Invalidations are built from the objects the generated code writes, so
+// they only have the keys it knows: UpdateMapAll and DeleteAll on queries
+// change rows which are never loaded and invalidate nothing, nor do deletes
+// by keys without RETURNING, as with MySQL. Caches fed by these hooks must
+// expire the rows of the models written that way by other means.
+type Plugin struct {
+}
+
+var _ gen.Plugin = &Plugin{}
+
+func (*Plugin) ConfigItem(ctx *gen.Context) {}
+
+func (p *Plugin) BunnyPlugin() {
+	gen.OnHook("model", p.modelHook(gen.MustLoadTemplate(templatesPackage, "templates/model.tpl")))
+
+	single := gen.MustLoadTemplate(templatesPackage, "templates/invalidate.tpl")
+	slice := gen.MustLoadTemplate(templatesPackage, "templates/invalidate_slice.tpl")
+	gen.OnHook("after_insert", p.hook(single, "insert"))
+	gen.OnHook("after_update", p.hook(single, "update"))
+	gen.OnHook("after_delete", p.hook(single, "delete"))
+	gen.OnHook("after_insert_slice", p.hook(slice, "insert"))
+	gen.OnHook("after_delete_slice", p.hook(slice, "delete"))
+}
+
+// hook passes the objects written with kind to their invalidate method,
+// which every model has.
+func (p *Plugin) hook(tpl *gen.TemplateList, kind string) gen.HookFunc {
+	return gen.ModelHook(tpl, func(model *schema.Model) (map[string]interface{}, bool) {
+		return map[string]interface{}{"Kind": kind}, true
+	})
+}
+
+func (p *Plugin) modelHook(tpl *gen.TemplateList) gen.HookFunc {
+	return func(buf *bytes.Buffer, data map[string]interface{}, args ...interface{}) {
+		tpl.ExecuteBuf(data, buf)
+	}
+}
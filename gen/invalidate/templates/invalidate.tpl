@@ -0,0 +1 @@
+	{{.Var}}.invalidate(ctx, "{{.Kind}}")
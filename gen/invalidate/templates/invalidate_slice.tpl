@@ -0,0 +1,3 @@
+	for _, obj := range {{.Var}} {
+		obj.invalidate(ctx, "{{.Kind}}")
+	}
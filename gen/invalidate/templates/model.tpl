@@ -0,0 +1,24 @@
+{{- $modelNameSingular := .Model.Name | singular | titleCase -}}
+
+// Add{{$modelNameSingular}}InvalidateHook registers fn to be called with every
+// committed insert, update or delete of a {{$modelNameSingular}} row, keyed by
+// its primary key and unique keys. See bunny.OnInvalidate.
+func Add{{$modelNameSingular}}InvalidateHook(fn bunny.InvalidateHook) {
+	bunny.OnInvalidate("{{.Model.Name}}", fn)
+}
+
+// invalidate passes the write of o to the invalidation hooks, once it's committed.
+func (o *{{$modelNameSingular}}) invalidate(ctx context.Context, kind string) {
+	bunny.Invalidate(ctx, bunny.Invalidation{
+		Model: "{{.Model.Name}}",
+		Kind:  kind,
+		Key:   bunny.CacheKey({{range $i, $f := .Model.PrimaryKey.Fields}}{{if $i}}, {{end}}o.{{$f | titleCasePath}}{{end}}),
+		{{- if .Model.Uniques}}
+		Uniques: map[string]string{
+			{{- range $u := .Model.Uniques}}
+			"{{$.Model.UniqueName $u}}": bunny.CacheKey({{range $i, $f := $u.Fields}}{{if $i}}, {{end}}{{fieldExpr $.Model "o" $f}}{{end}}),
+			{{- end}}
+		},
+		{{- end}}
+	})
+}
@@ -1,10 +1,10 @@
 package gen
 
 import (
+	"log"
 	"os"
 
 	"github.com/spf13/cobra"
-	"github.com/sqlbunny/sqlbunny/runtime/queries"
 )
 
 var rootCmd *cobra.Command
@@ -30,22 +30,49 @@ func expandAll(items []ConfigItem) []ConfigItem {
 }
 
 func Run(items []ConfigItem) {
+	projectFile := os.Getenv("SQLBUNNY_CONFIG")
+	if projectFile == "" {
+		projectFile = ProjectFile
+	}
+	project, err := LoadProjectConfig(projectFile)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if project != nil {
+		var enabled []ConfigItem
+		for _, i := range items {
+			if project.enabled(i) {
+				enabled = append(enabled, i)
+			}
+		}
+		if unknown := project.unknownPlugins(items); len(unknown) != 0 {
+			enabled = append(enabled, unknownPlugins(unknown))
+		}
+		items = enabled
+	}
+
 	items = expandAll(items)
 
-	rootCmd = &cobra.Command{Use: "sqlbunny"}
+	rootCmd = &cobra.Command{
+		Use:              "sqlbunny",
+		PersistentPreRun: checkWarnings,
+	}
+	rootCmd.PersistentFlags().Bool("strict", false, "Fail on schema warnings")
 
 	Config = &ConfigStruct{
 		Items: items,
 
-		Dialect: queries.Dialect{
-			LQ:                '"',
-			RQ:                '"',
-			IndexPlaceholders: true,
-			UseTopClause:      false,
-		},
+		Dialect: DialectPostgres,
 
 		ModelsPackagePath: "./models",
 		ModelsPackageName: "models",
+
+		Project: project,
+	}
+	if project != nil {
+		if err := project.apply(Config); err != nil {
+			log.Fatal(err)
+		}
 	}
 
 	rootCmd.AddCommand(&cobra.Command{
@@ -70,6 +97,21 @@ func Run(items []ConfigItem) {
 	}
 }
 
+// PrintWarnings prints schema warnings.
+func PrintWarnings(warnings []error) {
+	for _, w := range warnings {
+		log.Printf("Warning: %v", w)
+	}
+}
+
+func checkWarnings(cmd *cobra.Command, args []string) {
+	PrintWarnings(Config.Warnings)
+	if strict, _ := cmd.Flags().GetBool("strict"); strict && len(Config.Warnings) != 0 {
+		log.Printf("%d warnings found, failing because of --strict.", len(Config.Warnings))
+		os.Exit(1)
+	}
+}
+
 func gen(cmd *cobra.Command, args []string) {
 	for _, f := range genFuncs {
 		f()
@@ -0,0 +1,57 @@
+package migration
+
+import (
+	bunnyschema "github.com/sqlbunny/sqlbunny/schema"
+	"github.com/sqlbunny/sqlschema/operations"
+	"github.com/sqlbunny/sqlschema/schema"
+)
+
+// extras are the parts of the database the SQL schema doesn't have, tracked
+// from the operations of the migrations.
+type extras struct {
+	storage  storageState
+	triggers triggerState
+	raw      rawState
+	policies *policyState
+	grants   grantState
+	foreign  foreignState
+	views    viewState
+	funcs    functionState
+}
+
+func newExtras() *extras {
+	return &extras{
+		storage:  storageState{},
+		triggers: triggerState{},
+		policies: newPolicyState(),
+		grants:   grantState{},
+		foreign:  foreignState{},
+		views:    viewState{},
+		funcs:    functionState{},
+	}
+}
+
+func (e *extras) apply(ops []operations.Operation) {
+	e.storage.apply(ops)
+	e.triggers.apply(ops)
+	e.raw.apply(ops)
+	e.policies.apply(ops)
+	e.grants.apply(ops)
+	e.foreign.apply(ops)
+	e.views.apply(ops)
+	e.funcs.apply(ops)
+}
+
+// schemaExtras returns the extras of the schema, whose SQL schema is db.
+func schemaExtras(s *bunnyschema.Schema, db *schema.Database) *extras {
+	return &extras{
+		storage:  schemaStorage(s),
+		triggers: schemaTriggers(s),
+		raw:      schemaRaw(s),
+		policies: schemaPolicies(s),
+		grants:   schemaGrants(s),
+		foreign:  schemaForeign(s, db),
+		views:    schemaViews(s),
+		funcs:    schemaFunctions(s),
+	}
+}
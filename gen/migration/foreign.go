@@ -0,0 +1,93 @@
+package migration
+
+import (
+	"reflect"
+	"sort"
+
+	"github.com/sqlbunny/sqlbunny/runtime/migration"
+	bunnyschema "github.com/sqlbunny/sqlbunny/schema"
+	"github.com/sqlbunny/sqlschema/operations"
+	"github.com/sqlbunny/sqlschema/schema"
+)
+
+// foreignState is the foreign tables, by table with an empty name. They're
+// not in the SQL schema, so they're tracked like the triggers.
+type foreignState map[objectKey]migration.CreateForeignTable
+
+func (s foreignState) apply(ops []operations.Operation) {
+	for _, op := range ops {
+		switch o := op.(type) {
+		case migration.CreateForeignTable:
+			s[objectKey{o.SchemaName, o.TableName, ""}] = o
+		case migration.DropForeignTable:
+			delete(s, objectKey{o.SchemaName, o.TableName, ""})
+		}
+	}
+}
+
+// schemaForeign returns the foreign tables of the schema, with the columns
+// of the tables of their models in db.
+func schemaForeign(s *bunnyschema.Schema, db *schema.Database) foreignState {
+	res := foreignState{}
+	for _, m := range s.Models {
+		if m.ForeignTable == nil {
+			continue
+		}
+		remote := make(map[string]string)
+		for _, c := range m.ForeignTable.Columns {
+			remote[c.Field.SQLName()] = c.RemoteName
+		}
+
+		t := db.Schemas[""].Tables[m.Name]
+		var columns []migration.ForeignColumn
+		for name, c := range t.Columns {
+			columns = append(columns, migration.ForeignColumn{
+				Name:       name,
+				Type:       c.Type,
+				Default:    c.Default,
+				Nullable:   c.Nullable,
+				RemoteName: remote[name],
+			})
+		}
+		sort.Slice(columns, func(i, j int) bool {
+			return columns[i].Name < columns[j].Name
+		})
+
+		var options map[string]string
+		if len(m.ForeignTable.Options) != 0 {
+			options = m.ForeignTable.Options
+		}
+		res[objectKey{"", m.Name, ""}] = migration.CreateForeignTable{
+			TableName: m.Name,
+			Server:    m.ForeignTable.Server,
+			Options:   options,
+			Columns:   columns,
+		}
+	}
+	return res
+}
+
+// dropForeign returns the operations dropping the foreign tables of s1
+// which aren't in s2 or are changed.
+func dropForeign(s1, s2 foreignState) []operations.Operation {
+	var ops []operations.Operation
+	for _, k := range sortedObjectKeys(s1) {
+		if t2, ok := s2[k]; ok && reflect.DeepEqual(s1[k], t2) {
+			continue
+		}
+		ops = append(ops, migration.DropForeignTable{SchemaName: k.schema, TableName: k.table})
+	}
+	return ops
+}
+
+// createForeign returns the operations creating the foreign tables of s2
+// which aren't in s1, run after dropForeign.
+func createForeign(s1, s2 foreignState) []operations.Operation {
+	var ops []operations.Operation
+	for _, k := range sortedObjectKeys(s2) {
+		if _, ok := s1[k]; !ok {
+			ops = append(ops, s2[k])
+		}
+	}
+	return ops
+}
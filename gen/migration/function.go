@@ -0,0 +1,79 @@
+package migration
+
+import (
+	"reflect"
+
+	"github.com/sqlbunny/sqlbunny/runtime/migration"
+	"github.com/sqlbunny/sqlbunny/runtime/strmangle"
+	bunnyschema "github.com/sqlbunny/sqlbunny/schema"
+	"github.com/sqlbunny/sqlschema/operations"
+)
+
+// functionState is the functions, by function with an empty table. They're
+// not in the SQL schema, so they're tracked like the triggers.
+type functionState map[objectKey]migration.CreateFunction
+
+func (s functionState) apply(ops []operations.Operation) {
+	for _, op := range ops {
+		switch o := op.(type) {
+		case migration.CreateFunction:
+			s[objectKey{o.SchemaName, "", o.FunctionName}] = o
+		case migration.DropFunction:
+			delete(s, objectKey{o.SchemaName, "", o.FunctionName})
+		}
+	}
+}
+
+// schemaFunctions returns the functions of the schema.
+func schemaFunctions(s *bunnyschema.Schema) functionState {
+	res := functionState{}
+	for _, f := range s.Functions {
+		dependsOn := append([]string(nil), f.DependsOn...)
+		if f.ReturnsModel != nil && !strmangle.SetInclude(f.ReturnsModel.Name, dependsOn) {
+			dependsOn = append(dependsOn, f.ReturnsModel.Name)
+		}
+		res[objectKey{"", "", f.Name}] = migration.CreateFunction{
+			FunctionName: f.Name,
+			Procedure:    f.Procedure,
+			Args:         f.SQLArgs(),
+			Returns:      f.SQLReturns(),
+			Language:     f.Language,
+			Volatility:   f.Volatility,
+			Body:         f.Body,
+			DependsOn:    dependsOn,
+		}
+	}
+	return res
+}
+
+// dropFunctions returns the operations dropping the functions of s1 which
+// aren't in s2 or are changed, to run before the tables they use are
+// dropped.
+func dropFunctions(s1, s2 functionState) []operations.Operation {
+	var ops []operations.Operation
+	for _, k := range sortedObjectKeys(s1) {
+		f1 := s1[k]
+		if f2, ok := s2[k]; ok && reflect.DeepEqual(f1, f2) {
+			continue
+		}
+		ops = append(ops, migration.DropFunction{
+			SchemaName:   k.schema,
+			FunctionName: k.name,
+			Procedure:    f1.Procedure,
+			Args:         f1.Args,
+		})
+	}
+	return ops
+}
+
+// createFunctions returns the operations creating the functions of s2 which
+// aren't in s1, with the changed ones already dropped by dropFunctions.
+func createFunctions(s1, s2 functionState) []operations.Operation {
+	var ops []operations.Operation
+	for _, k := range sortedObjectKeys(s2) {
+		if _, ok := s1[k]; !ok {
+			ops = append(ops, s2[k])
+		}
+	}
+	return ops
+}
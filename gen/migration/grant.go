@@ -0,0 +1,83 @@
+package migration
+
+import (
+	"sort"
+
+	"github.com/sqlbunny/sqlbunny/runtime/migration"
+	"github.com/sqlbunny/sqlbunny/runtime/strmangle"
+	bunnyschema "github.com/sqlbunny/sqlbunny/schema"
+	"github.com/sqlbunny/sqlschema/operations"
+)
+
+// grantState is the privileges of the roles on the tables, by table with
+// the role as name, tracked like the triggers.
+type grantState map[objectKey][]string
+
+func (s grantState) apply(ops []operations.Operation) {
+	for _, op := range ops {
+		switch o := op.(type) {
+		case migration.GrantPrivileges:
+			k := objectKey{o.SchemaName, o.TableName, o.Role}
+			s[k] = strmangle.SetMerge(s[k], o.Privileges)
+			sort.Strings(s[k])
+		case migration.RevokePrivileges:
+			k := objectKey{o.SchemaName, o.TableName, o.Role}
+			s[k] = strmangle.SetComplement(s[k], o.Privileges)
+			if len(s[k]) == 0 {
+				delete(s, k)
+			}
+		case operations.DropTable:
+			s.dropTable(o.SchemaName, o.TableName)
+		case migration.DropForeignTable:
+			s.dropTable(o.SchemaName, o.TableName)
+		case migration.DropView:
+			s.dropTable(o.SchemaName, o.ViewName)
+		case operations.RenameTable:
+			for k, p := range s {
+				if k.schema == o.SchemaName && k.table == o.TableName {
+					delete(s, k)
+					s[objectKey{k.schema, o.NewTableName, k.name}] = p
+				}
+			}
+		}
+	}
+}
+
+func (s grantState) dropTable(schema, table string) {
+	for k := range s {
+		if k.schema == schema && k.table == table {
+			delete(s, k)
+		}
+	}
+}
+
+// schemaGrants returns the privileges of the roles on the tables of the
+// schema.
+func schemaGrants(s *bunnyschema.Schema) grantState {
+	res := grantState{}
+	for _, m := range s.Models {
+		for _, g := range m.Grants {
+			privileges := append([]string(nil), g.Privileges...)
+			sort.Strings(privileges)
+			res[objectKey{"", m.Name, g.Role}] = privileges
+		}
+	}
+	return res
+}
+
+// diffGrants returns the operations revoking the privileges of s1 which
+// aren't in s2, and granting the privileges of s2 which aren't in s1.
+func diffGrants(s1, s2 grantState) []operations.Operation {
+	var ops []operations.Operation
+	for _, k := range sortedObjectKeys(s1) {
+		if revoke := strmangle.SetComplement(s1[k], s2[k]); len(revoke) != 0 {
+			ops = append(ops, migration.RevokePrivileges{SchemaName: k.schema, TableName: k.table, Role: k.name, Privileges: revoke})
+		}
+	}
+	for _, k := range sortedObjectKeys(s2) {
+		if grant := strmangle.SetComplement(s2[k], s1[k]); len(grant) != 0 {
+			ops = append(ops, migration.GrantPrivileges{SchemaName: k.schema, TableName: k.table, Role: k.name, Privileges: grant})
+		}
+	}
+	return ops
+}
@@ -0,0 +1,419 @@
+package migration
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"fmt"
+	"go/format"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/lib/pq"
+	"github.com/spf13/cobra"
+	"github.com/sqlbunny/sqlbunny/gen"
+)
+
+// stdTypes are the stdtypes types of the SQL types.
+var stdTypes = map[string]string{
+	"smallint":                 "int16",
+	"integer":                  "int32",
+	"bigint":                   "int64",
+	"real":                     "float32",
+	"double precision":         "float64",
+	"boolean":                  "bool",
+	"text":                     "string",
+	"bytea":                    "bytea",
+	"jsonb":                    "jsonb",
+	"tsvector":                 "tsvector",
+	"uuid":                     "uuid",
+	"timestamp with time zone": "time",
+}
+
+// stdArrayTypes are the Go types of the arrays of the SQL types, from
+// lib/pq.
+var stdArrayTypes = map[string]string{
+	"integer":          "github.com/lib/pq.Int32Array",
+	"bigint":           "github.com/lib/pq.Int64Array",
+	"real":             "github.com/lib/pq.Float32Array",
+	"double precision": "github.com/lib/pq.Float64Array",
+	"boolean":          "github.com/lib/pq.BoolArray",
+	"text":             "github.com/lib/pq.StringArray",
+	"bytea":            "github.com/lib/pq.ByteaArray",
+}
+
+type introspectedColumn struct {
+	name    string
+	sqlType string
+	notNull bool
+}
+
+type introspectedKey struct {
+	name           string
+	kind           string // "p", "u", "f", "i" for indexes or "ui" for unique indexes
+	columns        []string
+	foreignTable   string
+	foreignColumns []string
+}
+
+type introspectedCheck struct {
+	name string
+	def  string
+}
+
+type introspectedTable struct {
+	name    string
+	columns []*introspectedColumn
+	keys    []*introspectedKey
+	checks  []*introspectedCheck
+}
+
+const introspectColumnsQuery = `
+SELECT c.relname, a.attname, format_type(a.atttypid, a.atttypmod), a.attnotnull
+FROM pg_attribute a
+JOIN pg_class c ON c.oid = a.attrelid
+JOIN pg_namespace n ON n.oid = c.relnamespace
+WHERE n.nspname = $1 AND c.relkind = 'r' AND a.attnum > 0 AND NOT a.attisdropped
+ORDER BY c.relname, a.attnum`
+
+const introspectKeysQuery = `
+SELECT c.relname, con.conname, con.contype,
+	ARRAY(SELECT a.attname FROM unnest(con.conkey) WITH ORDINALITY k(attnum, i)
+		JOIN pg_attribute a ON a.attrelid = con.conrelid AND a.attnum = k.attnum ORDER BY k.i),
+	coalesce(fc.relname, ''),
+	ARRAY(SELECT a.attname FROM unnest(con.confkey) WITH ORDINALITY k(attnum, i)
+		JOIN pg_attribute a ON a.attrelid = con.confrelid AND a.attnum = k.attnum ORDER BY k.i)
+FROM pg_constraint con
+JOIN pg_class c ON c.oid = con.conrelid
+JOIN pg_namespace n ON n.oid = c.relnamespace
+LEFT JOIN pg_class fc ON fc.oid = con.confrelid
+WHERE n.nspname = $1 AND con.contype IN ('p', 'u', 'f')
+UNION ALL
+SELECT c.relname, ic.relname, CASE WHEN i.indisunique THEN 'ui' ELSE 'i' END,
+	ARRAY(SELECT a.attname FROM unnest(i.indkey::int2[]) WITH ORDINALITY k(attnum, n)
+		JOIN pg_attribute a ON a.attrelid = i.indrelid AND a.attnum = k.attnum ORDER BY k.n),
+	'', '{}'::name[]
+FROM pg_index i
+JOIN pg_class c ON c.oid = i.indrelid
+JOIN pg_class ic ON ic.oid = i.indexrelid
+JOIN pg_namespace n ON n.oid = c.relnamespace
+WHERE n.nspname = $1 AND c.relkind = 'r' AND NOT i.indisprimary
+	AND i.indexprs IS NULL AND i.indpred IS NULL
+	AND NOT EXISTS (SELECT 1 FROM pg_constraint con WHERE con.conindid = i.indexrelid)
+ORDER BY 1, 2`
+
+const introspectChecksQuery = `
+SELECT c.relname, con.conname, pg_get_constraintdef(con.oid)
+FROM pg_constraint con
+JOIN pg_class c ON c.oid = con.conrelid
+JOIN pg_namespace n ON n.oid = c.relnamespace
+WHERE n.nspname = $1 AND con.contype = 'c'
+ORDER BY 1, 2`
+
+// introspect reads the tables of the Postgres schema, with their columns,
+// keys, plain indexes and CHECK constraints. Expression and partial indexes
+// are skipped.
+func introspect(ctx context.Context, db *sql.DB, schemaName string) ([]*introspectedTable, error) {
+	tables := map[string]*introspectedTable{}
+	var names []string
+	table := func(name string) *introspectedTable {
+		t, ok := tables[name]
+		if !ok {
+			t = &introspectedTable{name: name}
+			tables[name] = t
+			names = append(names, name)
+		}
+		return t
+	}
+
+	rows, err := db.QueryContext(ctx, introspectColumnsQuery, schemaName)
+	if err != nil {
+		return nil, fmt.Errorf("introspect columns: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var tableName string
+		c := &introspectedColumn{}
+		if err := rows.Scan(&tableName, &c.name, &c.sqlType, &c.notNull); err != nil {
+			return nil, fmt.Errorf("introspect columns: %w", err)
+		}
+		t := table(tableName)
+		t.columns = append(t.columns, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("introspect columns: %w", err)
+	}
+
+	rows, err = db.QueryContext(ctx, introspectKeysQuery, schemaName)
+	if err != nil {
+		return nil, fmt.Errorf("introspect keys: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var tableName string
+		k := &introspectedKey{}
+		if err := rows.Scan(&tableName, &k.name, &k.kind, pq.Array(&k.columns), &k.foreignTable, pq.Array(&k.foreignColumns)); err != nil {
+			return nil, fmt.Errorf("introspect keys: %w", err)
+		}
+		if t, ok := tables[tableName]; ok {
+			t.keys = append(t.keys, k)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("introspect keys: %w", err)
+	}
+
+	rows, err = db.QueryContext(ctx, introspectChecksQuery, schemaName)
+	if err != nil {
+		return nil, fmt.Errorf("introspect checks: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var tableName string
+		c := &introspectedCheck{}
+		if err := rows.Scan(&tableName, &c.name, &c.def); err != nil {
+			return nil, fmt.Errorf("introspect checks: %w", err)
+		}
+		if t, ok := tables[tableName]; ok {
+			t.checks = append(t.checks, c)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("introspect checks: %w", err)
+	}
+
+	sort.Strings(names)
+	res := make([]*introspectedTable, len(names))
+	for i, name := range names {
+		res[i] = tables[name]
+	}
+	return res, nil
+}
+
+// typeName returns the name of the type of the SQL type: its stdtypes
+// type, or a name like "character_varying_255" for "character varying(255)",
+// and "string_array" for "text[]".
+func typeName(sqlType string) string {
+	if std, ok := stdTypes[sqlType]; ok {
+		return std
+	}
+	if elem := strings.TrimSuffix(sqlType, "[]"); elem != sqlType {
+		return typeName(elem) + "_array"
+	}
+	if sqlType == "timestamp without time zone" {
+		return "timestamp"
+	}
+	return strings.Trim(strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= '0' && r <= '9' {
+			return r
+		}
+		return '_'
+	}, strings.ToLower(sqlType)), "_")
+}
+
+// isPrimaryKey reports whether the columns are the primary key of the
+// table with name.
+func isPrimaryKey(tables []*introspectedTable, name string, columns []string) bool {
+	for _, t := range tables {
+		if t.name != name {
+			continue
+		}
+		for _, k := range t.keys {
+			if k.kind == "p" {
+				return strings.Join(k.columns, ",") == strings.Join(columns, ",")
+			}
+		}
+	}
+	return false
+}
+
+// writeDSL writes the Go source of the definitions of the tables, as a
+// function returning the config items of the types and models.
+func writeDSL(tables []*introspectedTable, packageName string) ([]byte, error) {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "package %s\n\n", packageName)
+	buf.WriteString("import (\n\t\"github.com/sqlbunny/sqlbunny/gen\"\n\t. \"github.com/sqlbunny/sqlbunny/gen/core\"\n)\n\n")
+	buf.WriteString("// Schema returns the types and models of the introspected tables. Pass\n")
+	buf.WriteString("// them to Run with the stdtypes plugin.\n")
+	buf.WriteString("func Schema() []gen.ConfigItem {\n\treturn []gen.ConfigItem{\n")
+
+	// Types for the SQL types without a stdtypes type.
+	defined := map[string]bool{}
+	for _, t := range tables {
+		for _, c := range t.columns {
+			if _, ok := stdTypes[c.sqlType]; ok {
+				continue
+			}
+			name := typeName(c.sqlType)
+			if defined[name] {
+				continue
+			}
+			defined[name] = true
+			if c.sqlType == "timestamp without time zone" {
+				fmt.Fprintf(&buf, "Type(%q, Timestamp{}),\n", name)
+				continue
+			}
+			if elem := strings.TrimSuffix(c.sqlType, "[]"); elem != c.sqlType {
+				goType, ok := stdArrayTypes[elem]
+				if !ok {
+					fmt.Fprintf(&buf, "// TODO: arrays of %s have no Go type, set one scanning them.\n", elem)
+					goType = "string"
+				}
+				fmt.Fprintf(&buf, "Type(%q, BaseType{\nGo: %q,\nPostgres: SQLType{Type: %q, ZeroValue: \"'{}'\"},\n}),\n", name, goType, c.sqlType)
+				continue
+			}
+			buf.WriteString("// TODO: check the Go type and the zero value.\n")
+			fmt.Fprintf(&buf, "Type(%q, BaseType{\nGo: \"string\",\nPostgres: SQLType{Type: %q, ZeroValue: \"''\"},\n}),\n", name, c.sqlType)
+		}
+	}
+
+	for _, t := range tables {
+		// Single column keys are field flags.
+		flags := map[string][]string{}
+		var items []string
+		for _, k := range t.keys {
+			single := len(k.columns) == 1
+			switch k.kind {
+			case "p":
+				if single {
+					flags[k.columns[0]] = append(flags[k.columns[0]], "PrimaryKey")
+				} else {
+					items = append(items, "PrimaryKey("+quoteAll(k.columns)+")")
+				}
+			case "u":
+				if single {
+					flags[k.columns[0]] = append(flags[k.columns[0]], "Unique")
+				} else {
+					items = append(items, "Unique("+quoteAll(k.columns)+")")
+				}
+			case "ui":
+				if single {
+					flags[k.columns[0]] = append(flags[k.columns[0]], "UniqueIndex")
+				} else {
+					items = append(items, "UniqueIndex("+quoteAll(k.columns)+")")
+				}
+			case "i":
+				if single {
+					flags[k.columns[0]] = append(flags[k.columns[0]], "Index")
+				} else {
+					items = append(items, "Index("+quoteAll(k.columns)+")")
+				}
+			case "f":
+				// Foreign keys reference the primary key unless told otherwise.
+				references := ""
+				if !isPrimaryKey(tables, k.foreignTable, k.foreignColumns) {
+					references = ".References(" + quoteAll(k.foreignColumns) + ")"
+				}
+				if single {
+					flags[k.columns[0]] = append(flags[k.columns[0]], "ForeignKey("+strconv.Quote(k.foreignTable)+")"+references)
+				} else {
+					items = append(items, "ModelForeignKey("+strconv.Quote(k.foreignTable)+", "+quoteAll(k.columns)+")"+references)
+				}
+			}
+		}
+
+		fmt.Fprintf(&buf, "Model(%q,\n", t.name)
+		for _, c := range t.columns {
+			fields := []string{strconv.Quote(c.name), strconv.Quote(typeName(c.sqlType))}
+			if !c.notNull {
+				fields = append(fields, "Null")
+			}
+			fields = append(fields, flags[c.name]...)
+			fmt.Fprintf(&buf, "Field(%s),\n", strings.Join(fields, ", "))
+		}
+		for _, i := range items {
+			buf.WriteString(i + ",\n")
+		}
+		buf.WriteString("),\n")
+
+		// The definitions have no CHECK constraints, they're kept as raw SQL.
+		for _, c := range t.checks {
+			up := fmt.Sprintf("ALTER TABLE %s ADD CONSTRAINT %s %s", pq.QuoteIdentifier(t.name), pq.QuoteIdentifier(c.name), c.def)
+			down := fmt.Sprintf("ALTER TABLE %s DROP CONSTRAINT %s", pq.QuoteIdentifier(t.name), pq.QuoteIdentifier(c.name))
+			fmt.Fprintf(&buf, "RawSQL(%q, %q, %q),\n", up, down, t.name)
+		}
+	}
+	buf.WriteString("}\n}\n")
+
+	return format.Source(buf.Bytes())
+}
+
+func quoteAll(names []string) string {
+	quoted := make([]string, len(names))
+	for i, n := range names {
+		quoted[i] = strconv.Quote(n)
+	}
+	return strings.Join(quoted, ", ")
+}
+
+func (p *Plugin) cmdIntrospect(cmd *cobra.Command, args []string) {
+	dsn, _ := cmd.Flags().GetString("dsn")
+	env, _ := cmd.Flags().GetString("env")
+	schemaName, _ := cmd.Flags().GetString("schema")
+	packageName, _ := cmd.Flags().GetString("package")
+	sqlboiler, _ := cmd.Flags().GetString("sqlboiler")
+	if sqlboiler != "" {
+		p.introspectSQLBoiler(sqlboiler, packageName)
+		return
+	}
+	if dsn == "" && env != "" {
+		var err error
+		if dsn, err = gen.Config.DSN(env); err != nil {
+			log.Fatal(err)
+		}
+	}
+	if dsn == "" {
+		log.Fatal("The --dsn or --env flag is required.")
+	}
+
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer db.Close()
+
+	tables, err := introspect(context.Background(), db, schemaName)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if len(tables) == 0 {
+		log.Fatalf("No tables found in schema '%s'.", schemaName)
+	}
+
+	src, err := writeDSL(tables, packageName)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Print(string(src))
+}
+
+// introspectSQLBoiler prints the definitions of the tables of the schema
+// printed by a sqlboiler driver in file, or the standard input if it's "-".
+func (p *Plugin) introspectSQLBoiler(file, packageName string) {
+	r := os.Stdin
+	if file != "-" {
+		f, err := os.Open(file)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer f.Close()
+		r = f
+	}
+
+	tables, err := readSQLBoiler(r)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if len(tables) == 0 {
+		log.Fatal("No tables found in the sqlboiler schema.")
+	}
+
+	src, err := writeDSL(tables, packageName)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Print(string(src))
+}
@@ -16,6 +16,7 @@ import (
 	"github.com/sqlbunny/sqlbunny/gen"
 	"github.com/sqlbunny/sqlbunny/runtime/migration"
 	"github.com/sqlbunny/sqlschema/diff"
+	"github.com/sqlbunny/sqlschema/operations"
 	"github.com/sqlbunny/sqlschema/schema"
 )
 
@@ -54,10 +55,24 @@ func (p *Plugin) BunnyPlugin() {
 		Use: "merge",
 		Run: p.cmdMerge,
 	})
-	cmd.AddCommand(&cobra.Command{
+	genSQLCmd := &cobra.Command{
 		Use: "gensql",
 		Run: p.cmdGenSQL,
-	})
+	}
+	genSQLCmd.Flags().String("dialect", "postgres", "SQL dialect to generate: postgres, cockroachdb or mysql")
+	cmd.AddCommand(genSQLCmd)
+
+	introspectCmd := &cobra.Command{
+		Use:   "introspect",
+		Short: "Print the definitions of the tables of an existing Postgres database",
+		Run:   p.cmdIntrospect,
+	}
+	introspectCmd.Flags().String("dsn", "", "Postgres connection string of the database")
+	introspectCmd.Flags().String("env", "", "Environment of the database in the project config file, instead of --dsn")
+	introspectCmd.Flags().String("schema", "public", "Postgres schema of the tables")
+	introspectCmd.Flags().String("package", "main", "Package name of the printed source")
+	introspectCmd.Flags().String("sqlboiler", "", "JSON schema printed by a sqlboiler driver like sqlboiler-psql, or - for stdin, instead of a database")
+	gen.AddCommand(introspectCmd)
 }
 
 func (p *Plugin) cmdCheck(cmd *cobra.Command, args []string) {
@@ -66,9 +81,10 @@ func (p *Plugin) cmdCheck(cmd *cobra.Command, args []string) {
 	}
 
 	s1 := newDB()
-	p.applyAll(s1)
+	ex := newExtras()
+	p.applyAll(s1, ex)
 	s2 := gen.Config.Schema.SQLSchema()
-	ops := diff.Diff(s1, s2)
+	ops := diffAll(s1, s2, ex)
 
 	if len(ops) != 0 {
 		log.Fatal("Migrations are not up to date with the defined models. You need to run 'migration gen'.")
@@ -98,9 +114,10 @@ func (p *Plugin) cmdGen(cmd *cobra.Command, args []string) {
 	p.ensureStore()
 
 	s1 := newDB()
-	head := p.applyAll(s1)
+	ex := newExtras()
+	head := p.applyAll(s1, ex)
 	s2 := gen.Config.Schema.SQLSchema()
-	ops := diff.Diff(s1, s2)
+	ops := diffAll(s1, s2, ex)
 	if len(ops) == 0 {
 		log.Fatal("No model changes found, doing nothing.")
 	}
@@ -163,6 +180,22 @@ func (p *Plugin) ensureStore() {
 
 		gen.WriteFile(p.PackagePath, "store.go", buf.Bytes())
 
+		buf.Reset()
+		gen.WritePackageName(&buf, p.PackageName)
+		buf.WriteString("import (\n")
+		buf.WriteString("    \"testing\"\n")
+		buf.WriteString("\n")
+		buf.WriteString("    \"github.com/sqlbunny/sqlbunny/runtime/bunnytest\"\n")
+		buf.WriteString(")\n")
+		buf.WriteString("\n")
+		buf.WriteString("// TestMigrationsSQL checks the SQL of the migrations against the golden\n")
+		buf.WriteString("// files in testdata. Run it with SQLBUNNY_UPDATE_GOLDEN=1 to update them.\n")
+		buf.WriteString("func TestMigrationsSQL(t *testing.T) {\n")
+		buf.WriteString("    bunnytest.CheckMigrations(t, &Store, \"testdata\")\n")
+		buf.WriteString("}\n")
+
+		gen.WriteFile(p.PackagePath, "store_test.go", buf.Bytes())
+
 		if p.Store == nil {
 			log.Println("Initial migrations package created.")
 			log.Println("To generate migrations, you need to add a reference to the")
@@ -207,18 +240,65 @@ func (p *Plugin) writeMigration(m *migration.Migration) {
 func (p *Plugin) cmdGenSQL(cmd *cobra.Command, args []string) {
 	s1 := newDB()
 	s2 := gen.Config.Schema.SQLSchema()
-	ops := diff.Diff(s1, s2)
+	ops := diffAll(s1, s2, newExtras())
 	if len(ops) == 0 {
 		log.Fatal("No models found, doing nothing.")
 	}
 
-	for _, op := range ops {
-		q := op.GetSQL()
+	name, _ := cmd.Flags().GetString("dialect")
+	dialect, err := migration.DialectByName(name)
+	if err != nil {
+		log.Fatal(err)
+	}
+	stmts, err := dialect.Statements(newDB(), ops)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	for _, q := range stmts {
 		fmt.Println(q + ";\n")
 	}
 }
 
-func (p *Plugin) applyAll(db *schema.Database) string {
+// diffAll returns the operations changing the database db and its extras
+// ex to the generated schema s2.
+func diffAll(db, s2 *schema.Database, ex *extras) []operations.Operation {
+	want := schemaExtras(gen.Config.Schema, s2)
+
+	// The foreign tables and views aren't in the SQL schema.
+	for k := range want.foreign {
+		delete(s2.Schemas[k.schema].Tables, k.table)
+	}
+	for k := range want.views {
+		delete(s2.Schemas[k.schema].Tables, k.table)
+	}
+	ops2 := renameColumns(db, gen.Config.Schema)
+	ops2 = append(ops2, diff.Diff(db, s2)...)
+
+	// Raw SQL, triggers, policies, views, functions and foreign tables are
+	// dropped before the tables, and created after them with the grants.
+	// The views are recreated when the tables they depend on change.
+	dropped := dropForeign(ex.foreign, want.foreign)
+	ops := dropRaw(ex.raw, want.raw)
+	ops = append(ops, dropTriggers(ex.triggers, want.triggers)...)
+	ops = append(ops, dropPolicies(ex.policies, want.policies)...)
+	ops = append(ops, dropViews(ex.views, want.views, changedTables(append(ops2, dropped...)))...)
+	ops = append(ops, dropFunctions(ex.funcs, want.funcs)...)
+	ops = append(ops, dropped...)
+	ex.apply(ops)
+	ex.apply(ops2)
+	ops = append(ops, ops2...)
+	ops = append(ops, createForeign(ex.foreign, want.foreign)...)
+	ops = append(ops, createFunctions(ex.funcs, want.funcs)...)
+	ops = append(ops, createViews(ex.views, want.views)...)
+	ops = append(ops, diffStorage(ex.storage, want.storage)...)
+	ops = append(ops, createTriggers(ex.triggers, want.triggers)...)
+	ops = append(ops, createPolicies(ex.policies, want.policies)...)
+	ops = append(ops, diffGrants(ex.grants, want.grants)...)
+	return append(ops, createRaw(ex.raw, want.raw)...)
+}
+
+func (p *Plugin) applyAll(db *schema.Database, ex *extras) string {
 	s := p.Store
 
 	if len(s.Migrations) == 0 {
@@ -232,6 +312,7 @@ func (p *Plugin) applyAll(db *schema.Database) string {
 	head := heads[0]
 
 	err := s.RunMigration(head, nil, func(m *migration.Migration) error {
+		ex.apply(m.Operations)
 		return ApplyMigration(m, db)
 	})
 	if err != nil {
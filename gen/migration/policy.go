@@ -0,0 +1,134 @@
+package migration
+
+import (
+	"reflect"
+
+	"github.com/sqlbunny/sqlbunny/runtime/migration"
+	bunnyschema "github.com/sqlbunny/sqlbunny/schema"
+	"github.com/sqlbunny/sqlschema/operations"
+)
+
+// policyState is the row-level security of the tables, by table with an
+// empty name, and their policies, tracked like the triggers.
+type policyState struct {
+	rls      map[objectKey]migration.SetRowLevelSecurity
+	policies map[objectKey]migration.CreatePolicy
+}
+
+func newPolicyState() *policyState {
+	return &policyState{
+		rls:      map[objectKey]migration.SetRowLevelSecurity{},
+		policies: map[objectKey]migration.CreatePolicy{},
+	}
+}
+
+func (s *policyState) apply(ops []operations.Operation) {
+	for _, op := range ops {
+		switch o := op.(type) {
+		case migration.SetRowLevelSecurity:
+			k := objectKey{o.SchemaName, o.TableName, ""}
+			if o.Enable || o.Force {
+				s.rls[k] = o
+			} else {
+				delete(s.rls, k)
+			}
+		case migration.CreatePolicy:
+			s.policies[objectKey{o.SchemaName, o.TableName, o.PolicyName}] = o
+		case migration.DropPolicy:
+			delete(s.policies, objectKey{o.SchemaName, o.TableName, o.PolicyName})
+		case operations.DropTable:
+			for k := range s.rls {
+				if k.schema == o.SchemaName && k.table == o.TableName {
+					delete(s.rls, k)
+				}
+			}
+			for k := range s.policies {
+				if k.schema == o.SchemaName && k.table == o.TableName {
+					delete(s.policies, k)
+				}
+			}
+		case operations.RenameTable:
+			for k, r := range s.rls {
+				if k.schema == o.SchemaName && k.table == o.TableName {
+					delete(s.rls, k)
+					r.TableName = o.NewTableName
+					s.rls[objectKey{k.schema, o.NewTableName, k.name}] = r
+				}
+			}
+			for k, p := range s.policies {
+				if k.schema == o.SchemaName && k.table == o.TableName {
+					delete(s.policies, k)
+					p.TableName = o.NewTableName
+					s.policies[objectKey{k.schema, o.NewTableName, k.name}] = p
+				}
+			}
+		}
+	}
+}
+
+// schemaPolicies returns the row-level security and policies of the tables
+// of the schema.
+func schemaPolicies(s *bunnyschema.Schema) *policyState {
+	res := newPolicyState()
+	for _, m := range s.Models {
+		if m.RowLevelSecurity {
+			res.rls[objectKey{"", m.Name, ""}] = migration.SetRowLevelSecurity{
+				TableName: m.Name,
+				Enable:    true,
+				Force:     m.ForceRowLevelSecurity,
+			}
+		}
+		for _, p := range m.Policies {
+			res.policies[objectKey{"", m.Name, p.Name}] = migration.CreatePolicy{
+				TableName:   m.Name,
+				PolicyName:  p.Name,
+				Restrictive: p.Restrictive,
+				Command:     p.Command,
+				Roles:       p.Roles,
+				Using:       p.Using,
+				WithCheck:   p.WithCheck,
+			}
+		}
+	}
+	return res
+}
+
+// dropPolicies returns the operations dropping the policies of s1 which
+// aren't in s2 or are changed.
+func dropPolicies(s1, s2 *policyState) []operations.Operation {
+	var ops []operations.Operation
+	for _, k := range sortedObjectKeys(s1.policies) {
+		if p2, ok := s2.policies[k]; ok && reflect.DeepEqual(s1.policies[k], p2) {
+			continue
+		}
+		ops = append(ops, migration.DropPolicy{SchemaName: k.schema, TableName: k.table, PolicyName: k.name})
+	}
+	return ops
+}
+
+// createPolicies returns the operations changing the row-level security of
+// the tables from s1 to s2, and creating the policies of s2 which aren't in
+// s1, with the changed ones already dropped by dropPolicies.
+func createPolicies(s1, s2 *policyState) []operations.Operation {
+	var ops []operations.Operation
+	keys := sortedObjectKeys(s2.rls)
+	for _, k := range sortedObjectKeys(s1.rls) {
+		if _, ok := s2.rls[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	for _, k := range keys {
+		r1, r2 := s1.rls[k], s2.rls[k]
+		if r1.Enable == r2.Enable && r1.Force == r2.Force {
+			continue
+		}
+		r2.SchemaName, r2.TableName = k.schema, k.table
+		ops = append(ops, r2)
+	}
+	for _, k := range sortedObjectKeys(s2.policies) {
+		if _, ok := s1.policies[k]; !ok {
+			ops = append(ops, s2.policies[k])
+		}
+	}
+	return ops
+}
@@ -0,0 +1,111 @@
+package migration
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/sqlbunny/sqlbunny/runtime/migration"
+	bunnyschema "github.com/sqlbunny/sqlbunny/schema"
+	"github.com/sqlbunny/sqlschema/operations"
+)
+
+// rawState is the raw SQL run by the migrations and not undone, in the
+// order it was run.
+type rawState []migration.CreateRawSQL
+
+func (s *rawState) apply(ops []operations.Operation) {
+	for _, op := range ops {
+		switch o := op.(type) {
+		case migration.CreateRawSQL:
+			*s = append(*s, o)
+		case migration.DropRawSQL:
+			for i, r := range *s {
+				if r.Up == o.Up {
+					*s = append((*s)[:i], (*s)[i+1:]...)
+					break
+				}
+			}
+		}
+	}
+}
+
+func (s rawState) find(up string) *migration.CreateRawSQL {
+	for i := range s {
+		if s[i].Up == up {
+			return &s[i]
+		}
+	}
+	return nil
+}
+
+// schemaRaw returns the raw SQL of the schema, followed by the CHECK
+// constraints of the columns of text enums.
+func schemaRaw(s *bunnyschema.Schema) rawState {
+	var res rawState
+	for _, r := range s.RawSQL {
+		res = append(res, migration.CreateRawSQL{
+			Up:        r.Up,
+			Down:      r.Down,
+			DependsOn: r.DependsOn,
+		})
+	}
+	return append(res, schemaChecks(s)...)
+}
+
+// schemaChecks returns the raw SQL adding the CHECK constraints of the
+// columns of the tables. The constraint expression is part of the up SQL,
+// so changing the choices of an enum replaces its constraints.
+func schemaChecks(s *bunnyschema.Schema) rawState {
+	names := make([]string, 0, len(s.Models))
+	for name := range s.Models {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var res rawState
+	for _, name := range names {
+		m := s.Models[name]
+		if !m.HasTable() {
+			continue
+		}
+		for _, c := range m.Columns() {
+			if c.Check == "" {
+				continue
+			}
+			constraint := m.CheckName(c.Name)
+			res = append(res, migration.CreateRawSQL{
+				Up:        fmt.Sprintf("ALTER TABLE \"%s\" ADD CONSTRAINT \"%s\" CHECK (%s)", m.Name, constraint, c.Check),
+				Down:      fmt.Sprintf("ALTER TABLE \"%s\" DROP CONSTRAINT \"%s\"", m.Name, constraint),
+				DependsOn: []string{m.Name},
+			})
+		}
+	}
+	return res
+}
+
+// dropRaw returns the operations undoing the raw SQL of s1 which isn't in
+// s2 or is changed, in reverse order.
+func dropRaw(s1, s2 rawState) []operations.Operation {
+	var ops []operations.Operation
+	for i := len(s1) - 1; i >= 0; i-- {
+		r := s1[i]
+		if r2 := s2.find(r.Up); r2 != nil && reflect.DeepEqual(r, *r2) {
+			continue
+		}
+		ops = append(ops, migration.DropRawSQL{Up: r.Up, Down: r.Down})
+	}
+	return ops
+}
+
+// createRaw returns the operations running the raw SQL of s2 which isn't in
+// s1, with the changed ones already undone by dropRaw.
+func createRaw(s1, s2 rawState) []operations.Operation {
+	var ops []operations.Operation
+	for _, r := range s2 {
+		if s1.find(r.Up) == nil {
+			ops = append(ops, r)
+		}
+	}
+	return ops
+}
@@ -0,0 +1,52 @@
+package migration
+
+import (
+	"sort"
+
+	bunnyschema "github.com/sqlbunny/sqlbunny/schema"
+	"github.com/sqlbunny/sqlschema/operations"
+	"github.com/sqlbunny/sqlschema/schema"
+)
+
+// renameColumns returns the operations renaming the columns of the tables
+// of db to the ones of the renamed fields of s, see Field.RenamedFrom, and
+// applies them to db, so they aren't dropped and added by the diff. Columns
+// which are already renamed are skipped, so the renames can be removed once
+// a migration has them.
+func renameColumns(db *schema.Database, s *bunnyschema.Schema) []operations.Operation {
+	names := make([]string, 0, len(s.Models))
+	for name := range s.Models {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var res []operations.Operation
+	for _, name := range names {
+		m := s.Models[name]
+		t, ok := db.Schemas[""].Tables[m.Name]
+		if !ok || !m.HasTable() {
+			continue
+		}
+		for _, c := range m.Columns() {
+			if c.RenamedFrom == "" {
+				continue
+			}
+			if _, ok := t.Columns[c.RenamedFrom]; !ok {
+				continue
+			}
+			if _, ok := t.Columns[c.Name]; ok {
+				continue
+			}
+			op := operations.RenameColumn{
+				TableName:     m.Name,
+				OldColumnName: c.RenamedFrom,
+				NewColumnName: c.Name,
+			}
+			if err := op.Apply(db); err != nil {
+				panic(err)
+			}
+			res = append(res, op)
+		}
+	}
+	return res
+}
@@ -0,0 +1,95 @@
+package migration
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+)
+
+// sqlboilerDBInfo is the schema printed by the sqlboiler drivers, like
+// sqlboiler-psql, which sqlboiler generates its models from.
+type sqlboilerDBInfo struct {
+	Tables []sqlboilerTable `json:"tables"`
+}
+
+type sqlboilerTable struct {
+	Name    string `json:"name"`
+	IsView  bool   `json:"is_view"`
+	Columns []struct {
+		Name       string `json:"name"`
+		DBType     string `json:"db_type"`
+		FullDBType string `json:"full_db_type"`
+		Nullable   bool   `json:"nullable"`
+		Unique     bool   `json:"unique"`
+	} `json:"columns"`
+	PKey *struct {
+		Name    string   `json:"name"`
+		Columns []string `json:"columns"`
+	} `json:"p_key"`
+	FKeys []struct {
+		Name          string `json:"name"`
+		Column        string `json:"column"`
+		ForeignTable  string `json:"foreign_table"`
+		ForeignColumn string `json:"foreign_column"`
+	} `json:"f_keys"`
+}
+
+// readSQLBoiler reads the tables of the schema printed by a sqlboiler driver,
+// so projects coming from sqlboiler can translate the schema its models are
+// generated from. Views are skipped. The drivers only tell the single column
+// uniques, and no indexes.
+func readSQLBoiler(r io.Reader) ([]*introspectedTable, error) {
+	var info sqlboilerDBInfo
+	if err := json.NewDecoder(r).Decode(&info); err != nil {
+		return nil, fmt.Errorf("read sqlboiler schema: %w", err)
+	}
+
+	var res []*introspectedTable
+	for _, st := range info.Tables {
+		if st.IsView {
+			continue
+		}
+		t := &introspectedTable{name: st.Name}
+
+		pkey := map[string]bool{}
+		if st.PKey != nil {
+			t.keys = append(t.keys, &introspectedKey{name: st.PKey.Name, kind: "p", columns: st.PKey.Columns})
+			if len(st.PKey.Columns) == 1 {
+				pkey[st.PKey.Columns[0]] = true
+			}
+		}
+
+		for _, sc := range st.Columns {
+			sqlType := sc.FullDBType
+			if sqlType == "" {
+				sqlType = sc.DBType
+			}
+			t.columns = append(t.columns, &introspectedColumn{name: sc.Name, sqlType: sqlType, notNull: !sc.Nullable})
+			if sc.Unique && !pkey[sc.Name] {
+				t.keys = append(t.keys, &introspectedKey{name: st.Name + "_" + sc.Name + "_key", kind: "u", columns: []string{sc.Name}})
+			}
+		}
+
+		// The columns of composite foreign keys are listed one by one,
+		// under the name of their constraint.
+		fkeys := map[string]*introspectedKey{}
+		for _, sf := range st.FKeys {
+			k, ok := fkeys[sf.Name]
+			if !ok {
+				k = &introspectedKey{name: sf.Name, kind: "f", foreignTable: sf.ForeignTable}
+				fkeys[sf.Name] = k
+				t.keys = append(t.keys, k)
+			}
+			k.columns = append(k.columns, sf.Column)
+			k.foreignColumns = append(k.foreignColumns, sf.ForeignColumn)
+		}
+
+		res = append(res, t)
+	}
+
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].name < res[j].name
+	})
+	return res, nil
+}
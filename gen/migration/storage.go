@@ -0,0 +1,151 @@
+package migration
+
+import (
+	"sort"
+
+	"github.com/sqlbunny/sqlbunny/runtime/migration"
+	bunnyschema "github.com/sqlbunny/sqlbunny/schema"
+	"github.com/sqlbunny/sqlschema/operations"
+)
+
+type storageKey struct {
+	schema string
+	table  string
+	index  string
+}
+
+type storage struct {
+	params     map[string]string
+	tablespace string
+}
+
+// storageState is the storage of the tables and indexes. The SQL schema
+// has no storage, so it's tracked from the operations of the migrations.
+type storageState map[storageKey]*storage
+
+func (s storageState) apply(ops []operations.Operation) {
+	for _, op := range ops {
+		switch o := op.(type) {
+		case migration.SetStorage:
+			k := storageKey{o.SchemaName, o.TableName, o.IndexName}
+			st, ok := s[k]
+			if !ok {
+				st = &storage{params: map[string]string{}}
+				s[k] = st
+			}
+			for p, v := range o.Params {
+				st.params[p] = v
+			}
+			for _, p := range o.Reset {
+				delete(st.params, p)
+			}
+			switch o.Tablespace {
+			case "":
+			case migration.DefaultTablespace:
+				st.tablespace = ""
+			default:
+				st.tablespace = o.Tablespace
+			}
+		case operations.DropTable:
+			for k := range s {
+				if k.schema == o.SchemaName && k.table == o.TableName {
+					delete(s, k)
+				}
+			}
+		case operations.DropIndex:
+			delete(s, storageKey{o.SchemaName, o.TableName, o.IndexName})
+		case operations.RenameTable:
+			for k, st := range s {
+				if k.schema == o.SchemaName && k.table == o.TableName {
+					delete(s, k)
+					s[storageKey{k.schema, o.NewTableName, k.index}] = st
+				}
+			}
+		}
+	}
+}
+
+// schemaStorage returns the storage of the tables and indexes of the schema.
+func schemaStorage(s *bunnyschema.Schema) storageState {
+	res := storageState{}
+	add := func(k storageKey, st *bunnyschema.Storage) {
+		params := map[string]string{}
+		for p, v := range st.Params {
+			params[p] = v
+		}
+		res[k] = &storage{params: params, tablespace: st.Tablespace}
+	}
+	for _, m := range s.Models {
+		if m.Storage != nil {
+			add(storageKey{"", m.Name, ""}, m.Storage)
+		}
+		for _, i := range m.Indexes {
+			if i.Storage != nil {
+				add(storageKey{"", m.Name, m.IndexName(i)}, i.Storage)
+			}
+		}
+	}
+	return res
+}
+
+// diffStorage returns the operations changing the storage from s1 to s2,
+// sorted by table and index.
+func diffStorage(s1, s2 storageState) []operations.Operation {
+	var keys []storageKey
+	for k := range s1 {
+		keys = append(keys, k)
+	}
+	for k := range s2 {
+		if _, ok := s1[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := keys[i], keys[j]
+		if a.schema != b.schema {
+			return a.schema < b.schema
+		}
+		if a.table != b.table {
+			return a.table < b.table
+		}
+		return a.index < b.index
+	})
+
+	var ops []operations.Operation
+	for _, k := range keys {
+		st1, st2 := s1[k], s2[k]
+		if st1 == nil {
+			st1 = &storage{}
+		}
+		if st2 == nil {
+			st2 = &storage{}
+		}
+
+		op := migration.SetStorage{SchemaName: k.schema, TableName: k.table, IndexName: k.index}
+		for p, v := range st2.params {
+			if v1, ok := st1.params[p]; !ok || v1 != v {
+				if op.Params == nil {
+					op.Params = map[string]string{}
+				}
+				op.Params[p] = v
+			}
+		}
+		for p := range st1.params {
+			if _, ok := st2.params[p]; !ok {
+				op.Reset = append(op.Reset, p)
+			}
+		}
+		sort.Strings(op.Reset)
+		if st1.tablespace != st2.tablespace {
+			op.Tablespace = st2.tablespace
+			if op.Tablespace == "" {
+				op.Tablespace = migration.DefaultTablespace
+			}
+		}
+
+		if op.Params != nil || op.Reset != nil || op.Tablespace != "" {
+			ops = append(ops, op)
+		}
+	}
+	return ops
+}
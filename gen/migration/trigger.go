@@ -0,0 +1,139 @@
+package migration
+
+import (
+	"reflect"
+	"sort"
+
+	"github.com/sqlbunny/sqlbunny/runtime/migration"
+	bunnyschema "github.com/sqlbunny/sqlbunny/schema"
+	"github.com/sqlbunny/sqlschema/operations"
+)
+
+// objectKey is the key of an object of a table, like a trigger.
+type objectKey struct {
+	schema string
+	table  string
+	name   string
+}
+
+type trigger struct {
+	def migration.CreateTrigger
+	// function is the name of the function created for the body, kept if
+	// the table is renamed.
+	function string
+}
+
+// triggerState is the triggers of the tables, tracked from the operations of
+// the migrations like the storage.
+type triggerState map[objectKey]*trigger
+
+func (s triggerState) apply(ops []operations.Operation) {
+	for _, op := range ops {
+		switch o := op.(type) {
+		case migration.CreateTrigger:
+			t := &trigger{def: o}
+			if o.Body != "" {
+				t.function = migration.TriggerFunctionName(o.TableName, o.TriggerName)
+			}
+			s[objectKey{o.SchemaName, o.TableName, o.TriggerName}] = t
+		case migration.DropTrigger:
+			delete(s, objectKey{o.SchemaName, o.TableName, o.TriggerName})
+		case operations.DropTable:
+			s.dropTable(o.SchemaName, o.TableName)
+		case migration.DropView:
+			s.dropTable(o.SchemaName, o.ViewName)
+		case operations.RenameTable:
+			for k, t := range s {
+				if k.schema == o.SchemaName && k.table == o.TableName {
+					delete(s, k)
+					t.def.TableName = o.NewTableName
+					s[objectKey{k.schema, o.NewTableName, k.name}] = t
+				}
+			}
+		}
+	}
+}
+
+func (s triggerState) dropTable(schema, table string) {
+	for k := range s {
+		if k.schema == schema && k.table == table {
+			delete(s, k)
+		}
+	}
+}
+
+// schemaTriggers returns the triggers of the tables of the schema, with the
+// triggers of the views.
+func schemaTriggers(s *bunnyschema.Schema) triggerState {
+	res := triggerState{}
+	for _, m := range s.Models {
+		triggers := append(m.ViewTriggers(), m.HistoryTriggers()...)
+		for _, t := range append(triggers, m.Triggers...) {
+			var columns []string
+			for _, p := range t.UpdateFields {
+				columns = append(columns, p.SQLName())
+			}
+			res[objectKey{"", m.Name, t.Name}] = &trigger{def: migration.CreateTrigger{
+				TableName:     m.Name,
+				TriggerName:   t.Name,
+				Timing:        t.Timing,
+				Events:        t.Events,
+				UpdateColumns: columns,
+				Statement:     t.Statement,
+				When:          t.When,
+				Function:      t.Function,
+				Body:          t.Body,
+			}}
+		}
+	}
+	return res
+}
+
+func sortedObjectKeys[V any](m map[objectKey]V) []objectKey {
+	keys := make([]objectKey, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := keys[i], keys[j]
+		if a.schema != b.schema {
+			return a.schema < b.schema
+		}
+		if a.table != b.table {
+			return a.table < b.table
+		}
+		return a.name < b.name
+	})
+	return keys
+}
+
+// dropTriggers returns the operations dropping the triggers of s1 which
+// aren't in s2 or are changed, to run before their tables are dropped.
+func dropTriggers(s1, s2 triggerState) []operations.Operation {
+	var ops []operations.Operation
+	for _, k := range sortedObjectKeys(s1) {
+		t1 := s1[k]
+		if t2, ok := s2[k]; ok && reflect.DeepEqual(t1.def, t2.def) {
+			continue
+		}
+		ops = append(ops, migration.DropTrigger{
+			SchemaName:  k.schema,
+			TableName:   k.table,
+			TriggerName: k.name,
+			Function:    t1.function,
+		})
+	}
+	return ops
+}
+
+// createTriggers returns the operations creating the triggers of s2 which
+// aren't in s1, with the changed ones already dropped by dropTriggers.
+func createTriggers(s1, s2 triggerState) []operations.Operation {
+	var ops []operations.Operation
+	for _, k := range sortedObjectKeys(s2) {
+		if _, ok := s1[k]; !ok {
+			ops = append(ops, s2[k].def)
+		}
+	}
+	return ops
+}
@@ -0,0 +1,122 @@
+package migration
+
+import (
+	"reflect"
+
+	"github.com/sqlbunny/sqlbunny/runtime/migration"
+	bunnyschema "github.com/sqlbunny/sqlbunny/schema"
+	"github.com/sqlbunny/sqlschema/operations"
+)
+
+// viewState is the views, by view with an empty name. They're not in the
+// SQL schema, so they're tracked like the foreign tables.
+type viewState map[objectKey]migration.CreateView
+
+func (s viewState) apply(ops []operations.Operation) {
+	for _, op := range ops {
+		switch o := op.(type) {
+		case migration.CreateView:
+			s[objectKey{o.SchemaName, o.ViewName, ""}] = o
+		case migration.DropView:
+			delete(s, objectKey{o.SchemaName, o.ViewName, ""})
+		}
+	}
+}
+
+// schemaViews returns the views of the schema.
+func schemaViews(s *bunnyschema.Schema) viewState {
+	res := viewState{}
+	for _, m := range s.Models {
+		if m.View == nil {
+			continue
+		}
+		res[objectKey{"", m.Name, ""}] = migration.CreateView{
+			ViewName:    m.Name,
+			Query:       m.View.Query,
+			CheckOption: m.View.CheckOption,
+			DependsOn:   m.View.DependsOn,
+		}
+	}
+	return res
+}
+
+// viewOrder returns the views of s after the views they depend on.
+func viewOrder(s viewState) []objectKey {
+	var res []objectKey
+	seen := make(map[objectKey]bool)
+	var visit func(k objectKey)
+	visit = func(k objectKey) {
+		if seen[k] {
+			return
+		}
+		seen[k] = true
+		for _, d := range s[k].DependsOn {
+			if dk := (objectKey{k.schema, d, ""}); hasKey(s, dk) {
+				visit(dk)
+			}
+		}
+		res = append(res, k)
+	}
+	for _, k := range sortedObjectKeys(s) {
+		visit(k)
+	}
+	return res
+}
+
+func hasKey(s viewState, k objectKey) bool {
+	_, ok := s[k]
+	return ok
+}
+
+// dropViews returns the operations dropping the views of s1 which aren't in
+// s2, are changed, or depend on a table of changed or on a dropped view,
+// before the views they depend on.
+func dropViews(s1, s2 viewState, changed map[string]bool) []operations.Operation {
+	dropped := make(map[string]bool)
+	var ops []operations.Operation
+	for _, k := range viewOrder(s1) {
+		v1 := s1[k]
+		drop := !reflect.DeepEqual(v1, s2[k])
+		for _, d := range v1.DependsOn {
+			drop = drop || changed[d] || dropped[d]
+		}
+		if drop {
+			dropped[k.table] = true
+			ops = append([]operations.Operation{migration.DropView{SchemaName: k.schema, ViewName: k.table}}, ops...)
+		}
+	}
+	return ops
+}
+
+// createViews returns the operations creating the views of s2 which aren't
+// in s1, after the views they depend on.
+func createViews(s1, s2 viewState) []operations.Operation {
+	var ops []operations.Operation
+	for _, k := range viewOrder(s2) {
+		if _, ok := s1[k]; !ok {
+			ops = append(ops, s2[k])
+		}
+	}
+	return ops
+}
+
+// changedTables returns the tables changed by ops, which the views
+// depending on them are recreated for.
+func changedTables(ops []operations.Operation) map[string]bool {
+	res := make(map[string]bool)
+	for _, op := range ops {
+		switch o := op.(type) {
+		case operations.AlterTable:
+			res[o.TableName] = true
+		case operations.DropTable:
+			res[o.TableName] = true
+		case operations.RenameTable:
+			res[o.TableName] = true
+		case operations.RenameColumn:
+			res[o.TableName] = true
+		case migration.DropForeignTable:
+			res[o.TableName] = true
+		}
+	}
+	return res
+}
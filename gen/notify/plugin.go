@@ -0,0 +1,66 @@
+package notify
+
+import (
+	"github.com/sqlbunny/sqlbunny/gen"
+	"github.com/sqlbunny/sqlbunny/gen/core"
+	"github.com/sqlbunny/sqlbunny/schema"
+)
+
+const (
+	templatesPackage = "github.com/sqlbunny/sqlbunny/gen/notify"
+)
+
+// Plugin makes the generated code send a NOTIFY after writes to models
+// annotated with Notify. The payload is the primary key of the written row.
+type Plugin struct {
+}
+
+var _ gen.Plugin = &Plugin{}
+
+func (*Plugin) ConfigItem(ctx *gen.Context) {}
+
+func (p *Plugin) BunnyPlugin() {
+	single := gen.MustLoadTemplate(templatesPackage, "templates/notify.tpl")
+	slice := gen.MustLoadTemplate(templatesPackage, "templates/notify_slice.tpl")
+
+	gen.OnHook("after_insert", p.hook(single))
+	gen.OnHook("after_update", p.hook(single))
+	gen.OnHook("after_delete", p.hook(single))
+	gen.OnHook("after_insert_slice", p.hook(slice))
+	gen.OnHook("after_delete_slice", p.hook(slice))
+}
+
+func (p *Plugin) hook(tpl *gen.TemplateList) gen.HookFunc {
+	return gen.ModelHook(tpl, func(model *schema.Model) (map[string]interface{}, bool) {
+		channel, ok := model.GetExtension(notifyExt{}).(string)
+		if !ok {
+			return nil, false
+		}
+		return map[string]interface{}{"Channel": channel}, true
+	})
+}
+
+type notifyExt struct{}
+
+type defNotify struct {
+	channel string
+}
+
+func (d defNotify) ModelItem(ctx *core.ModelContext) {
+	if ctx.Model.GetExtension(notifyExt{}) != nil {
+		ctx.AddError("Model '%s' has Notify defined multiple times", ctx.Model.Name)
+	}
+	ctx.Model.SetExtension(notifyExt{}, d.channel)
+}
+
+// Notify makes the generated code send a notification on channel after every
+// insert, update or delete of a row of the model, with the row's primary key
+// as payload. Composite primary keys are separated by commas.
+//
+// Bulk updates and deletes that go through a query (UpdateMapAll, DeleteAll on
+// queries) don't know which rows they change, so they don't notify.
+func Notify(channel string) core.ModelItem {
+	return defNotify{
+		channel: channel,
+	}
+}
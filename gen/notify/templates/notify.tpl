@@ -0,0 +1,3 @@
+	if err := bunny.Notify(ctx, "{{.Channel}}", bunny.NotifyPayload({{range $i, $f := .Model.PrimaryKey.Fields}}{{if $i}}, {{end}}{{$.Var}}.{{$f | titleCasePath}}{{end}})); err != nil {
+		return err
+	}
@@ -0,0 +1,5 @@
+	for _, obj := range {{.Var}} {
+		if err := bunny.Notify(ctx, "{{.Channel}}", bunny.NotifyPayload({{range $i, $f := .Model.PrimaryKey.Fields}}{{if $i}}, {{end}}obj.{{$f | titleCasePath}}{{end}})); err != nil {
+			return err
+		}
+	}
@@ -0,0 +1,99 @@
+package outbox
+
+import (
+	"github.com/sqlbunny/sqlbunny/gen"
+	"github.com/sqlbunny/sqlbunny/gen/core"
+	"github.com/sqlbunny/sqlbunny/runtime/bunny"
+	"github.com/sqlbunny/sqlbunny/schema"
+)
+
+const (
+	templatesPackage = "github.com/sqlbunny/sqlbunny/gen/outbox"
+)
+
+// Plugin makes the generated code write an event to the outbox table in the
+// transaction of every write to models annotated with Outbox, for
+// bunny.PollOutbox to publish. It defines the model of the outbox table,
+// bunny.OutboxTable, so the migrations create it.
+type Plugin struct {
+}
+
+var _ gen.Plugin = &Plugin{}
+
+func (*Plugin) ConfigItem(ctx *gen.Context) {}
+
+func (p *Plugin) Expand() []gen.ConfigItem {
+	return []gen.ConfigItem{
+		core.Type("outbox_event_id", core.BaseType{
+			Go:     "int64",
+			GoNull: "github.com/sqlbunny/sqlbunny/types/null.Int64",
+			Postgres: core.SQLType{
+				Type: "bigserial",
+			},
+		}),
+		core.Model(bunny.OutboxTable,
+			core.Field("id", "outbox_event_id", core.PrimaryKey),
+			core.Field("topic", "string"),
+			core.Field("kind", "string"),
+			core.Field("key", "string"),
+			core.Field("payload", "jsonb"),
+			core.Field("created_at", "time"),
+			core.Field("published_at", "time", core.Null, core.Index),
+		),
+	}
+}
+
+func (p *Plugin) BunnyPlugin() {
+	check := gen.MustLoadTemplate(templatesPackage, "templates/check.tpl")
+	single := gen.MustLoadTemplate(templatesPackage, "templates/outbox.tpl")
+	slice := gen.MustLoadTemplate(templatesPackage, "templates/outbox_slice.tpl")
+
+	gen.OnHook("before_insert", p.hook(check, ""))
+	gen.OnHook("before_update", p.hook(check, ""))
+	gen.OnHook("before_delete", p.hook(check, ""))
+	gen.OnHook("before_insert_slice", p.hook(check, ""))
+	gen.OnHook("before_delete_slice", p.hook(check, ""))
+	gen.OnHook("after_insert", p.hook(single, "insert"))
+	gen.OnHook("after_update", p.hook(single, "update"))
+	gen.OnHook("after_delete", p.hook(single, "delete"))
+	gen.OnHook("after_insert_slice", p.hook(slice, "insert"))
+	gen.OnHook("after_delete_slice", p.hook(slice, "delete"))
+}
+
+func (p *Plugin) hook(tpl *gen.TemplateList, kind string) gen.HookFunc {
+	return gen.ModelHook(tpl, func(model *schema.Model) (map[string]interface{}, bool) {
+		topic, ok := model.GetExtension(outboxExt{}).(string)
+		if !ok {
+			return nil, false
+		}
+		return map[string]interface{}{"Topic": topic, "Kind": kind}, true
+	})
+}
+
+type outboxExt struct{}
+
+type defOutbox struct {
+	topic string
+}
+
+func (d defOutbox) ModelItem(ctx *core.ModelContext) {
+	if ctx.Model.GetExtension(outboxExt{}) != nil {
+		ctx.AddError("Model '%s' has Outbox defined multiple times", ctx.Model.Name)
+	}
+	ctx.Model.SetExtension(outboxExt{}, d.topic)
+}
+
+// Outbox makes the generated code write an event on topic to the outbox
+// table after every insert, update or delete of a row of the model, with
+// the row's primary key and JSON, in the transaction of the write. Writes
+// outside transactions fail with bunny.ErrOutboxNotAtomic before writing
+// the row.
+//
+// Upserts write insert events. Writes which don't know their rows
+// (InsertIgnoreAll, UpdateMapAll and DeleteAll on queries) don't write
+// events.
+func Outbox(topic string) core.ModelItem {
+	return defOutbox{
+		topic: topic,
+	}
+}
@@ -0,0 +1,3 @@
+	if err := bunny.CheckOutbox(ctx); err != nil {
+		return err
+	}
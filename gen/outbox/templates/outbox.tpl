@@ -0,0 +1,3 @@
+	if err := bunny.WriteOutbox(ctx, "{{.Topic}}", "{{.Kind}}", bunny.NotifyPayload({{range $i, $f := .Model.PrimaryKey.Fields}}{{if $i}}, {{end}}{{$.Var}}.{{$f | titleCasePath}}{{end}}), {{.Var}}); err != nil {
+		return err
+	}
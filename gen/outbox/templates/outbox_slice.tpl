@@ -0,0 +1,5 @@
+	for _, obj := range {{.Var}} {
+		if err := bunny.WriteOutbox(ctx, "{{.Topic}}", "{{.Kind}}", bunny.NotifyPayload({{range $i, $f := .Model.PrimaryKey.Fields}}{{if $i}}, {{end}}obj.{{$f | titleCasePath}}{{end}}), obj); err != nil {
+			return err
+		}
+	}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 
 	"github.com/spf13/cobra"
+	"github.com/sqlbunny/sqlbunny/schema"
 )
 
 type Plugin interface {
@@ -35,6 +36,32 @@ func OnHook(name string, f HookFunc) {
 	hookFuncs[name] = append(hookFuncs[name], f)
 }
 
+// ModelHook returns a HookFunc executing tpl in the hooks of the writes of
+// models, like after_insert or before_delete_slice, whose arguments are the
+// variable with the written objects and their model. tpl gets them as Var
+// and Model, with a copy of the rest of the data and the entries returned by
+// fields for the model. It's skipped for the models fields returns false for.
+func ModelHook(tpl *TemplateList, fields func(model *schema.Model) (map[string]interface{}, bool)) HookFunc {
+	return func(buf *bytes.Buffer, data map[string]interface{}, args ...interface{}) {
+		model := args[1].(*schema.Model)
+		extra, ok := fields(model)
+		if !ok {
+			return
+		}
+
+		data2 := make(map[string]interface{}, len(data)+len(extra)+2)
+		for k, v := range data {
+			data2[k] = v
+		}
+		for k, v := range extra {
+			data2[k] = v
+		}
+		data2["Var"] = args[0]
+		data2["Model"] = model
+		tpl.ExecuteBuf(data2, buf)
+	}
+}
+
 func hook(data map[string]interface{}, name string, args ...interface{}) string {
 	var buf bytes.Buffer
 	for _, f := range hookFuncs[name] {
@@ -0,0 +1,188 @@
+package gen
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path"
+	"reflect"
+
+	"github.com/sqlbunny/sqlbunny/runtime/queries"
+	"gopkg.in/yaml.v3"
+)
+
+// ProjectFile is the project config file, read from the working directory
+// if it exists. The SQLBUNNY_CONFIG environment variable sets another path.
+const ProjectFile = "sqlbunny.yaml"
+
+// ProjectConfig is the project config file. It's applied before the Config
+// items passed to Run, which override it:
+//
+//	output: ./models
+//	package: models
+//	dialect: postgres
+//	plugins: [stdtypes, migration]
+//	tests: true
+//	types:
+//	  money: {go: github.com/acme/money.Amount, postgres: numeric(12, 2)}
+//	environments:
+//	  dev: {dsn: "postgres://localhost/app_dev?sslmode=disable"}
+type ProjectConfig struct {
+	// Output is the directory of the generated models package.
+	Output string `yaml:"output"`
+
+	// Package is the name of the generated models package.
+	Package string `yaml:"package"`
+
+	// Dialect of the generated code, "postgres" or "mysql".
+	Dialect string `yaml:"dialect"`
+
+	// Plugins are the names of the enabled plugins, the names of their
+	// packages, like "migration". If it's empty all the plugins passed to
+	// Run are enabled. The core plugin is always enabled. Names of plugins
+	// which aren't passed to Run are errors.
+	Plugins []string `yaml:"plugins"`
+
+	// Tests enables the generated integration tests, see
+	// ConfigStruct.GenerateTests.
+	Tests bool `yaml:"tests"`
+
+	// Types override the Go types of the types with their names.
+	Types map[string]ProjectType `yaml:"types"`
+
+	// Environments are the databases of the project by environment name,
+	// used by the commands connecting to a database with --env.
+	Environments map[string]ProjectEnvironment `yaml:"environments"`
+}
+
+// ProjectType overrides the Go type of a type. Empty fields keep their
+// definition.
+type ProjectType struct {
+	Go       string `yaml:"go"`
+	GoNull   string `yaml:"go_null"`
+	Postgres string `yaml:"postgres"`
+}
+
+// ProjectEnvironment is a database of the project.
+type ProjectEnvironment struct {
+	DSN string `yaml:"dsn"`
+}
+
+// LoadProjectConfig reads the project config file at path. It returns nil
+// if the file doesn't exist.
+func LoadProjectConfig(path string) (*ProjectConfig, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var c ProjectConfig
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&c); err != nil {
+		return nil, fmt.Errorf("invalid project config '%s': %w", path, err)
+	}
+	return &c, nil
+}
+
+func (c *ProjectConfig) apply(s *ConfigStruct) error {
+	if c.Output != "" {
+		s.ModelsPackagePath = c.Output
+	}
+	if c.Package != "" {
+		s.ModelsPackageName = c.Package
+	}
+	if c.Dialect != "" {
+		d, err := DialectByName(c.Dialect)
+		if err != nil {
+			return err
+		}
+		s.Dialect = d
+	}
+	if c.Tests {
+		s.GenerateTests = true
+	}
+	return nil
+}
+
+// enabled reports whether the item is enabled: it's not a plugin, or it's
+// a plugin in Plugins.
+func (c *ProjectConfig) enabled(item ConfigItem) bool {
+	if _, ok := item.(Plugin); !ok || len(c.Plugins) == 0 {
+		return true
+	}
+	name := pluginName(item)
+	if name == "core" {
+		return true
+	}
+	for _, p := range c.Plugins {
+		if p == name {
+			return true
+		}
+	}
+	return false
+}
+
+// unknownPlugins returns the names in Plugins which aren't the names of
+// plugins in items.
+func (c *ProjectConfig) unknownPlugins(items []ConfigItem) []string {
+	known := map[string]bool{"core": true}
+	for _, i := range items {
+		if _, ok := i.(Plugin); ok {
+			known[pluginName(i)] = true
+		}
+	}
+	var res []string
+	for _, p := range c.Plugins {
+		if !known[p] {
+			res = append(res, p)
+		}
+	}
+	return res
+}
+
+// unknownPlugins is a config item reporting the plugins enabled in the
+// project config which aren't passed to Run, like misspelled ones.
+type unknownPlugins []string
+
+func (u unknownPlugins) ConfigItem(ctx *Context) {
+	for _, name := range u {
+		ctx.AddError("%s enables plugin '%s', which isn't passed to Run", ProjectFile, name)
+	}
+}
+
+// pluginName returns the name of the package of the plugin.
+func pluginName(p ConfigItem) string {
+	t := reflect.TypeOf(p)
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return path.Base(t.PkgPath())
+}
+
+// DSN returns the DSN of the environment with name in the project config.
+func (c *ConfigStruct) DSN(env string) (string, error) {
+	if c.Project == nil {
+		return "", fmt.Errorf("environment '%s' not found, there's no %s", env, ProjectFile)
+	}
+	e, ok := c.Project.Environments[env]
+	if !ok || e.DSN == "" {
+		return "", fmt.Errorf("environment '%s' not found in %s", env, ProjectFile)
+	}
+	return e.DSN, nil
+}
+
+// DialectByName returns the dialect of the generated code with name,
+// "postgres" or "mysql".
+func DialectByName(name string) (queries.Dialect, error) {
+	switch name {
+	case "", "postgres":
+		return DialectPostgres, nil
+	case "mysql":
+		return DialectMySQL, nil
+	}
+	return queries.Dialect{}, fmt.Errorf("unknown dialect '%s'", name)
+}
@@ -98,6 +98,17 @@ func (p *Plugin) Expand() []gen.ConfigItem {
 			},
 		}),
 
+		core.Type("tsvector", core.BaseType{
+			Go:     "string",
+			GoNull: "github.com/sqlbunny/sqlbunny/types/null.String",
+			Postgres: core.SQLType{
+				Type:      "tsvector",
+				ZeroValue: "''",
+			},
+		}),
+
+		core.Type("uuid", core.UUID{}),
+
 		core.Type("time", core.BaseType{
 			Go:     "time.Time",
 			GoNull: "github.com/sqlbunny/sqlbunny/types/null.Time",
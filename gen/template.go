@@ -150,6 +150,7 @@ var TemplateFunctions = template.FuncMap{
 	"whereInClause":   WhereInClause,
 	"joinOnClause":    JoinOnClause,
 	"joinWhereClause": JoinWhereClause,
+	"placeholders":    strmangle.Placeholders,
 
 	"sqlNames": func(ps []schema.Path) []string {
 		res := make([]string, len(ps))
@@ -161,6 +162,19 @@ var TemplateFunctions = template.FuncMap{
 	"modelColumns":      modelColumns,
 	"modelPKColumns":    modelPKColumns,
 	"modelNonPKColumns": modelNonPKColumns,
+	"dbDefaultColumns":  modelDBDefaultColumns,
+	"insertColumns":     modelInsertColumns,
+	"immutableColumns":  modelImmutableColumns,
+	"updateColumns":     modelUpdateColumns,
+	"patchFields":       patchFields,
+	"fieldColumns":      fieldColumns,
+	"jsonName":          jsonName,
+	"scanDests":         scanDests,
+	"fieldExpr":         fieldExpr,
+	"tableColumns":      tableColumns,
+	"mapKeys":           mapKeys,
+	"uniqueKeys":        uniqueKeys,
+	"hasPII":            schema.HasPII,
 
 	"quotes": func(s string) string {
 		d := Config.Dialect
@@ -177,6 +191,21 @@ var TemplateFunctions = template.FuncMap{
 	},
 	"hook": hook,
 
+	// sqlArg converts the Go expression of the field's value to a query
+	// argument.
+	"sqlArg": func(f *schema.Field, expr string) string {
+		if f != nil && f.IsBinary() {
+			expr = "queries.BinaryValue(" + expr + ")"
+		}
+		if f != nil && f.Encryption != schema.EncryptionNone {
+			expr = fmt.Sprintf("bunny.Encrypted{Plaintext: %s, Deterministic: %t}", expr, f.Encryption == schema.EncryptionDeterministic)
+		}
+		if f != nil && f.PII {
+			expr = "bunny.Redact(" + expr + ")"
+		}
+		return expr
+	},
+
 	"doCompare": func(a, b string, ca, cb *schema.Field) string {
 		if ca.Type.GoType().Name == "[]byte" && cb.Type.GoType().Name == "[]byte" {
 			return "0 == bytes.Compare(" + a + ", " + b + ")"
@@ -219,6 +248,275 @@ func modelNonPKColumns(m *schema.Model) []string {
 	return c
 }
 
+// modelDBDefaultColumns returns the columns of the fields with a DBDefault,
+// which the generated inserts leave to the database.
+func modelDBDefaultColumns(m *schema.Model) []string {
+	var res []string
+	for _, c := range m.Columns() {
+		if c.DBDefault != "" {
+			res = append(res, c.Name)
+		}
+	}
+	return res
+}
+
+// modelInsertColumns returns the columns written by the generated inserts
+// without a whitelist.
+func modelInsertColumns(m *schema.Model) []string {
+	return strmangle.SetComplement(modelColumns(m), modelDBDefaultColumns(m))
+}
+
+// modelImmutableColumns returns the columns of the Immutable fields, which
+// the generated updates don't write.
+func modelImmutableColumns(m *schema.Model) []string {
+	var res []string
+	for _, c := range m.Columns() {
+		if c.Immutable {
+			res = append(res, c.Name)
+		}
+	}
+	return res
+}
+
+// modelUpdateColumns returns the columns written by the generated updates
+// without a whitelist.
+func modelUpdateColumns(m *schema.Model) []string {
+	return strmangle.SetComplement(modelNonPKColumns(m), modelImmutableColumns(m))
+}
+
+// patchFields returns the fields of the model which aren't in its primary
+// key, which the patch structs change.
+func patchFields(m *schema.Model) []*schema.Field {
+	var res []*schema.Field
+	for _, f := range m.Fields {
+		pk := false
+		for _, p := range m.PrimaryKey.Fields {
+			pk = pk || p[0] == f.Name
+		}
+		if !pk {
+			res = append(res, f)
+		}
+	}
+	return res
+}
+
+// fieldColumns returns the columns of the field of the model, which are
+// the columns of all its fields if it's a struct.
+func fieldColumns(m *schema.Model, f *schema.Field) []string {
+	var res []string
+	for name := range m.Table.Columns {
+		if name == f.Name || strings.HasPrefix(name, f.Name+"__") {
+			res = append(res, name)
+		}
+	}
+	sort.Strings(res)
+	return res
+}
+
+// scanDest is the scan destination of a column of a model in its generated
+// scanRow function, as the Go expression of a pointer into the object o.
+type scanDest struct {
+	Column string
+	Dest   string
+}
+
+// scanDests returns the scan destinations of the columns of the model, in
+// the order of Model.Columns. Like Bind, the columns of the fields of
+// nullable structs ignore NULLs, and encrypted columns are decrypted.
+func scanDests(m *schema.Model) []scanDest {
+	var res []scanDest
+	var walk func(f *schema.Field, prefix schema.Path, expr string, inNullable bool)
+	walk = func(f *schema.Field, prefix schema.Path, expr string, inNullable bool) {
+		path := append(prefix[:len(prefix):len(prefix)], f.Name)
+		expr += "." + strmangle.TitleCase(f.Name)
+		wrap := func(dest string) string {
+			if inNullable {
+				return "queries.IgnoreNull(" + dest + ")"
+			}
+			return dest
+		}
+		switch t := f.Type.(type) {
+		case *schema.Struct:
+			inner := expr
+			if f.Nullable {
+				inner += "." + strmangle.TitleCase(t.Name)
+			}
+			for _, f2 := range t.Fields {
+				walk(f2, path, inner, inNullable || f.Nullable)
+			}
+			if f.Nullable {
+				res = append(res, scanDest{Column: path.SQLName(), Dest: wrap("&" + expr + ".Valid")})
+			}
+		case schema.BaseType:
+			dest := wrap("&" + expr)
+			if f.Encryption != schema.EncryptionNone {
+				dest = "queries.Decrypted(" + dest + ")"
+			}
+			res = append(res, scanDest{Column: path.SQLName(), Dest: dest})
+		}
+	}
+	for _, f := range m.Fields {
+		walk(f, nil, "o", false)
+	}
+	return res
+}
+
+// fieldExpr returns the Go expression of the value of the column of the
+// field of the model at path p in the object o, like scanDests: through the
+// inner value of nullable structs, and their Valid flag for their own column.
+func fieldExpr(m *schema.Model, o string, p schema.Path) string {
+	expr := o
+	fields := m.Fields
+	for i, name := range p {
+		var f *schema.Field
+		for _, f2 := range fields {
+			if f2.Name == name {
+				f = f2
+			}
+		}
+		if f == nil {
+			return expr + "." + titleCasePath(p[i:])
+		}
+		expr += "." + strmangle.TitleCase(name)
+		t, ok := f.Type.(*schema.Struct)
+		if !ok {
+			continue
+		}
+		if f.Nullable {
+			if i == len(p)-1 {
+				return expr + ".Valid"
+			}
+			expr += "." + strmangle.TitleCase(t.Name)
+		}
+		fields = t.Fields
+	}
+	return expr
+}
+
+// tableColumn is a column of a model with the Go type of its values in
+// queries.Column, which is the non null type of nullable fields so they can
+// be joined with the columns they reference.
+type tableColumn struct {
+	Name   string
+	GoType schema.GoType
+}
+
+// tableColumns returns the columns of the model, in the order of
+// Model.Columns.
+func tableColumns(m *schema.Model) []tableColumn {
+	var res []tableColumn
+	var walk func(f *schema.Field, prefix schema.Path)
+	walk = func(f *schema.Field, prefix schema.Path) {
+		path := append(prefix[:len(prefix):len(prefix)], f.Name)
+		switch t := f.Type.(type) {
+		case *schema.Struct:
+			for _, f2 := range t.Fields {
+				walk(f2, path)
+			}
+			if f.Nullable {
+				res = append(res, tableColumn{Name: path.SQLName(), GoType: schema.GoType{Name: "bool"}})
+			}
+		case schema.BaseType:
+			res = append(res, tableColumn{Name: path.SQLName(), GoType: t.GoType()})
+		}
+	}
+	for _, f := range m.Fields {
+		walk(f, nil)
+	}
+	return res
+}
+
+// mapKey is a key of a model which the generated By<Name> and AllBy<Name>
+// methods map the objects by.
+type mapKey struct {
+	Name  string
+	Path  schema.Path
+	Field *schema.Field
+}
+
+// mapKeys returns the single field primary key and uniques of the model
+// whose values can key a map: the fields of the keys must not be nullable,
+// nor be in nullable structs, and their Go type must be comparable.
+func mapKeys(m *schema.Model) []mapKey {
+	var res []mapKey
+	add := func(fields []schema.Path) {
+		if len(fields) != 1 {
+			return
+		}
+		path := fields[0]
+		for i := range path {
+			f := m.FindField(path[:i+1])
+			if f == nil || f.Nullable {
+				return
+			}
+		}
+		f := m.FindField(path)
+		if name := f.GoType().Name; strings.HasPrefix(name, "[]") || strings.HasPrefix(name, "map[") {
+			return
+		}
+		for _, k := range res {
+			if k.Path.Equals(path) {
+				return
+			}
+		}
+		res = append(res, mapKey{Name: strings.Replace(titleCasePath(path), ".", "", -1), Path: path, Field: f})
+	}
+
+	if m.PrimaryKey != nil {
+		add(m.PrimaryKey.Fields)
+	}
+	for _, u := range m.Uniques {
+		add(u.Fields)
+	}
+	return res
+}
+
+// uniqueKey is the primary key or a unique of a model, which the generated
+// GetOrCreateBy<Name> methods look the rows up by.
+type uniqueKey struct {
+	Name   string
+	Fields []schema.Path
+}
+
+// uniqueKeys returns the primary key and the uniques of the model whose
+// fields aren't structs, so they can be compared to their values.
+func uniqueKeys(m *schema.Model) []uniqueKey {
+	var res []uniqueKey
+	add := func(fields []schema.Path) {
+		name := ""
+		for _, p := range fields {
+			if f := m.FindField(p); f == nil || f.IsStruct() {
+				return
+			}
+			name += strings.Replace(titleCasePath(p), ".", "", -1)
+		}
+		for _, k := range res {
+			if k.Name == name {
+				return
+			}
+		}
+		res = append(res, uniqueKey{Name: name, Fields: fields})
+	}
+
+	if m.PrimaryKey != nil {
+		add(m.PrimaryKey.Fields)
+	}
+	for _, u := range m.Uniques {
+		add(u.Fields)
+	}
+	return res
+}
+
+// jsonName returns the name of the field in JSON, from its json tag.
+func jsonName(f *schema.Field) string {
+	if tag, ok := f.Tags["json"]; ok {
+		if name := strings.Split(tag, ",")[0]; name != "" {
+			return name
+		}
+	}
+	return f.Name
+}
+
 func titleCasePath(p schema.Path) string {
 	var res = ""
 	for i, n := range p {
@@ -1,15 +1,33 @@
 module github.com/sqlbunny/sqlbunny
 
-go 1.12
+go 1.18
 
 require (
 	github.com/davecgh/go-spew v1.1.1
-	github.com/lib/pq v1.2.0
+	github.com/go-sql-driver/mysql v1.7.1
+	github.com/jackc/pgx/v4 v4.18.3
+	github.com/lib/pq v1.10.2
 	github.com/sanity-io/litter v1.2.0
 	github.com/spf13/cobra v0.0.5
 	github.com/sqlbunny/errors v0.0.0-20190927201458-cf9913986328
 	github.com/sqlbunny/sqlschema v0.0.0-20200106010312-30ff3295bbdf
 	github.com/volatiletech/inflect v0.0.0-20170731032912-e7201282ae8d
-	golang.org/x/tools v0.0.0-20190802220118-1d1727260058
+	golang.org/x/tools v0.6.0
 	gopkg.in/DATA-DOG/go-sqlmock.v2 v2.0.0-20180914054222-c19298f520d0
+	gopkg.in/yaml.v3 v3.0.1
+)
+
+require (
+	github.com/jackc/chunkreader/v2 v2.0.1 // indirect
+	github.com/jackc/pgconn v1.14.3 // indirect
+	github.com/jackc/pgio v1.0.0 // indirect
+	github.com/jackc/pgpassfile v1.0.0 // indirect
+	github.com/jackc/pgproto3/v2 v2.3.3 // indirect
+	github.com/jackc/pgservicefile v0.0.0-20221227161230-091c0ba34f0a // indirect
+	github.com/jackc/pgtype v1.14.0 // indirect
+	github.com/spf13/pflag v1.0.3 // indirect
+	golang.org/x/crypto v0.20.0 // indirect
+	golang.org/x/mod v0.8.0 // indirect
+	golang.org/x/sys v0.17.0 // indirect
+	golang.org/x/text v0.14.0 // indirect
 )
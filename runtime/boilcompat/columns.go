@@ -0,0 +1,59 @@
+// Package boilcompat has the helpers of the sqlboiler compatible methods
+// generated by the boilcompat plugin, for projects migrating from sqlboiler.
+package boilcompat
+
+type columnsKind int
+
+const (
+	columnsInfer columnsKind = iota
+	columnsWhitelist
+	columnsBlacklist
+)
+
+// Columns selects the columns written by an insert, like boil.Columns.
+type Columns struct {
+	kind columnsKind
+	cols []string
+}
+
+// Infer writes the columns inferred by the generated methods.
+func Infer() Columns {
+	return Columns{kind: columnsInfer}
+}
+
+// Whitelist writes only cols.
+func Whitelist(cols ...string) Columns {
+	return Columns{kind: columnsWhitelist, cols: cols}
+}
+
+// Blacklist writes all the columns but cols.
+func Blacklist(cols ...string) Columns {
+	return Columns{kind: columnsBlacklist, cols: cols}
+}
+
+// List returns the whitelist of the columns of a model, all, to pass to
+// the generated methods. It's nil if the columns are inferred.
+func (c Columns) List(all []string) []string {
+	switch c.kind {
+	case columnsWhitelist:
+		return c.cols
+	case columnsBlacklist:
+		res := make([]string, 0, len(all))
+		for _, a := range all {
+			if !contains(c.cols, a) {
+				res = append(res, a)
+			}
+		}
+		return res
+	}
+	return nil
+}
+
+func contains(cols []string, col string) bool {
+	for _, c := range cols {
+		if c == col {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,27 @@
+package boilcompat
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestColumnsList(t *testing.T) {
+	t.Parallel()
+
+	all := []string{"id", "name", "email"}
+	tests := []struct {
+		columns Columns
+		want    []string
+	}{
+		{Infer(), nil},
+		{Whitelist("name"), []string{"name"}},
+		{Blacklist("id", "email"), []string{"name"}},
+		{Blacklist(), all},
+	}
+
+	for i, test := range tests {
+		if got := test.columns.List(all); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%d) want: %v, got: %v", i, test.want, got)
+		}
+	}
+}
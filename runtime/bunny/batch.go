@@ -0,0 +1,132 @@
+package bunny
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// maxBatchSize is the number of keys a batch is loaded at, before its wait
+// elapses, to keep queries reasonably sized.
+const maxBatchSize = 1000
+
+type contextBatcherKeyType struct{}
+
+var contextBatcherKey = contextBatcherKeyType{}
+
+// BatchLoadFunc loads the rows of a model with the given primary keys, each
+// one holding the values of the primary key fields. It returns the rows by
+// the CacheKey of their primary key, without the ones that don't exist.
+type BatchLoadFunc func(ctx context.Context, keys [][]interface{}) (map[string]interface{}, error)
+
+type batcher struct {
+	wait time.Duration
+
+	mu      sync.Mutex
+	batches map[string]*batch
+}
+
+type batch struct {
+	ctx  context.Context
+	load BatchLoadFunc
+	keys [][]interface{}
+	seen map[string]bool
+
+	once sync.Once
+	done chan struct{}
+	rows map[string]interface{}
+	err  error
+}
+
+// WithBatching returns a context in which the lookups of rows by primary key
+// of the generated Find functions are batched, dataloader-style: the keys
+// looked up concurrently during wait are loaded with a single query per
+// model, like "WHERE id IN (...)". It suits request handlers fanning out to
+// many goroutines, where each one would otherwise run its own query.
+//
+// Lookups in transactions aren't batched, since they must see their own
+// writes, nor lookups with settings (see WithSetting), whose rows depend on
+// them. The batch query runs in the context of the first lookup, without
+// its cancellation, so that the other lookups don't fail with it.
+func WithBatching(ctx context.Context, wait time.Duration) context.Context {
+	return context.WithValue(ctx, contextBatcherKey, &batcher{
+		wait:    wait,
+		batches: make(map[string]*batch),
+	})
+}
+
+// BatchLoad looks up the row of model with the primary key in the batch of
+// ctx, loaded with load. batched is false if ctx has no batching, in which
+// case the caller looks the row up itself. row is nil if it doesn't exist.
+func BatchLoad(ctx context.Context, model string, load BatchLoadFunc, key ...interface{}) (row interface{}, batched bool, err error) {
+	b, ok := ctx.Value(contextBatcherKey).(*batcher)
+	// Without the shard of a sharded model, leave the error to the query.
+	if !ok || checkShard(ctx) != nil || cacheBypassed(ctx) {
+		return nil, false, nil
+	}
+
+	name := tenantModel(ctx, model)
+	if shard, ok := ShardFromContext(ctx); ok {
+		// The batches of each shard are loaded from it.
+		name = fmt.Sprintf("%s\x00%d", name, shard)
+	}
+	k := CacheKey(key...)
+
+	b.mu.Lock()
+	bt := b.batches[name]
+	if bt == nil {
+		bt = &batch{
+			ctx:  detachedContext{ctx},
+			load: load,
+			seen: make(map[string]bool),
+			done: make(chan struct{}),
+		}
+		b.batches[name] = bt
+		time.AfterFunc(b.wait, func() { b.run(name, bt) })
+	}
+	if !bt.seen[k] {
+		bt.seen[k] = true
+		bt.keys = append(bt.keys, key)
+	}
+	full := len(bt.keys) >= maxBatchSize
+	b.mu.Unlock()
+
+	if full {
+		go b.run(name, bt)
+	}
+
+	select {
+	case <-bt.done:
+		if bt.err != nil {
+			return nil, true, bt.err
+		}
+		return bt.rows[k], true, nil
+	case <-ctx.Done():
+		return nil, true, ctx.Err()
+	}
+}
+
+// run loads bt once, after removing it from the pending batches.
+func (b *batcher) run(name string, bt *batch) {
+	bt.once.Do(func() {
+		b.mu.Lock()
+		if b.batches[name] == bt {
+			delete(b.batches, name)
+		}
+		b.mu.Unlock()
+
+		bt.rows, bt.err = bt.load(bt.ctx, bt.keys)
+		close(bt.done)
+	})
+}
+
+// detachedContext keeps the values of a context, without its deadline and
+// cancellation.
+type detachedContext struct {
+	context.Context
+}
+
+func (detachedContext) Deadline() (time.Time, bool) { return time.Time{}, false }
+func (detachedContext) Done() <-chan struct{}       { return nil }
+func (detachedContext) Err() error                  { return nil }
@@ -0,0 +1,95 @@
+package bunny
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"gopkg.in/DATA-DOG/go-sqlmock.v2"
+)
+
+func TestBatchLoad(t *testing.T) {
+	var mu sync.Mutex
+	var loads [][][]interface{}
+	load := func(ctx context.Context, keys [][]interface{}) (map[string]interface{}, error) {
+		mu.Lock()
+		loads = append(loads, keys)
+		mu.Unlock()
+
+		rows := make(map[string]interface{})
+		for _, key := range keys {
+			if key[0] != "missing" {
+				rows[CacheKey(key...)] = key[0]
+			}
+		}
+		return rows, nil
+	}
+
+	db, _, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := WithBatching(ContextWithDB(context.Background(), db), 10*time.Millisecond)
+
+	ids := []string{"a", "b", "a", "missing"}
+	rows := make([]interface{}, len(ids))
+	var wg sync.WaitGroup
+	for i, id := range ids {
+		wg.Add(1)
+		go func(i int, id string) {
+			defer wg.Done()
+			row, batched, err := BatchLoad(ctx, "book", load, id)
+			if !batched || err != nil {
+				t.Errorf("BatchLoad(%s) = %v, %v", id, batched, err)
+			}
+			rows[i] = row
+		}(i, id)
+	}
+	wg.Wait()
+
+	if len(loads) != 1 {
+		t.Fatalf("expected 1 load, got %d", len(loads))
+	}
+	if len(loads[0]) != 3 {
+		t.Errorf("expected the 3 distinct keys to be loaded, got %v", loads[0])
+	}
+	if rows[0] != "a" || rows[1] != "b" || rows[2] != "a" || rows[3] != nil {
+		t.Errorf("wrong rows: %v", rows)
+	}
+}
+
+func TestBatchLoadWithoutBatching(t *testing.T) {
+	load := func(ctx context.Context, keys [][]interface{}) (map[string]interface{}, error) {
+		t.Error("unexpected load")
+		return nil, nil
+	}
+
+	db, _, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, batched, _ := BatchLoad(ContextWithDB(context.Background(), db), "book", load, "a"); batched {
+		t.Error("lookups without batching shouldn't be batched")
+	}
+}
+
+func TestBatchLoadSettings(t *testing.T) {
+	load := func(ctx context.Context, keys [][]interface{}) (map[string]interface{}, error) {
+		t.Error("unexpected load")
+		return nil, nil
+	}
+
+	db, _, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := WithBatching(ContextWithDB(context.Background(), db), 10*time.Millisecond)
+
+	for _, user := range []string{"1", "2"} {
+		if _, batched, _ := BatchLoad(WithSetting(ctx, "app.user_id", user), "book", load, "a"); batched {
+			t.Errorf("lookups with settings of user %s shouldn't be batched", user)
+		}
+	}
+}
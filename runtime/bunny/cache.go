@@ -0,0 +1,216 @@
+package bunny
+
+import (
+	"container/list"
+	"context"
+	"reflect"
+	"sync"
+)
+
+// Cache caches model rows by primary key. Generated Find functions consult it,
+// and generated Update and Delete methods invalidate it. With a tenant context
+// (see WithTenant), model names are prefixed by the tenant and "\x00".
+//
+// Values passed to Set are pointers to model structs. Implementations must
+// store a copy, since the caller keeps using the value. Get copies the cached
+// row into dest, a pointer to a struct of the same model. This lets out of
+// process implementations (Redis, memcached) serialize values, for example
+// as JSON.
+type Cache interface {
+	Get(ctx context.Context, model string, key string, dest interface{}) bool
+	Set(ctx context.Context, model string, key string, value interface{})
+	Invalidate(ctx context.Context, model string, key string)
+	// InvalidateModel invalidates all the cached rows of model. It's used
+	// by bulk writes, which don't know which rows they change.
+	InvalidateModel(ctx context.Context, model string)
+}
+
+var cache Cache
+
+// SetCache sets the cache used for primary key lookups. By default there's
+// no cache.
+func SetCache(c Cache) {
+	cache = c
+}
+
+// CacheKey formats primary key values as a cache key.
+func CacheKey(values ...interface{}) string {
+	return joinValues(values, "\x00")
+}
+
+// cacheBypassed returns whether the cache is bypassed with ctx: in
+// transactions, which must see their own writes, and with settings (see
+// WithSetting), since the rows row-level security policies let them see
+// aren't the ones other contexts see.
+func cacheBypassed(ctx context.Context) bool {
+	return IsAtomic(ctx) || len(settingsFromContext(ctx)) != 0
+}
+
+// CacheGet looks up a row in the cache.
+// The cache is bypassed in transactions and with settings, see cacheBypassed.
+func CacheGet(ctx context.Context, model string, key string, dest interface{}) bool {
+	if cache == nil || cacheBypassed(ctx) {
+		return false
+	}
+	return cache.Get(ctx, tenantModel(ctx, model), key, dest)
+}
+
+// CacheSet stores a row in the cache. Rows read in transactions aren't
+// stored, since they might not be committed, nor rows read with settings,
+// nor rows read from the read replica, which can be older than the last
+// invalidation of the row. See CacheFillContext.
+func CacheSet(ctx context.Context, model string, key string, value interface{}) {
+	if cache == nil || cacheBypassed(ctx) {
+		return
+	}
+	if _, replica := readDB(ctx); replica {
+		return
+	}
+	cache.Set(ctx, tenantModel(ctx, model), key, value)
+}
+
+// CacheFillContext returns the context to read the rows stored with CacheSet
+// with: if there's a cache, reads are routed to the primary instead of the
+// read replica, so their rows can be stored.
+func CacheFillContext(ctx context.Context) context.Context {
+	if cache == nil || cacheBypassed(ctx) {
+		return ctx
+	}
+	return ForceWriter(ctx)
+}
+
+// CacheInvalidate removes a row from the cache. In a transaction, the row is
+// invalidated again when it commits, in case it was cached with its old value
+// in the meantime.
+func CacheInvalidate(ctx context.Context, model string, key string) {
+	if cache == nil {
+		return
+	}
+	model = tenantModel(ctx, model)
+	cache.Invalidate(ctx, model, key)
+	if IsAtomic(ctx) {
+		c := cache
+		OnCommit(ctx, func(ctx context.Context) error {
+			c.Invalidate(ctx, model, key)
+			return nil
+		})
+	}
+}
+
+// CacheInvalidateModel removes all rows of model from the cache, like CacheInvalidate.
+func CacheInvalidateModel(ctx context.Context, model string) {
+	if cache == nil {
+		return
+	}
+	model = tenantModel(ctx, model)
+	cache.InvalidateModel(ctx, model)
+	if IsAtomic(ctx) {
+		c := cache
+		OnCommit(ctx, func(ctx context.Context) error {
+			c.InvalidateModel(ctx, model)
+			return nil
+		})
+	}
+}
+
+// LRUCache is an in-memory Cache holding a bounded number of rows, evicting
+// the least recently used ones.
+//
+// Rows are stored as shallow copies, so slice and map fields are shared with
+// the values passed to Set and returned by Get.
+type LRUCache struct {
+	size int
+
+	mu    sync.Mutex
+	lru   *list.List
+	items map[lruKey]*list.Element
+}
+
+type lruKey struct {
+	model string
+	key   string
+}
+
+type lruEntry struct {
+	key   lruKey
+	value reflect.Value
+}
+
+var _ Cache = &LRUCache{}
+
+// NewLRUCache creates an LRUCache holding at most size rows.
+func NewLRUCache(size int) *LRUCache {
+	if size <= 0 {
+		panic("LRUCache size must be positive")
+	}
+	return &LRUCache{
+		size:  size,
+		lru:   list.New(),
+		items: make(map[lruKey]*list.Element),
+	}
+}
+
+func (c *LRUCache) Get(ctx context.Context, model string, key string, dest interface{}) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.items[lruKey{model, key}]
+	if !ok {
+		return false
+	}
+	c.lru.MoveToFront(e)
+	reflect.ValueOf(dest).Elem().Set(e.Value.(*lruEntry).value)
+	return true
+}
+
+func (c *LRUCache) Set(ctx context.Context, model string, key string, value interface{}) {
+	v := reflect.New(reflect.TypeOf(value).Elem()).Elem()
+	v.Set(reflect.ValueOf(value).Elem())
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	k := lruKey{model, key}
+	if e, ok := c.items[k]; ok {
+		e.Value.(*lruEntry).value = v
+		c.lru.MoveToFront(e)
+		return
+	}
+
+	c.items[k] = c.lru.PushFront(&lruEntry{key: k, value: v})
+	for c.lru.Len() > c.size {
+		e := c.lru.Back()
+		c.lru.Remove(e)
+		delete(c.items, e.Value.(*lruEntry).key)
+	}
+}
+
+func (c *LRUCache) Invalidate(ctx context.Context, model string, key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	k := lruKey{model, key}
+	if e, ok := c.items[k]; ok {
+		c.lru.Remove(e)
+		delete(c.items, k)
+	}
+}
+
+func (c *LRUCache) InvalidateModel(ctx context.Context, model string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for k, e := range c.items {
+		if k.model == model {
+			c.lru.Remove(e)
+			delete(c.items, k)
+		}
+	}
+}
+
+// Len returns the number of rows currently in the cache.
+func (c *LRUCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.lru.Len()
+}
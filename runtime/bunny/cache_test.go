@@ -0,0 +1,107 @@
+package bunny
+
+import (
+	"context"
+	"testing"
+
+	"gopkg.in/DATA-DOG/go-sqlmock.v2"
+)
+
+type cacheTestRow struct {
+	ID   int
+	Name string
+}
+
+func TestLRUCache(t *testing.T) {
+	ctx := context.Background()
+	c := NewLRUCache(2)
+
+	row := &cacheTestRow{ID: 1, Name: "a"}
+	c.Set(ctx, "row", CacheKey(1), row)
+	row.Name = "changed"
+
+	var got cacheTestRow
+	if !c.Get(ctx, "row", CacheKey(1), &got) {
+		t.Fatal("expected a cache hit")
+	}
+	if got.Name != "a" {
+		t.Errorf("expected a copy of the row, got %q", got.Name)
+	}
+
+	c.Set(ctx, "row", CacheKey(2), &cacheTestRow{ID: 2})
+	c.Set(ctx, "row", CacheKey(3), &cacheTestRow{ID: 3})
+	if c.Get(ctx, "row", CacheKey(1), &got) {
+		t.Error("expected row 1 to be evicted")
+	}
+	if c.Len() != 2 {
+		t.Errorf("expected 2 cached rows, got %d", c.Len())
+	}
+
+	c.Invalidate(ctx, "row", CacheKey(2))
+	if c.Get(ctx, "row", CacheKey(2), &got) {
+		t.Error("expected row 2 to be invalidated")
+	}
+
+	c.Set(ctx, "other", CacheKey(1), &cacheTestRow{ID: 1})
+	c.InvalidateModel(ctx, "row")
+	if c.Len() != 1 {
+		t.Errorf("expected 1 cached row, got %d", c.Len())
+	}
+}
+
+func TestCacheSettings(t *testing.T) {
+	defer SetCache(nil)
+	SetCache(NewLRUCache(10))
+
+	db, _, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := ContextWithDB(context.Background(), db)
+	user1 := WithSetting(ctx, "app.user_id", "1")
+	user2 := WithSetting(ctx, "app.user_id", "2")
+
+	var got cacheTestRow
+	CacheSet(user1, "row", CacheKey(1), &cacheTestRow{ID: 1, Name: "user 1"})
+	if CacheGet(user2, "row", CacheKey(1), &got) || CacheGet(user1, "row", CacheKey(1), &got) {
+		t.Error("expected rows read with settings not to be cached")
+	}
+
+	CacheSet(ctx, "row", CacheKey(1), &cacheTestRow{ID: 1, Name: "all"})
+	if CacheGet(user2, "row", CacheKey(1), &got) {
+		t.Error("expected the cache to be bypassed with settings")
+	}
+	if !CacheGet(ctx, "row", CacheKey(1), &got) || got.Name != "all" {
+		t.Errorf("expected a cache hit without settings, got %+v", got)
+	}
+}
+
+func TestCacheReplica(t *testing.T) {
+	defer SetCache(nil)
+	SetCache(NewLRUCache(10))
+
+	db, _, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	replica, _, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := ContextWithReplica(ContextWithDB(context.Background(), db), replica)
+
+	var got cacheTestRow
+	CacheSet(ctx, "row", CacheKey(1), &cacheTestRow{ID: 1, Name: "replica"})
+	if CacheGet(ctx, "row", CacheKey(1), &got) {
+		t.Error("expected rows read from the replica not to be cached")
+	}
+
+	fill := CacheFillContext(ctx)
+	if _, replica := readDB(fill); replica {
+		t.Error("expected cache fills to read from the primary")
+	}
+	CacheSet(fill, "row", CacheKey(1), &cacheTestRow{ID: 1, Name: "primary"})
+	if !CacheGet(ctx, "row", CacheKey(1), &got) || got.Name != "primary" {
+		t.Errorf("expected a cache hit, got %+v", got)
+	}
+}
@@ -0,0 +1,125 @@
+package bunny
+
+import (
+	"context"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// CommentOptions configures the comments appended to the statements run by
+// Exec, Query and QueryRow, in the sqlcommenter format:
+//
+//	SELECT * FROM "book" WHERE "id"=$1 /*action='find',application='api',model='book'*/
+//
+// so statements in pg_stat_statements and slow query logs can be attributed
+// to the code running them.
+type CommentOptions struct {
+	// Enabled turns the comments on.
+	Enabled bool
+
+	// Application is the name of the application, added as the
+	// application tag if it's not empty.
+	Application string
+
+	// Traceparent returns the W3C traceparent of the span in ctx, added as
+	// the traceparent tag if it's not empty. Statements with a trace are
+	// unique, so they don't share prepared statements in a StmtCache.
+	Traceparent func(ctx context.Context) string
+
+	// Fingerprint adds the fingerprint of the statements as the fingerprint
+	// tag, see Fingerprint. pg_stat_statements keeps the comment of the
+	// first statement of an entry, so its fingerprint maps the entry to the
+	// logs and metrics of its call site.
+	Fingerprint bool
+}
+
+var commentOptions CommentOptions
+
+// SetCommentOptions sets the comments appended to statements. By default
+// statements have no comments.
+func SetCommentOptions(opts CommentOptions) {
+	commentOptions = opts
+}
+
+type contextOperationKeyType struct{}
+
+var contextOperationKey = contextOperationKeyType{}
+
+type operation struct {
+	model  string
+	action string
+}
+
+// WithOperation tags the statements run with ctx with the model and the
+// action running them, like "book" and "insert". The generated code tags
+// its operations. The outermost operation is kept, so the statements of
+// Reload are tagged as reload and not as the find it calls.
+// The operation is also added to the QueryLogInfo of the statements.
+// Without comments enabled nor a logger ctx is returned as is.
+func WithOperation(ctx context.Context, model, action string) context.Context {
+	if !commentOptions.Enabled && logger == nil {
+		return ctx
+	}
+	if _, ok := ctx.Value(contextOperationKey).(operation); ok {
+		return ctx
+	}
+	return context.WithValue(ctx, contextOperationKey, operation{model: model, action: action})
+}
+
+// commentQuery appends the comment with the tags of ctx to query. Queries
+// with comments are returned as is, as sqlcommenter does.
+func commentQuery(ctx context.Context, query string) string {
+	if !commentOptions.Enabled || strings.Contains(query, "/*") {
+		return query
+	}
+
+	tags := map[string]string{}
+	if op, ok := ctx.Value(contextOperationKey).(operation); ok {
+		tags["model"] = op.model
+		tags["action"] = op.action
+	}
+	if commentOptions.Application != "" {
+		tags["application"] = commentOptions.Application
+	}
+	if commentOptions.Traceparent != nil {
+		if tp := commentOptions.Traceparent(ctx); tp != "" {
+			tags["traceparent"] = tp
+		}
+	}
+	if commentOptions.Fingerprint {
+		tags["fingerprint"] = queryFingerprint(ctx, query)
+	}
+	if len(tags) == 0 {
+		return query
+	}
+
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	trimmed := strings.TrimRight(query, "; \t\n")
+	b.WriteString(trimmed)
+	b.WriteString(" /*")
+	for i, k := range keys {
+		if i != 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(commentEscape(k))
+		b.WriteString("='")
+		b.WriteString(commentEscape(tags[k]))
+		b.WriteByte('\'')
+	}
+	b.WriteString("*/")
+	b.WriteString(query[len(trimmed):])
+	return b.String()
+}
+
+// commentEscape URL-encodes s, which escapes the quotes and the "*/" that
+// would end the comment.
+func commentEscape(s string) string {
+	return strings.Replace(url.QueryEscape(s), "+", "%20", -1)
+}
@@ -0,0 +1,71 @@
+package bunny
+
+import (
+	"context"
+	"testing"
+
+	"gopkg.in/DATA-DOG/go-sqlmock.v2"
+)
+
+func TestCommentQuery(t *testing.T) {
+	defer SetCommentOptions(CommentOptions{})
+
+	ctx := context.Background()
+	if q := commentQuery(WithOperation(ctx, "book", "find"), "SELECT 1"); q != "SELECT 1" {
+		t.Errorf("expected no comment when disabled, got %q", q)
+	}
+
+	SetCommentOptions(CommentOptions{
+		Enabled:     true,
+		Application: "api server",
+		Traceparent: func(ctx context.Context) string {
+			return "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+		},
+	})
+	opCtx := WithOperation(WithOperation(ctx, "book", "reload"), "book", "find")
+
+	tests := []struct {
+		ctx   context.Context
+		query string
+		want  string
+	}{
+		{
+			opCtx,
+			`SELECT * FROM "book";`,
+			`SELECT * FROM "book" /*action='reload',application='api%20server',model='book',traceparent='00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01'*/;`,
+		},
+		{
+			ctx,
+			`SELECT 1 /* tagged */`,
+			`SELECT 1 /* tagged */`,
+		},
+	}
+	for i, test := range tests {
+		if got := commentQuery(test.ctx, test.query); got != test.want {
+			t.Errorf("[%d] expected %q, got %q", i, test.want, got)
+		}
+	}
+
+	SetCommentOptions(CommentOptions{Enabled: true})
+	if got, want := commentQuery(WithOperation(ctx, "x*/y", "it's"), "SELECT 1"), "SELECT 1 /*action='it%27s',model='x%2A%2Fy'*/"; got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+}
+
+func TestCommentExec(t *testing.T) {
+	defer SetCommentOptions(CommentOptions{})
+	SetCommentOptions(CommentOptions{Enabled: true, Application: "api"})
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	mock.ExpectExec(`UPDATE a SET x = 1 /\*application='api'\*/`).WillReturnResult(sqlmock.NewResult(0, 1))
+
+	if _, err := Exec(ContextWithDB(context.Background(), db), "UPDATE a SET x = 1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,89 @@
+package bunny
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/sqlbunny/errors"
+)
+
+// CopyFrom bulk inserts n rows into table using the Postgres COPY protocol,
+// which is much faster than INSERT for large amounts of rows. The values of
+// the i-th row, in the order of columns, are returned by row(i).
+//
+// COPY must run in a transaction. If ctx is not already in one, CopyFrom
+// starts one with Atomic. The transactions of DBs with their own COPY
+// implementation, like pgxbunny.DB, run on a connection of their own, which
+// the copy runs on. table can be qualified with its schema.
+func CopyFrom(ctx context.Context, table string, columns []string, n int, row func(i int) []interface{}) error {
+	if err := checkShard(ctx); err != nil {
+		return err
+	}
+	tx, ok := DBFromContext(ctx).(*txNode)
+	if !ok {
+		return Atomic(ctx, func(ctx context.Context) error {
+			return CopyFrom(ctx, table, columns, n, row)
+		})
+	}
+	if tx.child != nil {
+		panic("Transaction has a subtransaction active, can't run statements in it.")
+	}
+	if _, err := tenantTx(ctx, tx); err != nil {
+		return err
+	}
+
+	query := copyIn(table, columns)
+	begin := time.Now()
+	var err error
+	if tx.copier != nil {
+		err = tx.copier.CopyFromConn(ctx, tx.conn, table, columns, n, row)
+	} else {
+		err = copyRows(ctx, tx, query, n, row)
+	}
+	logQuery(ctx, QueryLogInfo{
+		Query:    query,
+		Duration: time.Since(begin),
+		Err:      err,
+	})
+	return err
+}
+
+// copier is implemented by DBs that support COPY natively. CopyFromConn
+// runs the copy on conn, which is in the transaction CopyFrom is called in.
+type copier interface {
+	beginTxer
+	Conn(ctx context.Context) (*sql.Conn, error)
+	CopyFromConn(ctx context.Context, conn *sql.Conn, table string, columns []string, n int, row func(i int) []interface{}) error
+}
+
+// copyIn returns the COPY statement of table, which can be qualified with
+// its schema.
+func copyIn(table string, columns []string) string {
+	if i := strings.IndexByte(table, '.'); i >= 0 {
+		return pq.CopyInSchema(table[:i], table[i+1:], columns...)
+	}
+	return pq.CopyIn(table, columns...)
+}
+
+func copyRows(ctx context.Context, tx *txNode, query string, n int, row func(i int) []interface{}) error {
+	stmt, err := tx.dbTx.PrepareContext(ctx, query)
+	if err != nil {
+		return errors.Errorf("copy: %w", err)
+	}
+	defer stmt.Close()
+
+	for i := 0; i < n; i++ {
+		if _, err := stmt.ExecContext(ctx, row(i)...); err != nil {
+			return errors.Errorf("copy row %d: %w", i, err)
+		}
+	}
+
+	// Executing the statement without arguments flushes the buffered rows.
+	if _, err := stmt.ExecContext(ctx); err != nil {
+		return errors.Errorf("copy: %w", err)
+	}
+	return stmt.Close()
+}
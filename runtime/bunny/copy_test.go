@@ -0,0 +1,87 @@
+package bunny
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"gopkg.in/DATA-DOG/go-sqlmock.v2"
+)
+
+func TestCopyFrom(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rows := [][]interface{}{{1, "a"}, {2, "b"}}
+
+	mock.ExpectBegin()
+	prep := mock.ExpectPrepare(`COPY "user" \("id", "name"\) FROM STDIN`)
+	prep.ExpectExec().WithArgs(1, "a").WillReturnResult(sqlmock.NewResult(0, 0))
+	prep.ExpectExec().WithArgs(2, "b").WillReturnResult(sqlmock.NewResult(0, 0))
+	prep.ExpectExec().WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectCommit()
+
+	ctx := ContextWithDB(context.Background(), db)
+	err = CopyFrom(ctx, "user", []string{"id", "name"}, len(rows), func(i int) []interface{} {
+		return rows[i]
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+type connCopier struct {
+	*sql.DB
+	table string
+	rows  int
+	inTx  bool
+}
+
+func (c *connCopier) CopyFromConn(ctx context.Context, conn *sql.Conn, table string, columns []string, n int, row func(i int) []interface{}) error {
+	c.table = table
+	c.rows = n
+	// The transaction holds the connection, so the copy must run on it.
+	_, err := conn.ExecContext(ctx, "SELECT 1")
+	c.inTx = err == nil
+	return nil
+}
+
+func TestCopyFromConn(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`SELECT set_config\('search_path', \$1, true\)`).WithArgs(`"acme"`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`SELECT 1`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	c := &connCopier{DB: db}
+	ctx := WithTenant(ContextWithDB(context.Background(), c), "acme")
+	err = CopyFrom(ctx, "billing.invoice", []string{"id"}, 2, func(i int) []interface{} {
+		return []interface{}{i}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.table != "billing.invoice" || c.rows != 2 || !c.inTx {
+		t.Errorf("wrong copy: %+v", c)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestCopyIn(t *testing.T) {
+	if got, want := copyIn("billing.invoice", []string{"id"}), `COPY "billing"."invoice" ("id") FROM STDIN`; got != want {
+		t.Errorf("expected %s, got %s", want, got)
+	}
+}
@@ -3,10 +3,11 @@ package bunny
 import (
 	"context"
 	"database/sql"
+	"database/sql/driver"
 	"fmt"
-	"math/rand"
 	"time"
 
+	"github.com/go-sql-driver/mysql"
 	"github.com/lib/pq"
 	"github.com/sqlbunny/errors"
 )
@@ -35,96 +36,307 @@ func DBFromContext(ctx context.Context) DB {
 }
 
 func Exec(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	if err := checkShard(ctx); err != nil {
+		return nil, err
+	}
 	db := DBFromContext(ctx)
-	begin := time.Now()
-	res, err := db.ExecContext(ctx, query, args...)
-	if logger != nil {
-		logger.LogQuery(ctx, QueryLogInfo{
-			Query:    query,
-			Duration: time.Since(begin),
-			Err:      err,
-			Args:     args,
+	if ok, err := tenantTx(ctx, db); err != nil {
+		return nil, err
+	} else if !ok {
+		var res sql.Result
+		err := Atomic(ctx, func(ctx context.Context) error {
+			var err error
+			res, err = Exec(ctx, query, args...)
+			return err
 		})
+		return res, err
 	}
+	query = commentQuery(ctx, query)
+	debugQuery(ctx, query, args)
+	begin := time.Now()
+	res, err := intercept(db).ExecContext(ctx, query, args...)
+	logQuery(ctx, QueryLogInfo{
+		Query:    query,
+		Duration: time.Since(begin),
+		Err:      err,
+		Args:     args,
+	})
 	return res, err
 }
 
+// Query runs a query returning rows. Outside transactions, transient errors
+// are retried according to the RetryPolicy set with SetRetryPolicy.
 func Query(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
-	db := DBFromContext(ctx)
-	begin := time.Now()
-	res, err := db.QueryContext(ctx, query, args...)
-	if logger != nil {
-		logger.LogQuery(ctx, QueryLogInfo{
+	if err := checkShard(ctx); err != nil {
+		return nil, err
+	}
+	db, replica := readDB(ctx)
+	_, inTx := db.(*txNode)
+	policy := retryPolicy
+	if !readOnlyStatement(query) {
+		policy = RetryPolicy{}
+	}
+
+	if ok, err := tenantTx(ctx, db); err != nil {
+		return nil, err
+	} else if !ok {
+		return nil, ErrTenantOutsideTransaction
+	}
+
+	query = commentQuery(ctx, query)
+	debugQuery(ctx, query, args)
+	for try := 0; ; try++ {
+		begin := time.Now()
+		res, err := intercept(db).QueryContext(ctx, query, args...)
+		logQuery(ctx, QueryLogInfo{
 			Query:    query,
 			Duration: time.Since(begin),
 			Err:      err,
 			Args:     args,
+			Replica:  replica,
+			Attempt:  try,
 		})
+		if err == nil || inTx || try+1 >= policy.MaxAttempts || !IsTransientError(err) {
+			return res, err
+		}
+		if !policy.wait(ctx, policy.delay(try)) {
+			return res, err
+		}
 	}
-	return res, err
 }
 
+// QueryRow runs a query returning at most one row. Errors, including the
+// ones of a shard context which can't be routed, and ErrTenantOutsideTransaction
+// outside transactions with a tenant or settings, are returned by the Scan
+// of the row.
 func QueryRow(ctx context.Context, query string, args ...interface{}) *sql.Row {
-	db := DBFromContext(ctx)
-	begin := time.Now()
-	res := db.QueryRowContext(ctx, query, args...)
-	if logger != nil {
-		logger.LogQuery(ctx, QueryLogInfo{
-			Query:    query,
-			Duration: time.Since(begin),
-			Err:      nil, // TODO how to get the error without causing quantum decoherence in res?
-			Args:     args,
-		})
+	if err := checkShard(ctx); err != nil {
+		return ErrorRow(err)
+	}
+	db, replica := readDB(ctx)
+	if ok, err := tenantTx(ctx, db); err != nil {
+		return ErrorRow(err)
+	} else if !ok {
+		return ErrorRow(ErrTenantOutsideTransaction)
 	}
+	query = commentQuery(ctx, query)
+	debugQuery(ctx, query, args)
+	begin := time.Now()
+	res := intercept(db).QueryRowContext(ctx, query, args...)
+	logQuery(ctx, QueryLogInfo{
+		Query:    query,
+		Duration: time.Since(begin),
+		Err:      nil, // TODO how to get the error without causing quantum decoherence in res?
+		Args:     args,
+		Replica:  replica,
+	})
 	return res
 }
 
+// errConnector is a driver.Connector failing with err, see ErrorRow.
+type errConnector struct {
+	err error
+}
+
+func (c errConnector) Connect(context.Context) (driver.Conn, error) { return nil, c.err }
+func (c errConnector) Driver() driver.Driver                        { return nil }
+
+// ErrorRow returns a row whose Scan returns err, for the functions returning
+// a row which fail before running their query. database/sql has no other way
+// to build one.
+func ErrorRow(err error) *sql.Row {
+	db := sql.OpenDB(errConnector{err})
+	defer db.Close()
+	return db.QueryRow("")
+}
+
+// QueryRowScan runs a query returning at most one row and scans it into dest,
+// returning ErrNoRows if there's none. Unlike QueryRow, it can be used outside
+// transactions with a tenant context (see WithTenant).
+func QueryRowScan(ctx context.Context, query string, args []interface{}, dest ...interface{}) error {
+	if err := checkShard(ctx); err != nil {
+		return err
+	}
+	return AtomicTenant(ctx, func(ctx context.Context) error {
+		return QueryRow(ctx, query, args...).Scan(dest...)
+	})
+}
+
 // Atomic invokes the passed function in the context of a managed SQL
 // transaction.  Any errors returned from the user-supplied function are
 // returned from this function.
 //
-// Retries are automatically performed in case of serialization failures or deadlocks.
+// Retries are automatically performed in case of serialization failures or deadlocks,
+// according to the RetryPolicy set with SetTxRetryPolicy. This includes
+// CockroachDB's transaction restart errors, which it reports as serialization
+// failures.
+//
+// If ctx is already in a transaction, fn runs in a savepoint instead: if it
+// returns an error only its changes are rolled back, and the outer
+// transaction can carry on.
 func Atomic(ctx context.Context, fn func(ctx context.Context) error) error {
-	return doAtomic(ctx, fn, false)
+	return doAtomic(ctx, fn, TxOptions{})
 }
 
 // AtomicReadOnly invokes the passed function in the context of a managed SQL
 // read only transaction. Any errors returned from the user-supplied function
 // are returned from this function.
 //
-// Retries are automatically performed in case of serialization failures or deadlocks.
+// Retries are automatically performed in case of serialization failures or deadlocks,
+// like Atomic.
 func AtomicReadOnly(ctx context.Context, fn func(ctx context.Context) error) error {
-	return doAtomic(ctx, fn, true)
+	return doAtomic(ctx, fn, TxOptions{ReadOnly: true})
 }
 
-func doAtomic(ctx context.Context, fn func(ctx context.Context) error, readOnly bool) error {
-	var err error
-	for try := uint(0); try < 12; try++ {
-		err = doTransaction(ctx, fn, readOnly)
-		if err == nil {
-			return nil
-		}
+// AtomicRead invokes the passed function in a read only transaction at
+// repeatable read, so its queries see the same snapshot of the database. It
+// groups reads which must be consistent with each other more cheaply than
+// Atomic, and runs on the replica of ctx, unless it's from ForceWriter. See
+// ContextWithReplica.
+//
+// Reads which must also be serializable run on the primary with a
+// deferrable transaction, which waits for a snapshot free of serialization
+// failures instead of being retried:
+//
+//	bunny.AtomicWith(ctx, bunny.TxOptions{ReadOnly: true, Deferrable: true}, fn)
+func AtomicRead(ctx context.Context, fn func(ctx context.Context) error) error {
+	return doAtomic(ctx, fn, TxOptions{Isolation: RepeatableRead, ReadOnly: true, Replica: true})
+}
+
+// IsolationLevel is a transaction isolation level.
+type IsolationLevel int
+
+const (
+	// Serializable is the default isolation level of Atomic.
+	Serializable IsolationLevel = iota
+	RepeatableRead
+	ReadCommitted
+)
+
+func (l IsolationLevel) sqlLevel() sql.IsolationLevel {
+	switch l {
+	case RepeatableRead:
+		return sql.LevelRepeatableRead
+	case ReadCommitted:
+		return sql.LevelReadCommitted
+	default:
+		return sql.LevelSerializable
+	}
+}
+
+func (l IsolationLevel) String() string {
+	return l.sqlLevel().String()
+}
 
-		if !shouldRetryTransaction(err) {
+// TxOptions configures the transaction started by AtomicWith.
+// The zero value gives the same transaction as Atomic.
+type TxOptions struct {
+	Isolation IsolationLevel
+	ReadOnly  bool
+
+	// Deferrable makes a serializable read only transaction wait for a
+	// snapshot on which it can't fail to serialize, in Postgres. It's
+	// ignored by other transactions.
+	Deferrable bool
+
+	// Replica runs a read only transaction on the replica of the context,
+	// like the reads made outside transactions, see ContextWithReplica.
+	Replica bool
+
+	// MaxAttempts overrides the MaxAttempts of the policy set with
+	// SetTxRetryPolicy if non zero. 1 disables retrying, for functions with
+	// side effects outside the database.
+	MaxAttempts int
+}
+
+// AtomicWith is like Atomic, but starts the transaction with the passed options.
+//
+// If ctx is already in a transaction the options are ignored, since fn runs
+// in a savepoint of the existing transaction.
+func AtomicWith(ctx context.Context, opts TxOptions, fn func(ctx context.Context) error) error {
+	return doAtomic(ctx, fn, opts)
+}
+
+// BeginRollback starts a transaction in the database of ctx which is never
+// committed. It returns the context running in the transaction, where
+// Atomic blocks run in savepoints, and the function rolling it back. The
+// OnCommit hooks of the transaction never run.
+//
+// It isolates the writes of tests, see bunnytest.WithRollback.
+func BeginRollback(ctx context.Context) (context.Context, func() error, error) {
+	db, ok := DBFromContext(ctx).(beginTxer)
+	if !ok {
+		return nil, nil, errors.New("sqlbunny: database does not support transactions")
+	}
+	node, err := beginTx(ctx, db, nil)
+	if err != nil {
+		return nil, nil, errors.Errorf("BeginTx failed: %w", err)
+	}
+	node.shard, node.sharded = ShardFromContext(ctx)
+	if c, ok := db.(*StmtCache); ok {
+		node.stmts = c
+	}
+	return ContextWithDB(ctx, node), node.Rollback, nil
+}
+
+func doAtomic(ctx context.Context, fn func(ctx context.Context) error, opts TxOptions) error {
+	if err := checkShard(ctx); err != nil {
+		return err
+	}
+	if IsAtomic(ctx) {
+		// Nested blocks run in a savepoint. Serialization failures and
+		// deadlocks can't be fixed by retrying just the savepoint, so leave
+		// them to the outermost block, which retries the whole transaction.
+		return doTransaction(ctx, fn, opts, 0)
+	}
+
+	policy := txRetryPolicy
+	if opts.MaxAttempts != 0 {
+		policy.MaxAttempts = opts.MaxAttempts
+	}
+	for try := 0; ; try++ {
+		err := doTransaction(ctx, fn, opts, try)
+		if err == nil || try+1 >= policy.MaxAttempts || !shouldRetryTransaction(err) {
+			return err
+		}
+		if !policy.wait(ctx, policy.delay(try)) {
 			return err
 		}
-
-		time.Sleep(time.Millisecond * time.Duration(rand.Int31n(1<<try)))
 	}
-	return err
 }
 
 func shouldRetryTransaction(err error) bool {
+	switch errorCode(err) {
+	case "40001": // serialization_failures
+		return true
+	case "40P01": // deadlock_detected
+		return true
+	}
+	return false
+}
+
+// errorCode returns the SQLSTATE code of a database error, or "" if err
+// doesn't come from the database. lib/pq, pgx and go-sql-driver/mysql errors
+// are supported.
+func errorCode(err error) string {
 	var pqerr *pq.Error
 	if errors.As(err, &pqerr) {
-		switch pqerr.Code {
-		case "40001": // serialization_failures
-			return true
-		case "40P01": // deadlock_detected
-			return true
+		return string(pqerr.Code)
+	}
+	var myerr *mysql.MySQLError
+	if errors.As(err, &myerr) {
+		if myerr.SQLState == [5]byte{} {
+			return ""
 		}
+		return string(myerr.SQLState[:])
 	}
-	return false
+	var stateErr interface {
+		SQLState() string
+	}
+	if errors.As(err, &stateErr) {
+		return stateErr.SQLState()
+	}
+	return ""
 }
 
 // rollbackOnPanic rolls the passed transaction back if the code in the calling
@@ -149,42 +361,84 @@ func rollbackOnPanic(ctx context.Context, tx *txNode, begin time.Time) {
 
 type txNode struct {
 	dbTx     *sql.Tx
+	stmts    *StmtCache
 	parent   *txNode
 	child    *txNode
 	depth    int
 	onCommit []func(context.Context) error
+
+	// Tenant whose schema is selected, see WithTenant.
+	tenant string
+	// Settings set in the transaction, see WithSetting. The map is shared
+	// with the savepoints and never changed.
+	settings map[string]string
+	// Shard the transaction runs in, see WithShardKey.
+	shard   int
+	sharded bool
+	// Connection the transaction runs on, and the DB whose COPY
+	// implementation runs on it, see beginTx.
+	conn   *sql.Conn
+	copier copier
 }
 
 func (t *txNode) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
 	if t.child != nil {
 		panic("Transaction has a subtransaction active, can't run statements in it.")
 	}
+	if t.stmts != nil && useStmtCache(ctx, args) {
+		stmt, release, err := t.stmts.txStmt(ctx, t.dbTx, query)
+		if err != nil {
+			return nil, err
+		}
+		defer release()
+		return stmt.ExecContext(ctx, args...)
+	}
 	return t.dbTx.ExecContext(ctx, query, args...)
 }
 func (t *txNode) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
 	if t.child != nil {
 		panic("Transaction has a subtransaction active, can't run statements in it.")
 	}
+	if t.stmts != nil && useStmtCache(ctx, args) {
+		stmt, release, err := t.stmts.txStmt(ctx, t.dbTx, query)
+		if err != nil {
+			return nil, err
+		}
+		defer release()
+		return stmt.QueryContext(ctx, args...)
+	}
 	return t.dbTx.QueryContext(ctx, query, args...)
 }
 func (t *txNode) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
 	if t.child != nil {
 		panic("Transaction has a subtransaction active, can't run statements in it.")
 	}
+	if t.stmts != nil && useStmtCache(ctx, args) {
+		stmt, release, err := t.stmts.txStmt(ctx, t.dbTx, query)
+		if err == nil {
+			defer release()
+			return stmt.QueryRowContext(ctx, args...)
+		}
+	}
 	return t.dbTx.QueryRowContext(ctx, query, args...)
 }
 
 func (t *txNode) Commit() error {
 	if t.parent == nil {
+		defer t.closeConn()
 		return t.dbTx.Commit()
 	}
 	_, err := t.dbTx.Exec(fmt.Sprintf("RELEASE SAVEPOINT savepoint_%d", t.depth))
 	t.parent.child = nil
+	// The tenant and settings selected in the savepoint outlive it.
+	t.parent.tenant = t.tenant
+	t.parent.settings = t.settings
 	return err
 }
 
 func (t *txNode) Rollback() error {
 	if t.parent == nil {
+		defer t.closeConn()
 		return t.dbTx.Rollback()
 	}
 	_, err := t.dbTx.Exec(fmt.Sprintf("ROLLBACK TO SAVEPOINT savepoint_%d", t.depth))
@@ -192,6 +446,13 @@ func (t *txNode) Rollback() error {
 	return err
 }
 
+// closeConn releases the connection of the transaction once it's ended.
+func (t *txNode) closeConn() {
+	if t.conn != nil {
+		t.conn.Close()
+	}
+}
+
 func (t *txNode) runOnCommit(ctx context.Context) error {
 	if t.parent != nil {
 		// If we're in a subtransaction, we don't want to execute the onCommits yet.
@@ -216,24 +477,61 @@ type beginTxer interface {
 	BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error)
 }
 
+// beginTx begins a transaction in db. Transactions of DBs with their own
+// COPY implementation are begun on a connection of their own, which
+// CopyFrom runs on, and which is released when they end.
+func beginTx(ctx context.Context, db beginTxer, opts *sql.TxOptions) (*txNode, error) {
+	c, ok := db.(copier)
+	if !ok {
+		tx, err := db.BeginTx(ctx, opts)
+		if err != nil {
+			return nil, err
+		}
+		return &txNode{dbTx: tx}, nil
+	}
+	conn, err := c.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	tx, err := conn.BeginTx(ctx, opts)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return &txNode{dbTx: tx, conn: conn, copier: c}, nil
+}
+
 // Transaction invokes the passed function in the context of a managed SQL
 // transaction.  Any errors returned from
 // the user-supplied function are returned from this function.
-func doTransaction(ctx context.Context, fn func(ctx context.Context) error, readOnly bool) error {
+func doTransaction(ctx context.Context, fn func(ctx context.Context) error, opts TxOptions, attempt int) error {
 	if logger != nil {
 		ctx = logger.LogBegin(ctx, BeginLogInfo{
-			ReadOnly: readOnly,
+			ReadOnly:  opts.ReadOnly,
+			Isolation: opts.Isolation,
+			Attempt:   attempt,
 		})
 	}
 	begin := time.Now()
 
+	db := DBFromContext(ctx)
+	if opts.Replica && opts.ReadOnly {
+		db, _ = readDB(ctx)
+	}
+
 	var node *txNode
-	switch db := DBFromContext(ctx).(type) {
+	switch db := db.(type) {
 	case beginTxer:
-		tx, err := db.BeginTx(ctx, &sql.TxOptions{
-			Isolation: sql.LevelSerializable,
-			ReadOnly:  readOnly,
+		var err error
+		node, err = beginTx(ctx, db, &sql.TxOptions{
+			Isolation: opts.Isolation.sqlLevel(),
+			ReadOnly:  opts.ReadOnly,
 		})
+		if err == nil && opts.Deferrable && opts.ReadOnly && opts.Isolation == Serializable {
+			if _, err = node.dbTx.ExecContext(ctx, "SET TRANSACTION DEFERRABLE"); err != nil {
+				_ = node.Rollback()
+			}
+		}
 		if err != nil {
 			retErr := errors.Errorf("BeginTx failed: %w", err)
 			if logger != nil {
@@ -244,15 +542,22 @@ func doTransaction(ctx context.Context, fn func(ctx context.Context) error, read
 			}
 			return retErr
 		}
-		node = &txNode{
-			dbTx:  tx,
-			depth: 0,
+		node.shard, node.sharded = ShardFromContext(ctx)
+		if c, ok := db.(*StmtCache); ok {
+			node.stmts = c
 		}
 	case *txNode:
 		node = &txNode{
-			dbTx:   db.dbTx,
-			parent: db,
-			depth:  db.depth + 1,
+			dbTx:     db.dbTx,
+			stmts:    db.stmts,
+			parent:   db,
+			depth:    db.depth + 1,
+			tenant:   db.tenant,
+			settings: db.settings,
+			shard:    db.shard,
+			sharded:  db.sharded,
+			conn:     db.conn,
+			copier:   db.copier,
 		}
 		_, err := db.dbTx.Exec(fmt.Sprintf("SAVEPOINT savepoint_%d", node.depth))
 		if err != nil {
@@ -0,0 +1,139 @@
+package bunny
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/lib/pq"
+	"gopkg.in/DATA-DOG/go-sqlmock.v2"
+)
+
+func TestAtomicNestedSavepoint(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE a`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`SAVEPOINT savepoint_1`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`UPDATE b`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`ROLLBACK TO SAVEPOINT savepoint_1`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`SAVEPOINT savepoint_1`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`UPDATE c`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`RELEASE SAVEPOINT savepoint_1`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	ctx := ContextWithDB(context.Background(), db)
+	errInner := errors.New("inner")
+
+	err = Atomic(ctx, func(ctx context.Context) error {
+		if _, err := Exec(ctx, "UPDATE a SET x = 1"); err != nil {
+			return err
+		}
+		err := Atomic(ctx, func(ctx context.Context) error {
+			if _, err := Exec(ctx, "UPDATE b SET x = 1"); err != nil {
+				return err
+			}
+			return errInner
+		})
+		if !errors.Is(err, errInner) {
+			t.Errorf("expected inner error, got %v", err)
+		}
+		return Atomic(ctx, func(ctx context.Context) error {
+			_, err := Exec(ctx, "UPDATE c SET x = 1")
+			return err
+		})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestAtomicNestedRetriesOutermost(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`SAVEPOINT savepoint_1`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`UPDATE a`).WillReturnError(&pq.Error{Code: "40001"})
+	mock.ExpectExec(`ROLLBACK TO SAVEPOINT savepoint_1`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+	mock.ExpectBegin()
+	mock.ExpectExec(`SAVEPOINT savepoint_1`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`UPDATE a`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`RELEASE SAVEPOINT savepoint_1`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	ctx := ContextWithDB(context.Background(), db)
+
+	err = Atomic(ctx, func(ctx context.Context) error {
+		return Atomic(ctx, func(ctx context.Context) error {
+			_, err := Exec(ctx, "UPDATE a SET x = 1")
+			return err
+		})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestAtomicWith(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// sqlmock has no expectations on transaction options, so just check that
+	// the transaction goes through.
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT 1`).WillReturnRows(sqlmock.NewRows([]string{"x"}).AddRow(1))
+	mock.ExpectCommit()
+
+	ctx := ContextWithDB(context.Background(), db)
+	err = AtomicWith(ctx, TxOptions{Isolation: ReadCommitted, ReadOnly: true}, func(ctx context.Context) error {
+		var x int
+		return QueryRow(ctx, "SELECT 1").Scan(&x)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestAtomicWithDeferrable(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	mock.ExpectBegin()
+	mock.ExpectExec(`SET TRANSACTION DEFERRABLE`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(`SELECT 1`).WillReturnRows(sqlmock.NewRows([]string{"x"}).AddRow(1))
+	mock.ExpectCommit()
+
+	ctx := ContextWithDB(context.Background(), db)
+	err = AtomicWith(ctx, TxOptions{ReadOnly: true, Deferrable: true}, func(ctx context.Context) error {
+		var x int
+		return QueryRow(ctx, "SELECT 1").Scan(&x)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,205 @@
+package bunny
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+type contextDebugKeyType struct{}
+
+var contextDebugKey = contextDebugKeyType{}
+
+// WithDebug returns a context in which the statements run by Exec, Query
+// and QueryRow are written to w, or to stderr if w is nil, with their
+// arguments interpolated so they can be pasted into psql:
+//
+//	SELECT * FROM "book" WHERE "id"='b1' AND "status"=1;
+//
+// Statements whose arguments can't be interpolated are written with the
+// arguments after them.
+func WithDebug(ctx context.Context, w io.Writer) context.Context {
+	if w == nil {
+		w = os.Stderr
+	}
+	return context.WithValue(ctx, contextDebugKey, w)
+}
+
+func debugQuery(ctx context.Context, query string, args []interface{}) {
+	w, ok := ctx.Value(contextDebugKey).(io.Writer)
+	if !ok {
+		return
+	}
+	sql, err := InterpolateQuery(query, args)
+	if err != nil {
+		fmt.Fprintf(w, "%s; -- %v, args: %v\n", query, err, args)
+		return
+	}
+	fmt.Fprintf(w, "%s;\n", sql)
+}
+
+// InterpolateQuery returns query with its placeholders, $1 style or ? style,
+// replaced by the SQL literals of args. Placeholders in string literals,
+// quoted identifiers, comments and dollar-quoted strings are left as is, and
+// ? are only placeholders in queries without $1 style ones.
+//
+// It's meant for debugging: the literals are escaped, but queries must still
+// be run with their arguments.
+func InterpolateQuery(query string, args []interface{}) (string, error) {
+	literals := make([]string, len(args))
+	for i, arg := range args {
+		l, err := sqlLiteral(arg)
+		if err != nil {
+			return "", fmt.Errorf("argument %d: %w", i+1, err)
+		}
+		literals[i] = l
+	}
+
+	// Postgres queries can have ? operators, so ? are only placeholders in
+	// queries without $1 style ones.
+	indexed := false
+	for i := 0; i < len(query) && !indexed; i++ {
+		if end := quotedEnd(query, i); end != -1 {
+			i = end
+			continue
+		}
+		indexed = query[i] == '$' && i+1 < len(query) && isDigit(query[i+1])
+	}
+	if !indexed && len(args) == 0 {
+		return query, nil
+	}
+
+	var b strings.Builder
+	next := 0
+	for i := 0; i < len(query); i++ {
+		if end := quotedEnd(query, i); end != -1 {
+			b.WriteString(query[i : end+1])
+			i = end
+			continue
+		}
+		c := query[i]
+		switch {
+		case c == '$' && i+1 < len(query) && isDigit(query[i+1]):
+			end := i + 1
+			for end < len(query) && isDigit(query[end]) {
+				end++
+			}
+			n, _ := strconv.Atoi(query[i+1 : end])
+			if n < 1 || n > len(literals) {
+				return "", fmt.Errorf("placeholder $%d has no argument", n)
+			}
+			b.WriteString(literals[n-1])
+			i = end - 1
+		case c == '?' && !indexed:
+			if next >= len(literals) {
+				return "", fmt.Errorf("placeholder %d has no argument", next+1)
+			}
+			b.WriteString(literals[next])
+			next++
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String(), nil
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// quotedEnd returns the index of the last byte of the string literal,
+// quoted identifier, comment or dollar-quoted string starting at i in query,
+// or -1 if there's none. Unterminated ones end with the query.
+func quotedEnd(query string, i int) int {
+	c := query[i]
+	switch {
+	case c == '\'' || c == '"' || c == '`':
+		// Doubled quotes are part of it.
+		end := i + 1
+		for end < len(query) {
+			if query[end] == c {
+				if end+1 < len(query) && query[end+1] == c {
+					end += 2
+					continue
+				}
+				return end
+			}
+			end++
+		}
+		return len(query) - 1
+	case strings.HasPrefix(query[i:], "--"):
+		end := strings.IndexByte(query[i:], '\n')
+		if end == -1 {
+			return len(query) - 1
+		}
+		return i + end
+	case strings.HasPrefix(query[i:], "/*"):
+		end := strings.Index(query[i+2:], "*/")
+		if end == -1 {
+			return len(query) - 1
+		}
+		return i + 2 + end + 1
+	case c == '$':
+		// $$ or $tag$, like the bodies of functions.
+		j := i + 1
+		for j < len(query) && (query[j] == '_' || unicode.IsLetter(rune(query[j])) || j > i+1 && isDigit(query[j])) {
+			j++
+		}
+		if j == len(query) || query[j] != '$' {
+			return -1
+		}
+		tag := query[i : j+1]
+		end := strings.Index(query[j+1:], tag)
+		if end == -1 {
+			return len(query) - 1
+		}
+		return j + 1 + end + len(tag) - 1
+	}
+	return -1
+}
+
+// sqlLiteral returns the SQL literal of the driver value of v.
+func sqlLiteral(v interface{}) (string, error) {
+	v, err := driver.DefaultParameterConverter.ConvertValue(v)
+	if err != nil {
+		return "", err
+	}
+	switch v := v.(type) {
+	case nil:
+		return "NULL", nil
+	case bool:
+		if v {
+			return "TRUE", nil
+		}
+		return "FALSE", nil
+	case int64:
+		return strconv.FormatInt(v, 10), nil
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64), nil
+	case string:
+		return quoteLiteral(v), nil
+	case []byte:
+		return "'\\x" + hex.EncodeToString(v) + "'", nil
+	case time.Time:
+		return quoteLiteral(v.Format("2006-01-02 15:04:05.999999999Z07:00")), nil
+	}
+	return "", fmt.Errorf("unsupported value %T", v)
+}
+
+// quoteLiteral quotes s as a SQL string literal, with standard conforming
+// strings. Strings with backslashes use the E'...' syntax, so they're read the
+// same without standard conforming strings.
+func quoteLiteral(s string) string {
+	s = strings.ReplaceAll(s, "'", "''")
+	if strings.Contains(s, `\`) {
+		return "E'" + strings.ReplaceAll(s, `\`, `\\`) + "'"
+	}
+	return "'" + s + "'"
+}
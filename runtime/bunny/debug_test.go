@@ -0,0 +1,69 @@
+package bunny
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/sqlbunny/sqlbunny/types/null"
+	"gopkg.in/DATA-DOG/go-sqlmock.v2"
+)
+
+func TestInterpolateQuery(t *testing.T) {
+	at := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		query string
+		args  []interface{}
+		want  string
+	}{
+		{`SELECT * FROM "book" WHERE "id"=$1 AND "price">$2`, []interface{}{"b1", 10}, `SELECT * FROM "book" WHERE "id"='b1' AND "price">10`},
+		{`SELECT $2, $1, $1`, []interface{}{true, nil}, `SELECT NULL, TRUE, TRUE`},
+		{`SELECT $1`, []interface{}{"it's"}, `SELECT 'it''s'`},
+		{`SELECT $1`, []interface{}{`a\b`}, `SELECT E'a\\b'`},
+		{`SELECT $1`, []interface{}{[]byte{0xde, 0xad}}, `SELECT '\xdead'`},
+		{`SELECT $1`, []interface{}{at}, `SELECT '2020-01-02 03:04:05Z'`},
+		{`SELECT $1`, []interface{}{null.String{}}, `SELECT NULL`},
+		{`SELECT '$1', "$1", $1 /* $1 */`, []interface{}{1.5}, `SELECT '$1', "$1", 1.5 /* $1 */`},
+		{`SELECT data ? 'key' FROM a WHERE id=$1`, []interface{}{1}, `SELECT data ? 'key' FROM a WHERE id=1`},
+		{"SELECT * FROM `book` WHERE `id`=? AND `title`='?'", []interface{}{"b1"}, "SELECT * FROM `book` WHERE `id`='b1' AND `title`='?'"},
+		{"SELECT * FROM `book` WHERE `title`='$1' AND `id`=?", []interface{}{"b1"}, "SELECT * FROM `book` WHERE `title`='$1' AND `id`='b1'"},
+		{`DO $body$ BEGIN PERFORM $1; END $body$; SELECT ?`, []interface{}{2}, `DO $body$ BEGIN PERFORM $1; END $body$; SELECT 2`},
+		{`SELECT $$ $1 $$, $1`, []interface{}{2}, `SELECT $$ $1 $$, 2`},
+		{`SELECT data ? 'key' FROM a`, nil, `SELECT data ? 'key' FROM a`},
+	}
+
+	for i, test := range tests {
+		got, err := InterpolateQuery(test.query, test.args)
+		if err != nil {
+			t.Errorf("[%d] unexpected error: %v", i, err)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("[%d] wrong query:\nwant: %s\ngot:  %s", i, test.want, got)
+		}
+	}
+
+	if _, err := InterpolateQuery(`SELECT $2`, []interface{}{1}); err == nil {
+		t.Error("expected an error for a placeholder without argument")
+	}
+}
+
+func TestWithDebug(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	mock.ExpectExec(`UPDATE a`).WillReturnResult(sqlmock.NewResult(0, 1))
+
+	var buf bytes.Buffer
+	ctx := WithDebug(ContextWithDB(context.Background(), db), &buf)
+	if _, err := Exec(ctx, "UPDATE a SET x = $1", "y"); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := buf.String(), "UPDATE a SET x = 'y';\n"; got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+}
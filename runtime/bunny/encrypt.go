@@ -0,0 +1,199 @@
+package bunny
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql/driver"
+
+	"github.com/sqlbunny/errors"
+)
+
+// KeyProvider provides the AES keys (16, 24 or 32 bytes) of the encrypted
+// columns, like LocalKeys or a KMS client caching its data keys. Values
+// store the ID of the key encrypting them, so keys can be rotated: new
+// values are encrypted with the current key, and old ones are decrypted
+// with the key they were encrypted with.
+type KeyProvider interface {
+	// CurrentKey returns the key encrypting new values, and its ID.
+	CurrentKey() (id string, key []byte, err error)
+	// Key returns the key with id.
+	Key(id string) ([]byte, error)
+}
+
+var keyProvider KeyProvider
+
+// SetKeyProvider sets the provider of the keys of the encrypted columns.
+// Reading or writing them fails with ErrNoKeyProvider until it's set.
+func SetKeyProvider(p KeyProvider) {
+	keyProvider = p
+}
+
+// ErrNoKeyProvider is returned when encrypted columns are read or written
+// before SetKeyProvider is called.
+var ErrNoKeyProvider = errors.New("sqlbunny: no key provider set for encrypted columns")
+
+// ErrDecrypt is returned when a value of an encrypted column can't be
+// decrypted, because it's invalid or was encrypted with another key.
+var ErrDecrypt = errors.New("sqlbunny: unable to decrypt value")
+
+// LocalKeys is a KeyProvider with the keys in memory, by ID.
+type LocalKeys struct {
+	// Current is the ID of the current key.
+	Current string
+	Keys    map[string][]byte
+}
+
+var _ KeyProvider = LocalKeys{}
+
+func (k LocalKeys) CurrentKey() (string, []byte, error) {
+	key, err := k.Key(k.Current)
+	return k.Current, key, err
+}
+
+func (k LocalKeys) Key(id string) ([]byte, error) {
+	key, ok := k.Keys[id]
+	if !ok {
+		return nil, errors.Errorf("sqlbunny: unknown encryption key '%s'", id)
+	}
+	return key, nil
+}
+
+// encryptionVersion is the first byte of encrypted values, identifying
+// their format: the version, the length of the key ID and the key ID, the
+// AES-GCM nonce and the sealed value.
+const encryptionVersion = 1
+
+// Encrypted is a value written to an encrypted column: its Value method
+// encrypts Plaintext, a string or []byte or a driver.Valuer returning one,
+// with the current key. The generated code writes the fields of encrypted
+// columns with it.
+//
+// Deterministic encryption derives the nonce from the plaintext, so equal
+// values encrypted with the same key are equal: it's the HMAC-SHA256 of the
+// plaintext, truncated to the nonce size, keyed with a subkey of the key,
+// see nonceKey, so the key isn't used by both HMAC and AES-GCM. The columns of fields with
+// EncryptedDeterministic can be compared with Encrypted arguments:
+//
+//	qm.Where("email = ?", bunny.Encrypted{Plaintext: email, Deterministic: true})
+//
+// Values encrypted with a key which is not the current key anymore only
+// match once they're written again.
+type Encrypted struct {
+	Plaintext     interface{}
+	Deterministic bool
+}
+
+var _ driver.Valuer = Encrypted{}
+
+// String returns RedactedText, so loggers printing the query arguments
+// don't reveal the plaintext.
+func (e Encrypted) String() string {
+	return RedactedText
+}
+
+// GoString returns RedactedText, like String.
+func (e Encrypted) GoString() string {
+	return RedactedText
+}
+
+// Value implements the driver.Valuer interface. Null values stay null.
+func (e Encrypted) Value() (driver.Value, error) {
+	v := e.Plaintext
+	if valuer, ok := v.(driver.Valuer); ok {
+		var err error
+		if v, err = valuer.Value(); err != nil {
+			return nil, err
+		}
+	}
+	var plaintext []byte
+	switch v := v.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		plaintext = []byte(v)
+	case []byte:
+		plaintext = v
+	default:
+		return nil, errors.Errorf("sqlbunny: unable to encrypt value of type %T, it must be a string or []byte", v)
+	}
+
+	if keyProvider == nil {
+		return nil, ErrNoKeyProvider
+	}
+	id, key, err := keyProvider.CurrentKey()
+	if err != nil {
+		return nil, err
+	}
+	if len(id) > 255 {
+		return nil, errors.Errorf("sqlbunny: encryption key ID '%s' is too long", id)
+	}
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if e.Deterministic {
+		mac := hmac.New(sha256.New, nonceKey(key))
+		mac.Write(plaintext)
+		copy(nonce, mac.Sum(nil))
+	} else if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	res := make([]byte, 0, 2+len(id)+len(nonce)+len(plaintext)+aead.Overhead())
+	res = append(res, encryptionVersion, byte(len(id)))
+	res = append(res, id...)
+	res = append(res, nonce...)
+	return aead.Seal(res, nonce, plaintext, []byte(id)), nil
+}
+
+// nonceKey returns the key of the HMAC deriving the nonces of deterministic
+// encryption with key: HMAC-SHA256(key, "sqlbunny nonce").
+func nonceKey(key []byte) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte("sqlbunny nonce"))
+	return mac.Sum(nil)
+}
+
+// Decrypt returns the plaintext of a value of an encrypted column, written
+// by Encrypted.
+func Decrypt(value []byte) ([]byte, error) {
+	if len(value) < 2 || value[0] != encryptionVersion || len(value) < 2+int(value[1]) {
+		return nil, ErrDecrypt
+	}
+	n := 2 + int(value[1])
+	id := string(value[2:n])
+	value = value[n:]
+
+	if keyProvider == nil {
+		return nil, ErrNoKeyProvider
+	}
+	key, err := keyProvider.Key(id)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(value) < aead.NonceSize() {
+		return nil, ErrDecrypt
+	}
+	plaintext, err := aead.Open(nil, value[:aead.NonceSize()], value[aead.NonceSize():], []byte(id))
+	if err != nil {
+		return nil, ErrDecrypt
+	}
+	return plaintext, nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, errors.Errorf("sqlbunny: invalid encryption key: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
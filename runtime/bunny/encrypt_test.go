@@ -0,0 +1,71 @@
+package bunny
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"testing"
+
+	"github.com/sqlbunny/errors"
+)
+
+func encrypt(t *testing.T, plaintext interface{}, deterministic bool) []byte {
+	t.Helper()
+	v, err := Encrypted{Plaintext: plaintext, Deterministic: deterministic}.Value()
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ := v.([]byte)
+	return b
+}
+
+func TestEncrypted(t *testing.T) {
+	keys := LocalKeys{Current: "k1", Keys: map[string][]byte{"k1": bytes.Repeat([]byte{1}, 32)}}
+	SetKeyProvider(keys)
+	defer SetKeyProvider(nil)
+
+	a, b := encrypt(t, "secret", false), encrypt(t, "secret", false)
+	if bytes.Equal(a, b) {
+		t.Error("expected randomized encryption to encrypt equal values to different bytes")
+	}
+	c, d := encrypt(t, []byte("secret"), true), encrypt(t, "secret", true)
+	if !bytes.Equal(c, d) {
+		t.Error("expected deterministic encryption to encrypt equal values to equal bytes")
+	}
+	// The nonce isn't keyed with the AES key itself.
+	mac := hmac.New(sha256.New, keys.Keys["k1"])
+	mac.Write([]byte("secret"))
+	if nonce := c[2+len("k1") : 2+len("k1")+12]; bytes.Equal(nonce, mac.Sum(nil)[:12]) {
+		t.Error("expected the deterministic nonce to be keyed with a subkey")
+	}
+	if v, err := (Encrypted{}).Value(); v != nil || err != nil {
+		t.Errorf("expected null values to stay null, got %#v, %v", v, err)
+	}
+
+	// Values encrypted with a previous key are still decrypted.
+	keys.Keys["k2"] = bytes.Repeat([]byte{2}, 16)
+	keys.Current = "k2"
+	SetKeyProvider(keys)
+	for _, v := range [][]byte{a, encrypt(t, "secret", false)} {
+		got, err := Decrypt(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != "secret" {
+			t.Errorf("expected secret, got %q", got)
+		}
+	}
+
+	a[len(a)-1] ^= 1
+	if _, err := Decrypt(a); !errors.Is(err, ErrDecrypt) {
+		t.Errorf("expected ErrDecrypt for tampered values, got %v", err)
+	}
+	if _, err := Decrypt([]byte("secret")); !errors.Is(err, ErrDecrypt) {
+		t.Errorf("expected ErrDecrypt for plaintext values, got %v", err)
+	}
+
+	SetKeyProvider(nil)
+	if _, err := Decrypt(b); !errors.Is(err, ErrNoKeyProvider) {
+		t.Errorf("expected ErrNoKeyProvider, got %v", err)
+	}
+}
@@ -0,0 +1,67 @@
+package bunny
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"regexp"
+	"strings"
+)
+
+var (
+	fingerprintComment     = regexp.MustCompile(`(?s)/\*.*?\*/|--[^\n]*`)
+	fingerprintLiteral     = regexp.MustCompile(`'(?:[^']|'')*'|\$\d+|\b\d+(?:\.\d+)?\b`)
+	fingerprintList        = regexp.MustCompile(`\?(?:\s*,\s*\?)+`)
+	fingerprintRows        = regexp.MustCompile(`\(\?\)(?:\s*,\s*\(\?\))+`)
+	fingerprintWhitespace  = regexp.MustCompile(`\s+`)
+	fingerprintPunctuation = regexp.MustCompile(`\s*([(),=<>])\s*`)
+)
+
+// normalizeQuery returns the shape of query: without comments, with its
+// literals and placeholders replaced by ?, and the lists of them, like the
+// ones of IN clauses and of multi-row inserts, collapsed to one.
+func normalizeQuery(query string) string {
+	query = fingerprintComment.ReplaceAllString(query, " ")
+	query = fingerprintLiteral.ReplaceAllString(query, "?")
+	query = fingerprintWhitespace.ReplaceAllString(query, " ")
+	query = fingerprintPunctuation.ReplaceAllString(query, "$1")
+	query = fingerprintList.ReplaceAllString(query, "?")
+	query = fingerprintRows.ReplaceAllString(query, "(?)")
+	return strings.TrimRight(strings.TrimSpace(query), ";")
+}
+
+// Fingerprint returns a stable identifier of the statements run by an
+// operation of a model, see WithOperation, with the shape of query. It's
+// the same for every run of a generated call site with the same query mods,
+// whatever their arguments and the length of their IN lists, so it groups
+// statements in logs and metrics, and maps pg_stat_statements entries back
+// to the code running them when it's added to comments, see CommentOptions.
+func Fingerprint(model, action, query string) string {
+	h := fnv.New64a()
+	h.Write([]byte(model))
+	h.Write([]byte{0})
+	h.Write([]byte(action))
+	h.Write([]byte{0})
+	h.Write([]byte(normalizeQuery(query)))
+	return fmt.Sprintf("%016x", h.Sum64())
+}
+
+// queryFingerprint returns the fingerprint of query run with the operation
+// of ctx.
+func queryFingerprint(ctx context.Context, query string) string {
+	op, _ := ctx.Value(contextOperationKey).(operation)
+	return Fingerprint(op.model, op.action, query)
+}
+
+// logQuery logs info, with the operation of ctx and the fingerprint of its
+// query, if there's a logger.
+func logQuery(ctx context.Context, info QueryLogInfo) {
+	if logger == nil {
+		return
+	}
+	op, _ := ctx.Value(contextOperationKey).(operation)
+	info.Model = op.model
+	info.Action = op.action
+	info.Fingerprint = Fingerprint(op.model, op.action, info.Query)
+	logger.LogQuery(ctx, info)
+}
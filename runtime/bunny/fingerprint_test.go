@@ -0,0 +1,90 @@
+package bunny
+
+import (
+	"context"
+	"testing"
+
+	"gopkg.in/DATA-DOG/go-sqlmock.v2"
+)
+
+type queryLogger struct {
+	Logger
+	infos []QueryLogInfo
+}
+
+func (l *queryLogger) LogQuery(ctx context.Context, info QueryLogInfo) {
+	l.infos = append(l.infos, info)
+}
+
+func TestNormalizeQuery(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{
+			`SELECT * FROM "book" WHERE "id" IN ($1, $2, $3) LIMIT 1;`,
+			`SELECT * FROM "book" WHERE "id" IN(?)LIMIT ?`,
+		},
+		{
+			"INSERT INTO \"book\" (\"id\",\"name\")\n\tVALUES ($1,$2),($3,$4) /*action='insert'*/",
+			`INSERT INTO "book"("id","name")VALUES(?)`,
+		},
+		{
+			`SELECT 'it''s', 1.5, "col1" FROM a -- trailing`,
+			`SELECT ?,"col1" FROM a`,
+		},
+	}
+	for i, test := range tests {
+		if got := normalizeQuery(test.query); got != test.want {
+			t.Errorf("[%d] expected %q, got %q", i, test.want, got)
+		}
+	}
+}
+
+func TestFingerprint(t *testing.T) {
+	fp := Fingerprint("book", "all", `SELECT * FROM "book" WHERE "id" IN ($1,$2)`)
+	if len(fp) != 16 {
+		t.Errorf("expected 16 hex digits, got %q", fp)
+	}
+	if got := Fingerprint("book", "all", `SELECT * FROM "book" WHERE "id" IN ($1,$2,$3,$4) /*traceparent='x'*/`); got != fp {
+		t.Errorf("expected the same fingerprint for another IN list, got %q and %q", fp, got)
+	}
+	if Fingerprint("book", "count", `SELECT * FROM "book" WHERE "id" IN ($1,$2)`) == fp {
+		t.Error("expected another fingerprint for another action")
+	}
+	if Fingerprint("book", "all", `SELECT * FROM "book" WHERE "name" IN ($1,$2)`) == fp {
+		t.Error("expected another fingerprint for another query")
+	}
+}
+
+func TestFingerprintLogAndComment(t *testing.T) {
+	defer SetCommentOptions(CommentOptions{})
+	SetCommentOptions(CommentOptions{Enabled: true, Fingerprint: true})
+	l := &queryLogger{}
+	SetLogger(l)
+	defer SetLogger(nil)
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	fp := Fingerprint("book", "all", `SELECT * FROM "book"`)
+	mock.ExpectQuery(`SELECT \* FROM "book" /\*action='all',fingerprint='` + fp + `',model='book'\*/`).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	ctx := WithOperation(ContextWithDB(context.Background(), db), "book", "all")
+	rows, err := Query(ctx, `SELECT * FROM "book"`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows.Close()
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+
+	if len(l.infos) != 1 {
+		t.Fatalf("expected 1 logged query, got %d", len(l.infos))
+	}
+	if info := l.infos[0]; info.Model != "book" || info.Action != "all" || info.Fingerprint != fp {
+		t.Errorf("wrong logged query: %+v", info)
+	}
+}
@@ -0,0 +1,27 @@
+package bunny
+
+import "fmt"
+
+// ImmutableColumnError is returned by the generated updates writing a column
+// of an Immutable field, which only inserts write.
+type ImmutableColumnError struct {
+	Model  string
+	Column string
+}
+
+func (e *ImmutableColumnError) Error() string {
+	return fmt.Sprintf("sqlbunny: column %s of %s is immutable", e.Column, e.Model)
+}
+
+// CheckImmutable returns an ImmutableColumnError for the first of columns
+// which is one of the immutable columns of the model.
+func CheckImmutable(model string, immutable, columns []string) error {
+	for _, c := range columns {
+		for _, i := range immutable {
+			if c == i {
+				return &ImmutableColumnError{Model: model, Column: c}
+			}
+		}
+	}
+	return nil
+}
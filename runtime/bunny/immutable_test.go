@@ -0,0 +1,27 @@
+package bunny
+
+import (
+	"testing"
+
+	"github.com/sqlbunny/errors"
+)
+
+func TestCheckImmutable(t *testing.T) {
+	immutable := []string{"created_at", "external_id"}
+
+	if err := CheckImmutable("user", immutable, []string{"name", "email"}); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	if err := CheckImmutable("user", nil, []string{"created_at"}); err != nil {
+		t.Errorf("expected no error without immutable columns, got %v", err)
+	}
+
+	err := CheckImmutable("user", immutable, []string{"name", "external_id", "created_at"})
+	var ie *ImmutableColumnError
+	if !errors.As(err, &ie) {
+		t.Fatalf("expected an ImmutableColumnError, got %v", err)
+	}
+	if ie.Model != "user" || ie.Column != "external_id" {
+		t.Errorf("wrong error: %+v", ie)
+	}
+}
@@ -0,0 +1,69 @@
+package bunny
+
+import (
+	"context"
+	"sync"
+)
+
+// Invalidation is a committed write of a model row, passed to the
+// invalidation hooks registered with OnInvalidate.
+type Invalidation struct {
+	Model string
+	// Tenant is the tenant of the write, see WithTenant.
+	Tenant string
+	// Kind is "insert", "update" or "delete".
+	Kind string
+	// Key is the primary key of the row, formatted by CacheKey.
+	Key string
+	// Uniques are the unique keys of the row, formatted by CacheKey, by the
+	// name of their constraint or unique index. On update they have the new
+	// values, so caches keyed by a unique key that can change must also
+	// invalidate by primary key.
+	Uniques map[string]string
+}
+
+// InvalidateHook is the signature of invalidation hooks.
+type InvalidateHook func(ctx context.Context, inv Invalidation)
+
+var (
+	invalidateMu    sync.RWMutex
+	invalidateHooks = map[string][]InvalidateHook{}
+)
+
+// OnInvalidate registers a hook called with every committed write of a row
+// of model, or of any model if model is empty. It lets external caches stay
+// consistent with transactional writes: writes in a transaction are only
+// passed to the hooks once the topmost transaction commits, and never if it
+// rolls back.
+func OnInvalidate(model string, fn InvalidateHook) {
+	invalidateMu.Lock()
+	defer invalidateMu.Unlock()
+	invalidateHooks[model] = append(invalidateHooks[model], fn)
+}
+
+// Invalidate passes inv to the invalidation hooks of its model, after the
+// transaction of ctx commits, or right away outside transactions. It's
+// called by the generated code.
+func Invalidate(ctx context.Context, inv Invalidation) {
+	invalidateMu.RLock()
+	var hooks []InvalidateHook
+	hooks = append(hooks, invalidateHooks[inv.Model]...)
+	hooks = append(hooks, invalidateHooks[""]...)
+	invalidateMu.RUnlock()
+	if len(hooks) == 0 {
+		return
+	}
+
+	inv.Tenant = TenantFromContext(ctx)
+	run := func(ctx context.Context) error {
+		for _, fn := range hooks {
+			fn(ctx, inv)
+		}
+		return nil
+	}
+	if IsAtomic(ctx) {
+		OnCommit(ctx, run)
+		return
+	}
+	_ = run(ctx)
+}
@@ -0,0 +1,80 @@
+package bunny
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/sqlbunny/errors"
+	"gopkg.in/DATA-DOG/go-sqlmock.v2"
+)
+
+func TestInvalidate(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	OnInvalidate("invalidate_test", func(ctx context.Context, inv Invalidation) {
+		got = append(got, inv.Kind+" "+inv.Key+" "+inv.Uniques["email"])
+	})
+	ctx := ContextWithDB(context.Background(), db)
+	inv := func(kind, key string) Invalidation {
+		return Invalidation{Model: "invalidate_test", Kind: kind, Key: key, Uniques: map[string]string{"email": key + "@example.com"}}
+	}
+
+	// Outside transactions, the hooks run right away.
+	Invalidate(ctx, inv("insert", "a"))
+	if strings.Join(got, ",") != "insert a a@example.com" {
+		t.Errorf("unexpected invalidations outside transactions: %v", got)
+	}
+
+	// In transactions, the hooks run after the commit.
+	got = nil
+	mock.ExpectBegin()
+	mock.ExpectExec(`SAVEPOINT savepoint_1`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`RELEASE SAVEPOINT savepoint_1`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+	err = Atomic(ctx, func(ctx context.Context) error {
+		Invalidate(ctx, inv("update", "b"))
+		// The write of a committed savepoint waits for the topmost
+		// transaction.
+		if err := Atomic(ctx, func(ctx context.Context) error {
+			Invalidate(ctx, inv("delete", "c"))
+			return nil
+		}); err != nil {
+			return err
+		}
+		if got != nil {
+			t.Errorf("hooks ran before commit: %v", got)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(got, ",") != "update b b@example.com,delete c c@example.com" {
+		t.Errorf("unexpected invalidations after commit: %v", got)
+	}
+
+	// Rolled back writes are never passed to the hooks.
+	got = nil
+	mock.ExpectBegin()
+	mock.ExpectRollback()
+	errRollback := errors.New("rollback")
+	err = Atomic(ctx, func(ctx context.Context) error {
+		Invalidate(ctx, inv("update", "d"))
+		return errRollback
+	})
+	if !errors.Is(err, errRollback) {
+		t.Fatalf("expected rollback error, got %v", err)
+	}
+	if got != nil {
+		t.Errorf("hooks ran after rollback: %v", got)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,104 @@
+package bunny
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/sqlbunny/errors"
+)
+
+// Notification is a notification received on a channel by Listen.
+type Notification struct {
+	Channel string
+	Payload string
+
+	// Reconnected is true if this is not an actual notification, but a signal
+	// that the connection to the database was lost and established again.
+	// Notifications sent in the meantime are lost, so the listener should
+	// resynchronize its state.
+	Reconnected bool
+}
+
+// ListenConfig configures how Listen reconnects to the database.
+type ListenConfig struct {
+	// MinReconnectInterval is the delay before reconnecting after the connection
+	// is lost. It doubles after each failed attempt, up to MaxReconnectInterval.
+	// Defaults to 10 milliseconds.
+	MinReconnectInterval time.Duration
+	// MaxReconnectInterval defaults to one minute.
+	MaxReconnectInterval time.Duration
+}
+
+// listenPingInterval is how often the connection is checked when no
+// notifications are received, as recommended by lib/pq.
+const listenPingInterval = 90 * time.Second
+
+// Listen opens a dedicated connection to the database at dsn, LISTENs on
+// channels and calls fn for each notification received, until ctx is done or
+// fn returns an error. The connection is reestablished automatically if it's lost.
+//
+// LISTEN/NOTIFY is Postgres specific, CockroachDB doesn't support it.
+func Listen(ctx context.Context, dsn string, config ListenConfig, channels []string, fn func(ctx context.Context, n Notification) error) error {
+	if config.MinReconnectInterval == 0 {
+		config.MinReconnectInterval = 10 * time.Millisecond
+	}
+	if config.MaxReconnectInterval == 0 {
+		config.MaxReconnectInterval = time.Minute
+	}
+
+	l := pq.NewListener(dsn, config.MinReconnectInterval, config.MaxReconnectInterval, nil)
+	defer l.Close()
+
+	for _, c := range channels {
+		if err := l.Listen(c); err != nil {
+			return errors.Errorf("listen on %q: %w", c, err)
+		}
+	}
+
+	ping := time.NewTicker(listenPingInterval)
+	defer ping.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case n := <-l.Notify:
+			// lib/pq sends a nil notification after reconnecting.
+			var err error
+			if n == nil {
+				err = fn(ctx, Notification{Reconnected: true})
+			} else {
+				err = fn(ctx, Notification{Channel: n.Channel, Payload: n.Extra})
+			}
+			if err != nil {
+				return err
+			}
+		case <-ping.C:
+			// A failed ping makes the listener reconnect.
+			go l.Ping()
+		}
+	}
+}
+
+// Notify sends a notification with payload on channel. Inside a transaction,
+// the notification is delivered when the transaction commits.
+func Notify(ctx context.Context, channel string, payload string) error {
+	_, err := Exec(ctx, "SELECT pg_notify($1, $2)", channel, payload)
+	return err
+}
+
+// NotifyPayload formats values as a notification payload, separated by commas.
+func NotifyPayload(values ...interface{}) string {
+	return joinValues(values, ",")
+}
+
+func joinValues(values []interface{}, sep string) string {
+	parts := make([]string, len(values))
+	for i, v := range values {
+		parts[i] = fmt.Sprint(v)
+	}
+	return strings.Join(parts, sep)
+}
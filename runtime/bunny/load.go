@@ -0,0 +1,31 @@
+package bunny
+
+import "context"
+
+type contextLoadParallelismKeyType struct{}
+
+var contextLoadParallelismKey = contextLoadParallelismKeyType{}
+
+// WithLoadParallelism returns a context in which the independent
+// relationships eager loaded by a query, like "Author" and "Comments" or
+// "Comments.Author" and "Comments.Likes", are loaded concurrently, running
+// up to n load queries at a time on separate connections of the pool.
+//
+// Loads in transactions stay serial, since a transaction runs on a single
+// connection; so do the loads of tenant queries, which run in one. The
+// hooks of the loaded models may run concurrently, so they must be safe
+// for it.
+func WithLoadParallelism(ctx context.Context, n int) context.Context {
+	return context.WithValue(ctx, contextLoadParallelismKey, n)
+}
+
+// LoadParallelism returns the number of load queries of the eager loads
+// with ctx that can run at a time, set with WithLoadParallelism. It's 1 in
+// transactions and by default.
+func LoadParallelism(ctx context.Context) int {
+	n, _ := ctx.Value(contextLoadParallelismKey).(int)
+	if _, isTx := ctx.Value(ContextDBKey).(*txNode); n < 1 || isTx {
+		return 1
+	}
+	return n
+}
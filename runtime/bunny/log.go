@@ -2,6 +2,7 @@ package bunny
 
 import (
 	"context"
+	"database/sql"
 	"time"
 )
 
@@ -10,10 +11,32 @@ type QueryLogInfo struct {
 	Args     []interface{}
 	Duration time.Duration
 	Err      error
+
+	// Replica is true if the query was routed to a read replica.
+	Replica bool
+
+	// Attempt is the number of previous attempts of the query that failed
+	// with a transient error and were retried.
+	Attempt int
+
+	// Model and Action are the operation running the query, see
+	// WithOperation. They're empty for queries run outside generated code.
+	Model  string
+	Action string
+
+	// Fingerprint identifies the call site and the shape of the query, see
+	// Fingerprint. Metrics should be labeled with it rather than with the
+	// query, which is unique when it has a traceparent comment.
+	Fingerprint string
 }
 
 type BeginLogInfo struct {
-	ReadOnly bool
+	ReadOnly  bool
+	Isolation IsolationLevel
+
+	// Attempt is the number of previous attempts of the transaction that
+	// failed with a serialization failure or a deadlock and were retried.
+	Attempt int
 }
 
 type CommitLogInfo struct {
@@ -32,6 +55,20 @@ type Logger interface {
 	LogRollback(ctx context.Context, info RollbackLogInfo)
 }
 
+// PoolLogInfo is the statistics of a connection pool, see WatchPool.
+type PoolLogInfo struct {
+	Stats sql.DBStats
+
+	// Replica is true for the pool of the read replica.
+	Replica bool
+}
+
+// PoolLogger is a Logger that also logs the statistics of the connection
+// pools, see WatchPool.
+type PoolLogger interface {
+	LogPool(ctx context.Context, info PoolLogInfo)
+}
+
 var logger Logger
 
 func SetLogger(l Logger) {
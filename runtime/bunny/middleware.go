@@ -0,0 +1,32 @@
+package bunny
+
+// Executor runs the statements of Exec, Query and QueryRow. It's the
+// database, transaction or replica the statement was routed to, wrapped by
+// the middlewares added with Use.
+type Executor = DB
+
+// Middleware wraps the Executor of every statement, to implement cross-cutting
+// concerns like authorization assertions or fault injection once.
+type Middleware func(next Executor) Executor
+
+var middlewares []Middleware
+
+// Use adds a middleware wrapping the execution of every statement. The first
+// middleware added is the outermost one: it runs first, and calls the next
+// ones through next. Middlewares must be added at startup, before statements
+// run.
+//
+// Middlewares see the statements after they're routed, so transaction
+// control statements, tenant selection and COPY don't go through them.
+func Use(mw Middleware) {
+	middlewares = append(middlewares, mw)
+}
+
+// intercept wraps db with the middlewares.
+func intercept(db DB) Executor {
+	e := Executor(db)
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		e = middlewares[i](e)
+	}
+	return e
+}
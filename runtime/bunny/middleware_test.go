@@ -0,0 +1,73 @@
+package bunny
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/sqlbunny/errors"
+	"gopkg.in/DATA-DOG/go-sqlmock.v2"
+)
+
+var errDenied = errors.New("denied")
+
+type recordingExecutor struct {
+	Executor
+	name  string
+	calls *[]string
+}
+
+func (e recordingExecutor) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	*e.calls = append(*e.calls, e.name)
+	if query == "DROP TABLE a" {
+		return nil, errDenied
+	}
+	return e.Executor.ExecContext(ctx, query, args...)
+}
+
+func TestUse(t *testing.T) {
+	defer func() { middlewares = nil }()
+
+	var calls []string
+	Use(func(next Executor) Executor { return recordingExecutor{next, "outer", &calls} })
+	Use(func(next Executor) Executor { return recordingExecutor{next, "inner", &calls} })
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	mock.ExpectExec(`UPDATE a`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE b`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	ctx := ContextWithDB(context.Background(), db)
+	if _, err := Exec(ctx, "UPDATE a SET x = 1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Exec(ctx, "DROP TABLE a"); err != errDenied {
+		t.Errorf("expected the middleware's error, got %v", err)
+	}
+	err = Atomic(ctx, func(ctx context.Context) error {
+		_, err := Exec(ctx, "UPDATE b SET x = 1")
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The outer middleware denies the DROP TABLE before the inner one runs.
+	want := []string{"outer", "inner", "outer", "outer", "inner"}
+	if len(calls) != len(want) {
+		t.Fatalf("expected calls %v, got %v", want, calls)
+	}
+	for i := range want {
+		if calls[i] != want[i] {
+			t.Fatalf("expected calls %v, got %v", want, calls)
+		}
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
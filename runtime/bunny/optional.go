@@ -0,0 +1,37 @@
+package bunny
+
+import "encoding/json"
+
+// Optional is a value which may be absent, like the fields of the generated
+// patch structs. Set reports whether it's present.
+//
+// In JSON an Optional is its value. It's set when the key is present, even
+// with null, so the nullable fields of a merge patch can be set to null:
+// with a null.String Value, {"name": null} sets Name to an invalid null.String,
+// and {} leaves it unset.
+type Optional[T any] struct {
+	Value T
+	Set   bool
+}
+
+// OptionalFrom returns an Optional set to value.
+func OptionalFrom[T any](value T) Optional[T] {
+	return Optional[T]{Value: value, Set: true}
+}
+
+// UnmarshalJSON sets the Optional to the value in data.
+func (o *Optional[T]) UnmarshalJSON(data []byte) error {
+	if err := json.Unmarshal(data, &o.Value); err != nil {
+		return err
+	}
+	o.Set = true
+	return nil
+}
+
+// MarshalJSON returns the JSON of the value, or null if it isn't set.
+func (o Optional[T]) MarshalJSON() ([]byte, error) {
+	if !o.Set {
+		return []byte("null"), nil
+	}
+	return json.Marshal(o.Value)
+}
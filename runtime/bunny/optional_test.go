@@ -0,0 +1,43 @@
+package bunny
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+type testPatch struct {
+	Name  Optional[string]  `json:"name"`
+	Email Optional[*string] `json:"email"`
+}
+
+func TestOptionalJSON(t *testing.T) {
+	var p testPatch
+	if err := json.Unmarshal([]byte(`{"email": null}`), &p); err != nil {
+		t.Fatal(err)
+	}
+	if p.Name.Set {
+		t.Errorf("name is set to %q, want unset", p.Name.Value)
+	}
+	if !p.Email.Set || p.Email.Value != nil {
+		t.Errorf("email is %+v, want set to nil", p.Email)
+	}
+
+	if err := json.Unmarshal([]byte(`{"name": "bunny"}`), &p); err != nil {
+		t.Fatal(err)
+	}
+	if p.Name != OptionalFrom("bunny") {
+		t.Errorf("name is %+v, want set to bunny", p.Name)
+	}
+
+	if err := json.Unmarshal([]byte(`{"name": 1}`), &p); err == nil {
+		t.Error("unmarshaled a number into a string")
+	}
+
+	data, err := json.Marshal(testPatch{Name: OptionalFrom("bunny")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(data), `{"name":"bunny","email":null}`; got != want {
+		t.Errorf("marshaled %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,146 @@
+package bunny
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/sqlbunny/errors"
+)
+
+// OutboxTable is the table of the outbox events, defined by the outbox
+// plugin of the generator.
+const OutboxTable = "outbox_event"
+
+// OutboxEvent is an event of a write to a row of a model annotated with
+// Outbox, written to the outbox table in the transaction of the write.
+type OutboxEvent struct {
+	ID    int64
+	Topic string
+	// Kind is "insert", "update" or "delete".
+	Kind string
+	// Key is the primary key of the row, formatted like NotifyPayload.
+	Key string
+	// Payload is the JSON of the row, before it's deleted for deletes.
+	Payload   json.RawMessage
+	CreatedAt time.Time
+}
+
+// ErrOutboxNotAtomic is returned by the writes of models annotated with
+// Outbox outside transactions, where the outbox event can't be written
+// atomically with the row.
+var ErrOutboxNotAtomic = errors.New("sqlbunny: writes with outbox events must run in a transaction")
+
+// CheckOutbox returns ErrOutboxNotAtomic if ctx isn't in a transaction. The
+// generated code checks it before writing the rows of models annotated with
+// Outbox.
+func CheckOutbox(ctx context.Context) error {
+	if !IsAtomic(ctx) {
+		return ErrOutboxNotAtomic
+	}
+	return nil
+}
+
+// WriteOutbox writes an event of kind on topic to the outbox table, for the
+// row whose primary key is key.
+func WriteOutbox(ctx context.Context, topic, kind, key string, row interface{}) error {
+	payload, err := json.Marshal(row)
+	if err != nil {
+		return errors.Errorf("sqlbunny: unable to marshal outbox event: %w", err)
+	}
+	_, err = Exec(ctx, `INSERT INTO "`+OutboxTable+`" ("topic", "kind", "key", "payload", "created_at") VALUES ($1, $2, $3, $4, now())`, topic, kind, key, string(payload))
+	if err != nil {
+		return errors.Errorf("sqlbunny: unable to write outbox event: %w", err)
+	}
+	return nil
+}
+
+// OutboxConfig configures PollOutbox.
+type OutboxConfig struct {
+	// BatchSize is the maximum number of events published at once. Defaults
+	// to 100.
+	BatchSize int
+	// Interval is the delay before polling again when there were no more
+	// events. Defaults to one second.
+	Interval time.Duration
+}
+
+// PollOutbox calls publish with the unpublished events of the outbox table in
+// order, in batches, and marks them published, until ctx is done or publish
+// returns an error.
+//
+// The batches are locked with FOR UPDATE SKIP LOCKED in a transaction, so
+// several pollers can run concurrently, but then the events of different
+// batches may be published out of order. Events are published at least once:
+// if the transaction fails after publish, the batch is published again.
+// Published events are kept in the table, with their published_at set, until
+// they're deleted.
+//
+// PollOutbox is Postgres specific.
+func PollOutbox(ctx context.Context, config OutboxConfig, publish func(ctx context.Context, events []*OutboxEvent) error) error {
+	if config.BatchSize == 0 {
+		config.BatchSize = 100
+	}
+	if config.Interval == 0 {
+		config.Interval = time.Second
+	}
+
+	for {
+		n, err := pollOutbox(ctx, config.BatchSize, publish)
+		if err != nil {
+			return err
+		}
+		if n == config.BatchSize {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(config.Interval):
+		}
+	}
+}
+
+// pollOutbox publishes a batch of events, and returns its size.
+func pollOutbox(ctx context.Context, batchSize int, publish func(ctx context.Context, events []*OutboxEvent) error) (int, error) {
+	var n int
+	err := Atomic(ctx, func(ctx context.Context) error {
+		rows, err := Query(ctx, `SELECT "id", "topic", "kind", "key", "payload", "created_at" FROM "`+OutboxTable+`" WHERE "published_at" IS NULL ORDER BY "id" LIMIT $1 FOR UPDATE SKIP LOCKED`, batchSize)
+		if err != nil {
+			return errors.Errorf("sqlbunny: unable to read outbox events: %w", err)
+		}
+		defer rows.Close()
+
+		var events []*OutboxEvent
+		var ids []int64
+		for rows.Next() {
+			e := &OutboxEvent{}
+			var payload []byte
+			if err := rows.Scan(&e.ID, &e.Topic, &e.Kind, &e.Key, &payload, &e.CreatedAt); err != nil {
+				return errors.Errorf("sqlbunny: unable to read outbox events: %w", err)
+			}
+			e.Payload = payload
+			events = append(events, e)
+			ids = append(ids, e.ID)
+		}
+		if err := rows.Err(); err != nil {
+			return errors.Errorf("sqlbunny: unable to read outbox events: %w", err)
+		}
+		rows.Close()
+
+		n = len(events)
+		if n == 0 {
+			return nil
+		}
+		if err := publish(ctx, events); err != nil {
+			return err
+		}
+		_, err = Exec(ctx, `UPDATE "`+OutboxTable+`" SET "published_at" = now() WHERE "id" = ANY($1)`, pq.Array(ids))
+		if err != nil {
+			return errors.Errorf("sqlbunny: unable to mark outbox events published: %w", err)
+		}
+		return nil
+	})
+	return n, err
+}
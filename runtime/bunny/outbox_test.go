@@ -0,0 +1,92 @@
+package bunny
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sqlbunny/errors"
+	"gopkg.in/DATA-DOG/go-sqlmock.v2"
+)
+
+func TestWriteOutbox(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`INSERT INTO "outbox_event" \("topic", "kind", "key", "payload", "created_at"\) VALUES \(\$1, \$2, \$3, \$4, now\(\)\)`).
+		WithArgs("user", "insert", "u1", `{"id":"u1"}`).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	ctx := ContextWithDB(context.Background(), db)
+	if err := CheckOutbox(ctx); !errors.Is(err, ErrOutboxNotAtomic) {
+		t.Errorf("expected ErrOutboxNotAtomic outside transactions, got %v", err)
+	}
+
+	err = Atomic(ctx, func(ctx context.Context) error {
+		if err := CheckOutbox(ctx); err != nil {
+			return err
+		}
+		return WriteOutbox(ctx, "user", "insert", "u1", map[string]string{"id": "u1"})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestPollOutbox(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	columns := []string{"id", "topic", "kind", "key", "payload", "created_at"}
+	selectEvents := `SELECT "id", "topic", "kind", "key", "payload", "created_at" FROM "outbox_event" WHERE "published_at" IS NULL ORDER BY "id" LIMIT \$1 FOR UPDATE SKIP LOCKED`
+
+	// A full batch is published and marked, and the next one is polled
+	// right away.
+	mock.ExpectBegin()
+	mock.ExpectQuery(selectEvents).WithArgs(2).WillReturnRows(sqlmock.NewRows(columns).
+		AddRow(1, "user", "insert", "u1", []byte(`{"id":"u1"}`), now).
+		AddRow(2, "user", "delete", "u2", []byte(`{"id":"u2"}`), now))
+	mock.ExpectExec(`UPDATE "outbox_event" SET "published_at" = now\(\) WHERE "id" = ANY\(\$1\)`).WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectCommit()
+
+	// A failed publish rolls back, so the events stay unpublished.
+	mock.ExpectBegin()
+	mock.ExpectQuery(selectEvents).WithArgs(2).WillReturnRows(sqlmock.NewRows(columns).
+		AddRow(3, "user", "update", "u1", []byte(`{"id":"u1"}`), now))
+	mock.ExpectRollback()
+
+	errPublish := errors.New("publish failed")
+	var published []string
+	ctx := ContextWithDB(context.Background(), db)
+	err = PollOutbox(ctx, OutboxConfig{BatchSize: 2}, func(ctx context.Context, events []*OutboxEvent) error {
+		for _, e := range events {
+			if e.ID == 3 {
+				return errPublish
+			}
+			published = append(published, e.Kind+" "+e.Key+" "+string(e.Payload))
+		}
+		return nil
+	})
+	if !errors.Is(err, errPublish) {
+		t.Fatalf("expected the publish error, got %v", err)
+	}
+
+	want := []string{`insert u1 {"id":"u1"}`, `delete u2 {"id":"u2"}`}
+	if strings.Join(published, "\n") != strings.Join(want, "\n") {
+		t.Errorf("expected published %q, got %q", want, published)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,93 @@
+package bunny
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/sqlbunny/errors"
+)
+
+// Pool is a DB with a pool of connections, like *sql.DB and StmtCache.
+type Pool interface {
+	DB
+	Stats() sql.DBStats
+	PingContext(ctx context.Context) error
+}
+
+var _ Pool = &sql.DB{}
+var _ Pool = &StmtCache{}
+
+// ErrNoPool is returned when the DB of the context isn't a Pool, like
+// inside transactions.
+var ErrNoPool = errors.New("sqlbunny: the database of the context has no connection pool")
+
+// PoolStats returns the statistics of the connection pool of the context's
+// DB: the open connections in use and idle, and the waits for one.
+func PoolStats(ctx context.Context) (sql.DBStats, error) {
+	p, ok := DBFromContext(ctx).(Pool)
+	if !ok {
+		return sql.DBStats{}, ErrNoPool
+	}
+	return p.Stats(), nil
+}
+
+// ReplicaPoolStats is like PoolStats, for the replica of the context, see
+// ContextWithReplica. It returns ErrNoPool if there's none.
+func ReplicaPoolStats(ctx context.Context) (sql.DBStats, error) {
+	p, ok := ctx.Value(contextReplicaKey).(Pool)
+	if !ok {
+		return sql.DBStats{}, ErrNoPool
+	}
+	return p.Stats(), nil
+}
+
+// Ping checks that the database of the context can be reached, and its
+// replica if it has one, for the health checks of readiness probes. It
+// returns ErrNoPool if the DB of the context isn't a Pool.
+func Ping(ctx context.Context) error {
+	p, ok := DBFromContext(ctx).(Pool)
+	if !ok {
+		return ErrNoPool
+	}
+	if err := p.PingContext(ctx); err != nil {
+		return errors.Errorf("sqlbunny: ping failed: %w", err)
+	}
+	if replica, ok := ctx.Value(contextReplicaKey).(Pool); ok {
+		if err := replica.PingContext(ctx); err != nil {
+			return errors.Errorf("sqlbunny: replica ping failed: %w", err)
+		}
+	}
+	return nil
+}
+
+// WatchPool logs the statistics of the connection pools of the context's DB
+// and replica every interval until ctx is done, if the Logger set with
+// SetLogger is a PoolLogger. It blocks, so run it in its own goroutine:
+//
+//	go bunny.WatchPool(ctx, time.Minute)
+func WatchPool(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			logPool(ctx)
+		}
+	}
+}
+
+func logPool(ctx context.Context) {
+	l, ok := logger.(PoolLogger)
+	if !ok {
+		return
+	}
+	if stats, err := PoolStats(ctx); err == nil {
+		l.LogPool(ctx, PoolLogInfo{Stats: stats})
+	}
+	if stats, err := ReplicaPoolStats(ctx); err == nil {
+		l.LogPool(ctx, PoolLogInfo{Stats: stats, Replica: true})
+	}
+}
@@ -0,0 +1,68 @@
+package bunny
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sqlbunny/errors"
+	"gopkg.in/DATA-DOG/go-sqlmock.v2"
+)
+
+type poolLogger struct {
+	Logger
+	infos []PoolLogInfo
+}
+
+func (l *poolLogger) LogPool(ctx context.Context, info PoolLogInfo) {
+	l.infos = append(l.infos, info)
+}
+
+func TestPool(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	replica, _, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.SetMaxOpenConns(3)
+
+	ctx := ContextWithDB(context.Background(), db)
+	if err := Ping(ctx); err != nil {
+		t.Fatal(err)
+	}
+	stats, err := PoolStats(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.MaxOpenConnections != 3 || stats.OpenConnections != 1 {
+		t.Errorf("wrong stats: %+v", stats)
+	}
+	if _, err := ReplicaPoolStats(ctx); err != ErrNoPool {
+		t.Errorf("expected ErrNoPool without replica, got %v", err)
+	}
+
+	l := &poolLogger{}
+	SetLogger(l)
+	logPool(ContextWithReplica(ctx, replica))
+	SetLogger(nil)
+	if len(l.infos) != 2 || l.infos[0].Replica || !l.infos[1].Replica {
+		t.Errorf("wrong logged stats: %+v", l.infos)
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectRollback()
+	err = Atomic(ctx, func(ctx context.Context) error {
+		if _, err := PoolStats(ctx); err != ErrNoPool {
+			t.Errorf("expected ErrNoPool in a transaction, got %v", err)
+		}
+		return Ping(ctx)
+	})
+	if !errors.Is(err, ErrNoPool) {
+		t.Errorf("expected ErrNoPool, got %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,41 @@
+package bunny
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+)
+
+// RedactedText replaces the values of PII fields in the generated String
+// methods, and in the query arguments passed to the Logger.
+const RedactedText = "[REDACTED]"
+
+// Redact returns v as a query argument which is written to the database as
+// v, but is formatted and marshaled to JSON as RedactedText, so loggers
+// printing the arguments don't reveal it. The generated code passes the
+// values of PII fields with it.
+func Redact(v interface{}) driver.Valuer {
+	return redacted{v: v}
+}
+
+type redacted struct {
+	v interface{}
+}
+
+func (r redacted) Value() (driver.Value, error) {
+	if v, ok := r.v.(driver.Valuer); ok {
+		return v.Value()
+	}
+	return driver.DefaultParameterConverter.ConvertValue(r.v)
+}
+
+func (r redacted) String() string {
+	return RedactedText
+}
+
+func (r redacted) GoString() string {
+	return RedactedText
+}
+
+func (r redacted) MarshalJSON() ([]byte, error) {
+	return json.Marshal(RedactedText)
+}
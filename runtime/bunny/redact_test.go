@@ -0,0 +1,27 @@
+package bunny
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/sqlbunny/sqlbunny/types/null"
+)
+
+func TestRedact(t *testing.T) {
+	for _, v := range []interface{}{"secret", null.StringFrom("secret")} {
+		r := Redact(v)
+		if s := fmt.Sprintf("%v %#v", r, []interface{}{r}); s != RedactedText+" []interface {}{"+RedactedText+"}" {
+			t.Errorf("expected redacted formatting, got %s", s)
+		}
+		if b, _ := json.Marshal(r); string(b) != `"`+RedactedText+`"` {
+			t.Errorf("expected redacted JSON, got %s", b)
+		}
+		if v, err := r.Value(); v != "secret" || err != nil {
+			t.Errorf("expected the value to be written, got %#v, %v", v, err)
+		}
+	}
+	if s := fmt.Sprint(Encrypted{Plaintext: "secret"}); s != RedactedText {
+		t.Errorf("expected encrypted values to be redacted, got %s", s)
+	}
+}
@@ -0,0 +1,41 @@
+package bunny
+
+import "context"
+
+type contextReplicaKeyType struct{}
+
+var contextReplicaKey = contextReplicaKeyType{}
+
+type contextForceWriterKeyType struct{}
+
+var contextForceWriterKey = contextForceWriterKeyType{}
+
+// ContextWithReplica returns a context in which reads (Query and QueryRow)
+// made outside transactions are sent to replica instead of the context's DB.
+// Exec, and everything inside Atomic, always goes to the context's DB, but
+// the read only transactions of AtomicRead go to the replica.
+func ContextWithReplica(ctx context.Context, replica DB) context.Context {
+	return context.WithValue(ctx, contextReplicaKey, replica)
+}
+
+// ForceWriter returns a context in which reads are no longer routed to the
+// replica. Use it for read-after-write paths that can't tolerate replication lag.
+func ForceWriter(ctx context.Context) context.Context {
+	return context.WithValue(ctx, contextForceWriterKey, true)
+}
+
+// readDB returns the DB that should be used to run a read query, and whether
+// it's a replica.
+func readDB(ctx context.Context) (DB, bool) {
+	db := DBFromContext(ctx)
+	if _, ok := db.(*txNode); ok {
+		return db, false
+	}
+	if force, _ := ctx.Value(contextForceWriterKey).(bool); force {
+		return db, false
+	}
+	if replica, ok := ctx.Value(contextReplicaKey).(DB); ok {
+		return replica, true
+	}
+	return db, false
+}
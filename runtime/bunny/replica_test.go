@@ -0,0 +1,99 @@
+package bunny
+
+import (
+	"context"
+	"testing"
+
+	"gopkg.in/DATA-DOG/go-sqlmock.v2"
+)
+
+func TestReplicaRouting(t *testing.T) {
+	writer, writerMock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	replica, replicaMock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	replicaMock.ExpectQuery(`SELECT 1`).WillReturnRows(sqlmock.NewRows([]string{"x"}).AddRow(1))
+	writerMock.ExpectExec(`UPDATE a`).WillReturnResult(sqlmock.NewResult(0, 1))
+	writerMock.ExpectQuery(`SELECT 2`).WillReturnRows(sqlmock.NewRows([]string{"x"}).AddRow(2))
+	writerMock.ExpectBegin()
+	writerMock.ExpectQuery(`SELECT 3`).WillReturnRows(sqlmock.NewRows([]string{"x"}).AddRow(3))
+	writerMock.ExpectCommit()
+
+	ctx := ContextWithDB(context.Background(), writer)
+	ctx = ContextWithReplica(ctx, replica)
+
+	var x int
+	if err := QueryRow(ctx, "SELECT 1").Scan(&x); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Exec(ctx, "UPDATE a SET x = 1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := QueryRow(ForceWriter(ctx), "SELECT 2").Scan(&x); err != nil {
+		t.Fatal(err)
+	}
+	err = Atomic(ctx, func(ctx context.Context) error {
+		return QueryRow(ctx, "SELECT 3").Scan(&x)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := writerMock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+	if err := replicaMock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestAtomicReadReplica(t *testing.T) {
+	writer, writerMock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	replica, replicaMock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	replicaMock.ExpectBegin()
+	replicaMock.ExpectQuery(`SELECT 1`).WillReturnRows(sqlmock.NewRows([]string{"x"}).AddRow(1))
+	replicaMock.ExpectQuery(`SELECT 2`).WillReturnRows(sqlmock.NewRows([]string{"x"}).AddRow(2))
+	replicaMock.ExpectCommit()
+	writerMock.ExpectBegin()
+	writerMock.ExpectQuery(`SELECT 3`).WillReturnRows(sqlmock.NewRows([]string{"x"}).AddRow(3))
+	writerMock.ExpectCommit()
+
+	ctx := ContextWithDB(context.Background(), writer)
+	ctx = ContextWithReplica(ctx, replica)
+
+	var x int
+	err = AtomicRead(ctx, func(ctx context.Context) error {
+		if err := QueryRow(ctx, "SELECT 1").Scan(&x); err != nil {
+			return err
+		}
+		return QueryRow(ctx, "SELECT 2").Scan(&x)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = AtomicRead(ForceWriter(ctx), func(ctx context.Context) error {
+		return QueryRow(ctx, "SELECT 3").Scan(&x)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := writerMock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+	if err := replicaMock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,146 @@
+package bunny
+
+import (
+	"context"
+	"database/sql/driver"
+	"io"
+	"math/rand"
+	"net"
+	"regexp"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/sqlbunny/errors"
+)
+
+// RetryPolicy configures automatic retries of read queries that fail with a
+// transient error, such as a connection reset or a server shutdown. It also
+// configures the retries of transactions, see SetTxRetryPolicy.
+//
+// Only the read only statements of Query calls made outside transactions are
+// retried: SELECT, VALUES, TABLE and WITH statements without INSERT, UPDATE,
+// DELETE, MERGE or SELECT INTO, whose error is known before any row is
+// returned. Writes with RETURNING aren't, since they may have been applied
+// before the connection failed, nor are locking reads. Reads calling
+// functions with side effects must not be run with a retry policy. Inside a
+// transaction a broken connection aborts the whole transaction, so retrying
+// a single statement would be wrong.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including the first one.
+	// Values below 2 disable retrying.
+	MaxAttempts int
+
+	// BaseDelay is the delay before the first retry. It doubles on each
+	// subsequent retry, and a random jitter is applied.
+	BaseDelay time.Duration
+
+	// MaxDelay caps the delay between retries. Zero means no cap.
+	MaxDelay time.Duration
+}
+
+var retryPolicy RetryPolicy
+
+// SetRetryPolicy sets the policy used to retry transient errors. By default
+// no retries are performed.
+func SetRetryPolicy(p RetryPolicy) {
+	retryPolicy = p
+}
+
+var txRetryPolicy = RetryPolicy{
+	MaxAttempts: 12,
+	BaseDelay:   time.Millisecond,
+	MaxDelay:    time.Second,
+}
+
+// SetTxRetryPolicy sets the policy used by Atomic to retry the transactions
+// failing with a serialization failure or a deadlock (SQLSTATE 40001 or
+// 40P01), which are likely to commit when run again. The whole function is
+// run again, so it must have no side effects outside the transaction. The
+// retries stop when the context of the transaction is done.
+//
+// By default up to 12 attempts are made, with delays from 1ms up to 1s.
+func SetTxRetryPolicy(p RetryPolicy) {
+	txRetryPolicy = p
+}
+
+func (p RetryPolicy) delay(try int) time.Duration {
+	d := p.BaseDelay << uint(try)
+	if d <= 0 || (p.MaxDelay > 0 && d > p.MaxDelay) {
+		d = p.MaxDelay
+	}
+	if d <= 0 {
+		return 0
+	}
+	// Jitter between d/2 and d, so clients failing at the same time don't
+	// retry in lockstep.
+	return d/2 + time.Duration(rand.Int63n(int64(d/2)+1))
+}
+
+// wait sleeps for d, unless the context ends before. It returns false if the
+// context is done, or if its deadline would pass before the retry.
+func (p RetryPolicy) wait(ctx context.Context, d time.Duration) bool {
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) <= d {
+		return false
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-t.C:
+		return true
+	}
+}
+
+var (
+	readOnlyStatementStart = regexp.MustCompile(`(?i)^(select|values|table|with)\b`)
+	writeKeyword           = regexp.MustCompile(`(?i)\b(insert|update|delete|merge|into)\b`)
+	lockingClause          = regexp.MustCompile(`(?i)\bfor\s+(update|no\s+key\s+update|share|key\s+share)\b`)
+)
+
+// readOnlyStatement returns whether query only reads rows, so it can be
+// retried. Quoted identifiers spelled like write keywords make it
+// conservatively false.
+func readOnlyStatement(query string) bool {
+	query = strings.TrimSpace(fingerprintComment.ReplaceAllString(query, " "))
+	return readOnlyStatementStart.MatchString(query) && !writeKeyword.MatchString(query) && !lockingClause.MatchString(query)
+}
+
+// IsTransientError returns whether err is likely to go away by retrying the
+// query, for example on another pool connection.
+func IsTransientError(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	if code := errorCode(err); code != "" {
+		switch code {
+		case "57P01": // admin_shutdown
+			return true
+		case "57P02": // crash_shutdown
+			return true
+		case "57P03": // cannot_connect_now
+			return true
+		case "53300": // too_many_connections
+			return true
+		}
+		// Class 08 is connection_exception
+		return strings.HasPrefix(code, "08")
+	}
+
+	if errors.Is(err, driver.ErrBadConn) ||
+		errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.EPIPE) {
+		return true
+	}
+
+	var neterr net.Error
+	if errors.As(err, &neterr) {
+		return true
+	}
+	return false
+}
@@ -0,0 +1,202 @@
+package bunny
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/lib/pq"
+	"gopkg.in/DATA-DOG/go-sqlmock.v2"
+)
+
+func TestQueryRetry(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	SetRetryPolicy(RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond})
+	defer SetRetryPolicy(RetryPolicy{})
+
+	mock.ExpectQuery(`SELECT 1`).WillReturnError(&pq.Error{Code: "57P01"})
+	mock.ExpectQuery(`SELECT 1`).WillReturnRows(sqlmock.NewRows([]string{"x"}).AddRow(1))
+	mock.ExpectQuery(`SELECT 2`).WillReturnError(&pq.Error{Code: "42601"})
+
+	ctx := ContextWithDB(context.Background(), db)
+
+	rows, err := Query(ctx, "SELECT 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows.Close()
+
+	if _, err := Query(ctx, "SELECT 2"); err == nil {
+		t.Fatal("expected error")
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestQueryRetryWrite(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	SetRetryPolicy(RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond})
+	defer SetRetryPolicy(RetryPolicy{})
+
+	mock.ExpectQuery(`INSERT INTO "book"`).WillReturnError(&pq.Error{Code: "57P01"})
+
+	ctx := ContextWithDB(context.Background(), db)
+	if _, err := Query(ctx, `INSERT INTO "book" ("name") VALUES ($1) RETURNING "id"`, "x"); err == nil {
+		t.Fatal("expected error")
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestReadOnlyStatement(t *testing.T) {
+	tests := []struct {
+		query string
+		want  bool
+	}{
+		{`SELECT * FROM "book"`, true},
+		{"  /* x */ select 1", true},
+		{`WITH b AS (SELECT * FROM "book") SELECT * FROM b`, true},
+		{`VALUES (1), (2)`, true},
+		{`INSERT INTO "book" ("name") VALUES ($1) RETURNING "id"`, false},
+		{`UPDATE "book" SET "name" = $1 RETURNING "id"`, false},
+		{`DELETE FROM "book" RETURNING "id"`, false},
+		{`WITH d AS (DELETE FROM "book" RETURNING *) SELECT * FROM d`, false},
+		{`SELECT * FROM "book" FOR UPDATE`, false},
+		{`SELECT * FROM "book" FOR SHARE`, false},
+		{`SELECT * FROM "book" FOR KEY SHARE SKIP LOCKED`, false},
+		{`SELECT * FROM "book" FOR NO KEY UPDATE`, false},
+		{`SELECT * INTO "copy" FROM "book"`, false},
+	}
+	for i, test := range tests {
+		if got := readOnlyStatement(test.query); got != test.want {
+			t.Errorf("[%d] %q: expected %v, got %v", i, test.query, test.want, got)
+		}
+	}
+}
+
+func TestQueryRetryDeadline(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	SetRetryPolicy(RetryPolicy{MaxAttempts: 3, BaseDelay: time.Hour})
+	defer SetRetryPolicy(RetryPolicy{})
+
+	mock.ExpectQuery(`SELECT 1`).WillReturnError(&pq.Error{Code: "57P01"})
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	ctx = ContextWithDB(ctx, db)
+
+	if _, err := Query(ctx, "SELECT 1"); err == nil {
+		t.Fatal("expected error")
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestAtomicRetry(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer SetTxRetryPolicy(txRetryPolicy)
+	SetTxRetryPolicy(RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond})
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE a`).WillReturnError(&pq.Error{Code: "40001"})
+	mock.ExpectRollback()
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE a`).WillReturnError(&pq.Error{Code: "40P01"})
+	mock.ExpectRollback()
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE a`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	ctx := ContextWithDB(context.Background(), db)
+
+	tries := 0
+	err = Atomic(ctx, func(ctx context.Context) error {
+		tries++
+		_, err := Exec(ctx, "UPDATE a SET x = 1")
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tries != 3 {
+		t.Errorf("expected 3 tries, got %d", tries)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestAtomicRetryMaxAttempts(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE a`).WillReturnError(&pq.Error{Code: "40001"})
+	mock.ExpectRollback()
+
+	ctx := ContextWithDB(context.Background(), db)
+
+	err = AtomicWith(ctx, TxOptions{MaxAttempts: 1}, func(ctx context.Context) error {
+		_, err := Exec(ctx, "UPDATE a SET x = 1")
+		return err
+	})
+	if errorCode(err) != "40001" {
+		t.Errorf("expected serialization failure, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+type sqlStateError string
+
+func (e sqlStateError) Error() string    { return "sqlstate " + string(e) }
+func (e sqlStateError) SQLState() string { return string(e) }
+
+func TestIsTransientError(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{&pq.Error{Code: "57P01"}, true},
+		{&pq.Error{Code: "08006"}, true},
+		{&pq.Error{Code: "23505"}, false},
+		{sqlStateError("57P01"), true},
+		{sqlStateError("42P01"), false},
+		{&mysql.MySQLError{Number: 1053, SQLState: [5]byte{'0', '8', 'S', '0', '1'}}, true},
+		{&mysql.MySQLError{Number: 1062, SQLState: [5]byte{'2', '3', '0', '0', '0'}}, false},
+		{nil, false},
+	}
+	for _, test := range tests {
+		if got := IsTransientError(test.err); got != test.want {
+			t.Errorf("IsTransientError(%v) = %v, want %v", test.err, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package bunny
+
+// SchemaInfo describes the generated models, with their columns, keys and
+// relationships, for generic tooling like admin interfaces and debugging
+// endpoints. It's returned by the generated Schema function, and must not be
+// modified. Keys and relationships are lists of column names.
+type SchemaInfo struct {
+	// Models are sorted by name.
+	Models []*ModelInfo
+}
+
+// Model returns the model with name, or nil if there's none.
+func (s *SchemaInfo) Model(name string) *ModelInfo {
+	for _, m := range s.Models {
+		if m.Name == name {
+			return m
+		}
+	}
+	return nil
+}
+
+// ModelInfo describes a model, see SchemaInfo.
+type ModelInfo struct {
+	Name string
+	// HasTable is false for the models of views and foreign tables.
+	HasTable bool
+
+	Columns       []ColumnInfo
+	PrimaryKey    []string
+	Indexes       [][]string
+	Uniques       [][]string
+	ForeignKeys   []ForeignKeyInfo
+	Relationships []RelationshipInfo
+}
+
+// Column returns the column with name, or nil if there's none.
+func (m *ModelInfo) Column(name string) *ColumnInfo {
+	for i := range m.Columns {
+		if m.Columns[i].Name == name {
+			return &m.Columns[i]
+		}
+	}
+	return nil
+}
+
+// Relationship returns the relationship with name, or nil if there's none.
+func (m *ModelInfo) Relationship(name string) *RelationshipInfo {
+	for i := range m.Relationships {
+		if m.Relationships[i].Name == name {
+			return &m.Relationships[i]
+		}
+	}
+	return nil
+}
+
+// ColumnInfo describes a column of a model, see SchemaInfo.
+type ColumnInfo struct {
+	Name      string
+	SQLType   string
+	Nullable  bool
+	PII       bool
+	Immutable bool
+	// DBDefault is the SQL expression of the default value set by the
+	// database on inserts, if any.
+	DBDefault string
+}
+
+// ForeignKeyInfo describes a foreign key of a model, see SchemaInfo.
+type ForeignKeyInfo struct {
+	Columns        []string
+	ForeignModel   string
+	ForeignColumns []string
+}
+
+// RelationshipInfo describes a relationship of a model, see SchemaInfo.
+// The rows of ForeignModel are related by their ForeignColumns equal to the
+// LocalColumns, or through the rows of JoinModel if it's set: its
+// JoinLocalColumns equal to the LocalColumns, and its JoinForeignColumns
+// equal to the ForeignColumns.
+type RelationshipInfo struct {
+	Name         string
+	ForeignModel string
+	ToMany       bool
+
+	LocalColumns   []string
+	ForeignColumns []string
+
+	JoinModel          string
+	JoinLocalColumns   []string
+	JoinForeignColumns []string
+}
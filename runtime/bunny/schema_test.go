@@ -0,0 +1,36 @@
+package bunny
+
+import "testing"
+
+func TestSchemaInfo(t *testing.T) {
+	s := &SchemaInfo{
+		Models: []*ModelInfo{
+			{
+				Name:          "book",
+				Columns:       []ColumnInfo{{Name: "id", SQLType: "bigint"}, {Name: "tag_id", SQLType: "bigint", Nullable: true}},
+				Relationships: []RelationshipInfo{{Name: "tag", ForeignModel: "tag", LocalColumns: []string{"tag_id"}, ForeignColumns: []string{"id"}}},
+			},
+			{Name: "tag"},
+		},
+	}
+
+	m := s.Model("book")
+	if m == nil || m.Name != "book" {
+		t.Fatalf("wrong model: %+v", m)
+	}
+	if s.Model("author") != nil {
+		t.Error("expected no author model")
+	}
+	if c := m.Column("tag_id"); c == nil || !c.Nullable {
+		t.Errorf("wrong column: %+v", c)
+	}
+	if m.Column("name") != nil {
+		t.Error("expected no name column")
+	}
+	if r := m.Relationship("tag"); r == nil || r.ForeignModel != "tag" {
+		t.Errorf("wrong relationship: %+v", r)
+	}
+	if m.Relationship("author") != nil {
+		t.Error("expected no author relationship")
+	}
+}
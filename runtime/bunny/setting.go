@@ -0,0 +1,75 @@
+package bunny
+
+import (
+	"context"
+	"sort"
+
+	"github.com/sqlbunny/errors"
+)
+
+type contextSettingsKeyType struct{}
+
+var contextSettingsKey = contextSettingsKeyType{}
+
+// WithSetting returns a context in which statements run with the Postgres
+// setting name set to value, like the variables of the request read by
+// row-level security policies:
+//
+//	ctx = bunny.WithSetting(ctx, "app.user_id", userID)
+//
+//	// Policy: "owner_id = current_setting('app.user_id')"
+//
+// Settings are set like the tenant of WithTenant, with set_config(name,
+// value, true) before the first statement of transactions, so they don't
+// outlive the transaction on pooled connections, and statements outside
+// transactions need one in the same way.
+func WithSetting(ctx context.Context, name, value string) context.Context {
+	old := settingsFromContext(ctx)
+	settings := make(map[string]string, len(old)+1)
+	for k, v := range old {
+		settings[k] = v
+	}
+	settings[name] = value
+	return context.WithValue(ctx, contextSettingsKey, settings)
+}
+
+// SettingFromContext returns the value of the setting set with WithSetting,
+// and whether it's set.
+func SettingFromContext(ctx context.Context, name string) (string, bool) {
+	value, ok := settingsFromContext(ctx)[name]
+	return value, ok
+}
+
+func settingsFromContext(ctx context.Context) map[string]string {
+	settings, _ := ctx.Value(contextSettingsKey).(map[string]string)
+	return settings
+}
+
+// useSettings sets the settings not already set in the transaction, for the
+// rest of it.
+func (t *txNode) useSettings(ctx context.Context, settings map[string]string) error {
+	var names []string
+	for name, value := range settings {
+		if current, ok := t.settings[name]; !ok || current != value {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return nil
+	}
+	sort.Strings(names)
+
+	// The settings of the parent transaction are shared, so they're copied.
+	res := make(map[string]string, len(t.settings)+len(names))
+	for k, v := range t.settings {
+		res[k] = v
+	}
+	for _, name := range names {
+		if _, err := t.dbTx.ExecContext(ctx, "SELECT set_config($1, $2, true)", name, settings[name]); err != nil {
+			return errors.Errorf("failed to set setting '%s': %w", name, err)
+		}
+		res[name] = settings[name]
+	}
+	t.settings = res
+	return nil
+}
@@ -0,0 +1,58 @@
+package bunny
+
+import (
+	"context"
+	"testing"
+
+	"gopkg.in/DATA-DOG/go-sqlmock.v2"
+)
+
+func TestSettings(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Outside transactions, Exec runs in its own one.
+	mock.ExpectBegin()
+	mock.ExpectExec(`SELECT set_config\(\$1, \$2, true\)`).WithArgs("app.user_id", "u1").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`UPDATE a`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	// In transactions, settings are set once, and again when they change.
+	mock.ExpectBegin()
+	mock.ExpectExec(`SELECT set_config\(\$1, \$2, true\)`).WithArgs("app.user_id", "u1").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`UPDATE a`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`UPDATE a`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`SELECT set_config\(\$1, \$2, true\)`).WithArgs("app.role", "admin").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`SELECT set_config\(\$1, \$2, true\)`).WithArgs("app.user_id", "u2").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`UPDATE b`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	ctx := WithSetting(ContextWithDB(context.Background(), db), "app.user_id", "u1")
+
+	if _, err := Exec(ctx, "UPDATE a SET x = 1"); err != nil {
+		t.Fatal(err)
+	}
+
+	err = Atomic(ctx, func(ctx context.Context) error {
+		for i := 0; i < 2; i++ {
+			if _, err := Exec(ctx, "UPDATE a SET x = 1"); err != nil {
+				return err
+			}
+		}
+		ctx = WithSetting(WithSetting(ctx, "app.user_id", "u2"), "app.role", "admin")
+		_, err := Exec(ctx, "UPDATE b SET x = 1")
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if v, ok := SettingFromContext(ctx, "app.user_id"); !ok || v != "u1" {
+		t.Errorf("expected setting u1, got %q", v)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,170 @@
+package bunny
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"hash/fnv"
+	"sync"
+
+	"github.com/sqlbunny/errors"
+)
+
+// ErrNoShardKey is returned by the statements of sharded models when the
+// context has shards, but none was selected with WithShardKey and the
+// statement has no shard key of its own.
+var ErrNoShardKey = errors.New("sqlbunny: no shard key in the query or the context")
+
+// ErrCrossShard is returned by the statements run in a transaction when
+// their shard key belongs to another shard than the transaction's.
+var ErrCrossShard = errors.New("sqlbunny: the shard key belongs to another shard than the transaction")
+
+// Shards are the database pools of a sharded deployment, where the rows of
+// the sharded models are spread across the pools by their shard key.
+type Shards struct {
+	// DBs are the pools of the shards, indexed by shard number.
+	DBs []DB
+	// Hash returns the shard number of a shard key, in [0, len(DBs)). By
+	// default the key is hashed with FNV-1a, after converting driver.Valuer
+	// keys to their value, so typed IDs and their underlying values go to
+	// the same shard.
+	Hash func(key interface{}) int
+}
+
+// Index returns the shard number of key.
+func (s *Shards) Index(key interface{}) int {
+	if s.Hash != nil {
+		return s.Hash(key)
+	}
+	if v, ok := key.(driver.Valuer); ok {
+		if v2, err := v.Value(); err == nil {
+			key = v2
+		}
+	}
+	h := fnv.New32a()
+	if b, ok := key.([]byte); ok {
+		h.Write(b)
+	} else {
+		fmt.Fprint(h, key)
+	}
+	return int(h.Sum32() % uint32(len(s.DBs)))
+}
+
+type contextShardsKeyType struct{}
+
+var contextShardsKey = contextShardsKeyType{}
+
+type contextShardKeyType struct{}
+
+var contextShardKey = contextShardKeyType{}
+
+type contextRequireShardKeyType struct{}
+
+var contextRequireShardKey = contextRequireShardKeyType{}
+
+// ContextWithShards returns a context holding the pools of the shards.
+// Statements of sharded models run in the pool of the shard selected with
+// WithShardKey, which the generated code calls with the shard key of the
+// objects and of the primary keys it's passed, and the qm.ShardKey query mod
+// with the shard key of a query. Without shards in the context, sharded
+// models run in the context's DB like the other models.
+//
+// Once a shard is selected it's the DB of the context, so the statements of
+// the models without a shard key run in it too, and Atomic starts the
+// transaction in it.
+func ContextWithShards(ctx context.Context, shards *Shards) context.Context {
+	return context.WithValue(ctx, contextShardsKey, shards)
+}
+
+// ShardsFromContext returns the shards set with ContextWithShards, or nil.
+func ShardsFromContext(ctx context.Context) *Shards {
+	s, _ := ctx.Value(contextShardsKey).(*Shards)
+	return s
+}
+
+// ShardFromContext returns the number of the shard selected with
+// WithShardKey, and whether there's one.
+func ShardFromContext(ctx context.Context) (int, bool) {
+	i, ok := ctx.Value(contextShardKey).(int)
+	return i, ok
+}
+
+// WithShardKey returns a context in which statements run in the shard of
+// key. If ctx is in a transaction the transaction stays the DB, and its
+// statements fail with ErrCrossShard if key belongs to another shard.
+func WithShardKey(ctx context.Context, key interface{}) context.Context {
+	s := ShardsFromContext(ctx)
+	if s == nil {
+		return ctx
+	}
+	i := s.Index(key)
+	if _, ok := ctx.Value(ContextDBKey).(*txNode); ok {
+		return context.WithValue(ctx, contextShardKey, i)
+	}
+	return withShard(ctx, s, i)
+}
+
+func withShard(ctx context.Context, s *Shards, i int) context.Context {
+	ctx = context.WithValue(ctx, contextShardKey, i)
+	// The replica of the context belongs to no shard.
+	ctx = ContextWithReplica(ctx, nil)
+	return ContextWithDB(ctx, s.DBs[i])
+}
+
+// RequireShard returns a context in which statements fail with
+// ErrNoShardKey if it has shards but none was selected. The generated code
+// of sharded models calls it when there's no shard key in its arguments.
+func RequireShard(ctx context.Context) context.Context {
+	return context.WithValue(ctx, contextRequireShardKey, true)
+}
+
+// checkShard checks the shard statements with ctx run in.
+func checkShard(ctx context.Context) error {
+	if ShardsFromContext(ctx) == nil {
+		return nil
+	}
+	i, ok := ShardFromContext(ctx)
+	if !ok {
+		if required, _ := ctx.Value(contextRequireShardKey).(bool); required {
+			return ErrNoShardKey
+		}
+		return nil
+	}
+	if node, isTx := ctx.Value(ContextDBKey).(*txNode); isTx && (!node.sharded || node.shard != i) {
+		return ErrCrossShard
+	}
+	return nil
+}
+
+// FanOut runs fn concurrently in every shard, with a context in which the
+// shard is selected, and returns the first error. It's meant for the reads
+// spanning all the shards, like the AllShards finisher of the generated
+// queries; fn must guard the results it gathers. Without shards in the
+// context fn runs once, with ctx.
+func FanOut(ctx context.Context, fn func(ctx context.Context) error) error {
+	s := ShardsFromContext(ctx)
+	if s == nil {
+		return fn(ctx)
+	}
+	if _, ok := ctx.Value(ContextDBKey).(*txNode); ok {
+		return errors.New("sqlbunny: FanOut can't run in a transaction")
+	}
+
+	errs := make([]error, len(s.DBs))
+	var wg sync.WaitGroup
+	for i := range s.DBs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = fn(withShard(ctx, s, i))
+		}(i)
+	}
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			return errors.Errorf("shard %d: %w", i, err)
+		}
+	}
+	return nil
+}
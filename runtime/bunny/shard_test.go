@@ -0,0 +1,112 @@
+package bunny
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+	"testing"
+
+	"github.com/sqlbunny/errors"
+	"gopkg.in/DATA-DOG/go-sqlmock.v2"
+)
+
+func TestShardRouting(t *testing.T) {
+	var dbs []DB
+	var mocks []sqlmock.Sqlmock
+	for i := 0; i < 2; i++ {
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatal(err)
+		}
+		dbs = append(dbs, db)
+		mocks = append(mocks, mock)
+	}
+	shards := &Shards{
+		DBs: dbs,
+		Hash: func(key interface{}) int {
+			return key.(int) % 2
+		},
+	}
+
+	mocks[1].ExpectExec(`UPDATE a`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mocks[0].ExpectBegin()
+	mocks[0].ExpectExec(`UPDATE b`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mocks[0].ExpectRollback()
+
+	ctx := ContextWithShards(context.Background(), shards)
+
+	if _, err := Exec(RequireShard(ctx), "UPDATE a SET x = 1"); err != ErrNoShardKey {
+		t.Errorf("expected ErrNoShardKey, got %v", err)
+	}
+	if _, err := Exec(WithShardKey(ctx, 3), "UPDATE a SET x = 1"); err != nil {
+		t.Fatal(err)
+	}
+	err := Atomic(WithShardKey(ctx, 2), func(ctx context.Context) error {
+		if _, err := Exec(WithShardKey(ctx, 4), "UPDATE b SET x = 1"); err != nil {
+			return err
+		}
+		_, err := Exec(WithShardKey(ctx, 5), "UPDATE b SET x = 1")
+		return err
+	})
+	if !errors.Is(err, ErrCrossShard) {
+		t.Errorf("expected ErrCrossShard, got %v", err)
+	}
+
+	for _, mock := range mocks {
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Error(err)
+		}
+	}
+}
+
+func TestFanOut(t *testing.T) {
+	var dbs []DB
+	var mocks []sqlmock.Sqlmock
+	for i := 0; i < 3; i++ {
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatal(err)
+		}
+		mock.ExpectQuery(`SELECT x`).WillReturnRows(sqlmock.NewRows([]string{"x"}).AddRow(i))
+		dbs = append(dbs, db)
+		mocks = append(mocks, mock)
+	}
+	ctx := ContextWithShards(context.Background(), &Shards{DBs: dbs})
+
+	var mu sync.Mutex
+	sum := 0
+	err := FanOut(ctx, func(ctx context.Context) error {
+		var x int
+		if err := QueryRowScan(RequireShard(ctx), "SELECT x FROM a", nil, &x); err != nil {
+			return err
+		}
+		mu.Lock()
+		sum += x
+		mu.Unlock()
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sum != 0+1+2 {
+		t.Errorf("expected the rows of all the shards, got sum %d", sum)
+	}
+
+	for _, mock := range mocks {
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Error(err)
+		}
+	}
+}
+
+func TestShardsIndex(t *testing.T) {
+	s := &Shards{DBs: make([]DB, 4)}
+	if a, b := s.Index("user1"), s.Index(sql.NullString{String: "user1", Valid: true}); a != b {
+		t.Errorf("expected valuers to hash like their value, got shards %d and %d", a, b)
+	}
+	for _, key := range []interface{}{"a", "b", 1, int64(2), []byte("c")} {
+		if i := s.Index(key); i < 0 || i >= 4 {
+			t.Errorf("shard of %v out of range: %d", key, i)
+		}
+	}
+}
@@ -0,0 +1,221 @@
+package bunny
+
+import (
+	"container/list"
+	"context"
+	"database/sql"
+	"sync"
+)
+
+// StmtCache is a DB that prepares statements and caches them, keyed by
+// their SQL text. database/sql takes care of preparing a cached statement
+// again on each pool connection it ends up running on.
+//
+// When the cache holds more than the configured number of statements, the
+// least recently used ones are closed.
+//
+// Statements without arguments are executed directly without preparing
+// them, since they're usually one-off (DDL, migrations) and may contain
+// multiple statements. Caching can also be disabled for a given context with
+// WithoutStmtCache.
+type StmtCache struct {
+	db   *sql.DB
+	size int
+
+	mu    sync.Mutex
+	lru   *list.List
+	stmts map[string]*list.Element
+}
+
+type cachedStmt struct {
+	query   string
+	stmt    *sql.Stmt
+	refs    int
+	evicted bool
+}
+
+var _ DB = &StmtCache{}
+var _ beginTxer = &StmtCache{}
+
+// NewStmtCache creates a StmtCache on top of db, holding at most size
+// prepared statements.
+func NewStmtCache(db *sql.DB, size int) *StmtCache {
+	if size <= 0 {
+		panic("StmtCache size must be positive")
+	}
+	return &StmtCache{
+		db:    db,
+		size:  size,
+		lru:   list.New(),
+		stmts: make(map[string]*list.Element),
+	}
+}
+
+type contextNoStmtCacheKeyType struct{}
+
+var contextNoStmtCacheKey = contextNoStmtCacheKeyType{}
+
+// WithoutStmtCache returns a context in which queries bypass the prepared
+// statement cache.
+func WithoutStmtCache(ctx context.Context) context.Context {
+	return context.WithValue(ctx, contextNoStmtCacheKey, true)
+}
+
+func useStmtCache(ctx context.Context, args []interface{}) bool {
+	if len(args) == 0 {
+		return false
+	}
+	noCache, _ := ctx.Value(contextNoStmtCacheKey).(bool)
+	return !noCache
+}
+
+func (c *StmtCache) acquire(ctx context.Context, query string) (*cachedStmt, error) {
+	c.mu.Lock()
+	if e, ok := c.stmts[query]; ok {
+		c.lru.MoveToFront(e)
+		s := e.Value.(*cachedStmt)
+		s.refs++
+		c.mu.Unlock()
+		return s, nil
+	}
+	c.mu.Unlock()
+
+	// Prepare without holding the lock, so a slow prepare doesn't block
+	// queries using other statements.
+	stmt, err := c.db.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if e, ok := c.stmts[query]; ok {
+		// Someone else prepared the same query concurrently, use theirs.
+		_ = stmt.Close()
+		c.lru.MoveToFront(e)
+		s := e.Value.(*cachedStmt)
+		s.refs++
+		return s, nil
+	}
+
+	s := &cachedStmt{
+		query: query,
+		stmt:  stmt,
+		refs:  1,
+	}
+	c.stmts[query] = c.lru.PushFront(s)
+
+	for c.lru.Len() > c.size {
+		e := c.lru.Back()
+		old := e.Value.(*cachedStmt)
+		c.lru.Remove(e)
+		delete(c.stmts, old.query)
+		old.evicted = true
+		if old.refs == 0 {
+			_ = old.stmt.Close()
+		}
+	}
+
+	return s, nil
+}
+
+func (c *StmtCache) release(s *cachedStmt) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	s.refs--
+	if s.evicted && s.refs == 0 {
+		_ = s.stmt.Close()
+	}
+}
+
+func (c *StmtCache) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	if !useStmtCache(ctx, args) {
+		return c.db.ExecContext(ctx, query, args...)
+	}
+	s, err := c.acquire(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer c.release(s)
+	return s.stmt.ExecContext(ctx, args...)
+}
+
+func (c *StmtCache) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	if !useStmtCache(ctx, args) {
+		return c.db.QueryContext(ctx, query, args...)
+	}
+	s, err := c.acquire(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	// The returned rows keep the statement alive until they're closed,
+	// even if it's evicted in the meantime.
+	defer c.release(s)
+	return s.stmt.QueryContext(ctx, args...)
+}
+
+func (c *StmtCache) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	if !useStmtCache(ctx, args) {
+		return c.db.QueryRowContext(ctx, query, args...)
+	}
+	s, err := c.acquire(ctx, query)
+	if err != nil {
+		// Let database/sql report the prepare error when the row is scanned.
+		return c.db.QueryRowContext(ctx, query, args...)
+	}
+	defer c.release(s)
+	return s.stmt.QueryRowContext(ctx, args...)
+}
+
+func (c *StmtCache) BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error) {
+	return c.db.BeginTx(ctx, opts)
+}
+
+// Stats returns the statistics of the connection pool of the underlying DB.
+func (c *StmtCache) Stats() sql.DBStats {
+	return c.db.Stats()
+}
+
+// PingContext pings the underlying DB.
+func (c *StmtCache) PingContext(ctx context.Context) error {
+	return c.db.PingContext(ctx)
+}
+
+// Len returns the number of statements currently in the cache.
+func (c *StmtCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.lru.Len()
+}
+
+// Close closes all the cached statements. The underlying *sql.DB is not closed.
+func (c *StmtCache) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var err error
+	for e := c.lru.Front(); e != nil; e = e.Next() {
+		s := e.Value.(*cachedStmt)
+		s.evicted = true
+		if s.refs == 0 {
+			if err2 := s.stmt.Close(); err2 != nil && err == nil {
+				err = err2
+			}
+		}
+	}
+	c.lru.Init()
+	c.stmts = make(map[string]*list.Element)
+	return err
+}
+
+// txStmt returns a statement for query usable in tx, from the cache.
+// The returned release function must be called once the statement has been executed.
+func (c *StmtCache) txStmt(ctx context.Context, tx *sql.Tx, query string) (*sql.Stmt, func(), error) {
+	s, err := c.acquire(ctx, query)
+	if err != nil {
+		return nil, nil, err
+	}
+	return tx.StmtContext(ctx, s.stmt), func() { c.release(s) }, nil
+}
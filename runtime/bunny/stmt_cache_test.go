@@ -0,0 +1,93 @@
+package bunny
+
+import (
+	"context"
+	"testing"
+
+	"gopkg.in/DATA-DOG/go-sqlmock.v2"
+)
+
+func TestStmtCacheReuse(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	prep := mock.ExpectPrepare(`SELECT \* FROM "user" WHERE "id"=\$1`)
+	prep.ExpectQuery().WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	prep.ExpectQuery().WithArgs(2).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(2))
+
+	c := NewStmtCache(db, 10)
+	ctx := ContextWithDB(context.Background(), c)
+
+	for _, id := range []int{1, 2} {
+		var got int
+		if err := QueryRow(ctx, `SELECT * FROM "user" WHERE "id"=$1`, id).Scan(&got); err != nil {
+			t.Fatal(err)
+		}
+		if got != id {
+			t.Errorf("expected %d, got %d", id, got)
+		}
+	}
+
+	if c.Len() != 1 {
+		t.Errorf("expected 1 cached statement, got %d", c.Len())
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestStmtCacheEviction(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mock.ExpectPrepare(`UPDATE a`).WillBeClosed().ExpectExec().WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectPrepare(`UPDATE b`).ExpectExec().WithArgs(2).WillReturnResult(sqlmock.NewResult(0, 1))
+
+	c := NewStmtCache(db, 1)
+	ctx := ContextWithDB(context.Background(), c)
+
+	if _, err := Exec(ctx, "UPDATE a SET x = $1", 1); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Exec(ctx, "UPDATE b SET x = $1", 2); err != nil {
+		t.Fatal(err)
+	}
+
+	if c.Len() != 1 {
+		t.Errorf("expected 1 cached statement, got %d", c.Len())
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestStmtCacheOptOut(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mock.ExpectExec(`UPDATE a`).WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`DROP TABLE a`).WillReturnResult(sqlmock.NewResult(0, 0))
+
+	c := NewStmtCache(db, 10)
+	ctx := ContextWithDB(context.Background(), c)
+
+	if _, err := Exec(WithoutStmtCache(ctx), "UPDATE a SET x = $1", 1); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Exec(ctx, "DROP TABLE a"); err != nil {
+		t.Fatal(err)
+	}
+
+	if c.Len() != 0 {
+		t.Errorf("expected no cached statements, got %d", c.Len())
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,92 @@
+package bunny
+
+import (
+	"context"
+
+	"github.com/lib/pq"
+	"github.com/sqlbunny/errors"
+)
+
+type contextTenantKeyType struct{}
+
+var contextTenantKey = contextTenantKeyType{}
+
+// ErrTenantOutsideTransaction is returned by Query when called outside a
+// transaction with a tenant context, or with settings (see WithSetting).
+var ErrTenantOutsideTransaction = errors.New("sqlbunny: tenant queries must run in a transaction")
+
+// WithTenant returns a context in which statements run in the tenant's
+// Postgres schema, for schema-per-tenant deployments. Each tenant schema
+// holds the same tables, and unqualified table names resolve to the
+// tenant's ones.
+//
+// The schema is selected with set_config('search_path', ..., true), which
+// only lasts until the end of the current transaction, so pooled connections
+// never keep a tenant's schema. Inside transactions it's set before the
+// first statement. Outside transactions Exec runs in its own transaction and
+// the generated code wraps reads in one with AtomicTenant, but Query and the
+// Scan of QueryRow fail with ErrTenantOutsideTransaction.
+func WithTenant(ctx context.Context, tenant string) context.Context {
+	return context.WithValue(ctx, contextTenantKey, tenant)
+}
+
+// TenantFromContext returns the tenant set with WithTenant, or "".
+func TenantFromContext(ctx context.Context) string {
+	tenant, _ := ctx.Value(contextTenantKey).(string)
+	return tenant
+}
+
+// AtomicTenant runs fn in a transaction if ctx has a tenant or settings and
+// is not already in a transaction, so fn can run queries in the tenant's
+// schema and with the settings. Otherwise fn runs directly.
+func AtomicTenant(ctx context.Context, fn func(ctx context.Context) error) error {
+	if !needsTx(ctx) || IsAtomic(ctx) {
+		return fn(ctx)
+	}
+	return Atomic(ctx, fn)
+}
+
+// useTenant selects the tenant's schema for the rest of the transaction.
+func (t *txNode) useTenant(ctx context.Context, tenant string) error {
+	if t.tenant == tenant {
+		return nil
+	}
+	if _, err := t.dbTx.ExecContext(ctx, "SELECT set_config('search_path', $1, true)", pq.QuoteIdentifier(tenant)); err != nil {
+		return errors.Errorf("failed to select tenant '%s': %w", tenant, err)
+	}
+	t.tenant = tenant
+	return nil
+}
+
+// needsTx returns whether the statements with ctx must run in a transaction,
+// for its tenant or settings.
+func needsTx(ctx context.Context) bool {
+	return TenantFromContext(ctx) != "" || len(settingsFromContext(ctx)) != 0
+}
+
+// tenantTx prepares the transaction statements with ctx's tenant and settings
+// must run in, selecting the tenant's schema and setting the settings. ok is
+// false if ctx has a tenant or settings but no transaction.
+func tenantTx(ctx context.Context, db DB) (ok bool, err error) {
+	if !needsTx(ctx) {
+		return true, nil
+	}
+	node, ok := db.(*txNode)
+	if !ok {
+		return false, nil
+	}
+	if tenant := TenantFromContext(ctx); tenant != "" {
+		if err := node.useTenant(ctx, tenant); err != nil {
+			return true, err
+		}
+	}
+	return true, node.useSettings(ctx, settingsFromContext(ctx))
+}
+
+// tenantModel namespaces a cache model name with ctx's tenant.
+func tenantModel(ctx context.Context, model string) string {
+	if tenant := TenantFromContext(ctx); tenant != "" {
+		return tenant + "\x00" + model
+	}
+	return model
+}
@@ -0,0 +1,89 @@
+package bunny
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sqlbunny/errors"
+	"gopkg.in/DATA-DOG/go-sqlmock.v2"
+)
+
+func TestTenantExec(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Outside transactions, Exec runs in its own one.
+	mock.ExpectBegin()
+	mock.ExpectExec(`SELECT set_config\('search_path', \$1, true\)`).WithArgs(`"acme"`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`UPDATE a`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	// In transactions, the tenant is selected once, and again when it changes.
+	mock.ExpectBegin()
+	mock.ExpectExec(`SELECT set_config\('search_path', \$1, true\)`).WithArgs(`"acme"`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`UPDATE a`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(`SELECT x FROM a`).WillReturnRows(sqlmock.NewRows([]string{"x"}).AddRow(1))
+	mock.ExpectExec(`SELECT set_config\('search_path', \$1, true\)`).WithArgs(`"globex"`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`UPDATE b`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	ctx := WithTenant(ContextWithDB(context.Background(), db), "acme")
+
+	if _, err := Exec(ctx, "UPDATE a SET x = 1"); err != nil {
+		t.Fatal(err)
+	}
+
+	err = Atomic(ctx, func(ctx context.Context) error {
+		if _, err := Exec(ctx, "UPDATE a SET x = 1"); err != nil {
+			return err
+		}
+		var x int
+		if err := QueryRow(ctx, "SELECT x FROM a").Scan(&x); err != nil {
+			return err
+		}
+		_, err := Exec(WithTenant(ctx, "globex"), "UPDATE b SET x = 1")
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestTenantQuery(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`SELECT set_config\('search_path', \$1, true\)`).WithArgs(`"acme"`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(`SELECT x FROM a`).WillReturnRows(sqlmock.NewRows([]string{"x"}).AddRow(1))
+	mock.ExpectCommit()
+
+	ctx := WithTenant(ContextWithDB(context.Background(), db), "acme")
+
+	if _, err := Query(ctx, "SELECT x FROM a"); !errors.Is(err, ErrTenantOutsideTransaction) {
+		t.Errorf("expected ErrTenantOutsideTransaction, got %v", err)
+	}
+	if err := QueryRow(ctx, "SELECT x FROM a").Scan(new(int)); !errors.Is(err, ErrTenantOutsideTransaction) {
+		t.Errorf("expected ErrTenantOutsideTransaction from QueryRow, got %v", err)
+	}
+
+	var x int
+	if err := QueryRowScan(ctx, "SELECT x FROM a", nil, &x); err != nil {
+		t.Fatal(err)
+	}
+	if x != 1 {
+		t.Errorf("expected 1, got %d", x)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
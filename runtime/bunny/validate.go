@@ -0,0 +1,264 @@
+package bunny
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"net/mail"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/sqlbunny/errors"
+)
+
+// ValidatorFunc checks value against a validation rule. param is the text
+// after "=" in the rule, like "255" in "max=255", or "". Nullable values are
+// passed as their driver value, and only if they're not null.
+type ValidatorFunc func(ctx context.Context, value interface{}, param string) error
+
+// ValidationError is returned by Validate for a field failing a rule.
+type ValidationError struct {
+	// Field is the Go path of the field, like "Home.Zip".
+	Field string
+	Rule  string
+	Err   error
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("sqlbunny: %s fails validation '%s': %v", e.Field, e.Rule, e.Err)
+}
+
+func (e *ValidationError) Unwrap() error {
+	return e.Err
+}
+
+var (
+	validatorsMu sync.RWMutex
+	validators   = map[string]ValidatorFunc{
+		"email": validateEmail,
+		"min":   validateMin,
+		"max":   validateMax,
+		"oneof": validateOneOf,
+	}
+)
+
+// RegisterValidator registers the validator for the rule name, usable in
+// validate tags like the built-in rules:
+//
+//   - required: the value is not null nor zero.
+//   - email: the value is an email address.
+//   - min=n, max=n: the length of strings, or the value of numbers, is in
+//     bounds.
+//   - oneof=a b c: the value is one of the space-separated ones.
+//
+// Registering an existing rule replaces it.
+func RegisterValidator(name string, fn ValidatorFunc) {
+	validatorsMu.Lock()
+	defer validatorsMu.Unlock()
+	validators[name] = fn
+}
+
+type validatedField struct {
+	index []int
+	name  string
+	rules []validationRule
+}
+
+type validationRule struct {
+	name  string
+	param string
+}
+
+var validatedFields sync.Map // reflect.Type -> []validatedField
+
+type contextNoValidationKeyType struct{}
+
+var contextNoValidationKey = contextNoValidationKeyType{}
+
+// WithoutValidation returns a context in which Validate accepts every value,
+// so fixtures and generated tests can write rows without valid field values.
+func WithoutValidation(ctx context.Context) context.Context {
+	return context.WithValue(ctx, contextNoValidationKey, true)
+}
+
+// Validate checks the fields of obj, a pointer to a model, against the rules
+// of their validate tags, like `validate:"required,max=255"`. The generated
+// Insert, Upsert and Update call it, and don't write invalid rows.
+func Validate(ctx context.Context, obj interface{}) error {
+	if ctx.Value(contextNoValidationKey) != nil {
+		return nil
+	}
+	v := reflect.Indirect(reflect.ValueOf(obj))
+	for _, f := range fieldsToValidate(v.Type()) {
+		value, ok := validatedValue(v, f.index)
+		if !ok {
+			continue
+		}
+		for _, rule := range f.rules {
+			var err error
+			if rule.name == "required" {
+				if value == nil || isZero(value) {
+					err = errors.New("value is required")
+				}
+			} else if value != nil {
+				validatorsMu.RLock()
+				fn := validators[rule.name]
+				validatorsMu.RUnlock()
+				if fn == nil {
+					return errors.Errorf("sqlbunny: unknown validation rule '%s' on %s", rule.name, f.name)
+				}
+				err = fn(ctx, value, rule.param)
+			}
+			if err != nil {
+				return &ValidationError{Field: f.name, Rule: rule.name, Err: err}
+			}
+		}
+	}
+	return nil
+}
+
+func isZero(value interface{}) bool {
+	return reflect.DeepEqual(value, reflect.Zero(reflect.TypeOf(value)).Interface())
+}
+
+// validatedValue returns the value of the field at index in v, nil if it's
+// null, or false if it's in a null struct, which isn't validated.
+func validatedValue(v reflect.Value, index []int) (interface{}, bool) {
+	for i, n := range index {
+		if i != 0 {
+			// Null structs wrap the struct with a Valid flag.
+			if valid := v.FieldByName("Valid"); valid.IsValid() && valid.Kind() == reflect.Bool && !valid.Bool() {
+				return nil, false
+			}
+		}
+		v = v.Field(n)
+	}
+
+	value := v.Interface()
+	if valuer, ok := value.(driver.Valuer); ok {
+		// Values failing to convert fail when written.
+		dv, _ := valuer.Value()
+		return dv, true
+	}
+	return value, true
+}
+
+// fieldsToValidate returns the fields of typ with validate tags, including
+// the ones of bound structs.
+func fieldsToValidate(typ reflect.Type) []validatedField {
+	if fields, ok := validatedFields.Load(typ); ok {
+		return fields.([]validatedField)
+	}
+
+	var fields []validatedField
+	var walk func(t reflect.Type, index []int, prefix string)
+	walk = func(t reflect.Type, index []int, prefix string) {
+		for i := 0; i < t.NumField(); i++ {
+			sf := t.Field(i)
+			idx := append(append([]int(nil), index...), i)
+
+			if tag := sf.Tag.Get("validate"); tag != "" {
+				var rules []validationRule
+				for _, r := range strings.Split(tag, ",") {
+					name, param := r, ""
+					if i := strings.IndexByte(r, '='); i != -1 {
+						name, param = r[:i], r[i+1:]
+					}
+					rules = append(rules, validationRule{name: strings.TrimSpace(name), param: param})
+				}
+				fields = append(fields, validatedField{index: idx, name: prefix + sf.Name, rules: rules})
+			}
+
+			if strings.Contains(sf.Tag.Get("bunny"), ",bind") && sf.Type.Kind() == reflect.Struct {
+				ft := sf.Type
+				if strings.Contains(sf.Tag.Get("bunny"), ",null:") && ft.NumField() != 0 && ft.Field(0).Type.Kind() == reflect.Struct {
+					// Null structs hold the struct in their first field.
+					walk(ft.Field(0).Type, append(idx, 0), prefix+sf.Name+".")
+					continue
+				}
+				walk(ft, idx, prefix+sf.Name+".")
+			}
+		}
+	}
+	walk(typ, nil, "")
+
+	validatedFields.Store(typ, fields)
+	return fields
+}
+
+func validateEmail(ctx context.Context, value interface{}, param string) error {
+	s, ok := value.(string)
+	if !ok {
+		return errors.Errorf("%T is not a string", value)
+	}
+	addr, err := mail.ParseAddress(s)
+	if err != nil || addr.Address != s {
+		return errors.New("not an email address")
+	}
+	return nil
+}
+
+func validateMin(ctx context.Context, value interface{}, param string) error {
+	n, err := validatedNumber(value, param)
+	if err != nil {
+		return err
+	}
+	if n.value < n.bound {
+		return errors.Errorf("%s is less than %s", n.what, param)
+	}
+	return nil
+}
+
+func validateMax(ctx context.Context, value interface{}, param string) error {
+	n, err := validatedNumber(value, param)
+	if err != nil {
+		return err
+	}
+	if n.value > n.bound {
+		return errors.Errorf("%s is more than %s", n.what, param)
+	}
+	return nil
+}
+
+type boundedNumber struct {
+	what  string
+	value float64
+	bound float64
+}
+
+// validatedNumber returns the length of strings or the value of numbers,
+// compared by min and max.
+func validatedNumber(value interface{}, param string) (boundedNumber, error) {
+	bound, err := strconv.ParseFloat(param, 64)
+	if err != nil {
+		return boundedNumber{}, errors.Errorf("invalid bound '%s'", param)
+	}
+
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.String:
+		return boundedNumber{"length", float64(utf8.RuneCountInString(v.String())), bound}, nil
+	case reflect.Slice:
+		return boundedNumber{"length", float64(v.Len()), bound}, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return boundedNumber{"value", float64(v.Int()), bound}, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return boundedNumber{"value", float64(v.Uint()), bound}, nil
+	case reflect.Float32, reflect.Float64:
+		return boundedNumber{"value", v.Float(), bound}, nil
+	}
+	return boundedNumber{}, errors.Errorf("%T has no length or value to compare", value)
+}
+
+func validateOneOf(ctx context.Context, value interface{}, param string) error {
+	s := fmt.Sprint(value)
+	for _, option := range strings.Fields(param) {
+		if s == option {
+			return nil
+		}
+	}
+	return errors.Errorf("%s is not one of %s", s, param)
+}
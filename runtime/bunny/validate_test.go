@@ -0,0 +1,106 @@
+package bunny
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sqlbunny/errors"
+	"github.com/sqlbunny/sqlbunny/types/null"
+)
+
+type validatedAddress struct {
+	Zip string `bunny:"zip" validate:"required,max=5"`
+}
+
+type nullValidatedAddress struct {
+	Address validatedAddress
+	Valid   bool
+}
+
+type validatedUser struct {
+	Email    string               `bunny:"email" validate:"required,email"`
+	Nick     null.String          `bunny:"nick" validate:"min=2"`
+	Role     string               `bunny:"role" validate:"oneof=admin staff"`
+	Age      int                  `bunny:"age" validate:"max=150"`
+	Home     validatedAddress     `bunny:"home__,bind"`
+	Previous nullValidatedAddress `bunny:"previous__,bind,null:previous"`
+}
+
+func TestValidate(t *testing.T) {
+	valid := func() *validatedUser {
+		return &validatedUser{Email: "pat@example.com", Role: "staff", Home: validatedAddress{Zip: "08001"}}
+	}
+
+	tests := []struct {
+		modify func(u *validatedUser)
+		field  string
+		rule   string
+	}{
+		{func(u *validatedUser) {}, "", ""},
+		{func(u *validatedUser) { u.Email = "" }, "Email", "required"},
+		{func(u *validatedUser) { u.Email = "pat" }, "Email", "email"},
+		{func(u *validatedUser) { u.Nick = null.StringFrom("p") }, "Nick", "min"},
+		{func(u *validatedUser) { u.Nick = null.StringFrom("pat") }, "", ""},
+		{func(u *validatedUser) { u.Role = "owner" }, "Role", "oneof"},
+		{func(u *validatedUser) { u.Age = 200 }, "Age", "max"},
+		{func(u *validatedUser) { u.Home.Zip = "" }, "Home.Zip", "required"},
+		{func(u *validatedUser) { u.Previous.Valid = true }, "Previous.Zip", "required"},
+	}
+
+	for i, test := range tests {
+		u := valid()
+		test.modify(u)
+		err := Validate(context.Background(), u)
+
+		if test.field == "" {
+			if err != nil {
+				t.Errorf("[%d] unexpected error: %v", i, err)
+			}
+			continue
+		}
+		var verr *ValidationError
+		if !errors.As(err, &verr) {
+			t.Errorf("[%d] expected a ValidationError, got %v", i, err)
+			continue
+		}
+		if verr.Field != test.field || verr.Rule != test.rule {
+			t.Errorf("[%d] expected %s to fail %s, got %v", i, test.field, test.rule, err)
+		}
+	}
+}
+
+func TestWithoutValidation(t *testing.T) {
+	ctx := WithoutValidation(context.Background())
+	if err := Validate(ctx, &validatedUser{}); err != nil {
+		t.Errorf("expected no validation, got %v", err)
+	}
+}
+
+func TestRegisterValidator(t *testing.T) {
+	defer func() {
+		validatorsMu.Lock()
+		delete(validators, "even")
+		validatorsMu.Unlock()
+	}()
+
+	type counter struct {
+		N int `bunny:"n" validate:"even"`
+	}
+
+	if err := Validate(context.Background(), &counter{N: 1}); err == nil {
+		t.Error("expected unknown rules to fail")
+	}
+
+	RegisterValidator("even", func(ctx context.Context, value interface{}, param string) error {
+		if value.(int)%2 != 0 {
+			return errors.New("odd")
+		}
+		return nil
+	})
+	if err := Validate(context.Background(), &counter{N: 2}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := Validate(context.Background(), &counter{N: 3}); err == nil {
+		t.Error("expected the registered validator to fail")
+	}
+}
@@ -0,0 +1,32 @@
+// Package bunnytest has helpers for tests of code using a database.
+package bunnytest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sqlbunny/sqlbunny/runtime/bunny"
+)
+
+// WithRollback runs fn with a context in a transaction of db, which is
+// rolled back when fn returns, also if it fails the test. The tests
+// sharing a database populated once are isolated this way, without
+// recreating the tables.
+//
+// The code under test can call Atomic, whose blocks run in savepoints of
+// the transaction. OnCommit hooks never run.
+func WithRollback(t testing.TB, db bunny.DB, fn func(ctx context.Context)) {
+	t.Helper()
+
+	ctx, rollback, err := bunny.BeginRollback(bunny.ContextWithDB(context.Background(), db))
+	if err != nil {
+		t.Fatalf("bunnytest: unable to begin the transaction: %v", err)
+	}
+	defer func() {
+		if err := rollback(); err != nil {
+			t.Errorf("bunnytest: unable to roll back the transaction: %v", err)
+		}
+	}()
+
+	fn(ctx)
+}
@@ -0,0 +1,90 @@
+package bunnytest
+
+import (
+	"context"
+	"runtime"
+	"testing"
+
+	"github.com/sqlbunny/sqlbunny/runtime/bunny"
+	"gopkg.in/DATA-DOG/go-sqlmock.v2"
+)
+
+// failingT records the failure of a test without failing the running test.
+type failingT struct {
+	testing.TB
+	failed bool
+}
+
+func (t *failingT) Helper() {}
+
+func (t *failingT) FailNow() {
+	t.failed = true
+	runtime.Goexit()
+}
+
+func (t *failingT) Errorf(format string, args ...interface{}) {
+	t.failed = true
+}
+
+func (t *failingT) Fatalf(format string, args ...interface{}) {
+	t.FailNow()
+}
+
+func TestWithRollback(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE a`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`SAVEPOINT savepoint_1`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`UPDATE b`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`RELEASE SAVEPOINT savepoint_1`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	WithRollback(t, db, func(ctx context.Context) {
+		if _, err := bunny.Exec(ctx, "UPDATE a SET x = 1"); err != nil {
+			t.Fatal(err)
+		}
+		err := bunny.Atomic(ctx, func(ctx context.Context) error {
+			_, err := bunny.Exec(ctx, "UPDATE b SET x = 1")
+			return err
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestWithRollbackFailedTest(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectRollback()
+
+	// The test fails in fn, in a goroutine of its own like a subtest.
+	inner := &failingT{}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		WithRollback(inner, db, func(ctx context.Context) {
+			inner.FailNow()
+		})
+	}()
+	<-done
+
+	if !inner.failed {
+		t.Error("expected the test to fail")
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,112 @@
+package bunnytest
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sqlbunny/sqlbunny/runtime/migration"
+	"github.com/sqlbunny/sqlschema/schema"
+)
+
+// UpdateGoldenEnv is the environment variable which, set to 1, makes
+// CheckMigrations write the golden files instead of comparing them.
+const UpdateGoldenEnv = "SQLBUNNY_UPDATE_GOLDEN"
+
+// CheckMigrations renders the SQL of the migrations of the store, in the
+// dialect of the store, and compares the SQL of each migration with its
+// golden file in dir, named after the migration like "00001_1f2e3d.sql".
+//
+// The test fails if a golden file is missing or has other SQL, or if there's
+// a golden file without a migration. So changes of the DDL, by new
+// migrations, edited ones or a new version of sqlbunny, are reviewed as
+// changes of the golden files, written with SQLBUNNY_UPDATE_GOLDEN=1.
+func CheckMigrations(t testing.TB, store *migration.Store, dir string) {
+	t.Helper()
+
+	update := os.Getenv(UpdateGoldenEnv) == "1"
+	d := store.Dialect
+	if d == nil {
+		d = migration.Postgres
+	}
+
+	heads := store.FindHeads()
+	if len(heads) > 1 {
+		t.Fatalf("bunnytest: the migrations have multiple heads %v, merge them first", heads)
+	}
+
+	db := schema.NewDatabase()
+	db.Schemas[""] = schema.NewSchema()
+	rendered := map[string]bool{}
+	if len(heads) == 1 {
+		err := store.RunMigration(heads[0], nil, func(m *migration.Migration) error {
+			stmts, err := d.Statements(db, m.Operations)
+			if err != nil {
+				return err
+			}
+			rendered[m.Name] = true
+			checkGolden(t, filepath.Join(dir, m.Name+".sql"), goldenSQL(stmts), update)
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("bunnytest: unable to render the migrations: %v", err)
+		}
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.sql"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range files {
+		if rendered[strings.TrimSuffix(filepath.Base(f), ".sql")] {
+			continue
+		}
+		if update {
+			if err := os.Remove(f); err != nil {
+				t.Error(err)
+			}
+			continue
+		}
+		t.Errorf("bunnytest: golden file %s has no migration, run the test with %s=1 to remove it", f, UpdateGoldenEnv)
+	}
+}
+
+func goldenSQL(stmts []string) []byte {
+	var buf bytes.Buffer
+	for _, s := range stmts {
+		buf.WriteString(s)
+		buf.WriteString(";\n\n")
+	}
+	return buf.Bytes()
+}
+
+func checkGolden(t testing.TB, path string, sql []byte, update bool) {
+	t.Helper()
+
+	golden, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		t.Error(err)
+		return
+	}
+	if bytes.Equal(golden, sql) && err == nil {
+		return
+	}
+
+	if update {
+		if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+			t.Error(err)
+			return
+		}
+		if err := os.WriteFile(path, sql, 0666); err != nil {
+			t.Error(err)
+		}
+		return
+	}
+	if os.IsNotExist(err) {
+		t.Errorf("bunnytest: golden file %s is missing, run the test with %s=1 to write it", path, UpdateGoldenEnv)
+		return
+	}
+	t.Errorf("bunnytest: the SQL of %s changed, run the test with %s=1 to update it.\nExpected:\n%s\nGot:\n%s", path, UpdateGoldenEnv, golden, sql)
+}
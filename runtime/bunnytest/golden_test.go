@@ -0,0 +1,85 @@
+package bunnytest
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sqlbunny/sqlbunny/runtime/migration"
+	"github.com/sqlbunny/sqlschema/operations"
+)
+
+func testStore() *migration.Store {
+	var s migration.Store
+	s.Register(&migration.Migration{
+		Name: "00001_a",
+		Operations: []operations.Operation{
+			operations.CreateTable{
+				TableName: "book",
+				Columns:   []operations.Column{{Name: "id", Type: "text"}},
+			},
+		},
+	})
+	s.Register(&migration.Migration{
+		Name:         "00002_b",
+		Dependencies: []string{"00001_a"},
+		Operations: []operations.Operation{
+			operations.AlterTable{
+				TableName: "book",
+				Ops: []operations.AlterTableSuboperation{
+					operations.AlterTableAddColumn{Name: "title", Type: "text", Nullable: true},
+				},
+			},
+		},
+	})
+	return &s
+}
+
+func TestCheckMigrations(t *testing.T) {
+	dir := t.TempDir()
+	store := testStore()
+
+	check := func() bool {
+		ft := &failingT{}
+		CheckMigrations(ft, store, dir)
+		return ft.failed
+	}
+
+	if !check() {
+		t.Fatal("expected missing golden files to fail")
+	}
+
+	t.Setenv(UpdateGoldenEnv, "1")
+	if check() {
+		t.Fatal("expected the golden files to be written")
+	}
+	sql, err := os.ReadFile(filepath.Join(dir, "00002_b.sql"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(sql), "title") {
+		t.Errorf("unexpected golden file:\n%s", sql)
+	}
+
+	t.Setenv(UpdateGoldenEnv, "")
+	if check() {
+		t.Fatal("expected the golden files to match")
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "00002_b.sql"), []byte("DROP TABLE book;\n\n"), 0666); err != nil {
+		t.Fatal(err)
+	}
+	if !check() {
+		t.Error("expected changed SQL to fail")
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "00003_c.sql"), nil, 0666); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(UpdateGoldenEnv, "1")
+	check()
+	if _, err := os.Stat(filepath.Join(dir, "00003_c.sql")); !os.IsNotExist(err) {
+		t.Error("expected the golden file without a migration to be removed")
+	}
+}
@@ -0,0 +1,287 @@
+package migration
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/sqlbunny/errors"
+	"github.com/sqlbunny/sqlschema/operations"
+	"github.com/sqlbunny/sqlschema/schema"
+)
+
+// Dialect translates migration operations to the SQL understood by a database.
+type Dialect interface {
+	// Statements returns the SQL statements performing ops, in order.
+	//
+	// db is the schema ops run against, and is updated with them. It can be
+	// nil if the schema isn't known, in which case dialects that need it
+	// return an error.
+	Statements(db *schema.Database, ops []operations.Operation) ([]string, error)
+}
+
+var (
+	// Postgres runs operations as is.
+	Postgres Dialect = postgresDialect{}
+
+	// CockroachDB avoids the Postgres DDL CockroachDB doesn't support: primary key
+	// changes are done with ALTER PRIMARY KEY, ALTER TABLE statements are split
+	// so each runs a single change, and column type changes enable
+	// CockroachDB's general ALTER COLUMN TYPE support first.
+	//
+	// Column types keep their Postgres size, see cockroachType: integer is
+	// INT4, as CockroachDB's INT is INT8, and serials are backed by sequences
+	// instead of becoming INT8 unique_rowid() columns.
+	//
+	// Since the primary key is replaced where the new one is created, columns
+	// of the old primary key can't be dropped in the same migration.
+	CockroachDB Dialect = cockroachDialect{}
+
+	// MySQL translates operations to MySQL 8 DDL, see mysql.go for the type
+	// mapping. It needs the schema the operations run against.
+	MySQL Dialect = mysqlDialect{}
+)
+
+// DialectByName returns the dialect with the given name: "postgres",
+// "cockroachdb" or "mysql".
+func DialectByName(name string) (Dialect, error) {
+	switch name {
+	case "", "postgres":
+		return Postgres, nil
+	case "cockroachdb":
+		return CockroachDB, nil
+	case "mysql":
+		return MySQL, nil
+	}
+	return nil, errors.Errorf("unknown migration dialect '%s'", name)
+}
+
+// statementsOperation is implemented by the operations of this package,
+// which can run more than one statement.
+type statementsOperation interface {
+	Statements() []string
+}
+
+// uniqueIndexSuffix is schema.UniqueIndexSuffix of the generator.
+const uniqueIndexSuffix = "___uidx"
+
+type postgresDialect struct{}
+
+func (postgresDialect) Statements(db *schema.Database, ops []operations.Operation) ([]string, error) {
+	var res []string
+	for _, op := range ops {
+		switch o := op.(type) {
+		case operations.CreateIndex:
+			// The columns can have options, which must not be quoted.
+			res = append(res, fmt.Sprintf("CREATE %s CONCURRENTLY \"%s\" ON %s (%s)", createIndex(o.IndexName), o.IndexName, sqlName(o.SchemaName, o.TableName), indexColumnList(o.Columns)))
+		case statementsOperation:
+			res = append(res, o.Statements()...)
+		default:
+			res = append(res, op.GetSQL())
+		}
+	}
+	return res, applyOperations(db, ops)
+}
+
+type cockroachDialect struct{}
+
+func (cockroachDialect) Statements(db *schema.Database, ops []operations.Operation) ([]string, error) {
+	// Tables whose primary key is dropped, waiting for the new one.
+	droppedPK := make(map[string]bool)
+
+	var res []string
+	for _, op := range ops {
+		switch o := op.(type) {
+		case operations.CreateIndex:
+			// CONCURRENTLY is meaningless for CockroachDB, which always builds
+			// indexes online.
+			res = append(res, fmt.Sprintf("CREATE %s \"%s\" ON %s (%s)", createIndex(o.IndexName), o.IndexName, sqlName(o.SchemaName, o.TableName), indexColumnList(o.Columns)))
+		case operations.CreateTable:
+			columns := make([]operations.Column, len(o.Columns))
+			serial := false
+			for i, c := range o.Columns {
+				typ, isSerial, err := cockroachType(c.Type)
+				if err != nil {
+					return nil, errors.Errorf("table %s column %s: %w", sqlName(o.SchemaName, o.TableName), c.Name, err)
+				}
+				c.Type = typ
+				columns[i] = c
+				serial = serial || isSerial
+			}
+			if serial {
+				res = append(res, cockroachSerialSequences)
+			}
+			o.Columns = columns
+			res = append(res, o.GetSQL())
+		case operations.AlterTable:
+			table := sqlName(o.SchemaName, o.TableName)
+			for _, sub := range o.Ops {
+				switch sub := sub.(type) {
+				case operations.AlterTableAddColumn:
+					typ, serial, err := cockroachType(sub.Type)
+					if err != nil {
+						return nil, errors.Errorf("table %s column %s: %w", table, sub.Name, err)
+					}
+					if serial {
+						res = append(res, cockroachSerialSequences)
+					}
+					sub.Type = typ
+					res = append(res, fmt.Sprintf("ALTER TABLE %s %s", table, sub.GetAlterTableSQL(&o)))
+				case operations.AlterTableDropPrimaryKey:
+					// Tables always have a primary key in CockroachDB, it can
+					// only be replaced by another one.
+					droppedPK[table] = true
+				case operations.AlterTableCreatePrimaryKey:
+					if droppedPK[table] {
+						delete(droppedPK, table)
+						res = append(res, fmt.Sprintf("ALTER TABLE %s ALTER PRIMARY KEY USING COLUMNS (%s)", table, columnList(sub.Columns)))
+					} else {
+						res = append(res, fmt.Sprintf("ALTER TABLE %s %s", table, sub.GetAlterTableSQL(&o)))
+					}
+				case operations.AlterTableSetType:
+					typ, serial, err := cockroachType(sub.Type)
+					if err != nil {
+						return nil, errors.Errorf("table %s column %s: %w", table, sub.Name, err)
+					}
+					if serial {
+						return nil, errors.Errorf("table %s column %s: can't change the type of an existing column to %s", table, sub.Name, sub.Type)
+					}
+					sub.Type = typ
+					res = append(res,
+						"SET enable_experimental_alter_column_type_general = true",
+						fmt.Sprintf("ALTER TABLE %s %s", table, sub.GetAlterTableSQL(&o)),
+					)
+				default:
+					res = append(res, fmt.Sprintf("ALTER TABLE %s %s", table, sub.GetAlterTableSQL(&o)))
+				}
+			}
+		case statementsOperation:
+			res = append(res, o.Statements()...)
+		default:
+			res = append(res, op.GetSQL())
+		}
+	}
+
+	for table := range droppedPK {
+		return nil, errors.Errorf("table %s: CockroachDB doesn't support dropping a primary key without creating a new one", table)
+	}
+	return res, applyOperations(db, ops)
+}
+
+// cockroachTypes maps the Postgres types used in migrations whose meaning
+// differs in CockroachDB to the CockroachDB ones.
+var cockroachTypes = map[string]string{
+	"integer": "INT4",
+	"int":     "INT4",
+	"json":    "JSONB",
+}
+
+// cockroachSerialTypes maps the Postgres serial types to CockroachDB ones,
+// which are only the size of the Postgres ones with cockroachSerialSequences.
+var cockroachSerialTypes = map[string]string{
+	"smallserial": "SERIAL2",
+	"serial2":     "SERIAL2",
+	"serial":      "SERIAL4",
+	"serial4":     "SERIAL4",
+	"bigserial":   "SERIAL8",
+	"serial8":     "SERIAL8",
+}
+
+// cockroachSerialSequences makes CockroachDB back the serial columns created
+// after it by sequences, like Postgres, instead of making them INT8 columns
+// defaulting to unique_rowid().
+const cockroachSerialSequences = "SET serial_normalization = 'sql_sequence'"
+
+// cockroachType returns the CockroachDB type for a Postgres type, and
+// whether it's a serial type. Arrays have the type of their elements, but
+// CockroachDB only supports arrays of one dimension, and not of JSON.
+func cockroachType(typ string) (string, bool, error) {
+	if strings.HasSuffix(typ, "[]") {
+		elem := strings.TrimSuffix(typ, "[]")
+		if strings.HasSuffix(elem, "]") {
+			return "", false, errors.Errorf("type '%s' isn't supported by CockroachDB, which only has arrays of one dimension", typ)
+		}
+		t, serial, err := cockroachType(elem)
+		if err != nil {
+			return "", false, err
+		}
+		if serial || strings.EqualFold(t, "jsonb") {
+			return "", false, errors.Errorf("type '%s' isn't supported by CockroachDB", typ)
+		}
+		return t + "[]", false, nil
+	}
+	if t, ok := cockroachSerialTypes[strings.ToLower(typ)]; ok {
+		return t, true, nil
+	}
+	if t, ok := cockroachTypes[strings.ToLower(typ)]; ok {
+		return t, false, nil
+	}
+	return typ, false, nil
+}
+
+// applyOperations applies ops to db, if known.
+func applyOperations(db *schema.Database, ops []operations.Operation) error {
+	if db == nil {
+		return nil
+	}
+	for _, op := range ops {
+		if err := op.Apply(db); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func sqlName(schema, name string) string {
+	if schema == "" {
+		return fmt.Sprintf("\"%s\"", name)
+	}
+	return fmt.Sprintf("\"%s\".\"%s\"", schema, name)
+}
+
+func columnList(columns []string) string {
+	res := make([]string, len(columns))
+	for i, c := range columns {
+		res[i] = fmt.Sprintf("\"%s\"", c)
+	}
+	return strings.Join(res, ", ")
+}
+
+// createIndex returns "UNIQUE INDEX" for the unique indexes of the
+// generator, whose names end with uniqueIndexSuffix, or "INDEX".
+func createIndex(name string) string {
+	if strings.HasSuffix(name, uniqueIndexSuffix) {
+		return "UNIQUE INDEX"
+	}
+	return "INDEX"
+}
+
+// indexColumn splits a column of an index into the column name and its
+// options, like the operator class in "name varchar_pattern_ops".
+func indexColumn(c string) (name, options string) {
+	if i := strings.IndexByte(c, ' '); i != -1 {
+		return c[:i], c[i+1:]
+	}
+	return c, ""
+}
+
+// indexColumnNames returns the names of the columns of an index.
+func indexColumnNames(columns []string) []string {
+	res := make([]string, len(columns))
+	for i, c := range columns {
+		res[i], _ = indexColumn(c)
+	}
+	return res
+}
+
+// indexColumnList is like columnList, for the columns of an index.
+func indexColumnList(columns []string) string {
+	res := make([]string, len(columns))
+	for i, c := range columns {
+		name, options := indexColumn(c)
+		res[i] = fmt.Sprintf("\"%s\"", name)
+		if options != "" {
+			res[i] += " " + options
+		}
+	}
+	return strings.Join(res, ", ")
+}
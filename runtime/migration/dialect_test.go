@@ -0,0 +1,390 @@
+package migration
+
+import (
+	"testing"
+
+	"github.com/sqlbunny/sqlschema/operations"
+	"github.com/sqlbunny/sqlschema/schema"
+)
+
+func TestIndexOptions(t *testing.T) {
+	ops := []operations.Operation{
+		operations.CreateIndex{TableName: "user", IndexName: "user___name___tenant___idx", Columns: []string{"name varchar_pattern_ops", "tenant"}},
+		operations.CreateIndex{TableName: "user", IndexName: "user___created_at___idx", Columns: []string{"created_at DESC NULLS LAST"}},
+		operations.CreateIndex{TableName: "user", IndexName: "user___email___uidx", Columns: []string{"email"}},
+	}
+
+	got, err := Postgres.Statements(nil, ops)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		`CREATE INDEX CONCURRENTLY "user___name___tenant___idx" ON "user" ("name" varchar_pattern_ops, "tenant")`,
+		`CREATE INDEX CONCURRENTLY "user___created_at___idx" ON "user" ("created_at" DESC NULLS LAST)`,
+		`CREATE UNIQUE INDEX CONCURRENTLY "user___email___uidx" ON "user" ("email")`,
+	}
+	checkEqual(t, "statements", got, want)
+
+	db := schema.NewDatabase()
+	db.Schemas[""] = schema.NewSchema()
+	if _, err := MySQL.Statements(db, ops[:1]); err == nil {
+		t.Error("expected an error for operator classes in MySQL")
+	}
+	if _, err := MySQL.Statements(db, ops[1:2]); err == nil {
+		t.Error("expected an error for nulls ordering in MySQL")
+	}
+
+	got, err = MySQL.Statements(db, []operations.Operation{
+		operations.CreateTable{TableName: "user", Columns: []operations.Column{{Name: "created_at", Type: "timestamptz"}}},
+		operations.CreateIndex{TableName: "user", IndexName: "user___created_at___idx", Columns: []string{"created_at DESC"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkEqual(t, "statements", got[1:], []string{"CREATE INDEX `user___created_at___idx` ON `user` (`created_at` DESC)"})
+}
+
+func TestCockroachDBDialect(t *testing.T) {
+	ops := []operations.Operation{
+		operations.AlterTable{
+			TableName: "user",
+			Ops: []operations.AlterTableSuboperation{
+				operations.AlterTableDropPrimaryKey{},
+			},
+		},
+		operations.AlterTable{
+			TableName: "user",
+			Ops: []operations.AlterTableSuboperation{
+				operations.AlterTableAddColumn{Name: "tenant", Type: "text", Nullable: true},
+				operations.AlterTableSetType{Name: "name", Type: "varchar(64)"},
+			},
+		},
+		operations.CreateIndex{TableName: "user", IndexName: "user___tenant___idx", Columns: []string{"tenant"}},
+		operations.AlterTable{
+			TableName: "user",
+			Ops: []operations.AlterTableSuboperation{
+				operations.AlterTableCreatePrimaryKey{Columns: []string{"tenant", "id"}},
+			},
+		},
+	}
+
+	got, err := CockroachDB.Statements(nil, ops)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		`ALTER TABLE "user" ADD COLUMN "tenant" text`,
+		`SET enable_experimental_alter_column_type_general = true`,
+		`ALTER TABLE "user" ALTER COLUMN "name" TYPE varchar(64)`,
+		`CREATE INDEX "user___tenant___idx" ON "user" ("tenant")`,
+		`ALTER TABLE "user" ALTER PRIMARY KEY USING COLUMNS ("tenant", "id")`,
+	}
+	checkEqual(t, "statements", got, want)
+
+	if _, err := CockroachDB.Statements(nil, ops[:1]); err == nil {
+		t.Error("expected an error when dropping a primary key without a new one")
+	}
+}
+
+func TestCockroachDBTypes(t *testing.T) {
+	ops := []operations.Operation{
+		operations.CreateTable{
+			TableName: "event",
+			Columns: []operations.Column{
+				{Name: "id", Type: "bigserial"},
+				{Name: "count", Type: "integer"},
+				{Name: "data", Type: "json"},
+				{Name: "tags", Type: "text[]"},
+				{Name: "ranks", Type: "integer[]"},
+			},
+		},
+		operations.AlterTable{
+			TableName: "event",
+			Ops: []operations.AlterTableSuboperation{
+				operations.AlterTableAddColumn{Name: "seq", Type: "serial", Nullable: true},
+				operations.AlterTableSetType{Name: "count", Type: "int"},
+			},
+		},
+	}
+
+	got, err := CockroachDB.Statements(nil, ops)
+	if err != nil {
+		t.Fatal(err)
+	}
+	create := operations.CreateTable{
+		TableName: "event",
+		Columns: []operations.Column{
+			{Name: "id", Type: "SERIAL8"},
+			{Name: "count", Type: "INT4"},
+			{Name: "data", Type: "JSONB"},
+			{Name: "tags", Type: "text[]"},
+			{Name: "ranks", Type: "INT4[]"},
+		},
+	}
+	want := []string{
+		`SET serial_normalization = 'sql_sequence'`,
+		create.GetSQL(),
+		`SET serial_normalization = 'sql_sequence'`,
+		`ALTER TABLE "event" ADD COLUMN "seq" SERIAL4`,
+		`SET enable_experimental_alter_column_type_general = true`,
+		`ALTER TABLE "event" ALTER COLUMN "count" TYPE INT4`,
+	}
+	checkEqual(t, "statements", got, want)
+
+	for _, typ := range []string{"jsonb[]", "text[][]"} {
+		ops := []operations.Operation{
+			operations.CreateTable{TableName: "event", Columns: []operations.Column{{Name: "x", Type: typ}}},
+		}
+		if _, err := CockroachDB.Statements(nil, ops); err == nil {
+			t.Errorf("expected an error for %s", typ)
+		}
+	}
+}
+
+func TestMySQLDialect(t *testing.T) {
+	db := schema.NewDatabase()
+	db.Schemas[""] = schema.NewSchema()
+
+	ops := []operations.Operation{
+		operations.CreateTable{
+			TableName: "user",
+			Columns: []operations.Column{
+				{Name: "id", Type: "text"},
+				{Name: "bio", Type: "text", Default: "''"},
+				{Name: "created_at", Type: "timestamptz", Default: "'0001-01-01 00:00:00+00'"},
+				{Name: "email", Type: "text", Nullable: true},
+			},
+		},
+		operations.AlterTable{
+			TableName: "user",
+			Ops: []operations.AlterTableSuboperation{
+				operations.AlterTableCreatePrimaryKey{Columns: []string{"id"}},
+				operations.AlterTableSetNotNull{Name: "email"},
+			},
+		},
+	}
+
+	got, err := MySQL.Statements(db, ops)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"CREATE TABLE `user` (\n" +
+			"    `id` VARCHAR(255) NOT NULL,\n" +
+			"    `bio` LONGTEXT NOT NULL DEFAULT (''),\n" +
+			"    `created_at` DATETIME(6) NOT NULL DEFAULT '0001-01-01 00:00:00',\n" +
+			"    `email` LONGTEXT\n" +
+			")",
+		"ALTER TABLE `user` ADD PRIMARY KEY (`id`)",
+		"ALTER TABLE `user` MODIFY COLUMN `email` LONGTEXT NOT NULL",
+	}
+	checkEqual(t, "statements", got, want)
+
+	// Keys on existing text columns narrow them first, and widen them back
+	// once dropped.
+	ops = []operations.Operation{
+		operations.CreateIndex{TableName: "user", IndexName: "user___email___idx", Columns: []string{"email"}},
+	}
+	got, err = MySQL.Statements(db, ops)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = []string{
+		"ALTER TABLE `user` MODIFY COLUMN `email` VARCHAR(255) NOT NULL",
+		"CREATE INDEX `user___email___idx` ON `user` (`email`)",
+	}
+	checkEqual(t, "statements", got, want)
+
+	ops = []operations.Operation{
+		operations.DropIndex{TableName: "user", IndexName: "user___email___idx"},
+	}
+	got, err = MySQL.Statements(db, ops)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = []string{
+		"DROP INDEX `user___email___idx` ON `user`",
+		"ALTER TABLE `user` MODIFY COLUMN `email` LONGTEXT NOT NULL",
+	}
+	checkEqual(t, "statements", got, want)
+
+	if _, err := MySQL.Statements(nil, ops); err == nil {
+		t.Error("expected an error without the database schema")
+	}
+}
+
+func TestSetStorage(t *testing.T) {
+	ops := []operations.Operation{
+		SetStorage{TableName: "event", Params: map[string]string{"fillfactor": "100", "autovacuum_vacuum_scale_factor": "0.01"}},
+		SetStorage{TableName: "event", IndexName: "event___created_at___idx", Reset: []string{"fillfactor"}, Tablespace: DefaultTablespace},
+	}
+
+	got, err := Postgres.Statements(nil, ops)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		`ALTER TABLE "event" SET (autovacuum_vacuum_scale_factor = 0.01, fillfactor = 100)`,
+		`ALTER INDEX "event___created_at___idx" RESET (fillfactor)`,
+		`ALTER INDEX "event___created_at___idx" SET TABLESPACE "pg_default"`,
+	}
+	checkEqual(t, "statements", got, want)
+}
+
+func TestTriggers(t *testing.T) {
+	ops := []operations.Operation{
+		CreateTrigger{TableName: "user", TriggerName: "set_updated_at", Timing: "BEFORE", Events: []string{"UPDATE"}, UpdateColumns: []string{"name"}, Body: "NEW.updated_at = now(); RETURN NEW;"},
+		CreateTrigger{TableName: "user", TriggerName: "audit", Timing: "AFTER", Events: []string{"INSERT", "DELETE"}, Statement: true, When: "true", Function: "audit()"},
+		DropTrigger{TableName: "user", TriggerName: "set_updated_at", Function: "user___set_updated_at"},
+	}
+
+	got, err := Postgres.Statements(nil, ops)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"CREATE OR REPLACE FUNCTION \"user___set_updated_at\"() RETURNS trigger LANGUAGE plpgsql AS $bunny$\nBEGIN\nNEW.updated_at = now(); RETURN NEW;\nEND\n$bunny$",
+		`CREATE TRIGGER "set_updated_at" BEFORE UPDATE OF "name" ON "user" FOR EACH ROW EXECUTE FUNCTION "user___set_updated_at"()`,
+		`CREATE TRIGGER "audit" AFTER INSERT OR DELETE ON "user" FOR EACH STATEMENT WHEN (true) EXECUTE FUNCTION audit()`,
+		`DROP TRIGGER "set_updated_at" ON "user"`,
+		`DROP FUNCTION "user___set_updated_at"()`,
+	}
+	checkEqual(t, "statements", got, want)
+}
+
+func TestFunctions(t *testing.T) {
+	ops := []operations.Operation{
+		CreateFunction{FunctionName: "invoice_total", Args: []string{"invoice_id bigint"}, Returns: "bigint", Language: "sql", Volatility: "STABLE", Body: "SELECT sum(amount) FROM invoice_line WHERE invoice_id = $1"},
+		CreateFunction{FunctionName: "close_invoices", Procedure: true, Language: "plpgsql", Body: "BEGIN UPDATE invoice SET closed = true; END"},
+		DropFunction{FunctionName: "invoice_total", Args: []string{"invoice_id bigint"}},
+		DropFunction{FunctionName: "close_invoices", Procedure: true},
+	}
+
+	got, err := Postgres.Statements(nil, ops)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"CREATE FUNCTION \"invoice_total\"(invoice_id bigint) RETURNS bigint LANGUAGE sql STABLE AS $bunny$\nSELECT sum(amount) FROM invoice_line WHERE invoice_id = $1\n$bunny$",
+		"CREATE PROCEDURE \"close_invoices\"() LANGUAGE plpgsql AS $bunny$\nBEGIN UPDATE invoice SET closed = true; END\n$bunny$",
+		`DROP FUNCTION "invoice_total"(invoice_id bigint)`,
+		`DROP PROCEDURE "close_invoices"()`,
+	}
+	checkEqual(t, "statements", got, want)
+}
+
+func TestRawSQL(t *testing.T) {
+	ops := []operations.Operation{
+		CreateRawSQL{Up: "CREATE EXTENSION pg_trgm", Down: "DROP EXTENSION pg_trgm"},
+		DropRawSQL{Up: "CREATE EXTENSION pg_trgm", Down: "DROP EXTENSION pg_trgm"},
+		DropRawSQL{Up: "CREATE EXTENSION btree_gist"},
+	}
+	want := []string{"CREATE EXTENSION pg_trgm", "DROP EXTENSION pg_trgm"}
+
+	got, err := Postgres.Statements(nil, ops)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkEqual(t, "statements", got, want)
+
+	db := schema.NewDatabase()
+	db.Schemas[""] = schema.NewSchema()
+	got, err = MySQL.Statements(db, ops)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkEqual(t, "mysql statements", got, want)
+}
+
+func TestPolicies(t *testing.T) {
+	ops := []operations.Operation{
+		SetRowLevelSecurity{TableName: "doc", Enable: true, Force: true},
+		CreatePolicy{TableName: "doc", PolicyName: "owner", Using: "owner_id = current_setting('app.user_id')"},
+		CreatePolicy{TableName: "doc", PolicyName: "no_archived", Restrictive: true, Command: "UPDATE", Roles: []string{"app"}, Using: "NOT archived", WithCheck: "NOT archived"},
+		DropPolicy{TableName: "doc", PolicyName: "owner"},
+		SetRowLevelSecurity{TableName: "doc"},
+	}
+
+	got, err := Postgres.Statements(nil, ops)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		`ALTER TABLE "doc" ENABLE ROW LEVEL SECURITY, FORCE ROW LEVEL SECURITY`,
+		`CREATE POLICY "owner" ON "doc" USING (owner_id = current_setting('app.user_id'))`,
+		`CREATE POLICY "no_archived" ON "doc" AS RESTRICTIVE FOR UPDATE TO app USING (NOT archived) WITH CHECK (NOT archived)`,
+		`DROP POLICY "owner" ON "doc"`,
+		`ALTER TABLE "doc" DISABLE ROW LEVEL SECURITY, NO FORCE ROW LEVEL SECURITY`,
+	}
+	checkEqual(t, "statements", got, want)
+}
+
+func TestGrants(t *testing.T) {
+	ops := []operations.Operation{
+		GrantPrivileges{TableName: "user", Role: "reporting", Privileges: []string{"SELECT"}},
+		GrantPrivileges{TableName: "user", Role: "PUBLIC", Privileges: []string{"INSERT", "UPDATE"}},
+		RevokePrivileges{TableName: "user", Role: "reporting", Privileges: []string{"SELECT"}},
+		GrantPrivileges{SchemaName: "app", TableName: "user", Role: "Admin", Privileges: []string{"ALL"}},
+	}
+
+	got, err := Postgres.Statements(nil, ops)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		`GRANT SELECT ON "user" TO "reporting"`,
+		`GRANT INSERT, UPDATE ON "user" TO PUBLIC`,
+		`REVOKE SELECT ON "user" FROM "reporting"`,
+		`GRANT ALL ON "app"."user" TO "Admin"`,
+	}
+	checkEqual(t, "statements", got, want)
+}
+
+func TestForeignTables(t *testing.T) {
+	ops := []operations.Operation{
+		CreateForeignTable{
+			TableName: "legacy_user",
+			Server:    "legacy",
+			Options:   map[string]string{"table_name": "users", "schema_name": "public"},
+			Columns: []ForeignColumn{
+				{Name: "id", Type: "bigint"},
+				{Name: "name", Type: "text", Default: "''", RemoteName: "full_name"},
+				{Name: "note", Type: "text", Nullable: true, RemoteName: "o'note"},
+			},
+		},
+		DropForeignTable{TableName: "legacy_user"},
+	}
+
+	got, err := Postgres.Statements(nil, ops)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"CREATE FOREIGN TABLE \"legacy_user\" (\n" +
+			"    \"id\" bigint NOT NULL,\n" +
+			"    \"name\" text OPTIONS (column_name 'full_name') NOT NULL DEFAULT '',\n" +
+			"    \"note\" text OPTIONS (column_name 'o''note')\n" +
+			") SERVER \"legacy\" OPTIONS (schema_name 'public', table_name 'users')",
+		`DROP FOREIGN TABLE "legacy_user"`,
+	}
+	checkEqual(t, "statements", got, want)
+}
+
+func TestViews(t *testing.T) {
+	ops := []operations.Operation{
+		CreateView{ViewName: "active_user", Query: "SELECT * FROM \"user\" WHERE active", CheckOption: "LOCAL", DependsOn: []string{"user"}},
+		CreateView{ViewName: "user_name", Query: "SELECT id, name FROM \"user\""},
+		DropView{ViewName: "active_user"},
+	}
+
+	got, err := Postgres.Statements(nil, ops)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		`CREATE VIEW "active_user" AS SELECT * FROM "user" WHERE active WITH LOCAL CHECK OPTION`,
+		`CREATE VIEW "user_name" AS SELECT id, name FROM "user"`,
+		`DROP VIEW "active_user"`,
+	}
+	checkEqual(t, "statements", got, want)
+}
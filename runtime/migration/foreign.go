@@ -0,0 +1,117 @@
+package migration
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/sqlbunny/errors"
+	"github.com/sqlbunny/sqlschema/schema"
+)
+
+// ForeignColumn is a column of a foreign table. RemoteName is the name of
+// the column in the remote table, if it's not Name.
+type ForeignColumn struct {
+	Name       string
+	Type       string
+	Default    string
+	Nullable   bool
+	RemoteName string
+}
+
+// CreateForeignTable is an operation creating a foreign table, whose rows
+// are read and written through the foreign data wrapper of Server, like a
+// postgres_fdw link. Options are the options of the wrapper, like
+// schema_name and table_name.
+//
+// The SQL schema has no foreign tables, so the schema is left unchanged.
+type CreateForeignTable struct {
+	SchemaName string
+	TableName  string
+	Server     string
+	Options    map[string]string
+	Columns    []ForeignColumn
+}
+
+// Statements returns the SQL statements of the operation.
+func (o CreateForeignTable) Statements() []string {
+	var x []string
+	for _, c := range o.Columns {
+		col := fmt.Sprintf("    \"%s\" %s", c.Name, c.Type)
+		if c.RemoteName != "" {
+			col += " OPTIONS (" + foreignOptions(map[string]string{"column_name": c.RemoteName}) + ")"
+		}
+		if !c.Nullable {
+			col += " NOT NULL"
+		}
+		if c.Default != "" {
+			col += " DEFAULT " + c.Default
+		}
+		x = append(x, col)
+	}
+	sql := fmt.Sprintf("CREATE FOREIGN TABLE %s (\n%s\n) SERVER \"%s\"", sqlName(o.SchemaName, o.TableName), strings.Join(x, ",\n"), o.Server)
+	if len(o.Options) != 0 {
+		sql += " OPTIONS (" + foreignOptions(o.Options) + ")"
+	}
+	return []string{sql}
+}
+
+// foreignOptions returns the options of a foreign table or column, sorted
+// by name.
+func foreignOptions(options map[string]string) string {
+	keys := make([]string, 0, len(options))
+	for k := range options {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	res := make([]string, len(keys))
+	for i, k := range keys {
+		res[i] = fmt.Sprintf("%s '%s'", k, strings.ReplaceAll(options[k], "'", "''"))
+	}
+	return strings.Join(res, ", ")
+}
+
+func (o CreateForeignTable) GetSQL() string {
+	return strings.Join(o.Statements(), ";\n")
+}
+
+func (o CreateForeignTable) Dump(w io.Writer) {
+	fmt.Fprintf(w, "migration.CreateForeignTable{\nSchemaName: %q,\nTableName: %q,\nServer: %q,\n", o.SchemaName, o.TableName, o.Server)
+	fmt.Fprintf(w, "Options: %#v,\nColumns: %#v,\n}", o.Options, o.Columns)
+}
+
+func (o CreateForeignTable) Apply(d *schema.Database) error {
+	s, ok := d.Schemas[o.SchemaName]
+	if !ok {
+		return errors.Errorf("no such schema: %s", o.SchemaName)
+	}
+	if _, ok := s.Tables[o.TableName]; ok {
+		return errors.Errorf("table already exists: %s", o.TableName)
+	}
+	return nil
+}
+
+// DropForeignTable is an operation dropping a foreign table. The rows stay
+// in the remote table.
+type DropForeignTable struct {
+	SchemaName string
+	TableName  string
+}
+
+// Statements returns the SQL statements of the operation.
+func (o DropForeignTable) Statements() []string {
+	return []string{fmt.Sprintf("DROP FOREIGN TABLE %s", sqlName(o.SchemaName, o.TableName))}
+}
+
+func (o DropForeignTable) GetSQL() string {
+	return strings.Join(o.Statements(), ";\n")
+}
+
+func (o DropForeignTable) Dump(w io.Writer) {
+	fmt.Fprintf(w, "migration.DropForeignTable{\nSchemaName: %q,\nTableName: %q,\n}", o.SchemaName, o.TableName)
+}
+
+func (o DropForeignTable) Apply(d *schema.Database) error {
+	return checkSchema(d, o.SchemaName)
+}
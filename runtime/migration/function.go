@@ -0,0 +1,95 @@
+package migration
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/sqlbunny/sqlschema/schema"
+)
+
+// CreateFunction is an operation creating the function FunctionName, or
+// the procedure with Procedure, whose body is the SQL of Body in Language.
+// Args are its arguments, "name type" each, and Returns its result type,
+// like "bigint" or "SETOF \"invoice\"". DependsOn are the tables it uses.
+//
+// The SQL schema has no functions, so the schema is left unchanged.
+type CreateFunction struct {
+	SchemaName   string
+	FunctionName string
+	Procedure    bool
+	Args         []string
+	Returns      string
+	Language     string
+	Volatility   string
+	Body         string
+	DependsOn    []string
+}
+
+// Statements returns the SQL statements of the operation.
+func (o CreateFunction) Statements() []string {
+	sql := fmt.Sprintf("CREATE %s %s(%s)", functionKind(o.Procedure), sqlName(o.SchemaName, o.FunctionName), strings.Join(o.Args, ", "))
+	if !o.Procedure {
+		sql += " RETURNS " + o.Returns
+	}
+	sql += " LANGUAGE " + o.Language
+	if o.Volatility != "" {
+		sql += " " + o.Volatility
+	}
+	return []string{sql + " AS $bunny$\n" + o.Body + "\n$bunny$"}
+}
+
+func (o CreateFunction) GetSQL() string {
+	return strings.Join(o.Statements(), ";\n")
+}
+
+func (o CreateFunction) Dump(w io.Writer) {
+	fmt.Fprintf(w, "migration.CreateFunction{\nSchemaName: %q,\nFunctionName: %q,\nProcedure: %t,\n", o.SchemaName, o.FunctionName, o.Procedure)
+	fmt.Fprintf(w, "Args: %#v,\nReturns: %q,\nLanguage: %q,\nVolatility: %q,\n", o.Args, o.Returns, o.Language, o.Volatility)
+	fmt.Fprintf(w, "Body: %q,\nDependsOn: %#v,\n}", o.Body, o.DependsOn)
+}
+
+func (o CreateFunction) Apply(d *schema.Database) error {
+	if err := checkSchema(d, o.SchemaName); err != nil {
+		return err
+	}
+	for _, t := range o.DependsOn {
+		if err := checkTable(d, o.SchemaName, t); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DropFunction is an operation dropping the function FunctionName, or the
+// procedure with Procedure, with the arguments Args of its CreateFunction.
+type DropFunction struct {
+	SchemaName   string
+	FunctionName string
+	Procedure    bool
+	Args         []string
+}
+
+// Statements returns the SQL statements of the operation.
+func (o DropFunction) Statements() []string {
+	return []string{fmt.Sprintf("DROP %s %s(%s)", functionKind(o.Procedure), sqlName(o.SchemaName, o.FunctionName), strings.Join(o.Args, ", "))}
+}
+
+func (o DropFunction) GetSQL() string {
+	return strings.Join(o.Statements(), ";\n")
+}
+
+func (o DropFunction) Dump(w io.Writer) {
+	fmt.Fprintf(w, "migration.DropFunction{\nSchemaName: %q,\nFunctionName: %q,\nProcedure: %t,\nArgs: %#v,\n}", o.SchemaName, o.FunctionName, o.Procedure, o.Args)
+}
+
+func (o DropFunction) Apply(d *schema.Database) error {
+	return checkSchema(d, o.SchemaName)
+}
+
+func functionKind(procedure bool) string {
+	if procedure {
+		return "PROCEDURE"
+	}
+	return "FUNCTION"
+}
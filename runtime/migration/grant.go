@@ -0,0 +1,73 @@
+package migration
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/sqlbunny/sqlschema/schema"
+)
+
+// GrantPrivileges is an operation granting the Privileges, like "SELECT"
+// or "ALL", on a table to Role, or to all roles if it's "PUBLIC".
+//
+// The SQL schema has no privileges, so the schema is left unchanged. The
+// table isn't checked, as it can be a foreign table or a view, which aren't
+// in it.
+type GrantPrivileges struct {
+	SchemaName string
+	TableName  string
+	Role       string
+	Privileges []string
+}
+
+// Statements returns the SQL statements of the operation.
+func (o GrantPrivileges) Statements() []string {
+	return []string{fmt.Sprintf("GRANT %s ON %s TO %s", strings.Join(o.Privileges, ", "), sqlName(o.SchemaName, o.TableName), roleName(o.Role))}
+}
+
+func (o GrantPrivileges) GetSQL() string {
+	return strings.Join(o.Statements(), ";\n")
+}
+
+func (o GrantPrivileges) Dump(w io.Writer) {
+	fmt.Fprintf(w, "migration.GrantPrivileges{\nSchemaName: %q,\nTableName: %q,\nRole: %q,\nPrivileges: %#v,\n}", o.SchemaName, o.TableName, o.Role, o.Privileges)
+}
+
+func (o GrantPrivileges) Apply(d *schema.Database) error {
+	return checkSchema(d, o.SchemaName)
+}
+
+// RevokePrivileges is an operation revoking the Privileges on a table from
+// Role.
+type RevokePrivileges struct {
+	SchemaName string
+	TableName  string
+	Role       string
+	Privileges []string
+}
+
+// Statements returns the SQL statements of the operation.
+func (o RevokePrivileges) Statements() []string {
+	return []string{fmt.Sprintf("REVOKE %s ON %s FROM %s", strings.Join(o.Privileges, ", "), sqlName(o.SchemaName, o.TableName), roleName(o.Role))}
+}
+
+func (o RevokePrivileges) GetSQL() string {
+	return strings.Join(o.Statements(), ";\n")
+}
+
+func (o RevokePrivileges) Dump(w io.Writer) {
+	fmt.Fprintf(w, "migration.RevokePrivileges{\nSchemaName: %q,\nTableName: %q,\nRole: %q,\nPrivileges: %#v,\n}", o.SchemaName, o.TableName, o.Role, o.Privileges)
+}
+
+func (o RevokePrivileges) Apply(d *schema.Database) error {
+	return checkSchema(d, o.SchemaName)
+}
+
+// roleName returns the quoted name of role, or PUBLIC, which is a keyword.
+func roleName(role string) string {
+	if strings.EqualFold(role, "PUBLIC") {
+		return "PUBLIC"
+	}
+	return fmt.Sprintf("\"%s\"", role)
+}
@@ -3,8 +3,10 @@ package migration
 import (
 	"context"
 
+	"github.com/sqlbunny/errors"
 	"github.com/sqlbunny/sqlbunny/runtime/bunny"
 	"github.com/sqlbunny/sqlschema/operations"
+	"github.com/sqlbunny/sqlschema/schema"
 )
 
 type Migration struct {
@@ -14,9 +16,18 @@ type Migration struct {
 }
 
 func (m Migration) Run(ctx context.Context) error {
-	for _, op := range m.Operations {
-		sql := op.GetSQL()
+	return m.RunDialect(ctx, Postgres, nil)
+}
 
+// RunDialect runs the migration with the SQL of the given dialect. db is the
+// schema before the migration, and is updated with it. It can be nil for
+// dialects that don't need it.
+func (m Migration) RunDialect(ctx context.Context, d Dialect, db *schema.Database) error {
+	stmts, err := d.Statements(db, m.Operations)
+	if err != nil {
+		return errors.Errorf("migration '%s': %w", m.Name, err)
+	}
+	for _, sql := range stmts {
 		_, err := bunny.Exec(ctx, sql)
 		if err != nil {
 			return err
@@ -0,0 +1,573 @@
+package migration
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/sqlbunny/errors"
+	"github.com/sqlbunny/sqlschema/operations"
+	"github.com/sqlbunny/sqlschema/schema"
+)
+
+// mysqlTypes maps the Postgres types used in migrations to MySQL ones.
+var mysqlTypes = map[string]string{
+	"smallint":                 "SMALLINT",
+	"integer":                  "INT",
+	"int":                      "INT",
+	"bigint":                   "BIGINT",
+	"real":                     "FLOAT",
+	"double precision":         "DOUBLE",
+	"boolean":                  "BOOLEAN",
+	"json":                     "JSON",
+	"jsonb":                    "JSON",
+	"date":                     "DATE",
+	"timestamp":                "DATETIME(6)",
+	"timestamptz":              "DATETIME(6)",
+	"timestamp with time zone": "DATETIME(6)",
+	"uuid":                     "CHAR(36)",
+}
+
+// mysqlPassthroughTypes are type prefixes with the same syntax in MySQL.
+var mysqlPassthroughTypes = []string{"varchar(", "char(", "numeric(", "decimal("}
+
+// mysqlType returns the MySQL type for a Postgres type. MySQL can't use TEXT
+// and BLOB columns in keys, so text and bytea columns that are part of a
+// primary key, unique, index or foreign key are limited to 255 characters.
+func mysqlType(typ string, key bool) (string, error) {
+	switch typ {
+	case "text":
+		if key {
+			return "VARCHAR(255)", nil
+		}
+		return "LONGTEXT", nil
+	case "bytea":
+		if key {
+			return "VARBINARY(255)", nil
+		}
+		return "LONGBLOB", nil
+	}
+	if t, ok := mysqlTypes[typ]; ok {
+		return t, nil
+	}
+	for _, p := range mysqlPassthroughTypes {
+		if strings.HasPrefix(typ, p) {
+			return strings.ToUpper(typ), nil
+		}
+	}
+	return "", errors.Errorf("type '%s' isn't supported by the MySQL dialect", typ)
+}
+
+// mysqlDefault translates a Postgres default value.
+func mysqlDefault(myType, def string) string {
+	if def == "" {
+		return ""
+	}
+	// MySQL doesn't accept the "+00" zone suffix, DATETIME values are
+	// stored without zone anyway.
+	if myType == "DATETIME(6)" && strings.HasSuffix(def, "+00'") {
+		def = strings.TrimSuffix(def, "+00'") + "'"
+	}
+	// Only expression defaults are allowed for these types.
+	switch myType {
+	case "LONGTEXT", "LONGBLOB", "JSON":
+		def = "(" + def + ")"
+	}
+	return def
+}
+
+type mysqlColumn struct {
+	schema, table, column string
+}
+
+type mysqlDialect struct{}
+
+func (mysqlDialect) Statements(db *schema.Database, ops []operations.Operation) ([]string, error) {
+	if db == nil {
+		return nil, errors.New("the MySQL dialect needs the database schema")
+	}
+	t := &mysqlTranslator{
+		db:    db,
+		keys:  make(map[mysqlColumn]bool),
+		types: make(map[mysqlColumn]string),
+		dirty: make(map[mysqlColumn]bool),
+	}
+	for _, op := range ops {
+		t.scanKeys(op)
+	}
+	for _, op := range ops {
+		if err := t.translate(op); err != nil {
+			return nil, err
+		}
+	}
+	if err := t.reconcileDirty(); err != nil {
+		return nil, err
+	}
+	return t.res, nil
+}
+
+// mysqlTranslator keeps the state of a MySQL translation. Column types depend
+// on whether columns are part of a key, so the actual type of columns created
+// or changed by the operations is tracked, and columns are changed to the
+// right type before keys are created on them and after keys are dropped.
+type mysqlTranslator struct {
+	db  *schema.Database
+	res []string
+
+	// Columns that are part of a key created by the operations.
+	keys map[mysqlColumn]bool
+	// Actual types of the columns created or changed by the operations.
+	types map[mysqlColumn]string
+	// Columns whose type must be checked at the end.
+	dirty map[mysqlColumn]bool
+}
+
+func (t *mysqlTranslator) scanKeys(op operations.Operation) {
+	add := func(schema, table string, columns []string) {
+		for _, c := range columns {
+			t.keys[mysqlColumn{schema, table, c}] = true
+		}
+	}
+	switch o := op.(type) {
+	case operations.CreateIndex:
+		add(o.SchemaName, o.TableName, indexColumnNames(o.Columns))
+	case operations.AlterTable:
+		for _, sub := range o.Ops {
+			switch sub := sub.(type) {
+			case operations.AlterTableCreatePrimaryKey:
+				add(o.SchemaName, o.TableName, sub.Columns)
+			case operations.AlterTableCreateUnique:
+				add(o.SchemaName, o.TableName, sub.Columns)
+			case operations.AlterTableCreateForeignKey:
+				add(o.SchemaName, o.TableName, sub.Columns)
+				add(sub.ForeignSchema, sub.ForeignTable, sub.ForeignColumns)
+			}
+		}
+	}
+}
+
+func (t *mysqlTranslator) table(schemaName, tableName string) (*schema.Table, error) {
+	s, ok := t.db.Schemas[schemaName]
+	if !ok {
+		return nil, errors.Errorf("no such schema: %s", schemaName)
+	}
+	tbl, ok := s.Tables[tableName]
+	if !ok {
+		return nil, errors.Errorf("no such table: %s", tableName)
+	}
+	return tbl, nil
+}
+
+// isKey returns whether a column is part of a key in the current schema.
+func (t *mysqlTranslator) isKey(c mysqlColumn) bool {
+	has := func(columns []string) bool {
+		for _, col := range columns {
+			if col == c.column {
+				return true
+			}
+		}
+		return false
+	}
+	for sn, s := range t.db.Schemas {
+		for tn, tbl := range s.Tables {
+			for _, fk := range tbl.ForeignKeys {
+				if fk.ForeignSchema == c.schema && fk.ForeignTable == c.table && has(fk.ForeignColumns) {
+					return true
+				}
+			}
+			if sn != c.schema || tn != c.table {
+				continue
+			}
+			if tbl.PrimaryKey != nil && has(tbl.PrimaryKey.Columns) {
+				return true
+			}
+			for _, i := range tbl.Indexes {
+				if has(indexColumnNames(i.Columns)) {
+					return true
+				}
+			}
+			for _, u := range tbl.Uniques {
+				if has(u.Columns) {
+					return true
+				}
+			}
+			for _, fk := range tbl.ForeignKeys {
+				if has(fk.LocalColumns) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// currentType returns the MySQL type a column has in the database.
+func (t *mysqlTranslator) currentType(c mysqlColumn, col *schema.Column) (string, error) {
+	if typ, ok := t.types[c]; ok {
+		return typ, nil
+	}
+	return mysqlType(col.Type, t.isKey(c))
+}
+
+// columnDef returns the definition of a column, as used by CREATE TABLE, ADD
+// COLUMN and MODIFY COLUMN.
+func columnDef(name, myType, def string, nullable bool) string {
+	res := fmt.Sprintf("`%s` %s", name, myType)
+	if !nullable {
+		res += " NOT NULL"
+	}
+	if def := mysqlDefault(myType, def); def != "" {
+		res += " DEFAULT " + def
+	}
+	return res
+}
+
+// setType changes the MySQL type of a column if it differs from typ.
+func (t *mysqlTranslator) setType(c mysqlColumn, typ string) error {
+	tbl, err := t.table(c.schema, c.table)
+	if err != nil {
+		return err
+	}
+	col, ok := tbl.Columns[c.column]
+	if !ok {
+		return errors.Errorf("no such column: %s", c.column)
+	}
+	cur, err := t.currentType(c, col)
+	if err != nil {
+		return err
+	}
+	if cur == typ {
+		return nil
+	}
+	t.modifyColumn(c, col, typ)
+	return nil
+}
+
+func (t *mysqlTranslator) modifyColumn(c mysqlColumn, col *schema.Column, myType string) {
+	t.res = append(t.res, fmt.Sprintf("ALTER TABLE %s MODIFY COLUMN %s", mysqlName(c.schema, c.table), columnDef(c.column, myType, col.Default, col.Nullable)))
+	t.types[c] = myType
+}
+
+// prepareKey changes the type of columns about to be part of a key.
+func (t *mysqlTranslator) prepareKey(schemaName, tableName string, columns []string) error {
+	tbl, err := t.table(schemaName, tableName)
+	if err != nil {
+		return err
+	}
+	for _, name := range columns {
+		col, ok := tbl.Columns[name]
+		if !ok {
+			return errors.Errorf("no such column: %s", name)
+		}
+		typ, err := mysqlType(col.Type, true)
+		if err != nil {
+			return err
+		}
+		if err := t.setType(mysqlColumn{schemaName, tableName, name}, typ); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// markDirty marks columns whose key status may change. Their current type is
+// recorded first, since it depends on the key status.
+func (t *mysqlTranslator) markDirty(schemaName, tableName string, columns []string) error {
+	tbl, err := t.table(schemaName, tableName)
+	if err != nil {
+		return err
+	}
+	for _, name := range columns {
+		c := mysqlColumn{schemaName, tableName, name}
+		col, ok := tbl.Columns[name]
+		if !ok {
+			continue
+		}
+		typ, err := t.currentType(c, col)
+		if err != nil {
+			return err
+		}
+		t.types[c] = typ
+		t.dirty[c] = true
+	}
+	return nil
+}
+
+// reconcileDirty changes the dirty columns to the type they should have in
+// the final schema.
+func (t *mysqlTranslator) reconcileDirty() error {
+	var cols []mysqlColumn
+	for c := range t.dirty {
+		cols = append(cols, c)
+	}
+	sortColumns(cols)
+	for _, c := range cols {
+		tbl, err := t.table(c.schema, c.table)
+		if err != nil {
+			// Dropped later on.
+			continue
+		}
+		col, ok := tbl.Columns[c.column]
+		if !ok {
+			continue
+		}
+		typ, err := mysqlType(col.Type, t.isKey(c))
+		if err != nil {
+			return err
+		}
+		if err := t.setType(c, typ); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (t *mysqlTranslator) translate(op operations.Operation) error {
+	switch o := op.(type) {
+	case operations.CreateTable:
+		var cols []string
+		for _, col := range o.Columns {
+			c := mysqlColumn{o.SchemaName, o.TableName, col.Name}
+			typ, err := mysqlType(col.Type, t.keys[c])
+			if err != nil {
+				return err
+			}
+			t.types[c] = typ
+			t.dirty[c] = true
+			cols = append(cols, columnDef(col.Name, typ, col.Default, col.Nullable))
+		}
+		t.res = append(t.res, fmt.Sprintf("CREATE TABLE %s (\n    %s\n)", mysqlName(o.SchemaName, o.TableName), strings.Join(cols, ",\n    ")))
+	case operations.DropTable:
+		t.res = append(t.res, fmt.Sprintf("DROP TABLE %s", mysqlName(o.SchemaName, o.TableName)))
+	case operations.RenameTable:
+		t.res = append(t.res, fmt.Sprintf("RENAME TABLE %s TO %s", mysqlName(o.SchemaName, o.TableName), mysqlName(o.SchemaName, o.NewTableName)))
+		t.moveTable(o.SchemaName, o.TableName, o.SchemaName, o.NewTableName)
+	case operations.SetTableSchema:
+		t.res = append(t.res, fmt.Sprintf("RENAME TABLE %s TO %s", mysqlName(o.SchemaName, o.TableName), mysqlName(o.NewSchemaName, o.TableName)))
+		t.moveTable(o.SchemaName, o.TableName, o.NewSchemaName, o.TableName)
+	case operations.RenameColumn:
+		t.res = append(t.res, fmt.Sprintf("ALTER TABLE %s RENAME COLUMN `%s` TO `%s`", mysqlName(o.SchemaName, o.TableName), o.OldColumnName, o.NewColumnName))
+		t.moveColumn(mysqlColumn{o.SchemaName, o.TableName, o.OldColumnName}, mysqlColumn{o.SchemaName, o.TableName, o.NewColumnName})
+	case operations.CreateIndex:
+		columns := make([]string, len(o.Columns))
+		for i, c := range o.Columns {
+			// MySQL only has the sort direction of the options.
+			name, options := indexColumn(c)
+			columns[i] = fmt.Sprintf("`%s`", name)
+			switch options {
+			case "":
+			case "ASC", "DESC":
+				columns[i] += " " + options
+			default:
+				return errors.Errorf("index %s: the options '%s' of column %s aren't supported by the MySQL dialect", o.IndexName, options, name)
+			}
+		}
+		if err := t.prepareKey(o.SchemaName, o.TableName, indexColumnNames(o.Columns)); err != nil {
+			return err
+		}
+		t.res = append(t.res, fmt.Sprintf("CREATE %s `%s` ON %s (%s)", createIndex(o.IndexName), o.IndexName, mysqlName(o.SchemaName, o.TableName), strings.Join(columns, ", ")))
+	case operations.DropIndex:
+		tbl, err := t.table(o.SchemaName, o.TableName)
+		if err != nil {
+			return err
+		}
+		if i, ok := tbl.Indexes[o.IndexName]; ok {
+			if err := t.markDirty(o.SchemaName, o.TableName, indexColumnNames(i.Columns)); err != nil {
+				return err
+			}
+		}
+		t.res = append(t.res, fmt.Sprintf("DROP INDEX `%s` ON %s", o.IndexName, mysqlName(o.SchemaName, o.TableName)))
+	case operations.CreateSchema:
+		t.res = append(t.res, fmt.Sprintf("CREATE SCHEMA `%s`", o.SchemaName))
+	case operations.DropSchema:
+		t.res = append(t.res, fmt.Sprintf("DROP SCHEMA `%s`", o.SchemaName))
+	case operations.AlterTable:
+		return t.translateAlterTable(o)
+	case operations.SQL:
+		t.res = append(t.res, o.SQL)
+	case CreateRawSQL:
+		t.res = append(t.res, o.Statements()...)
+	case DropRawSQL:
+		t.res = append(t.res, o.Statements()...)
+	default:
+		return errors.Errorf("operation %T isn't supported by the MySQL dialect", op)
+	}
+	return op.Apply(t.db)
+}
+
+func (t *mysqlTranslator) translateAlterTable(o operations.AlterTable) error {
+	table := mysqlName(o.SchemaName, o.TableName)
+	for _, sub := range o.Ops {
+		tbl, err := t.table(o.SchemaName, o.TableName)
+		if err != nil {
+			return err
+		}
+
+		var stmt string
+		modify := ""
+		switch sub := sub.(type) {
+		case operations.AlterTableAddColumn:
+			c := mysqlColumn{o.SchemaName, o.TableName, sub.Name}
+			typ, err := mysqlType(sub.Type, t.keys[c])
+			if err != nil {
+				return err
+			}
+			t.types[c] = typ
+			t.dirty[c] = true
+			stmt = "ADD COLUMN " + columnDef(sub.Name, typ, sub.Default, sub.Nullable)
+		case operations.AlterTableDropColumn:
+			delete(t.types, mysqlColumn{o.SchemaName, o.TableName, sub.Name})
+			stmt = fmt.Sprintf("DROP COLUMN `%s`", sub.Name)
+		case operations.AlterTableCreatePrimaryKey:
+			if err := t.prepareKey(o.SchemaName, o.TableName, sub.Columns); err != nil {
+				return err
+			}
+			stmt = fmt.Sprintf("ADD PRIMARY KEY (%s)", mysqlColumnList(sub.Columns))
+		case operations.AlterTableDropPrimaryKey:
+			if tbl.PrimaryKey != nil {
+				err = t.markDirty(o.SchemaName, o.TableName, tbl.PrimaryKey.Columns)
+			}
+			stmt = "DROP PRIMARY KEY"
+		case operations.AlterTableCreateUnique:
+			if err := t.prepareKey(o.SchemaName, o.TableName, sub.Columns); err != nil {
+				return err
+			}
+			stmt = fmt.Sprintf("ADD CONSTRAINT `%s` UNIQUE (%s)", sub.Name, mysqlColumnList(sub.Columns))
+		case operations.AlterTableDropUnique:
+			if u, ok := tbl.Uniques[sub.Name]; ok {
+				err = t.markDirty(o.SchemaName, o.TableName, u.Columns)
+			}
+			stmt = fmt.Sprintf("DROP INDEX `%s`", sub.Name)
+		case operations.AlterTableCreateForeignKey:
+			if err := t.prepareKey(o.SchemaName, o.TableName, sub.Columns); err != nil {
+				return err
+			}
+			if err := t.prepareKey(sub.ForeignSchema, sub.ForeignTable, sub.ForeignColumns); err != nil {
+				return err
+			}
+			stmt = fmt.Sprintf("ADD CONSTRAINT `%s` FOREIGN KEY (%s) REFERENCES %s (%s)", sub.Name, mysqlColumnList(sub.Columns), mysqlName(sub.ForeignSchema, sub.ForeignTable), mysqlColumnList(sub.ForeignColumns))
+		case *operations.AlterTableDropForeignKey:
+			stmt, err = t.dropForeignKey(o, tbl, sub.Name)
+		case operations.AlterTableDropForeignKey:
+			stmt, err = t.dropForeignKey(o, tbl, sub.Name)
+		case operations.AlterTableSetNotNull:
+			modify = sub.Name
+		case operations.AlterTableSetNull:
+			modify = sub.Name
+		case operations.AlterTableSetType:
+			modify = sub.Name
+			delete(t.types, mysqlColumn{o.SchemaName, o.TableName, sub.Name})
+		case operations.AlterTableSetDefault:
+			c := mysqlColumn{o.SchemaName, o.TableName, sub.Name}
+			col, ok := tbl.Columns[sub.Name]
+			if !ok {
+				return errors.Errorf("no such column: %s", sub.Name)
+			}
+			typ, err := t.currentType(c, col)
+			if err != nil {
+				return err
+			}
+			stmt = fmt.Sprintf("ALTER COLUMN `%s` SET DEFAULT %s", sub.Name, mysqlDefault(typ, sub.Default))
+		case operations.AlterTableDropDefault:
+			stmt = fmt.Sprintf("ALTER COLUMN `%s` DROP DEFAULT", sub.Name)
+		default:
+			return errors.Errorf("operation %T isn't supported by the MySQL dialect", sub)
+		}
+		if err != nil {
+			return err
+		}
+
+		if stmt != "" {
+			t.res = append(t.res, fmt.Sprintf("ALTER TABLE %s %s", table, stmt))
+		}
+		if err := sub.Apply(t.db, tbl, o); err != nil {
+			return errors.Errorf("%T on table %s: %w", sub, o.TableName, err)
+		}
+
+		// MySQL changes nullability and types by redefining the whole column,
+		// which is only known once the operation is applied.
+		if modify != "" {
+			c := mysqlColumn{o.SchemaName, o.TableName, modify}
+			col := tbl.Columns[modify]
+			typ, err := t.currentType(c, col)
+			if err != nil {
+				return err
+			}
+			t.modifyColumn(c, col, typ)
+		}
+	}
+	return nil
+}
+
+func (t *mysqlTranslator) dropForeignKey(o operations.AlterTable, tbl *schema.Table, name string) (string, error) {
+	if fk, ok := tbl.ForeignKeys[name]; ok {
+		if err := t.markDirty(o.SchemaName, o.TableName, fk.LocalColumns); err != nil {
+			return "", err
+		}
+		if err := t.markDirty(fk.ForeignSchema, fk.ForeignTable, fk.ForeignColumns); err != nil {
+			return "", err
+		}
+	}
+	return fmt.Sprintf("DROP FOREIGN KEY `%s`", name), nil
+}
+
+func (t *mysqlTranslator) moveColumn(from, to mysqlColumn) {
+	move := func(m map[mysqlColumn]bool) {
+		if m[from] {
+			delete(m, from)
+			m[to] = true
+		}
+	}
+	move(t.keys)
+	move(t.dirty)
+	if typ, ok := t.types[from]; ok {
+		delete(t.types, from)
+		t.types[to] = typ
+	}
+}
+
+func (t *mysqlTranslator) moveTable(fromSchema, fromTable, toSchema, toTable string) {
+	var cols []mysqlColumn
+	for c := range t.types {
+		cols = append(cols, c)
+	}
+	for c := range t.keys {
+		cols = append(cols, c)
+	}
+	for c := range t.dirty {
+		cols = append(cols, c)
+	}
+	for _, c := range cols {
+		if c.schema == fromSchema && c.table == fromTable {
+			t.moveColumn(c, mysqlColumn{toSchema, toTable, c.column})
+		}
+	}
+}
+
+func sortColumns(cols []mysqlColumn) {
+	sort.Slice(cols, func(i, j int) bool {
+		a, b := cols[i], cols[j]
+		if a.schema != b.schema {
+			return a.schema < b.schema
+		}
+		if a.table != b.table {
+			return a.table < b.table
+		}
+		return a.column < b.column
+	})
+}
+
+func mysqlName(schema, name string) string {
+	if schema == "" {
+		return fmt.Sprintf("`%s`", name)
+	}
+	return fmt.Sprintf("`%s`.`%s`", schema, name)
+}
+
+func mysqlColumnList(columns []string) string {
+	res := make([]string, len(columns))
+	for i, c := range columns {
+		res[i] = fmt.Sprintf("`%s`", c)
+	}
+	return strings.Join(res, ", ")
+}
@@ -0,0 +1,121 @@
+package migration
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/sqlbunny/sqlschema/schema"
+)
+
+// SetRowLevelSecurity is an operation enabling or disabling the row-level
+// security of a table. With Force, the policies also apply to the owner of
+// the table.
+//
+// The SQL schema has no row-level security, so the schema is left unchanged.
+type SetRowLevelSecurity struct {
+	SchemaName string
+	TableName  string
+	Enable     bool
+	Force      bool
+}
+
+// Statements returns the SQL statements of the operation.
+func (o SetRowLevelSecurity) Statements() []string {
+	table := sqlName(o.SchemaName, o.TableName)
+	enable, force := "DISABLE", "NO FORCE"
+	if o.Enable {
+		enable = "ENABLE"
+	}
+	if o.Force {
+		force = "FORCE"
+	}
+	return []string{fmt.Sprintf("ALTER TABLE %s %s ROW LEVEL SECURITY, %s ROW LEVEL SECURITY", table, enable, force)}
+}
+
+func (o SetRowLevelSecurity) GetSQL() string {
+	return strings.Join(o.Statements(), ";\n")
+}
+
+func (o SetRowLevelSecurity) Dump(w io.Writer) {
+	fmt.Fprintf(w, "migration.SetRowLevelSecurity{\nSchemaName: %q,\nTableName: %q,\nEnable: %t,\nForce: %t,\n}", o.SchemaName, o.TableName, o.Enable, o.Force)
+}
+
+func (o SetRowLevelSecurity) Apply(d *schema.Database) error {
+	return checkTable(d, o.SchemaName, o.TableName)
+}
+
+// CreatePolicy is an operation creating the row-level security policy
+// PolicyName of a table, for Command ("ALL", "SELECT", "INSERT", "UPDATE"
+// or "DELETE") and Roles, or all roles if it's empty. Using filters the
+// existing rows the command sees, and WithCheck checks the new ones.
+// Restrictive policies must all pass, and at least one of the others.
+type CreatePolicy struct {
+	SchemaName  string
+	TableName   string
+	PolicyName  string
+	Restrictive bool
+	Command     string
+	Roles       []string
+	Using       string
+	WithCheck   string
+}
+
+// Statements returns the SQL statements of the operation.
+func (o CreatePolicy) Statements() []string {
+	sql := fmt.Sprintf("CREATE POLICY \"%s\" ON %s", o.PolicyName, sqlName(o.SchemaName, o.TableName))
+	if o.Restrictive {
+		sql += " AS RESTRICTIVE"
+	}
+	if o.Command != "" {
+		sql += " FOR " + o.Command
+	}
+	if len(o.Roles) != 0 {
+		sql += " TO " + strings.Join(o.Roles, ", ")
+	}
+	if o.Using != "" {
+		sql += " USING (" + o.Using + ")"
+	}
+	if o.WithCheck != "" {
+		sql += " WITH CHECK (" + o.WithCheck + ")"
+	}
+	return []string{sql}
+}
+
+func (o CreatePolicy) GetSQL() string {
+	return strings.Join(o.Statements(), ";\n")
+}
+
+func (o CreatePolicy) Dump(w io.Writer) {
+	fmt.Fprintf(w, "migration.CreatePolicy{\nSchemaName: %q,\nTableName: %q,\nPolicyName: %q,\nRestrictive: %t,\n", o.SchemaName, o.TableName, o.PolicyName, o.Restrictive)
+	fmt.Fprintf(w, "Command: %q,\nRoles: %#v,\nUsing: %q,\nWithCheck: %q,\n}", o.Command, o.Roles, o.Using, o.WithCheck)
+}
+
+func (o CreatePolicy) Apply(d *schema.Database) error {
+	return checkTable(d, o.SchemaName, o.TableName)
+}
+
+// DropPolicy is an operation dropping the row-level security policy
+// PolicyName of a table.
+type DropPolicy struct {
+	SchemaName string
+	TableName  string
+	PolicyName string
+}
+
+// Statements returns the SQL statements of the operation.
+func (o DropPolicy) Statements() []string {
+	return []string{fmt.Sprintf("DROP POLICY \"%s\" ON %s", o.PolicyName, sqlName(o.SchemaName, o.TableName))}
+}
+
+func (o DropPolicy) GetSQL() string {
+	return strings.Join(o.Statements(), ";\n")
+}
+
+func (o DropPolicy) Dump(w io.Writer) {
+	fmt.Fprintf(w, "migration.DropPolicy{\nSchemaName: %q,\nTableName: %q,\nPolicyName: %q,\n}", o.SchemaName, o.TableName, o.PolicyName)
+}
+
+func (o DropPolicy) Apply(d *schema.Database) error {
+	return checkTable(d, o.SchemaName, o.TableName)
+}
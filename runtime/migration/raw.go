@@ -0,0 +1,68 @@
+package migration
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/sqlbunny/sqlschema/schema"
+)
+
+// CreateRawSQL is an operation running the raw SQL Up, for what the
+// definitions can't express. Down is the SQL undoing it, run by the
+// DropRawSQL of the Up, and DependsOn are the tables it uses.
+//
+// The SQL schema has no raw SQL, so the schema is left unchanged.
+type CreateRawSQL struct {
+	Up        string
+	Down      string
+	DependsOn []string
+}
+
+// Statements returns the SQL statements of the operation.
+func (o CreateRawSQL) Statements() []string {
+	return []string{o.Up}
+}
+
+func (o CreateRawSQL) GetSQL() string {
+	return o.Up
+}
+
+func (o CreateRawSQL) Dump(w io.Writer) {
+	fmt.Fprintf(w, "migration.CreateRawSQL{\nUp: %q,\nDown: %q,\nDependsOn: %#v,\n}", o.Up, o.Down, o.DependsOn)
+}
+
+func (o CreateRawSQL) Apply(d *schema.Database) error {
+	for _, t := range o.DependsOn {
+		if err := checkTable(d, "", t); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DropRawSQL is an operation undoing the CreateRawSQL of Up by running its
+// Down, if it has one.
+type DropRawSQL struct {
+	Up   string
+	Down string
+}
+
+// Statements returns the SQL statements of the operation.
+func (o DropRawSQL) Statements() []string {
+	if o.Down == "" {
+		return nil
+	}
+	return []string{o.Down}
+}
+
+func (o DropRawSQL) GetSQL() string {
+	return o.Down
+}
+
+func (o DropRawSQL) Dump(w io.Writer) {
+	fmt.Fprintf(w, "migration.DropRawSQL{\nUp: %q,\nDown: %q,\n}", o.Up, o.Down)
+}
+
+func (o DropRawSQL) Apply(d *schema.Database) error {
+	return nil
+}
@@ -3,22 +3,46 @@ package migration
 import (
 	"context"
 	"errors"
+	"fmt"
 	"time"
 
 	"github.com/sqlbunny/sqlbunny/runtime/bunny"
+	"github.com/sqlbunny/sqlschema/schema"
 )
 
-const (
-	checkMigrationsTableSQL  = "SELECT count(*) FROM information_schema.tables WHERE table_schema = 'public' AND table_name = 'migrations'"
-	createMigrationsTableSQL = "CREATE TABLE migrations (id text PRIMARY KEY, time timestamptz)"
-	insertMigrationSQL       = "INSERT INTO migrations (id, time) VALUES($1, $2)"
-	selectMigrationsSQL      = "SELECT id from migrations"
-)
+// bookkeepingSQL holds the queries managing the migrations table.
+type bookkeepingSQL struct {
+	checkTable  string
+	createTable string
+	insert      string
+	selectAll   string
+}
+
+var postgresBookkeeping = bookkeepingSQL{
+	checkTable:  "SELECT count(*) FROM information_schema.tables WHERE table_schema = current_schema() AND table_name = 'migrations'",
+	createTable: "CREATE TABLE migrations (id text PRIMARY KEY, time timestamptz)",
+	insert:      "INSERT INTO migrations (id, time) VALUES($1, $2)",
+	selectAll:   "SELECT id from migrations",
+}
+
+var mysqlBookkeeping = bookkeepingSQL{
+	checkTable:  "SELECT count(*) FROM information_schema.tables WHERE table_schema = DATABASE() AND table_name = 'migrations'",
+	createTable: "CREATE TABLE migrations (id VARCHAR(255) PRIMARY KEY, time DATETIME(6))",
+	insert:      "INSERT INTO migrations (id, time) VALUES(?, ?)",
+	selectAll:   "SELECT id from migrations",
+}
+
+func bookkeepingFor(d Dialect) bookkeepingSQL {
+	if _, ok := d.(mysqlDialect); ok {
+		return mysqlBookkeeping
+	}
+	return postgresBookkeeping
+}
 
-func getApplied(ctx context.Context) (map[string]struct{}, error) {
+func getApplied(ctx context.Context, sql bookkeepingSQL) (map[string]struct{}, error) {
 
 	applied := make(map[string]struct{})
-	rows, err := bunny.Query(ctx, selectMigrationsSQL)
+	rows, err := bunny.Query(ctx, sql.selectAll)
 	if err != nil {
 		return nil, err
 	}
@@ -37,17 +61,26 @@ func getApplied(ctx context.Context) (map[string]struct{}, error) {
 }
 
 func (s *Store) Run(ctx context.Context) error {
+	// Migration bookkeeping must never be read from a lagging replica.
+	ctx = bunny.ForceWriter(ctx)
+
+	dialect := s.Dialect
+	if dialect == nil {
+		dialect = Postgres
+	}
+	sql := bookkeepingFor(dialect)
+
 	var count int64
-	if err := bunny.QueryRow(ctx, checkMigrationsTableSQL).Scan(&count); err != nil {
+	if err := bunny.QueryRow(ctx, sql.checkTable).Scan(&count); err != nil {
 		return err
 	}
 	if count == 0 {
-		if _, err := bunny.Exec(ctx, createMigrationsTableSQL); err != nil {
+		if _, err := bunny.Exec(ctx, sql.createTable); err != nil {
 			return err
 		}
 	}
 
-	applied, err := getApplied(ctx)
+	applied, err := getApplied(ctx, sql)
 	if err != nil {
 		return err
 	}
@@ -57,7 +90,7 @@ func (s *Store) Run(ctx context.Context) error {
 		return err
 	}
 
-	rows, err := bunny.Query(ctx, selectMigrationsSQL)
+	rows, err := bunny.Query(ctx, sql.selectAll)
 	if err != nil {
 		return err
 	}
@@ -78,11 +111,22 @@ func (s *Store) Run(ctx context.Context) error {
 	}
 	head := heads[0]
 
-	return s.RunMigration(head, applied, func(m *Migration) error {
-		if err := m.Run(ctx); err != nil {
+	// Walk all the migrations to track the schema they run against, which
+	// dialects may need.
+	db := schema.NewDatabase()
+	db.Schemas[""] = schema.NewSchema()
+
+	return s.RunMigration(head, nil, func(m *Migration) error {
+		if _, ok := applied[m.Name]; ok {
+			if err := applyOperations(db, m.Operations); err != nil {
+				return fmt.Errorf("migration '%s': %w", m.Name, err)
+			}
+			return nil
+		}
+		if err := m.RunDialect(ctx, dialect, db); err != nil {
 			return err
 		}
-		if _, err := bunny.Exec(ctx, insertMigrationSQL, m.Name, time.Now()); err != nil {
+		if _, err := bunny.Exec(ctx, sql.insert, m.Name, time.Now()); err != nil {
 			return err
 		}
 		return nil
@@ -0,0 +1,81 @@
+package migration
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/sqlbunny/errors"
+	"github.com/sqlbunny/sqlschema/schema"
+)
+
+// DefaultTablespace is the Tablespace of SetStorage moving a table or index
+// back to the default tablespace of the database.
+const DefaultTablespace = "pg_default"
+
+// SetStorage is an operation changing the storage of a table, or of the
+// index IndexName of the table: it sets the storage parameters Params, like
+// fillfactor or autovacuum_vacuum_scale_factor, resets the parameters Reset
+// to their defaults, and moves it to Tablespace if it's set.
+//
+// The SQL schema has no storage, so the schema is left unchanged. Moving a
+// table or index to another tablespace rewrites it, with an exclusive lock.
+type SetStorage struct {
+	SchemaName string
+	TableName  string
+	IndexName  string
+	Params     map[string]string
+	Reset      []string
+	Tablespace string
+}
+
+// Statements returns the SQL statements of the operation.
+func (o SetStorage) Statements() []string {
+	kind, name := "TABLE", sqlName(o.SchemaName, o.TableName)
+	if o.IndexName != "" {
+		kind, name = "INDEX", sqlName(o.SchemaName, o.IndexName)
+	}
+
+	var res []string
+	if len(o.Params) != 0 {
+		keys := make([]string, 0, len(o.Params))
+		for k := range o.Params {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		params := make([]string, len(keys))
+		for i, k := range keys {
+			params[i] = k + " = " + o.Params[k]
+		}
+		res = append(res, fmt.Sprintf("ALTER %s %s SET (%s)", kind, name, strings.Join(params, ", ")))
+	}
+	if len(o.Reset) != 0 {
+		res = append(res, fmt.Sprintf("ALTER %s %s RESET (%s)", kind, name, strings.Join(o.Reset, ", ")))
+	}
+	if o.Tablespace != "" {
+		res = append(res, fmt.Sprintf("ALTER %s %s SET TABLESPACE \"%s\"", kind, name, o.Tablespace))
+	}
+	return res
+}
+
+func (o SetStorage) GetSQL() string {
+	return strings.Join(o.Statements(), ";\n")
+}
+
+func (o SetStorage) Dump(w io.Writer) {
+	fmt.Fprintf(w, "migration.SetStorage{\nSchemaName: %q,\nTableName: %q,\nIndexName: %q,\n", o.SchemaName, o.TableName, o.IndexName)
+	fmt.Fprintf(w, "Params: %#v,\nReset: %#v,\nTablespace: %q,\n}", o.Params, o.Reset, o.Tablespace)
+}
+
+func (o SetStorage) Apply(d *schema.Database) error {
+	if err := checkTable(d, o.SchemaName, o.TableName); err != nil {
+		return err
+	}
+	if o.IndexName != "" {
+		if _, ok := d.Schemas[o.SchemaName].Tables[o.TableName].Indexes[o.IndexName]; !ok {
+			return errors.Errorf("no such index: %s", o.IndexName)
+		}
+	}
+	return nil
+}
@@ -8,6 +8,9 @@ import (
 
 type Store struct {
 	Migrations map[string]*Migration
+
+	// Dialect used to run the migrations. Defaults to Postgres.
+	Dialect Dialect
 }
 
 func (s *Store) Register(m *Migration) {
@@ -0,0 +1,62 @@
+package migration
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/lib/pq"
+	"github.com/sqlbunny/errors"
+	"github.com/sqlbunny/sqlbunny/runtime/bunny"
+)
+
+type conner interface {
+	Conn(ctx context.Context) (*sql.Conn, error)
+}
+
+// RunTenants runs the migrations in the schema of each tenant, for
+// schema-per-tenant deployments (see bunny.WithTenant). Missing schemas are
+// created, and each one has its own migrations table.
+//
+// Each tenant is migrated on a dedicated connection with its search_path set,
+// since DDL like CREATE INDEX CONCURRENTLY can't run in the transactions
+// bunny.WithTenant relies on. The DB in ctx must be a *sql.DB. Tenants are
+// migrated in order, stopping at the first error.
+func (s *Store) RunTenants(ctx context.Context, tenants []string) error {
+	if _, ok := s.Dialect.(mysqlDialect); ok {
+		return errors.New("tenant migrations are not supported by the MySQL dialect")
+	}
+	db, ok := bunny.DBFromContext(ctx).(conner)
+	if !ok {
+		return errors.New("tenant migrations need a *sql.DB in the context")
+	}
+	for _, tenant := range tenants {
+		if err := s.runTenant(ctx, db, tenant); err != nil {
+			return errors.Errorf("tenant '%s': %w", tenant, err)
+		}
+	}
+	return nil
+}
+
+func (s *Store) runTenant(ctx context.Context, db conner, tenant string) error {
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	schema := pq.QuoteIdentifier(tenant)
+	if _, err := conn.ExecContext(ctx, "CREATE SCHEMA IF NOT EXISTS "+schema); err != nil {
+		return err
+	}
+	if _, err := conn.ExecContext(ctx, "SET search_path TO "+schema); err != nil {
+		return err
+	}
+	// The connection goes back to the pool afterwards, it must not keep the
+	// tenant's schema.
+	defer conn.ExecContext(context.Background(), "RESET search_path")
+
+	// The search_path is already set, statements must not select a tenant
+	// again.
+	ctx = bunny.WithTenant(ctx, "")
+	return s.Run(bunny.ContextWithDB(ctx, conn))
+}
@@ -0,0 +1,44 @@
+package migration
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sqlbunny/sqlbunny/runtime/bunny"
+	"github.com/sqlbunny/sqlschema/operations"
+	"gopkg.in/DATA-DOG/go-sqlmock.v2"
+)
+
+func TestRunTenants(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := Store{}
+	s.Register(&Migration{
+		Name:       "a",
+		Operations: []operations.Operation{operations.SQL{SQL: "CREATE TABLE a (id text)"}},
+	})
+
+	for _, tenant := range []string{"acme", "globex"} {
+		mock.ExpectExec(`CREATE SCHEMA IF NOT EXISTS "` + tenant + `"`).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(`SET search_path TO "` + tenant + `"`).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(`SELECT count\(\*\) FROM information_schema.tables`).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+		mock.ExpectExec(`CREATE TABLE migrations`).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(`SELECT id from migrations`).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+		mock.ExpectQuery(`SELECT id from migrations`).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+		mock.ExpectExec(`CREATE TABLE a`).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(`INSERT INTO migrations`).WithArgs("a", sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(`RESET search_path`).WillReturnResult(sqlmock.NewResult(0, 0))
+	}
+
+	ctx := bunny.ContextWithDB(context.Background(), db)
+	if err := s.RunTenants(ctx, []string{"acme", "globex"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
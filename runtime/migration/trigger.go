@@ -0,0 +1,133 @@
+package migration
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/sqlbunny/errors"
+	"github.com/sqlbunny/sqlschema/schema"
+)
+
+// CreateTrigger is an operation creating the trigger TriggerName on a
+// table. It runs Timing ("BEFORE", "AFTER" or "INSTEAD OF") the Events
+// ("INSERT", "UPDATE", "DELETE" or "TRUNCATE"), for each row or for each
+// statement, if When is true.
+//
+// The trigger executes Function, a call like "set_updated_at()", or if Body
+// is set, the PL/pgSQL function created for the trigger, with the
+// statements of Body between BEGIN and END.
+//
+// The SQL schema has no triggers, so the schema is left unchanged. The table
+// isn't checked, as it can be a view, which isn't in it.
+type CreateTrigger struct {
+	SchemaName  string
+	TableName   string
+	TriggerName string
+	Timing      string
+	Events      []string
+	// UpdateColumns are the columns whose update fires an UPDATE trigger,
+	// or nil for any column.
+	UpdateColumns []string
+	Statement     bool
+	When          string
+	Function      string
+	Body          string
+}
+
+// TriggerFunctionName returns the name of the function created for the
+// body of the trigger of the table.
+func TriggerFunctionName(table, trigger string) string {
+	return table + "___" + trigger
+}
+
+// Statements returns the SQL statements of the operation.
+func (o CreateTrigger) Statements() []string {
+	var res []string
+	function := o.Function
+	if o.Body != "" {
+		name := sqlName(o.SchemaName, TriggerFunctionName(o.TableName, o.TriggerName))
+		res = append(res, fmt.Sprintf("CREATE OR REPLACE FUNCTION %s() RETURNS trigger LANGUAGE plpgsql AS $bunny$\nBEGIN\n%s\nEND\n$bunny$", name, o.Body))
+		function = name + "()"
+	}
+
+	events := make([]string, len(o.Events))
+	for i, e := range o.Events {
+		events[i] = e
+		if e == "UPDATE" && len(o.UpdateColumns) != 0 {
+			events[i] += " OF " + columnList(o.UpdateColumns)
+		}
+	}
+	forEach := "ROW"
+	if o.Statement {
+		forEach = "STATEMENT"
+	}
+	sql := fmt.Sprintf("CREATE TRIGGER \"%s\" %s %s ON %s FOR EACH %s", o.TriggerName, o.Timing, strings.Join(events, " OR "), sqlName(o.SchemaName, o.TableName), forEach)
+	if o.When != "" {
+		sql += " WHEN (" + o.When + ")"
+	}
+	res = append(res, sql+" EXECUTE FUNCTION "+function)
+	return res
+}
+
+func (o CreateTrigger) GetSQL() string {
+	return strings.Join(o.Statements(), ";\n")
+}
+
+func (o CreateTrigger) Dump(w io.Writer) {
+	fmt.Fprintf(w, "migration.CreateTrigger{\nSchemaName: %q,\nTableName: %q,\nTriggerName: %q,\n", o.SchemaName, o.TableName, o.TriggerName)
+	fmt.Fprintf(w, "Timing: %q,\nEvents: %#v,\nUpdateColumns: %#v,\nStatement: %t,\n", o.Timing, o.Events, o.UpdateColumns, o.Statement)
+	fmt.Fprintf(w, "When: %q,\nFunction: %q,\nBody: %q,\n}", o.When, o.Function, o.Body)
+}
+
+func (o CreateTrigger) Apply(d *schema.Database) error {
+	return checkSchema(d, o.SchemaName)
+}
+
+// DropTrigger is an operation dropping the trigger TriggerName of a table,
+// and the function Function created for its body if it's set.
+type DropTrigger struct {
+	SchemaName  string
+	TableName   string
+	TriggerName string
+	Function    string
+}
+
+// Statements returns the SQL statements of the operation.
+func (o DropTrigger) Statements() []string {
+	res := []string{fmt.Sprintf("DROP TRIGGER \"%s\" ON %s", o.TriggerName, sqlName(o.SchemaName, o.TableName))}
+	if o.Function != "" {
+		res = append(res, fmt.Sprintf("DROP FUNCTION %s()", sqlName(o.SchemaName, o.Function)))
+	}
+	return res
+}
+
+func (o DropTrigger) GetSQL() string {
+	return strings.Join(o.Statements(), ";\n")
+}
+
+func (o DropTrigger) Dump(w io.Writer) {
+	fmt.Fprintf(w, "migration.DropTrigger{\nSchemaName: %q,\nTableName: %q,\nTriggerName: %q,\nFunction: %q,\n}", o.SchemaName, o.TableName, o.TriggerName, o.Function)
+}
+
+func (o DropTrigger) Apply(d *schema.Database) error {
+	return checkSchema(d, o.SchemaName)
+}
+
+func checkTable(d *schema.Database, schemaName, tableName string) error {
+	s, ok := d.Schemas[schemaName]
+	if !ok {
+		return errors.Errorf("no such schema: %s", schemaName)
+	}
+	if _, ok := s.Tables[tableName]; !ok {
+		return errors.Errorf("no such table: %s", tableName)
+	}
+	return nil
+}
+
+func checkSchema(d *schema.Database, schemaName string) error {
+	if _, ok := d.Schemas[schemaName]; !ok {
+		return errors.Errorf("no such schema: %s", schemaName)
+	}
+	return nil
+}
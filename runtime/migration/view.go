@@ -0,0 +1,75 @@
+package migration
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/sqlbunny/errors"
+	"github.com/sqlbunny/sqlschema/schema"
+)
+
+// CreateView is an operation creating a view, whose rows are the rows of
+// Query. With CheckOption "LOCAL" or "CASCADED", the view rejects writes of
+// rows it doesn't show. DependsOn are the tables and views the query reads.
+//
+// The SQL schema has no views, so the schema is left unchanged.
+type CreateView struct {
+	SchemaName  string
+	ViewName    string
+	Query       string
+	CheckOption string
+	DependsOn   []string
+}
+
+// Statements returns the SQL statements of the operation.
+func (o CreateView) Statements() []string {
+	sql := fmt.Sprintf("CREATE VIEW %s AS %s", sqlName(o.SchemaName, o.ViewName), o.Query)
+	if o.CheckOption != "" {
+		sql += " WITH " + o.CheckOption + " CHECK OPTION"
+	}
+	return []string{sql}
+}
+
+func (o CreateView) GetSQL() string {
+	return strings.Join(o.Statements(), ";\n")
+}
+
+func (o CreateView) Dump(w io.Writer) {
+	fmt.Fprintf(w, "migration.CreateView{\nSchemaName: %q,\nViewName: %q,\nQuery: %q,\n", o.SchemaName, o.ViewName, o.Query)
+	fmt.Fprintf(w, "CheckOption: %q,\nDependsOn: %#v,\n}", o.CheckOption, o.DependsOn)
+}
+
+func (o CreateView) Apply(d *schema.Database) error {
+	s, ok := d.Schemas[o.SchemaName]
+	if !ok {
+		return errors.Errorf("no such schema: %s", o.SchemaName)
+	}
+	if _, ok := s.Tables[o.ViewName]; ok {
+		return errors.Errorf("table already exists: %s", o.ViewName)
+	}
+	return nil
+}
+
+// DropView is an operation dropping a view, with its triggers.
+type DropView struct {
+	SchemaName string
+	ViewName   string
+}
+
+// Statements returns the SQL statements of the operation.
+func (o DropView) Statements() []string {
+	return []string{fmt.Sprintf("DROP VIEW %s", sqlName(o.SchemaName, o.ViewName))}
+}
+
+func (o DropView) GetSQL() string {
+	return strings.Join(o.Statements(), ";\n")
+}
+
+func (o DropView) Dump(w io.Writer) {
+	fmt.Fprintf(w, "migration.DropView{\nSchemaName: %q,\nViewName: %q,\n}", o.SchemaName, o.ViewName)
+}
+
+func (o DropView) Apply(d *schema.Database) error {
+	return checkSchema(d, o.SchemaName)
+}
@@ -0,0 +1,76 @@
+// Package pgxbunny provides a bunny.DB using the pgx driver through its
+// database/sql adapter, pgx/stdlib.
+//
+// It's not a native pgx backend: queries go through database/sql, so
+// everything in the runtime works as with lib/pq, while pgx handles the wire
+// protocol. bunny.CopyFrom uses pgx's COPY, and the native *pgx.Conn is
+// available with WithConn for other pgx-specific features.
+package pgxbunny
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/stdlib"
+	"github.com/sqlbunny/errors"
+	"github.com/sqlbunny/sqlbunny/runtime/bunny"
+)
+
+// DB is a bunny.DB using pgx. It can be put in a context with bunny.ContextWithDB.
+type DB struct {
+	*sql.DB
+}
+
+var _ bunny.DB = &DB{}
+
+// Open creates a DB connecting to the database at dsn, which can be a URL
+// or a key=value connection string.
+func Open(dsn string) (*DB, error) {
+	config, err := pgx.ParseConfig(dsn)
+	if err != nil {
+		return nil, errors.Errorf("pgxbunny: %w", err)
+	}
+	return OpenConfig(config), nil
+}
+
+// OpenConfig creates a DB from a pgx connection config.
+func OpenConfig(config *pgx.ConnConfig, opts ...stdlib.OptionOpenDB) *DB {
+	return &DB{
+		DB: stdlib.OpenDB(*config, opts...),
+	}
+}
+
+// WithConn calls fn with a native pgx connection from the pool. The connection
+// must not be used after fn returns.
+func (db *DB) WithConn(ctx context.Context, fn func(conn *pgx.Conn) error) error {
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	return conn.Raw(func(driverConn interface{}) error {
+		return fn(driverConn.(*stdlib.Conn).Conn())
+	})
+}
+
+// CopyFromConn implements bunny.CopyFrom with pgx's native COPY support,
+// which uses the binary format. bunny.CopyFrom runs it on the connection of
+// its transaction, after selecting its tenant and settings.
+func (db *DB) CopyFromConn(ctx context.Context, conn *sql.Conn, table string, columns []string, n int, row func(i int) []interface{}) error {
+	return conn.Raw(func(driverConn interface{}) error {
+		src := pgx.CopyFromSlice(n, func(i int) ([]interface{}, error) {
+			return row(i), nil
+		})
+		_, err := driverConn.(*stdlib.Conn).Conn().CopyFrom(ctx, tableIdentifier(table), columns, src)
+		return err
+	})
+}
+
+// tableIdentifier returns the identifier of table, which can be qualified
+// with its schema.
+func tableIdentifier(table string) pgx.Identifier {
+	return pgx.Identifier(strings.SplitN(table, ".", 2))
+}
@@ -0,0 +1,29 @@
+package pgxbunny
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+)
+
+// DB must implement the interface bunny.CopyFrom uses native COPY through.
+var _ interface {
+	BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error)
+	Conn(ctx context.Context) (*sql.Conn, error)
+	CopyFromConn(ctx context.Context, conn *sql.Conn, table string, columns []string, n int, row func(i int) []interface{}) error
+} = &DB{}
+
+func TestTableIdentifier(t *testing.T) {
+	tests := []struct {
+		table string
+		want  string
+	}{
+		{"user", `"user"`},
+		{"billing.invoice", `"billing"."invoice"`},
+	}
+	for i, test := range tests {
+		if got := tableIdentifier(test.table).Sanitize(); got != test.want {
+			t.Errorf("[%d] expected %s, got %s", i, test.want, got)
+		}
+	}
+}
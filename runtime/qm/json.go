@@ -0,0 +1,77 @@
+package qm
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/sqlbunny/sqlbunny/runtime/queries"
+)
+
+// jsonValue encodes a value as JSON when passed to the database.
+type jsonValue struct {
+	v interface{}
+}
+
+func (j jsonValue) Value() (driver.Value, error) {
+	switch v := j.v.(type) {
+	case json.RawMessage:
+		return string(v), nil
+	case []byte:
+		return string(v), nil
+	}
+	b, err := json.Marshal(j.v)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+// WhereJSONContains filters rows whose jsonb col contains value, encoded as
+// JSON, with the @> operator:
+//
+//	qm.WhereJSONContains("attributes", map[string]interface{}{"color": "red"})
+func WhereJSONContains(col string, value interface{}) QueryMod {
+	return func(q *queries.Query) {
+		queries.AppendWhere(q, fmt.Sprintf("%s @> ?::jsonb", quotePostgresCol(col)), jsonValue{value})
+	}
+}
+
+// WhereJSONHasKey filters rows whose jsonb col has key as a top-level key,
+// with the ? operator.
+func WhereJSONHasKey(col string, key string) QueryMod {
+	return func(q *queries.Query) {
+		queries.AppendWhere(q, fmt.Sprintf(`%s \? ?`, quotePostgresCol(col)), key)
+	}
+}
+
+// WhereJSONField filters rows on the text value at path in the jsonb col,
+// extracted with the -> and ->> operators, compared to value with op:
+//
+//	qm.WhereJSONField("attributes", []string{"size", "unit"}, "=", "cm")
+//
+// The extracted value is text, cast it in a Where clause to compare it as
+// another type.
+func WhereJSONField(col string, path []string, op string, value interface{}) QueryMod {
+	return func(q *queries.Query) {
+		clause, args := jsonPath(col, path)
+		args = append(args, value)
+		queries.AppendWhere(q, fmt.Sprintf("%s %s ?", clause, op), args...)
+	}
+}
+
+// jsonPath returns the expression extracting the text at path in col, and
+// its arguments.
+func jsonPath(col string, path []string) (string, []interface{}) {
+	if len(path) == 0 {
+		panic("qm: empty JSON path")
+	}
+
+	args := make([]interface{}, len(path))
+	for i, key := range path {
+		args[i] = key
+	}
+	ops := strings.Repeat("->?", len(path)-1) + "->>?"
+	return quotePostgresCol(col) + ops, args
+}
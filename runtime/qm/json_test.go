@@ -0,0 +1,62 @@
+package qm
+
+import (
+	"context"
+	"database/sql/driver"
+	"regexp"
+	"testing"
+
+	"github.com/sqlbunny/sqlbunny/runtime/bunny"
+	"github.com/sqlbunny/sqlbunny/runtime/queries"
+	"gopkg.in/DATA-DOG/go-sqlmock.v2"
+)
+
+// expectQuery checks the statement and arguments a select from "t" with mods
+// runs, with the Postgres dialect.
+func expectQuery(t *testing.T, mods []QueryMod, want string, args ...driver.Value) {
+	t.Helper()
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	mock.ExpectQuery("^" + regexp.QuoteMeta(want) + "$").WithArgs(args...).WillReturnRows(sqlmock.NewRows([]string{"x"}))
+
+	q := &queries.Query{}
+	queries.SetDialect(q, &queries.Dialect{LQ: '"', RQ: '"', IndexPlaceholders: true})
+	queries.SetFrom(q, `"t"`)
+	Apply(q, mods...)
+
+	rows, err := q.Query(bunny.ContextWithDB(context.Background(), db))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows.Close()
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestWhereJSONContains(t *testing.T) {
+	expectQuery(t, []QueryMod{WhereJSONContains("attributes", map[string]interface{}{"color": "red"})},
+		`SELECT * FROM "t" WHERE ("attributes" @> $1::jsonb);`, `{"color":"red"}`)
+	expectQuery(t, []QueryMod{WhereJSONContains("t.attributes", []byte(`{"size":1}`))},
+		`SELECT * FROM "t" WHERE ("t"."attributes" @> $1::jsonb);`, `{"size":1}`)
+}
+
+func TestWhereJSONHasKey(t *testing.T) {
+	expectQuery(t, []QueryMod{WhereJSONHasKey("attributes", "color")},
+		`SELECT * FROM "t" WHERE ("attributes" ? $1);`, "color")
+}
+
+func TestWhereJSONField(t *testing.T) {
+	expectQuery(t, []QueryMod{WhereJSONField("attributes", []string{"size", "unit"}, "=", "cm")},
+		`SELECT * FROM "t" WHERE ("attributes"->$1->>$2 = $3);`, "size", "unit", "cm")
+
+	defer func() {
+		if recover() == nil {
+			t.Error("expected a panic for an empty path")
+		}
+	}()
+	WhereJSONField("attributes", nil, "=", "cm")(&queries.Query{})
+}
@@ -1,6 +1,11 @@
 package qm
 
-import "github.com/sqlbunny/sqlbunny/runtime/queries"
+import (
+	"time"
+
+	"github.com/sqlbunny/sqlbunny/runtime/queries"
+	"github.com/sqlbunny/sqlbunny/runtime/strmangle"
+)
 
 // QueryMod to modify the query object
 type QueryMod func(q *queries.Query)
@@ -19,14 +24,163 @@ func SQL(sql string, args ...interface{}) QueryMod {
 	}
 }
 
-// Load allows you to specify foreign key relationships to eager load
+// Load allows you to specify a foreign key relationship to eager load
 // for your query. Passed in relationships need to be in the format
-// MyThing or MyThings.
+// MyThing or MyThings, and nested ones MyThing.MyOtherThings.
 // Relationship name plurality is important, if your relationship is
 // singular, you need to specify the singular form and vice versa.
-func Load(relationships ...string) QueryMod {
+//
+// mods filter, order and limit the rows of the last relationship of the
+// path. The loaded table is aliased "f". The limit and offset apply to the
+// rows of each parent of to-many relationships:
+//
+//	models.Posts(qm.Load("Comments", qm.Where("deleted = false"), qm.OrderBy("created_at DESC"), qm.Limit(10)))
+//
+// The number of rows of to-many relationships can be loaded without the rows
+// with MyThingsCount, into the MyThingsCount field of the loaded struct.
+func Load(relationship string, mods ...QueryMod) QueryMod {
+	return func(q *queries.Query) {
+		queries.AppendLoad(q, relationship)
+		if len(mods) != 0 {
+			fns := make([]func(*queries.Query), len(mods))
+			for i, mod := range mods {
+				fns[i] = mod
+			}
+			queries.AppendLoadMods(q, relationship, fns...)
+		}
+	}
+}
+
+// With adds a common table expression to the query, which can then be
+// referenced by name in the other clauses, like From, InnerJoin and Where.
+// name can include a column list, as in "totals(author_id, n)".
+//
+//	qm.With("recent", "SELECT id FROM book WHERE created_at > ?", since),
+//	qm.Where("id IN (SELECT id FROM recent)")
+func With(name string, clause string, args ...interface{}) QueryMod {
+	return func(q *queries.Query) {
+		queries.AppendWith(q, name, false, clause, args...)
+	}
+}
+
+// WithRecursive adds a recursive common table expression to the query, whose
+// clause can reference itself, for hierarchical queries:
+//
+//	qm.WithRecursive("tree(id)",
+//	    "SELECT id FROM category WHERE id = ? UNION ALL SELECT c.id FROM category c INNER JOIN tree t ON c.parent_id = t.id", rootID),
+//	qm.Where("id IN (SELECT id FROM tree)")
+func WithRecursive(name string, clause string, args ...interface{}) QueryMod {
+	return func(q *queries.Query) {
+		queries.AppendWith(q, name, true, clause, args...)
+	}
+}
+
+// Window selects the window function fn computed over the rows sharing the
+// partitionBy values, ordered by orderBy, as the column alias. Either
+// partitionBy or orderBy can be empty. It's selected in addition to the
+// other columns, or to all of them if none are selected, and is ignored by
+// Count.
+//
+// To bind it, embed the model in a struct with the extra column:
+//
+//	type rankedBook struct {
+//	    models.Book `bunny:",bind"`
+//	    Rank        int `bunny:"rank"`
+//	}
+//	var books []*rankedBook
+//	err := models.Books(qm.Window("row_number()", "author_id", "created_at DESC", "rank")).Bind(ctx, &books)
+//
+// Window columns can't be filtered in the WHERE clause of the same query. To
+// keep the first row of each partition, rank the rows in a CTE (see With) and
+// join it with qm.InnerJoin("ranked r ON r.id = book.id AND r.rank = 1").
+func Window(fn, partitionBy, orderBy, alias string) QueryMod {
 	return func(q *queries.Query) {
-		queries.AppendLoad(q, relationships...)
+		queries.AppendWindow(q, fn, partitionBy, orderBy, alias)
+	}
+}
+
+// Aggregate selects the aggregate function fn of col as the column alias, in
+// addition to the other selected columns. If no columns are selected, only
+// the aggregates are. It's ignored by Count, which counts the groups of
+// grouped queries.
+//
+// To bind the results, use a struct with the grouped and aggregated columns:
+//
+//	var stats []struct {
+//	    UserID     string    `bunny:"user_id"`
+//	    Orders     int64     `bunny:"orders"`
+//	    LastPlaced time.Time `bunny:"last_placed"`
+//	}
+//	err := models.Orders(
+//	    qm.Select("user_id"),
+//	    qm.Count("*", "orders"),
+//	    qm.Max("created_at", "last_placed"),
+//	    qm.GroupBy("user_id"),
+//	).Bind(ctx, &stats)
+func Aggregate(fn, col, alias string) QueryMod {
+	return func(q *queries.Query) {
+		queries.AppendAggregate(q, fn, col, alias)
+	}
+}
+
+// Count selects the number of rows with a non-null col, or of all rows if
+// col is "*", as the column alias. See Aggregate.
+func Count(col, alias string) QueryMod {
+	return Aggregate("count", col, alias)
+}
+
+// Sum selects the sum of col as the column alias. See Aggregate.
+func Sum(col, alias string) QueryMod {
+	return Aggregate("sum", col, alias)
+}
+
+// Avg selects the average of col as the column alias. See Aggregate.
+func Avg(col, alias string) QueryMod {
+	return Aggregate("avg", col, alias)
+}
+
+// Min selects the minimum of col as the column alias. See Aggregate.
+func Min(col, alias string) QueryMod {
+	return Aggregate("min", col, alias)
+}
+
+// Max selects the maximum of col as the column alias. See Aggregate.
+func Max(col, alias string) QueryMod {
+	return Aggregate("max", col, alias)
+}
+
+// Union combines the rows of the query with the ones of other, removing
+// duplicates. The ORDER BY, LIMIT and OFFSET of the query apply to the
+// combined rows, while other keeps its own. Both queries must select
+// compatible columns, paired by position: the builder panics if they select a
+// different number of columns, while the database checks their types. Queries
+// selecting all columns aren't checked before they're run.
+//
+//	models.Books(qm.Where("author_id = ?", id), qm.Union(models.Books(qm.Where("editor_id = ?", id)).Query))
+func Union(other *queries.Query) QueryMod {
+	return func(q *queries.Query) {
+		queries.AppendSetOp(q, "UNION", other)
+	}
+}
+
+// UnionAll is like Union, but keeps duplicates.
+func UnionAll(other *queries.Query) QueryMod {
+	return func(q *queries.Query) {
+		queries.AppendSetOp(q, "UNION ALL", other)
+	}
+}
+
+// Intersect keeps the rows of the query that are also returned by other, like Union.
+func Intersect(other *queries.Query) QueryMod {
+	return func(q *queries.Query) {
+		queries.AppendSetOp(q, "INTERSECT", other)
+	}
+}
+
+// Except removes the rows returned by other from the ones of the query, like Union.
+func Except(other *queries.Query) QueryMod {
+	return func(q *queries.Query) {
+		queries.AppendSetOp(q, "EXCEPT", other)
 	}
 }
 
@@ -37,6 +191,48 @@ func InnerJoin(clause string, args ...interface{}) QueryMod {
 	}
 }
 
+// InnerJoinOn joins the table on the equalities of columns built with On,
+// with typed columns instead of the clause of InnerJoin, for example the
+// users with their orders:
+//
+//	models.Users(qm.InnerJoinOn("order", qm.On(models.OrderTableColumns.UserID, models.UserTableColumns.ID)))
+func InnerJoinOn(table string, on ...queries.JoinCondition) QueryMod {
+	return func(q *queries.Query) {
+		queries.AppendJoinOn(q, queries.JoinInner, table, on...)
+	}
+}
+
+// LeftJoinOn is like InnerJoinOn, but keeps the rows without a joined row.
+func LeftJoinOn(table string, on ...queries.JoinCondition) QueryMod {
+	return func(q *queries.Query) {
+		queries.AppendJoinOn(q, queries.JoinOuterLeft, table, on...)
+	}
+}
+
+// On is the equality of the columns a and b of a join, which only compiles
+// if their values have the same Go type. The columns of nullable fields have
+// the type of their non null values.
+func On[T any](a, b queries.Column[T]) queries.JoinCondition {
+	return queries.JoinCondition{Left: a, Right: b}
+}
+
+// SelectColumns selects the columns of tables, like Select. With joins, the
+// columns are returned with their qualified names, like "order.id", so the
+// rows can be bound into a struct of the joined models, tagged with the
+// names of their tables:
+//
+//	type UserOrder struct {
+//		User  models.User  `bunny:"user.,bind"`
+//		Order models.Order `bunny:"order.,bind"`
+//	}
+func SelectColumns(cols ...queries.TableColumn) QueryMod {
+	return func(q *queries.Query) {
+		for _, c := range cols {
+			queries.AppendSelect(q, c.TableName()+"."+c.ColumnName())
+		}
+	}
+}
+
 // Select specific fields opposed to all fields
 func Select(fields ...string) QueryMod {
 	return func(q *queries.Query) {
@@ -44,7 +240,37 @@ func Select(fields ...string) QueryMod {
 	}
 }
 
-// Where allows you to specify a where clause for your statement
+// Unscoped removes the default scope of the model from the query, to see the
+// rows it filters out, like soft-deleted ones.
+func Unscoped() QueryMod {
+	return func(q *queries.Query) {
+		queries.SetUnscoped(q)
+	}
+}
+
+// Distinct removes duplicate rows from the results.
+func Distinct() QueryMod {
+	return func(q *queries.Query) {
+		queries.SetDistinct(q)
+	}
+}
+
+// DistinctOn keeps only the first row of each set of rows with the same
+// values for cols, like Postgres' DISTINCT ON. The ORDER BY clauses must
+// start with cols, in any order, and the next ones pick the row kept,
+// for example the latest book of each author:
+//
+//	models.Books(qm.DistinctOn("author_id"), qm.OrderBy("author_id"), qm.OrderBy("created_at DESC"))
+func DistinctOn(cols ...string) QueryMod {
+	return func(q *queries.Query) {
+		queries.AppendDistinctOn(q, cols...)
+	}
+}
+
+// Where allows you to specify a where clause for your statement.
+// Arguments that are queries are embedded as subqueries:
+//
+//	qm.Where("EXISTS ?", models.Orders(qm.Where("orders.user_id = users.id")).Query)
 func Where(clause string, args ...interface{}) QueryMod {
 	return func(q *queries.Query) {
 		queries.AppendWhere(q, clause, args...)
@@ -53,6 +279,10 @@ func Where(clause string, args ...interface{}) QueryMod {
 
 // WhereIn allows you to specify a "x IN (set)" clause for your where statement
 // Example clauses: "field in ?", "(field1,field2) in ?"
+//
+// The set can also be another query, passed as the only argument:
+//
+//	qm.WhereIn("id in ?", models.Orders(qm.Select("user_id")).Query)
 func WhereIn(clause string, args ...interface{}) QueryMod {
 	return func(q *queries.Query) {
 		queries.AppendIn(q, clause, args...)
@@ -67,9 +297,9 @@ func GroupBy(clause string) QueryMod {
 }
 
 // OrderBy allows you to specify a order by clause for your statement
-func OrderBy(clause string) QueryMod {
+func OrderBy(clause string, args ...interface{}) QueryMod {
 	return func(q *queries.Query) {
-		queries.AppendOrderBy(q, clause)
+		queries.AppendOrderBy(q, clause, args...)
 	}
 }
 
@@ -87,6 +317,19 @@ func From(from string) QueryMod {
 	}
 }
 
+// As aliases the table of the model in the query, so it can be joined with
+// itself. The other references to the table must then use the alias, for
+// example the employees with a manager named Ana:
+//
+//	models.Employees(qm.As("e"),
+//		qm.InnerJoin(`"employees" AS "m" ON "m"."id" = "e"."manager_id"`),
+//		qm.Where(`"m"."name" = ?`, "Ana"))
+func As(alias string) QueryMod {
+	return func(q *queries.Query) {
+		queries.SetAlias(q, alias)
+	}
+}
+
 // Limit the number of returned rows
 func Limit(limit int) QueryMod {
 	return func(q *queries.Query) {
@@ -107,3 +350,59 @@ func For(clause string) QueryMod {
 		queries.SetFor(q, clause)
 	}
 }
+
+// ForUpdate locks the selected rows for update.
+func ForUpdate() QueryMod {
+	return For("UPDATE")
+}
+
+// ForNoKeyUpdate locks the selected rows like ForUpdate, but doesn't block
+// inserts of rows referencing them through foreign keys.
+func ForNoKeyUpdate() QueryMod {
+	return For("NO KEY UPDATE")
+}
+
+// ForShare locks the selected rows against concurrent updates and deletes.
+func ForShare() QueryMod {
+	return For("SHARE")
+}
+
+// SkipLocked skips rows that are already locked instead of waiting for them.
+// It must be combined with a locking mod like ForUpdate, and is useful to
+// build job queues where each worker picks different rows.
+func SkipLocked() QueryMod {
+	return func(q *queries.Query) {
+		queries.SetLockWait(q, "SKIP LOCKED")
+	}
+}
+
+// NoWait makes the query fail instead of waiting if a row is already locked.
+// It must be combined with a locking mod like ForUpdate.
+func NoWait() QueryMod {
+	return func(q *queries.Query) {
+		queries.SetLockWait(q, "NOWAIT")
+	}
+}
+
+// ShardKey runs the query in the shard of key, instead of the shard
+// selected in the context, see bunny.WithShardKey.
+func ShardKey(key interface{}) QueryMod {
+	return func(q *queries.Query) {
+		queries.SetShardKey(q, key)
+	}
+}
+
+// Timeout cancels the query if it runs for longer than timeout. The
+// cancellation is propagated to the database, which aborts the statement.
+func Timeout(timeout time.Duration) QueryMod {
+	return func(q *queries.Query) {
+		queries.SetTimeout(q, timeout)
+	}
+}
+
+// quotePostgresCol quotes col in Postgres' style, for the mods using Postgres
+// only operators, like the jsonb and full-text search ones. Expressions are
+// left as is.
+func quotePostgresCol(col string) string {
+	return strmangle.IdentQuote('"', '"', col)
+}
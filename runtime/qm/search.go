@@ -0,0 +1,43 @@
+package qm
+
+import (
+	"fmt"
+
+	"github.com/sqlbunny/sqlbunny/runtime/queries"
+)
+
+// TsQuery filters rows whose tsvector col matches query, parsed with
+// to_tsquery using the text search config, like "english". If config is
+// empty, the server's default_text_search_config is used.
+//
+// col can also be an expression computing the tsvector from text columns:
+//
+//	qm.TsQuery("to_tsvector('english', title || ' ' || body)", "cat & dog", "english")
+func TsQuery(col, query, config string) QueryMod {
+	return func(q *queries.Query) {
+		clause, args := tsQuery(query, config)
+		queries.AppendWhere(q, fmt.Sprintf("%s @@ %s", quotePostgresCol(col), clause), args...)
+	}
+}
+
+// OrderByTsRank orders rows by their rank for query with ts_rank, the best
+// matches first. It's usually paired with TsQuery:
+//
+//	models.Posts(
+//	    qm.TsQuery("search", "cat & dog", "english"),
+//	    qm.OrderByTsRank("search", "cat & dog", "english"),
+//	)
+func OrderByTsRank(col, query, config string) QueryMod {
+	return func(q *queries.Query) {
+		clause, args := tsQuery(query, config)
+		queries.AppendOrderBy(q, fmt.Sprintf("ts_rank(%s, %s) DESC", quotePostgresCol(col), clause), args...)
+	}
+}
+
+// tsQuery returns the to_tsquery expression for query, and its arguments.
+func tsQuery(query, config string) (string, []interface{}) {
+	if config == "" {
+		return "to_tsquery(?)", []interface{}{query}
+	}
+	return "to_tsquery(?::regconfig, ?)", []interface{}{config, query}
+}
@@ -0,0 +1,22 @@
+package qm
+
+import (
+	"testing"
+)
+
+func TestTsQuery(t *testing.T) {
+	expectQuery(t, []QueryMod{TsQuery("search", "cat & dog", "english")},
+		`SELECT * FROM "t" WHERE ("search" @@ to_tsquery($1::regconfig, $2));`, "english", "cat & dog")
+	expectQuery(t, []QueryMod{TsQuery("search", "cat", "")},
+		`SELECT * FROM "t" WHERE ("search" @@ to_tsquery($1));`, "cat")
+	expectQuery(t, []QueryMod{TsQuery("to_tsvector('english', title)", "cat", "")},
+		`SELECT * FROM "t" WHERE (to_tsvector('english', title) @@ to_tsquery($1));`, "cat")
+}
+
+func TestOrderByTsRank(t *testing.T) {
+	expectQuery(t, []QueryMod{
+		TsQuery("search", "cat", "english"),
+		OrderByTsRank("t.search", "cat", "english"),
+	}, `SELECT * FROM "t" WHERE ("search" @@ to_tsquery($1::regconfig, $2)) ORDER BY ts_rank("t"."search", to_tsquery($3::regconfig, $4)) DESC;`,
+		"english", "cat", "english", "cat")
+}
@@ -0,0 +1 @@
+SELECT * FROM "jobs" LIMIT 1 FOR UPDATE SKIP LOCKED;
\ No newline at end of file
@@ -0,0 +1 @@
+WITH RECURSIVE roots AS (SELECT id FROM category WHERE name = $1), tree(id) AS (SELECT id FROM roots UNION ALL SELECT c.id FROM category c INNER JOIN tree t ON c.parent_id = t.id WHERE c.depth < $2) SELECT * FROM "category" WHERE (id IN (SELECT id FROM tree) AND visible = $3);
\ No newline at end of file
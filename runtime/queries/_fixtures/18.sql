@@ -0,0 +1 @@
+WITH stale AS (SELECT id FROM book WHERE updated_at < $1) UPDATE "book" SET "archived" = $2 WHERE (id IN (SELECT id FROM stale) AND owner = $3);
\ No newline at end of file
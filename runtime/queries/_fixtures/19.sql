@@ -0,0 +1 @@
+SELECT *, row_number() OVER (PARTITION BY author_id ORDER BY created_at DESC) AS "rank", sum(price) OVER (ORDER BY created_at) AS "running_total" FROM "book";
\ No newline at end of file
@@ -0,0 +1 @@
+(SELECT * FROM "book" WHERE (author_id = $1)) UNION (SELECT * FROM "book" WHERE (editor_id = $2)) EXCEPT (SELECT * FROM "book" WHERE (archived = $3)) ORDER BY title LIMIT 10;
\ No newline at end of file
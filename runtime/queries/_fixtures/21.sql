@@ -0,0 +1 @@
+SELECT COUNT(*) FROM ((SELECT "id" FROM "book") INTERSECT (SELECT "book_id" FROM "review")) AS "combined";
\ No newline at end of file
@@ -0,0 +1 @@
+SELECT * FROM "users" WHERE (active = $1 AND EXISTS (SELECT * FROM "orders" WHERE (orders.user_id = users.id AND total > $2))) AND "id" IN (SELECT "user_id" FROM "orders" WHERE (status = $3)) AND "role" IN ($4,$5);
\ No newline at end of file
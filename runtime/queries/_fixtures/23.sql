@@ -0,0 +1 @@
+DELETE FROM "orders" WHERE "user_id" IN (SELECT "id" FROM "users" WHERE (banned = $1));
\ No newline at end of file
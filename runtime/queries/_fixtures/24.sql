@@ -0,0 +1 @@
+SELECT DISTINCT ON ("author_id") * FROM "book" ORDER BY "book"."author_id", created_at DESC NULLS LAST;
\ No newline at end of file
@@ -0,0 +1 @@
+SELECT COUNT(*) FROM (SELECT DISTINCT "author_id" FROM "book") AS "counted";
\ No newline at end of file
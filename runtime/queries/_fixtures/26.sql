@@ -0,0 +1 @@
+SELECT "user_id", count(*) AS "orders", max("created_at") AS "last_placed" FROM "orders" GROUP BY user_id HAVING count(*) > $1;
\ No newline at end of file
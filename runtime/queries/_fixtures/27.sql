@@ -0,0 +1 @@
+SELECT COUNT(*) FROM (SELECT 1 FROM "orders" GROUP BY user_id) AS "counted";
\ No newline at end of file
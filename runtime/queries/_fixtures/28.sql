@@ -0,0 +1 @@
+SELECT * FROM "post" WHERE (search @@ to_tsquery($1)) ORDER BY ts_rank(search, to_tsquery($2)) DESC, id LIMIT 5;
\ No newline at end of file
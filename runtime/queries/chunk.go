@@ -0,0 +1,59 @@
+package queries
+
+import "strings"
+
+// chunks returns the queries q runs as: q itself, or a query per chunk of
+// the set of its largest IN clause, if it's chunked and has more than
+// MaxPlaceholders arguments.
+func (q *Query) chunks() []*Query {
+	if !q.chunked || len(q.in) == 0 {
+		return []*Query{q}
+	}
+
+	largest := 0
+	for i, in := range q.in {
+		if len(in.args) > len(q.in[largest].args) {
+			largest = i
+		}
+	}
+	set := q.in[largest]
+	if _, ok := inSubquery(set); ok {
+		return []*Query{q}
+	}
+
+	built := *q
+	built.rawSQL = rawSQL{}
+	_, args := buildQuery(&built)
+	if len(args) <= MaxPlaceholders {
+		return []*Query{q}
+	}
+
+	// The chunks hold whole groups of the set, like the pairs of
+	// "(a,b) IN ?". Sets with placeholders in their columns aren't split.
+	group := 1
+	if matches := rgxInClause.FindStringSubmatch(set.clause); matches != nil {
+		if strings.Contains(matches[1], "?") {
+			return []*Query{q}
+		}
+		group = len(strings.Split(matches[1], ","))
+	}
+	size := (MaxPlaceholders - (len(args) - len(set.args))) / group * group
+	if size <= 0 {
+		// Leave the error to the database.
+		return []*Query{q}
+	}
+
+	var res []*Query
+	for start := 0; start < len(set.args); start += size {
+		end := start + size
+		if end > len(set.args) {
+			end = len(set.args)
+		}
+		chunk := *q
+		chunk.rawSQL = rawSQL{}
+		chunk.in = append([]in(nil), q.in...)
+		chunk.in[largest] = in{clause: set.clause, args: set.args[start:end]}
+		res = append(res, &chunk)
+	}
+	return res
+}
@@ -0,0 +1,49 @@
+package queries
+
+import "testing"
+
+func TestChunks(t *testing.T) {
+	t.Parallel()
+
+	ids := make([]interface{}, 70000)
+	for i := range ids {
+		ids[i] = i
+	}
+	pairs := make([]interface{}, 2*40000)
+	for i := range pairs {
+		pairs[i] = i
+	}
+
+	tests := []struct {
+		q     *Query
+		sizes []int
+	}{
+		{&Query{from: []string{"t"}, in: []in{{clause: "id in ?", args: ids}}}, []int{70000}},
+		{&Query{from: []string{"t"}, in: []in{{clause: "id in ?", args: ids[:100]}}, chunked: true}, []int{100}},
+		{&Query{
+			from:    []string{"t"},
+			where:   []where{{clause: "deleted = ?", args: []interface{}{false}}},
+			in:      []in{{clause: "kind in ?", args: ids[:2]}, {clause: "id in ?", args: ids}},
+			chunked: true,
+		}, []int{MaxPlaceholders - 3, 70000 - (MaxPlaceholders - 3)}},
+		{&Query{from: []string{"t"}, in: []in{{clause: "(a,b) in ?", args: pairs}}, chunked: true}, []int{MaxPlaceholders - 1, 80000 - (MaxPlaceholders - 1)}},
+	}
+
+	for i, test := range tests {
+		test.q.dialect = &Dialect{LQ: '"', RQ: '"', IndexPlaceholders: true}
+		chunks := test.q.chunks()
+		if len(chunks) != len(test.sizes) {
+			t.Errorf("[%d] expected %d chunks, got %d", i, len(test.sizes), len(chunks))
+			continue
+		}
+		for j, c := range chunks {
+			set := c.in[len(c.in)-1]
+			if len(set.args) != test.sizes[j] {
+				t.Errorf("[%d] chunk %d: expected %d arguments in the set, got %d", i, j, test.sizes[j], len(set.args))
+			}
+			if _, args := buildQuery(c); test.q.chunked && len(args) > MaxPlaceholders {
+				t.Errorf("[%d] chunk %d: %d arguments", i, j, len(args))
+			}
+		}
+	}
+}
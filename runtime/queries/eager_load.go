@@ -4,52 +4,98 @@ import (
 	"context"
 	"reflect"
 	"strings"
+	"sync"
 
 	"github.com/sqlbunny/errors"
+	"github.com/sqlbunny/sqlbunny/runtime/bunny"
 	"github.com/sqlbunny/sqlbunny/runtime/strmangle"
 )
 
 type loadRelationshipState struct {
-	ctx    context.Context
-	loaded map[string]struct{}
-	toLoad []string
+	ctx  context.Context
+	mods map[string][]func(*Query)
+	// sem bounds the load functions running at a time, if they run
+	// concurrently.
+	sem chan struct{}
 }
 
-func (l loadRelationshipState) hasLoaded(depth int) bool {
-	_, ok := l.loaded[l.buildKey(depth)]
-	return ok
+// loadNode is a relationship to eager load, with the relationships to load
+// from the loaded objects.
+type loadNode struct {
+	name     string
+	key      string
+	children []*loadNode
 }
 
-func (l loadRelationshipState) setLoaded(depth int) {
-	l.loaded[l.buildKey(depth)] = struct{}{}
+type contextLoadModsKeyType struct{}
+
+var contextLoadModsKey = contextLoadModsKeyType{}
+
+// ApplyLoadMods applies the mods of the relationship being eager loaded in
+// ctx to q, the query of the generated load function.
+func ApplyLoadMods(ctx context.Context, q *Query) {
+	mods, _ := ctx.Value(contextLoadModsKey).([]func(*Query))
+	for _, mod := range mods {
+		mod(q)
+	}
+}
+
+// loadKey returns the key of the relationship path in the mods map, with
+// the title cased relationship names.
+func loadKey(relationship string) string {
+	pieces := strings.Split(relationship, ".")
+	for i := range pieces {
+		pieces[i] = strmangle.TitleCase(pieces[i])
+	}
+	return strings.Join(pieces, ".")
 }
 
-func (l loadRelationshipState) buildKey(depth int) string {
-	buf := strmangle.GetBuffer()
+// loadTree returns the relationships of the paths to load, with each one
+// loaded once even if it's in several paths.
+func loadTree(toLoad []string) []*loadNode {
+	var roots []*loadNode
+	for _, path := range toLoad {
+		nodes := &roots
+		key := ""
+		for _, name := range strings.Split(path, ".") {
+			name = strmangle.TitleCase(name)
+			if key != "" {
+				key += "."
+			}
+			key += name
 
-	for i, piece := range l.toLoad[:depth+1] {
-		if i != 0 {
-			buf.WriteByte('.')
+			var node *loadNode
+			for _, n := range *nodes {
+				if n.name == name {
+					node = n
+					break
+				}
+			}
+			if node == nil {
+				node = &loadNode{name: name, key: key}
+				*nodes = append(*nodes, node)
+			}
+			nodes = &node.children
 		}
-		buf.WriteString(piece)
 	}
-
-	str := buf.String()
-	strmangle.PutBuffer(buf)
-	return str
+	return roots
 }
 
 // eagerLoad loads all of the model's relationships
 //
 // toLoad should look like:
 // []string{"Relationship", "Relationship.NestedRelationship"} ... etc
+// mods are the mods of the load queries, by loadKey of the relationship
 // obj should be one of:
 // *[]*struct or *struct
 // bkind should reflect what kind of thing it is above
-func eagerLoad(ctx context.Context, toLoad []string, obj interface{}, bkind bindKind) error {
+func eagerLoad(ctx context.Context, toLoad []string, mods map[string][]func(*Query), obj interface{}, bkind bindKind) error {
 	state := loadRelationshipState{
-		ctx:    ctx,
-		loaded: map[string]struct{}{},
+		ctx:  ctx,
+		mods: mods,
+	}
+	if n := bunny.LoadParallelism(ctx); n > 1 {
+		state.sem = make(chan struct{}, n)
 	}
 
 	val := reflect.ValueOf(obj)
@@ -61,17 +107,7 @@ func eagerLoad(ctx context.Context, toLoad []string, obj interface{}, bkind bind
 		val = val.Elem()
 	}
 
-	for _, toLoad := range toLoad {
-		state.toLoad = strings.Split(toLoad, ".")
-		for i := range state.toLoad {
-			state.toLoad[i] = strmangle.TitleCase(state.toLoad[i])
-		}
-		if err := state.loadRelationships(0, val); err != nil {
-			return err
-		}
-	}
-
-	return nil
+	return state.loadRelationships(loadTree(toLoad), val)
 }
 
 // loadRelationships dynamically calls the template generated eager load
@@ -94,20 +130,65 @@ func eagerLoad(ctx context.Context, toLoad []string, obj interface{}, bkind bind
 //
 // That's to say that we descend the graph of relationships, and at each level
 // we gather all the things up we want to load into, load them, and then move
-// to the next level of the graph.
-func (l loadRelationshipState) loadRelationships(depth int, loadingFrom reflect.Value) error {
+// to the next level of the graph. With a load parallelism, the sibling
+// relationships of each level are loaded concurrently.
+func (l loadRelationshipState) loadRelationships(nodes []*loadNode, loadingFrom reflect.Value) error {
 	if loadingFrom.Len() == 0 {
 		return nil
 	}
 
-	if !l.hasLoaded(depth) {
-		if err := l.callLoadFunction(depth, loadingFrom); err != nil {
+	if l.sem == nil || len(nodes) < 2 {
+		for _, node := range nodes {
+			if err := l.loadRelationship(node, loadingFrom); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	// The load functions of the siblings set the relationship structs of
+	// the same objects, which must exist before they run concurrently.
+	if err := initRelationships(loadingFrom); err != nil {
+		return err
+	}
+
+	errs := make([]error, len(nodes))
+	var wg sync.WaitGroup
+	for i, node := range nodes {
+		wg.Add(1)
+		go func(i int, node *loadNode) {
+			defer wg.Done()
+			errs[i] = l.loadRelationship(node, loadingFrom)
+		}(i, node)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
 			return err
 		}
 	}
+	return nil
+}
+
+// loadRelationship loads the relationship of node into loadingFrom, and
+// then its children from the loaded objects.
+func (l loadRelationshipState) loadRelationship(node *loadNode, loadingFrom reflect.Value) error {
+	if l.sem != nil {
+		// The slot is only held by the load function, so the loads of the
+		// children can't wait for the ones of their parents.
+		l.sem <- struct{}{}
+	}
+	err := l.callLoadFunction(node, loadingFrom)
+	if l.sem != nil {
+		<-l.sem
+	}
+	if err != nil {
+		return err
+	}
 
 	// Check if we can stop
-	if depth+1 >= len(l.toLoad) {
+	if len(node.children) == 0 {
 		return nil
 	}
 
@@ -116,23 +197,18 @@ func (l loadRelationshipState) loadRelationships(depth int, loadingFrom reflect.
 	loadingFrom = reflect.Indirect(loadingFrom)
 
 	// Collect eagerly loaded things to send into next eager load call
-	slice, err := collectLoaded(l.toLoad[depth], loadingFrom)
+	slice, err := collectLoaded(node.name, loadingFrom)
 	if err != nil {
 		return err
 	}
 
-	// If we could collect nothing we're done
-	if slice.Len() == 0 {
-		return nil
-	}
-
-	return l.loadRelationships(depth+1, slice)
+	return l.loadRelationships(node.children, slice)
 }
 
 // callLoadFunction finds the loader struct, finds the method that we need
 // to call and calls it.
-func (l loadRelationshipState) callLoadFunction(depth int, loadingFrom reflect.Value) error {
-	current := l.toLoad[depth]
+func (l loadRelationshipState) callLoadFunction(node *loadNode, loadingFrom reflect.Value) error {
+	current := node.name
 	sliceType := loadingFrom.Type()
 	modelType := sliceType.Elem().Elem()
 	ln, found := modelType.FieldByName(loaderStructName)
@@ -147,9 +223,12 @@ func (l loadRelationshipState) callLoadFunction(depth int, loadingFrom reflect.V
 		return errors.Errorf("could not find %s%s method for eager loading", loadMethodPrefix, current)
 	}
 
+	// The mods are always set, so the ones of an outer eager load aren't
+	// applied to the relationships loaded by the bound rows.
+	ctx := context.WithValue(l.ctx, contextLoadModsKey, l.mods[node.key])
 	methodArgs := []reflect.Value{
 		reflect.Zero(ln.Type),
-		reflect.ValueOf(l.ctx),
+		reflect.ValueOf(ctx),
 		loadingFrom,
 	}
 
@@ -158,7 +237,22 @@ func (l loadRelationshipState) callLoadFunction(depth int, loadingFrom reflect.V
 		return errors.Errorf("failed to eager load %s: %w", current, intf.(error))
 	}
 
-	l.setLoaded(depth)
+	return nil
+}
+
+// initRelationships sets the relationship structs of the objects of
+// loadingFrom which have none.
+func initRelationships(loadingFrom reflect.Value) error {
+	f, ok := loadingFrom.Type().Elem().Elem().FieldByName(relationshipStructName)
+	if !ok {
+		return errors.New("relationship struct was not found")
+	}
+	for i := 0; i < loadingFrom.Len(); i++ {
+		r := loadingFrom.Index(i).Elem().FieldByIndex(f.Index)
+		if r.IsNil() {
+			r.Set(reflect.New(f.Type.Elem()))
+		}
+	}
 	return nil
 }
 
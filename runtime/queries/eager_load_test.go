@@ -2,8 +2,12 @@ package queries
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"testing"
+	"time"
+
+	"github.com/sqlbunny/sqlbunny/runtime/bunny"
 )
 
 var testEagerCounters struct {
@@ -13,6 +17,18 @@ var testEagerCounters struct {
 	NestedMany int
 }
 
+// testEagerWheres are the where clauses of the load mods passed to the load
+// functions, by relationship.
+var testEagerWheres = map[string][]string{}
+
+func testEagerApplyMods(ctx context.Context, relationship string) {
+	q := &Query{}
+	ApplyLoadMods(ctx, q)
+	for _, w := range q.where {
+		testEagerWheres[relationship] = append(testEagerWheres[relationship], w.clause)
+	}
+}
+
 type testEager struct {
 	ID int
 	R  *testEagerR
@@ -75,7 +91,8 @@ func (testEagerL) LoadChildOne(_ context.Context, slice []*testEager) error {
 	return nil
 }
 
-func (testEagerL) LoadChildMany(_ context.Context, slice []*testEager) error {
+func (testEagerL) LoadChildMany(ctx context.Context, slice []*testEager) error {
+	testEagerApplyMods(ctx, "ChildMany")
 	for _, o := range slice {
 		if o.R == nil {
 			o.R = &testEagerR{}
@@ -91,7 +108,8 @@ func (testEagerL) LoadChildMany(_ context.Context, slice []*testEager) error {
 	return nil
 }
 
-func (testEagerChildL) LoadNestedOne(_ context.Context, slice []*testEagerChild) error {
+func (testEagerChildL) LoadNestedOne(ctx context.Context, slice []*testEagerChild) error {
+	testEagerApplyMods(ctx, "NestedOne")
 	for _, o := range slice {
 		if o.R == nil {
 			o.R = &testEagerChildR{}
@@ -157,7 +175,7 @@ func TestEagerLoadFromOne(t *testing.T) {
 	obj := &testEager{}
 
 	toLoad := []string{"ChildOne.NestedMany", "ChildOne.NestedOne", "ChildMany.NestedMany", "ChildMany.NestedOne"}
-	err := eagerLoad(context.Background(), toLoad, obj, kindStruct)
+	err := eagerLoad(context.Background(), toLoad, nil, obj, kindStruct)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -199,7 +217,7 @@ func TestEagerLoadFromMany(t *testing.T) {
 	}
 
 	toLoad := []string{"ChildOne.NestedMany", "ChildOne.NestedOne", "ChildMany.NestedMany", "ChildMany.NestedOne"}
-	err := eagerLoad(context.Background(), toLoad, &slice, kindPtrSliceStruct)
+	err := eagerLoad(context.Background(), toLoad, nil, &slice, kindPtrSliceStruct)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -243,7 +261,7 @@ func TestEagerLoadZeroParents(t *testing.T) {
 	obj := &testEager{}
 
 	toLoad := []string{"ZeroMany.NestedMany", "ZeroOne.NestedOne", "ZeroMany.NestedMany", "ZeroOne.NestedOne"}
-	err := eagerLoad(context.Background(), toLoad, obj, kindStruct)
+	err := eagerLoad(context.Background(), toLoad, nil, obj, kindStruct)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -265,7 +283,7 @@ func TestEagerLoadZeroParentsMany(t *testing.T) {
 	}
 
 	toLoad := []string{"ZeroMany.NestedMany", "ZeroOne.NestedOne", "ZeroMany.NestedMany", "ZeroOne.NestedOne"}
-	err := eagerLoad(context.Background(), toLoad, &obj, kindPtrSliceStruct)
+	err := eagerLoad(context.Background(), toLoad, nil, &obj, kindPtrSliceStruct)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -329,3 +347,105 @@ func checkNestedMany(ns []*testEagerNested) {
 		panic(fmt.Sprintf("ns[1] had wrong id: %d", ns[1].ID))
 	}
 }
+
+func TestEagerLoadMods(t *testing.T) {
+	testEagerWheres = map[string][]string{}
+
+	q := &Query{}
+	AppendLoad(q, "childMany", "childMany.nestedOne")
+	AppendLoadMods(q, "childMany", func(q *Query) { AppendWhere(q, "a") })
+	AppendLoadMods(q, "ChildMany.NestedOne", func(q *Query) { AppendWhere(q, "b") }, func(q *Query) { AppendWhere(q, "c") })
+
+	obj := &testEager{}
+	if err := eagerLoad(context.Background(), q.load, q.loadMods, obj, kindStruct); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := fmt.Sprint(testEagerWheres["ChildMany"]); got != "[a]" {
+		t.Errorf("got ChildMany where %s", got)
+	}
+	if got := fmt.Sprint(testEagerWheres["NestedOne"]); got != "[b c]" {
+		t.Errorf("got NestedOne where %s", got)
+	}
+}
+
+type testParallel struct {
+	ID int
+	R  *testParallelR
+	L  testParallelL
+
+	// meet is closed by each of the loads of Left and Right, which wait for
+	// the other one, so they only succeed if they run concurrently. The
+	// loaders are called on a zero L, so the objects carry it.
+	meet [2]chan struct{}
+}
+
+type testParallelR struct {
+	Left  *testEagerChild
+	Right *testEagerChild
+}
+
+type testParallelL struct {
+}
+
+func testParallelLoad(mine, other chan struct{}) error {
+	close(mine)
+	select {
+	case <-other:
+		return nil
+	case <-time.After(time.Second):
+		return errors.New("the sibling load didn't run concurrently")
+	}
+}
+
+func (testParallelL) LoadLeft(_ context.Context, slice []*testParallel) error {
+	if err := testParallelLoad(slice[0].meet[0], slice[0].meet[1]); err != nil {
+		return err
+	}
+	for _, o := range slice {
+		o.R.Left = &testEagerChild{ID: 1}
+	}
+	return nil
+}
+
+func (testParallelL) LoadRight(_ context.Context, slice []*testParallel) error {
+	if err := testParallelLoad(slice[0].meet[1], slice[0].meet[0]); err != nil {
+		return err
+	}
+	for _, o := range slice {
+		o.R.Right = &testEagerChild{ID: 2}
+	}
+	return nil
+}
+
+func TestEagerLoadParallel(t *testing.T) {
+	meet := [2]chan struct{}{make(chan struct{}), make(chan struct{})}
+	slice := []*testParallel{{ID: 1, meet: meet}, {ID: 2, meet: meet}}
+
+	ctx := bunny.WithLoadParallelism(context.Background(), 2)
+	if err := eagerLoad(ctx, []string{"Left", "Right"}, nil, &slice, kindPtrSliceStruct); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, o := range slice {
+		if o.R.Left == nil || o.R.Left.ID != 1 || o.R.Right == nil || o.R.Right.ID != 2 {
+			t.Errorf("wrong relationships of %d: %+v", o.ID, o.R)
+		}
+	}
+}
+
+func TestLoadTree(t *testing.T) {
+	nodes := loadTree([]string{"childOne.nestedMany", "ChildOne.NestedOne", "childMany", "ChildOne"})
+
+	var walk func(nodes []*loadNode) string
+	walk = func(nodes []*loadNode) string {
+		res := ""
+		for _, n := range nodes {
+			res += n.key + "(" + walk(n.children) + ")"
+		}
+		return res
+	}
+	if got, want := walk(nodes), "ChildOne(ChildOne.NestedMany()ChildOne.NestedOne())ChildMany()"; got != want {
+		t.Errorf("wrong tree:\nwant: %s\ngot:  %s", want, got)
+	}
+}
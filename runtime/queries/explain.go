@@ -0,0 +1,130 @@
+package queries
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	"github.com/sqlbunny/errors"
+	"github.com/sqlbunny/sqlbunny/runtime/bunny"
+)
+
+// Plan is a node of a Postgres query plan, as returned by ExplainJSON.
+// The actual fields are only set by EXPLAIN ANALYZE.
+type Plan struct {
+	NodeType        string  `json:"Node Type"`
+	RelationName    string  `json:"Relation Name"`
+	Alias           string  `json:"Alias"`
+	IndexName       string  `json:"Index Name"`
+	StartupCost     float64 `json:"Startup Cost"`
+	TotalCost       float64 `json:"Total Cost"`
+	PlanRows        float64 `json:"Plan Rows"`
+	ActualRows      float64 `json:"Actual Rows"`
+	ActualTotalTime float64 `json:"Actual Total Time"`
+	Plans           []*Plan `json:"Plans"`
+}
+
+// Indexes returns the names of the indexes used by the plan and its
+// subplans, in plan order.
+func (p *Plan) Indexes() []string {
+	var res []string
+	if p.IndexName != "" {
+		res = append(res, p.IndexName)
+	}
+	for _, c := range p.Plans {
+		res = append(res, c.Indexes()...)
+	}
+	return res
+}
+
+// errExplainRollback rolls back the transaction of EXPLAIN ANALYZE.
+var errExplainRollback = errors.New("sqlbunny: explain rollback")
+
+// Explain returns the Postgres query plan of the query as text, so the
+// index usage of queries can be checked from tests.
+//
+// With analyze the query is executed to get the actual row counts and
+// times, in a transaction which is rolled back, so update and delete
+// queries have no side effects. Inside a transaction it runs in a savepoint.
+func (q *Query) Explain(ctx context.Context, analyze bool) (string, error) {
+	var lines []string
+	err := q.explain(ctx, "EXPLAIN", analyze, func(s string) {
+		lines = append(lines, s)
+	})
+	if err != nil {
+		return "", err
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+// ExplainJSON is like Explain, but returns the root node of the plan
+// parsed from EXPLAIN (FORMAT JSON).
+func (q *Query) ExplainJSON(ctx context.Context, analyze bool) (*Plan, error) {
+	var data string
+	if err := q.explain(ctx, "EXPLAIN (FORMAT JSON)", analyze, func(s string) {
+		data += s
+	}); err != nil {
+		return nil, err
+	}
+
+	var res []struct {
+		Plan *Plan `json:"Plan"`
+	}
+	if err := json.Unmarshal([]byte(data), &res); err != nil {
+		return nil, errors.Errorf("sqlbunny: invalid explain output: %w", err)
+	}
+	if len(res) == 0 || res[0].Plan == nil {
+		return nil, errors.New("sqlbunny: explain returned no plan")
+	}
+	return res[0].Plan, nil
+}
+
+func (q *Query) explain(ctx context.Context, explain string, analyze bool, row func(string)) error {
+	if q.err != nil {
+		return q.err
+	}
+	ctx = q.shardContext(ctx)
+	q = q.withContextMods(ctx)
+	ctx, cancel := q.timeoutContext(ctx)
+	defer cancel()
+
+	qs, args := buildQuery(q)
+	if analyze {
+		if strings.HasSuffix(explain, ")") {
+			explain = explain[:len(explain)-1] + ", ANALYZE)"
+		} else {
+			explain += " ANALYZE"
+		}
+	}
+	qs = explain + " " + qs
+
+	run := func(ctx context.Context) error {
+		rows, err := bunny.Query(ctx, qs, args...)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var s string
+			if err := rows.Scan(&s); err != nil {
+				return err
+			}
+			row(s)
+		}
+		return rows.Err()
+	}
+	if !analyze {
+		return run(ctx)
+	}
+
+	err := bunny.Atomic(ctx, func(ctx context.Context) error {
+		if err := run(ctx); err != nil {
+			return err
+		}
+		return errExplainRollback
+	})
+	if errors.Is(err, errExplainRollback) {
+		return nil
+	}
+	return err
+}
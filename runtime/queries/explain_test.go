@@ -0,0 +1,76 @@
+package queries
+
+import (
+	"reflect"
+	"testing"
+
+	"gopkg.in/DATA-DOG/go-sqlmock.v2"
+)
+
+func TestExplain(t *testing.T) {
+	t.Parallel()
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	query := &Query{
+		from:    []string{`"users"`},
+		dialect: &Dialect{LQ: '"', RQ: '"', IndexPlaceholders: true},
+	}
+	AppendWhere(query, "id = ?", 1)
+
+	rows := sqlmock.NewRows([]string{"QUERY PLAN"}).
+		AddRow("Index Scan using users_pkey on users").
+		AddRow("  Index Cond: (id = 1)")
+	mock.ExpectQuery(`^EXPLAIN SELECT \* FROM "users" WHERE \(id = \$1\);`).WithArgs(1).WillReturnRows(rows)
+
+	plan, err := query.Explain(dbToContext(db), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "Index Scan using users_pkey on users\n  Index Cond: (id = 1)"; plan != want {
+		t.Errorf("got plan %q, want %q", plan, want)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestExplainJSONAnalyze(t *testing.T) {
+	t.Parallel()
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	query := &Query{
+		from:    []string{`"users"`},
+		dialect: &Dialect{LQ: '"', RQ: '"', IndexPlaceholders: true},
+	}
+	SetDelete(query)
+
+	rows := sqlmock.NewRows([]string{"QUERY PLAN"}).AddRow(`[{"Plan": {"Node Type": "Delete", "Actual Rows": 0,
+		"Plans": [{"Node Type": "Index Scan", "Relation Name": "users", "Index Name": "users_pkey", "Actual Rows": 3}]}}]`)
+	mock.ExpectBegin()
+	mock.ExpectQuery(`^EXPLAIN \(FORMAT JSON, ANALYZE\) DELETE FROM "users";`).WillReturnRows(rows)
+	mock.ExpectRollback()
+
+	plan, err := query.ExplainJSON(dbToContext(db), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if plan.NodeType != "Delete" || len(plan.Plans) != 1 || plan.Plans[0].ActualRows != 3 {
+		t.Errorf("unexpected plan %+v", plan)
+	}
+	if got := plan.Indexes(); !reflect.DeepEqual(got, []string{"users_pkey"}) {
+		t.Errorf("got indexes %v", got)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
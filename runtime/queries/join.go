@@ -0,0 +1,67 @@
+package queries
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/sqlbunny/sqlbunny/runtime/strmangle"
+)
+
+// TableColumn is a column qualified by the name of its table, like the
+// columns of the generated <Model>TableColumns.
+type TableColumn interface {
+	TableName() string
+	ColumnName() string
+}
+
+// Column is a column of the table of a model, with T the Go type of its
+// values, so only columns of the same type can be joined by qm.On.
+type Column[T any] struct {
+	Table string
+	Name  string
+}
+
+// TableName returns the name of the table of the column.
+func (c Column[T]) TableName() string {
+	return c.Table
+}
+
+// ColumnName returns the name of the column.
+func (c Column[T]) ColumnName() string {
+	return c.Name
+}
+
+// String returns the qualified name of the column, like "book.owner_id".
+func (c Column[T]) String() string {
+	return c.Table + "." + c.Name
+}
+
+// JoinCondition is an equality of the columns of the ON clause of a join.
+type JoinCondition struct {
+	Left  TableColumn
+	Right TableColumn
+}
+
+// AppendJoinOn joins table to the query on the conditions, with kind
+// JoinInner or JoinOuterLeft. Unlike the clause of AppendInnerJoin, the
+// table and the columns are quoted when the query is built.
+func AppendJoinOn(q *Query, kind joinKind, table string, on ...JoinCondition) {
+	q.joins = append(q.joins, join{kind: kind, table: table, on: on})
+}
+
+// writeJoinOn writes the table and the ON clause of the join j.
+func writeJoinOn(q *Query, buf *bytes.Buffer, j join) {
+	buf.WriteString(strmangle.IdentQuote(q.dialect.LQ, q.dialect.RQ, j.table))
+	for i, c := range j.on {
+		if i == 0 {
+			buf.WriteString(" ON ")
+		} else {
+			buf.WriteString(" AND ")
+		}
+		fmt.Fprintf(buf, "%s = %s", quoteColumn(q, c.Left), quoteColumn(q, c.Right))
+	}
+}
+
+func quoteColumn(q *Query, c TableColumn) string {
+	return strmangle.IdentQuote(q.dialect.LQ, q.dialect.RQ, c.TableName()+"."+c.ColumnName())
+}
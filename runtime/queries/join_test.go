@@ -0,0 +1,82 @@
+package queries
+
+import (
+	"testing"
+
+	"gopkg.in/DATA-DOG/go-sqlmock.v2"
+)
+
+var (
+	joinUserID    = Column[int64]{Table: "users", Name: "id"}
+	joinUserName  = Column[string]{Table: "users", Name: "name"}
+	joinOrderID   = Column[int64]{Table: "orders", Name: "id"}
+	joinOrderUser = Column[int64]{Table: "orders", Name: "user_id"}
+)
+
+func TestBuildJoinOn(t *testing.T) {
+	t.Parallel()
+
+	dia := &Dialect{LQ: '"', RQ: '"', IndexPlaceholders: true}
+
+	q := &Query{from: []string{"users"}, dialect: dia}
+	AppendJoinOn(q, JoinInner, "orders", JoinCondition{Left: joinOrderUser, Right: joinUserID})
+	AppendInnerJoin(q, `"carts" ON "carts"."id" = ?`, 3)
+	AppendJoinOn(q, JoinOuterLeft, "orders", JoinCondition{Left: joinOrderUser, Right: joinUserID}, JoinCondition{Left: joinOrderID, Right: joinUserID})
+	AppendWhere(q, `"users"."id" = ?`, 5)
+
+	got, args := buildQuery(q)
+	want := `SELECT "users".* FROM "users" INNER JOIN "orders" ON "orders"."user_id" = "users"."id" INNER JOIN "carts" ON "carts"."id" = $1 LEFT JOIN "orders" ON "orders"."user_id" = "users"."id" AND "orders"."id" = "users"."id" WHERE ("users"."id" = $2);`
+	if got != want {
+		t.Errorf("wrong query:\nwant: %s\ngot:  %s", want, got)
+	}
+	if len(args) != 2 || args[0] != 3 || args[1] != 5 {
+		t.Errorf("wrong args: %v", args)
+	}
+}
+
+func TestBindJoinedColumns(t *testing.T) {
+	t.Parallel()
+
+	type user struct {
+		ID   int64  `bunny:"id"`
+		Name string `bunny:"name"`
+	}
+	type order struct {
+		ID     int64 `bunny:"id"`
+		UserID int64 `bunny:"user_id"`
+	}
+	type userOrder struct {
+		User  user  `bunny:"users.,bind"`
+		Order order `bunny:"orders.,bind"`
+	}
+
+	q := &Query{from: []string{"users"}, dialect: &Dialect{LQ: '"', RQ: '"', IndexPlaceholders: true}}
+	AppendJoinOn(q, JoinInner, "orders", JoinCondition{Left: joinOrderUser, Right: joinUserID})
+	for _, c := range []TableColumn{joinUserID, joinUserName, joinOrderID, joinOrderUser} {
+		AppendSelect(q, c.TableName()+"."+c.ColumnName())
+	}
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ret := sqlmock.NewRows([]string{"users.id", "users.name", "orders.id", "orders.user_id"})
+	ret.AddRow(int64(1), "pat", int64(7), int64(1))
+	mock.ExpectQuery(`SELECT "users"."id" as "users.id", "users"."name" as "users.name", "orders"."id" as "orders.id", "orders"."user_id" as "orders.user_id" FROM "users" INNER JOIN "orders" ON "orders"."user_id" = "users"."id";`).WillReturnRows(ret)
+
+	var res []*userOrder
+	if err := q.Bind(dbToContext(db), &res); err != nil {
+		t.Fatal(err)
+	}
+	if len(res) != 1 {
+		t.Fatalf("expected 1 row, got %d", len(res))
+	}
+	if o := res[0]; o.User.ID != 1 || o.User.Name != "pat" || o.Order.ID != 7 || o.Order.UserID != 1 {
+		t.Errorf("wrong row: %+v", o)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,47 @@
+package queries
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/sqlbunny/errors"
+	"github.com/sqlbunny/sqlbunny/runtime/bunny"
+)
+
+// AllMap executes the query, which must select two columns, and returns the
+// values of its second column by the ones of the first, for lookup tables:
+//
+//	names, err := queries.AllMap[int64, string](ctx, models.Countries(qm.Select("id", "name")).Query)
+//
+// If several rows have the same key, the value of the last one is kept.
+func AllMap[K comparable, V any](ctx context.Context, q *Query) (map[K]V, error) {
+	res := make(map[K]V)
+
+	ctx = q.shardContext(ctx)
+	q = q.withContextMods(ctx)
+	err := bunny.AtomicTenant(ctx, func(ctx context.Context) error {
+		return q.queryRows(ctx, func(rows *sql.Rows) error {
+			cols, err := rows.Columns()
+			if err != nil {
+				return errors.Errorf("bind failed to get field names: %w", err)
+			}
+			if len(cols) != 2 {
+				return errors.Errorf("map query must select 2 columns, got %d", len(cols))
+			}
+
+			for rows.Next() {
+				var k K
+				var v V
+				if err := rows.Scan(&k, &v); err != nil {
+					return errors.Errorf("failed to scan map row: %w", err)
+				}
+				res[k] = v
+			}
+			return rows.Err()
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
@@ -0,0 +1,45 @@
+package queries
+
+import (
+	"testing"
+
+	"gopkg.in/DATA-DOG/go-sqlmock.v2"
+)
+
+func TestAllMap(t *testing.T) {
+	t.Parallel()
+
+	query := &Query{
+		from:       []string{"countries"},
+		selectCols: []string{"id", "name"},
+		dialect:    &Dialect{LQ: '"', RQ: '"', IndexPlaceholders: true},
+	}
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ret := sqlmock.NewRows([]string{"id", "name"})
+	ret.AddRow(int64(1), "France")
+	ret.AddRow(int64(2), "Spain")
+	mock.ExpectQuery(`SELECT "id", "name" FROM "countries";`).WillReturnRows(ret)
+	mock.ExpectQuery(`SELECT "id", "name" FROM "countries";`).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(int64(1)))
+
+	ctx := dbToContext(db)
+	res, err := AllMap[int64, string](ctx, query)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res) != 2 || res[1] != "France" || res[2] != "Spain" {
+		t.Errorf("wrong map: %v", res)
+	}
+
+	if _, err := AllMap[int64, string](ctx, query); err == nil {
+		t.Error("expected an error for a single column")
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
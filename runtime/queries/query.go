@@ -3,8 +3,13 @@ package queries
 import (
 	"context"
 	"database/sql"
+	"reflect"
+	"strings"
+	"time"
 
+	"github.com/sqlbunny/errors"
 	"github.com/sqlbunny/sqlbunny/runtime/bunny"
+	"github.com/sqlbunny/sqlbunny/runtime/strmangle"
 )
 
 // joinKind is the type of join
@@ -22,21 +27,41 @@ const (
 type Query struct {
 	dialect    *Dialect
 	rawSQL     rawSQL
+	with       []with
+	setOps     []setOp
 	load       []string
+	loadMods   map[string][]func(*Query)
 	delete     bool
 	update     map[string]interface{}
 	selectCols []string
+	distinct   bool
+	distinctOn []string
+	aggregates []aggregate
+	windows    []window
 	count      bool
 	from       []string
+	alias      string
 	joins      []join
 	where      []where
 	in         []in
 	groupBy    []string
 	orderBy    []string
+	orderArgs  []interface{}
+	defOrderBy string
 	having     []having
 	limit      int
 	offset     int
+	limitPer   []string
 	forlock    string
+	lockWait   string
+	unscoped   bool
+	timeout    time.Duration
+	sharded    bool
+	shardKey   interface{}
+	chunked    bool
+	err        error
+
+	contextMods []func(ctx context.Context, q *Query)
 }
 
 // Dialect holds values that direct the query builder
@@ -54,6 +79,37 @@ type Dialect struct {
 	// Bool flag indicating whether "TOP" or "LIMIT" clause
 	// must be used for rows limitation
 	UseTopClause bool
+	// Bool flag indicating whether RETURNING clauses
+	// are supported.
+	UseReturning bool
+	// Bool flag indicating whether upserts use ON DUPLICATE KEY
+	// UPDATE instead of ON CONFLICT.
+	UseOnDuplicateKey bool
+}
+
+type with struct {
+	name      string
+	recursive bool
+	clause    string
+	args      []interface{}
+}
+
+type setOp struct {
+	kind  string
+	query *Query
+}
+
+type aggregate struct {
+	fn    string
+	col   string
+	alias string
+}
+
+type window struct {
+	fn          string
+	partitionBy string
+	orderBy     string
+	alias       string
 }
 
 type where struct {
@@ -80,9 +136,19 @@ type join struct {
 	kind   joinKind
 	clause string
 	args   []interface{}
+
+	// table and on are set instead of clause by AppendJoinOn.
+	table string
+	on    []JoinCondition
 }
 
-// Raw makes a raw query, usually for use with bind
+// Raw makes a raw query, usually for use with bind.
+//
+// Binding maps result columns onto struct fields by their bunny tags the same
+// way built queries do, so raw SQL can be bound into generated models,
+// including the columns of flattened struct fields (like "address__city").
+// Result columns that don't match any field are discarded. The SQL is passed
+// to the database as is, so it must use the dialect's placeholders ($1).
 func Raw(query string, args ...interface{}) *Query {
 	return &Query{
 		rawSQL: rawSQL{
@@ -94,18 +160,54 @@ func Raw(query string, args ...interface{}) *Query {
 
 // Exec executes a query that does not need a row returned
 func (q *Query) Exec(ctx context.Context) (sql.Result, error) {
+	if q.err != nil {
+		return nil, q.err
+	}
+	ctx = q.shardContext(ctx)
+	q = q.withContextMods(ctx)
+	ctx, cancel := q.timeoutContext(ctx)
+	defer cancel()
+
 	qs, args := buildQuery(q)
 	return bunny.Exec(ctx, qs, args...)
 }
 
-// QueryRow executes the query for the One finisher and returns a row
+// ScanRow executes the query and scans the single returned row into dest.
+// Unlike QueryRow, it honors the query timeout and can run outside
+// transactions with a tenant context.
+func (q *Query) ScanRow(ctx context.Context, dest ...interface{}) error {
+	if q.err != nil {
+		return q.err
+	}
+	ctx = q.shardContext(ctx)
+	q = q.withContextMods(ctx)
+	ctx, cancel := q.timeoutContext(ctx)
+	defer cancel()
+
+	qs, args := buildQuery(q)
+	return bunny.QueryRowScan(ctx, qs, args, dest...)
+}
+
+// QueryRow executes the query for the One finisher and returns a row.
+// The query timeout is not applied, since the row outlives the call.
 func (q *Query) QueryRow(ctx context.Context) *sql.Row {
+	if q.err != nil {
+		return bunny.ErrorRow(q.err)
+	}
+	ctx = q.shardContext(ctx)
+	q = q.withContextMods(ctx)
 	qs, args := buildQuery(q)
 	return bunny.QueryRow(ctx, qs, args...)
 }
 
-// Query executes the query for the All finisher and returns multiple rows
+// Query executes the query for the All finisher and returns multiple rows.
+// The query timeout is not applied, since the rows outlive the call.
 func (q *Query) Query(ctx context.Context) (*sql.Rows, error) {
+	if q.err != nil {
+		return nil, q.err
+	}
+	ctx = q.shardContext(ctx)
+	q = q.withContextMods(ctx)
 	qs, args := buildQuery(q)
 	return bunny.Query(ctx, qs, args...)
 }
@@ -130,6 +232,16 @@ func AppendLoad(q *Query, relationships ...string) {
 	q.load = append(q.load, relationships...)
 }
 
+// AppendLoadMods adds mods applied to the query eager loading the last
+// relationship of the path, like "Comments" or "Comments.Author".
+func AppendLoadMods(q *Query, relationship string, mods ...func(*Query)) {
+	if q.loadMods == nil {
+		q.loadMods = map[string][]func(*Query){}
+	}
+	key := loadKey(relationship)
+	q.loadMods[key] = append(q.loadMods[key], mods...)
+}
+
 // SetSelect on the query.
 func SetSelect(q *Query, sel []string) {
 	q.selectCols = sel
@@ -160,11 +272,133 @@ func SetOffset(q *Query, offset int) {
 	q.offset = offset
 }
 
+// SetLimitPer makes the limit and offset of the query apply to each group of
+// rows with the same values of cols, instead of to all the rows. The groups
+// are numbered in the order of the ORDER BY clauses, and the rows returned
+// are ordered by their number in their group.
+func SetLimitPer(q *Query, cols ...string) {
+	q.limitPer = append([]string(nil), cols...)
+}
+
 // SetFor on the query.
 func SetFor(q *Query, clause string) {
 	q.forlock = clause
 }
 
+// SetLockWait on the query. It's the behavior of the locking clause set with
+// SetFor when rows are already locked, like "SKIP LOCKED" or "NOWAIT".
+func SetLockWait(q *Query, clause string) {
+	q.lockWait = clause
+}
+
+// SetError makes the query fail with err when it's run, for mods which can't
+// be applied. Only the first error is kept.
+func SetError(q *Query, err error) {
+	if q.err == nil {
+		q.err = err
+	}
+}
+
+// SetTimeout on the query.
+func SetTimeout(q *Query, timeout time.Duration) {
+	q.timeout = timeout
+}
+
+// timeoutContext returns a context that is canceled once the query timeout
+// elapses, if the query has one.
+func (q *Query) timeoutContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if q.timeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, q.timeout)
+}
+
+// SetSharded marks the query as a query of a sharded model, which fails
+// with bunny.ErrNoShardKey if no shard is selected, see bunny.RequireShard.
+func SetSharded(q *Query) {
+	q.sharded = true
+}
+
+// SetShardKey on the query. The query runs in the shard of key, see
+// bunny.WithShardKey.
+func SetShardKey(q *Query, key interface{}) {
+	q.shardKey = key
+}
+
+// shardContext returns ctx with the shard of the query selected.
+func (q *Query) shardContext(ctx context.Context) context.Context {
+	if q.shardKey != nil {
+		ctx = bunny.WithShardKey(ctx, q.shardKey)
+	}
+	if q.sharded {
+		ctx = bunny.RequireShard(ctx)
+	}
+	return ctx
+}
+
+// SetContextMods on the query. The mods add clauses to the query from the
+// context it runs with, like a filter by the tenant of the context. They're
+// applied to a copy of the query each time it runs, so it can run with
+// different contexts. Raw SQL set on the query is replaced by the built one.
+func SetContextMods(q *Query, mods []func(ctx context.Context, q *Query)) {
+	q.contextMods = mods
+}
+
+// withContextMods returns a copy of q with its context mods applied for ctx,
+// or q itself if it has none.
+func (q *Query) withContextMods(ctx context.Context) *Query {
+	if len(q.contextMods) == 0 {
+		return q
+	}
+	q2 := *q
+	q2.contextMods = nil
+	q2.rawSQL = rawSQL{}
+	// Clip the clauses, so the mods append to copies of them.
+	q2.where = q.where[:len(q.where):len(q.where)]
+	q2.in = q.in[:len(q.in):len(q.in)]
+	q2.joins = q.joins[:len(q.joins):len(q.joins)]
+	for _, mod := range q.contextMods {
+		mod(ctx, &q2)
+	}
+	return &q2
+}
+
+// ContextWhere returns the where clauses the context mods add for ctx, as
+// " AND (...)" terms to append to the WHERE clause of the statements of the
+// generated methods on rows by primary key, like Update and Delete, with
+// their arguments. Placeholders are numbered from startAt with index
+// placeholders. It fails if the mods add anything but where clauses, which
+// those statements can't have.
+func ContextWhere(ctx context.Context, dialect *Dialect, mods []func(ctx context.Context, q *Query), startAt int) (string, []interface{}, error) {
+	if len(mods) == 0 {
+		return "", nil, nil
+	}
+	q := &Query{dialect: dialect}
+	for _, mod := range mods {
+		mod(ctx, q)
+	}
+	rest := *q
+	rest.where = nil
+	if !reflect.DeepEqual(rest, Query{dialect: dialect}) {
+		return "", nil, errors.New("context mods of statements by primary key can only add where clauses")
+	}
+
+	clause, args := whereClause(q, startAt)
+	if clause == "" {
+		return "", nil, nil
+	}
+	return " AND " + strings.TrimPrefix(clause, " WHERE "), args, nil
+}
+
+// SetChunked makes the query run as a statement per chunk of the set of
+// its largest IN clause when it has more than MaxPlaceholders arguments,
+// with the rows of all the statements bound. It's only correct for queries whose
+// rows each depend on a single element of the set, like the eager loads of
+// the generated code, and their ordering, if any, holds within each chunk.
+func SetChunked(q *Query) {
+	q.chunked = true
+}
+
 // SetUpdate on the query.
 func SetUpdate(q *Query, cols map[string]interface{}) {
 	q.update = cols
@@ -175,6 +409,27 @@ func AppendSelect(q *Query, fields ...string) {
 	q.selectCols = append(q.selectCols, fields...)
 }
 
+// SetUnscoped removes the default scope of the model from the query.
+func SetUnscoped(q *Query) {
+	q.unscoped = true
+}
+
+// IsUnscoped reports whether the default scope of the model is removed from
+// the query.
+func IsUnscoped(q *Query) bool {
+	return q.unscoped
+}
+
+// SetDistinct on the query.
+func SetDistinct(q *Query) {
+	q.distinct = true
+}
+
+// AppendDistinctOn on the query.
+func AppendDistinctOn(q *Query, cols ...string) {
+	q.distinctOn = append(q.distinctOn, cols...)
+}
+
 // AppendFrom on the query.
 func AppendFrom(q *Query, from ...string) {
 	q.from = append(q.from, from...)
@@ -185,6 +440,43 @@ func SetFrom(q *Query, from ...string) {
 	q.from = append([]string(nil), from...)
 }
 
+// SetAlias aliases the first from statement, the table of the model, so it
+// can be joined with itself.
+func SetAlias(q *Query, alias string) {
+	q.alias = alias
+}
+
+// TableRef returns the quoted alias of the table of the model in the query if
+// it has one, and table otherwise. table must already be quoted.
+func TableRef(q *Query, table string) string {
+	if q.alias == "" {
+		return table
+	}
+	return strmangle.IdentQuote(q.dialect.LQ, q.dialect.RQ, q.alias)
+}
+
+// AppendWith adds a common table expression (CTE) to the query.
+func AppendWith(q *Query, name string, recursive bool, clause string, args ...interface{}) {
+	q.with = append(q.with, with{name: name, recursive: recursive, clause: clause, args: args})
+}
+
+// AppendAggregate adds an aggregate function expression to the selected columns.
+func AppendAggregate(q *Query, fn, col, alias string) {
+	q.aggregates = append(q.aggregates, aggregate{fn: fn, col: col, alias: alias})
+}
+
+// AppendWindow adds a window function expression to the selected columns.
+func AppendWindow(q *Query, fn, partitionBy, orderBy, alias string) {
+	q.windows = append(q.windows, window{fn: fn, partitionBy: partitionBy, orderBy: orderBy, alias: alias})
+}
+
+// AppendSetOp combines the query with other using the set operation kind,
+// like "UNION" or "EXCEPT". Building the query panics if both are known to
+// select a different number of columns; nothing else about them is checked.
+func AppendSetOp(q *Query, kind string, other *Query) {
+	q.setOps = append(q.setOps, setOp{kind: kind, query: other})
+}
+
 // AppendInnerJoin on the query.
 func AppendInnerJoin(q *Query, clause string, args ...interface{}) {
 	q.joins = append(q.joins, join{clause: clause, kind: JoinInner, args: args})
@@ -210,7 +502,15 @@ func AppendGroupBy(q *Query, clause string) {
 	q.groupBy = append(q.groupBy, clause)
 }
 
+// SetDefaultOrderBy on the query. The clause orders the rows of select
+// queries without ORDER BY clauses, unless they're counts, or distinct,
+// grouped or aggregated queries, which it could make invalid.
+func SetDefaultOrderBy(q *Query, clause string) {
+	q.defOrderBy = clause
+}
+
 // AppendOrderBy on the query.
-func AppendOrderBy(q *Query, clause string) {
+func AppendOrderBy(q *Query, clause string, args ...interface{}) {
 	q.orderBy = append(q.orderBy, clause)
+	q.orderArgs = append(q.orderArgs, args...)
 }
@@ -41,9 +41,23 @@ func buildQuery(q *Query) (string, []interface{}) {
 }
 
 func buildSelectQuery(q *Query) (*bytes.Buffer, []interface{}) {
+	if len(q.setOps) != 0 {
+		return buildSetOpQuery(q)
+	}
+	q = withDefaultOrderBy(q)
+	if q.count && (q.distinct || len(q.distinctOn) != 0 || len(q.groupBy) != 0) {
+		return buildCountSubquery(q)
+	}
+	if len(q.limitPer) != 0 && (q.limit != 0 || q.offset != 0) && !q.count {
+		return buildLimitPerQuery(q)
+	}
+
+	checkDistinctOnOrder(q)
+
 	buf := strmangle.GetBuffer()
 	var args []interface{}
 
+	writeWith(q, buf, &args)
 	buf.WriteString("SELECT ")
 
 	if q.dialect.UseTopClause {
@@ -52,6 +66,12 @@ func buildSelectQuery(q *Query) (*bytes.Buffer, []interface{}) {
 		}
 	}
 
+	if len(q.distinctOn) != 0 {
+		fmt.Fprintf(buf, "DISTINCT ON (%s) ", strings.Join(strmangle.IdentQuoteSlice(q.dialect.LQ, q.dialect.RQ, q.distinctOn), ", "))
+	} else if q.distinct {
+		buf.WriteString("DISTINCT ")
+	}
+
 	if q.count {
 		buf.WriteString("COUNT(")
 	}
@@ -64,6 +84,8 @@ func buildSelectQuery(q *Query) (*bytes.Buffer, []interface{}) {
 		buf.WriteString(strings.Join(selectColsWithAs, ", "))
 	} else if hasSelectCols {
 		buf.WriteString(strings.Join(strmangle.IdentQuoteSlice(q.dialect.LQ, q.dialect.RQ, q.selectCols), ", "))
+	} else if len(q.aggregates) != 0 && !q.count {
+		// The aggregates are written below.
 	} else if hasJoins && !q.count {
 		selectColsWithStars := writeStars(q)
 		buf.WriteString(strings.Join(selectColsWithStars, ", "))
@@ -71,21 +93,43 @@ func buildSelectQuery(q *Query) (*bytes.Buffer, []interface{}) {
 		buf.WriteByte('*')
 	}
 
+	if !q.count {
+		for i, a := range q.aggregates {
+			if i != 0 || hasSelectCols {
+				buf.WriteString(", ")
+			}
+			writeAggregate(q, buf, a)
+		}
+		for _, w := range q.windows {
+			buf.WriteString(", ")
+			writeWindow(q, buf, w)
+		}
+	}
+
 	// close SQL COUNT function
 	if q.count {
 		buf.WriteByte(')')
 	}
 
-	fmt.Fprintf(buf, " FROM %s", strings.Join(strmangle.IdentQuoteSlice(q.dialect.LQ, q.dialect.RQ, q.from), ", "))
+	fmt.Fprintf(buf, " FROM %s", fromClause(q))
 
 	if len(q.joins) > 0 {
 		argsLen := len(args)
 		joinBuf := strmangle.GetBuffer()
 		for _, j := range q.joins {
-			if j.kind != JoinInner {
-				panic("only inner joins are supported")
+			switch j.kind {
+			case JoinInner:
+				joinBuf.WriteString(" INNER JOIN ")
+			case JoinOuterLeft:
+				joinBuf.WriteString(" LEFT JOIN ")
+			default:
+				panic("only inner and left joins are supported")
 			}
-			fmt.Fprintf(joinBuf, " INNER JOIN %s", j.clause)
+			if j.table != "" {
+				writeJoinOn(q, joinBuf, j)
+				continue
+			}
+			joinBuf.WriteString(j.clause)
 			args = append(args, j.args...)
 		}
 		var resp string
@@ -116,14 +160,309 @@ func buildSelectQuery(q *Query) (*bytes.Buffer, []interface{}) {
 	return buf, args
 }
 
+// withDefaultOrderBy returns q ordered by its default ORDER BY clause, if
+// it applies, see SetDefaultOrderBy.
+func withDefaultOrderBy(q *Query) *Query {
+	if q.defOrderBy == "" || len(q.orderBy) != 0 || q.count || q.distinct || len(q.distinctOn) != 0 || len(q.groupBy) != 0 || len(q.aggregates) != 0 {
+		return q
+	}
+	q2 := *q
+	q2.orderBy = []string{q.defOrderBy}
+	return &q2
+}
+
+// buildSetOpQuery builds a query combined with others with set operations.
+// The ORDER BY, LIMIT, OFFSET and locking clauses of q apply to the combined
+// result, the other clauses to q's own rows.
+func buildSetOpQuery(q *Query) (*bytes.Buffer, []interface{}) {
+	buf := strmangle.GetBuffer()
+	var args []interface{}
+
+	writeWith(q, buf, &args)
+	if q.count {
+		buf.WriteString("SELECT COUNT(*) FROM (")
+	}
+
+	first := *q
+	first.with = nil
+	first.setOps = nil
+	first.count = false
+	first.orderBy = nil
+	first.orderArgs = nil
+	first.defOrderBy = ""
+	first.limit = 0
+	first.offset = 0
+	first.forlock = ""
+	first.lockWait = ""
+
+	checkSetOpColumns(&first, q.setOps)
+
+	sql, subArgs := buildSubquery(&first, q.dialect, len(args)+1)
+	fmt.Fprintf(buf, "(%s)", sql)
+	args = append(args, subArgs...)
+	for _, op := range q.setOps {
+		sql, subArgs := buildSubquery(op.query, q.dialect, len(args)+1)
+		fmt.Fprintf(buf, " %s (%s)", op.kind, sql)
+		args = append(args, subArgs...)
+	}
+
+	writeModifiers(&Query{
+		dialect:   q.dialect,
+		orderBy:   q.orderBy,
+		orderArgs: q.orderArgs,
+		limit:     q.limit,
+		offset:    q.offset,
+		forlock:   q.forlock,
+		lockWait:  q.lockWait,
+	}, buf, &args)
+
+	if q.count {
+		buf.WriteString(") AS ")
+		buf.WriteString(strmangle.IdentQuote(q.dialect.LQ, q.dialect.RQ, "combined"))
+	}
+
+	buf.WriteByte(';')
+	return buf, args
+}
+
+// buildCountSubquery counts the rows of a distinct or grouped query, which
+// COUNT(DISTINCT ...) can't do for all columns, DISTINCT ON or GROUP BY.
+func buildCountSubquery(q *Query) (*bytes.Buffer, []interface{}) {
+	rows := *q
+	rows.count = false
+	rows.windows = nil
+	rows.rawSQL = rawSQL{}
+	if len(rows.groupBy) != 0 && len(rows.selectCols)+len(rows.aggregates) == 0 {
+		// All columns can't be selected from groups.
+		rows.selectCols = []string{"1"}
+	}
+
+	inner, args := buildSelectQuery(&rows)
+	defer strmangle.PutBuffer(inner)
+
+	buf := strmangle.GetBuffer()
+	fmt.Fprintf(buf, "SELECT COUNT(*) FROM (%s) AS %s;",
+		strings.TrimSuffix(inner.String(), ";"),
+		strmangle.IdentQuote(q.dialect.LQ, q.dialect.RQ, "counted"),
+	)
+	return buf, args
+}
+
+// buildLimitPerQuery builds a query limited per group of rows, see
+// SetLimitPer. The rows are numbered in their group with ROW_NUMBER, and the
+// outer query keeps the ones in the limit and offset.
+func buildLimitPerQuery(q *Query) (*bytes.Buffer, []interface{}) {
+	if len(q.orderArgs) != 0 {
+		panic("sqlbunny: ORDER BY arguments can't be used with a limit per group")
+	}
+
+	rn := strmangle.IdentQuote(q.dialect.LQ, q.dialect.RQ, "bunny_rn")
+
+	rows := *q
+	rows.limitPer = nil
+	rows.limit = 0
+	rows.offset = 0
+	rows.orderBy = nil
+	rows.defOrderBy = ""
+	rows.rawSQL = rawSQL{}
+	rows.windows = append(append([]window(nil), q.windows...), window{
+		fn:          "ROW_NUMBER()",
+		partitionBy: strings.Join(q.limitPer, ", "),
+		orderBy:     strings.Join(q.orderBy, ", "),
+		alias:       "bunny_rn",
+	})
+
+	inner, args := buildSelectQuery(&rows)
+	defer strmangle.PutBuffer(inner)
+
+	buf := strmangle.GetBuffer()
+	fmt.Fprintf(buf, "SELECT * FROM (%s) AS %s WHERE %s > %d",
+		strings.TrimSuffix(inner.String(), ";"),
+		strmangle.IdentQuote(q.dialect.LQ, q.dialect.RQ, "limited"),
+		rn, q.offset,
+	)
+	if q.limit != 0 {
+		fmt.Fprintf(buf, " AND %s <= %d", rn, q.offset+q.limit)
+	}
+	fmt.Fprintf(buf, " ORDER BY %s;", rn)
+	return buf, args
+}
+
+// checkDistinctOnOrder panics if the ORDER BY clauses of a DISTINCT ON query
+// don't start with the DISTINCT ON columns, which the database rejects.
+func checkDistinctOnOrder(q *Query) {
+	if len(q.distinctOn) == 0 || len(q.orderBy) == 0 {
+		return
+	}
+
+	var terms []string
+	for _, clause := range q.orderBy {
+		depth, start := 0, 0
+		for i := 0; i <= len(clause); i++ {
+			if i < len(clause) {
+				switch clause[i] {
+				case '(':
+					depth++
+				case ')':
+					depth--
+				}
+				if clause[i] != ',' || depth != 0 {
+					continue
+				}
+			}
+			if term := orderTerm(clause[start:i]); term != "" {
+				terms = append(terms, term)
+			}
+			start = i + 1
+		}
+	}
+
+	leading := make(map[string]bool)
+	for i := 0; i < len(terms) && i < len(q.distinctOn); i++ {
+		leading[distinctTerm(q, terms[i])] = true
+	}
+	for _, col := range q.distinctOn {
+		if !leading[distinctTerm(q, col)] {
+			panic(fmt.Sprintf("ORDER BY must start with the DISTINCT ON columns, %s is missing", col))
+		}
+	}
+}
+
+// orderTerm strips the ASC, DESC and NULLS options of an ORDER BY term.
+func orderTerm(s string) string {
+	fields := strings.Fields(s)
+	for len(fields) > 1 {
+		switch strings.ToUpper(fields[len(fields)-1]) {
+		case "ASC", "DESC", "FIRST", "LAST", "NULLS":
+			fields = fields[:len(fields)-1]
+			continue
+		}
+		break
+	}
+	return strings.Join(fields, " ")
+}
+
+// distinctTerm normalizes a column name or expression for comparisons,
+// ignoring quotes, case and table qualifiers.
+func distinctTerm(q *Query, s string) string {
+	s = strings.ToLower(strings.TrimSpace(s))
+	s = strings.NewReplacer(string(q.dialect.LQ), "", string(q.dialect.RQ), "").Replace(s)
+	if i := strings.LastIndexByte(s, '.'); i != -1 && !strings.ContainsAny(s, "()") {
+		s = s[i+1:]
+	}
+	return s
+}
+
+// checkSetOpColumns panics if queries combined with set operations are known
+// to return a different number of columns. It only counts the columns: their
+// names don't have to match, since set operations pair them by position, and
+// their types are only known to the database, which checks them. Queries
+// selecting all columns, with SELECT *, or raw queries aren't checked.
+func checkSetOpColumns(q *Query, ops []setOp) {
+	count := func(q *Query) int {
+		if len(q.selectCols)+len(q.aggregates) == 0 || len(q.from) == 0 {
+			return -1
+		}
+		return len(q.selectCols) + len(q.aggregates) + len(q.windows)
+	}
+	n := count(q)
+	for _, op := range ops {
+		if m := count(op.query); n != -1 && m != -1 && n != m {
+			panic(fmt.Sprintf("queries combined with %s select %d and %d columns", op.kind, n, m))
+		}
+	}
+}
+
+// buildSubquery builds q to be embedded in a query using dialect, with
+// placeholders numbered from startAt. Raw queries are embedded as is, after
+// numbering their ? placeholders.
+func buildSubquery(q *Query, dialect *Dialect, startAt int) (string, []interface{}) {
+	sql, args := subquerySQL(q, dialect)
+	if dialect.IndexPlaceholders {
+		sql, _ = convertQuestionMarks(sql, startAt)
+	}
+	return sql, args
+}
+
+// subquerySQL builds q to be embedded in a query using dialect, with ?
+// placeholders. Raw queries are embedded as is.
+func subquerySQL(q *Query, dialect *Dialect) (string, []interface{}) {
+	var sql string
+	var args []interface{}
+	if len(q.from) == 0 && len(q.rawSQL.sql) != 0 {
+		sql, args = q.rawSQL.sql, q.rawSQL.args
+	} else {
+		// Build with ? placeholders, they're numbered once embedded.
+		d := *dialect
+		d.IndexPlaceholders = false
+		sub := *q
+		sub.dialect = &d
+		sub.rawSQL = rawSQL{}
+
+		var buf *bytes.Buffer
+		buf, args = buildSelectQuery(&sub)
+		sql = buf.String()
+		strmangle.PutBuffer(buf)
+	}
+
+	return strings.TrimSuffix(sql, ";"), args
+}
+
+// expandSubqueries replaces the placeholders of clause bound to a *Query
+// argument with the query between parentheses, and the argument with the
+// query's ones.
+func expandSubqueries(dialect *Dialect, clause string, args []interface{}) (string, []interface{}) {
+	hasSubquery := false
+	for _, arg := range args {
+		if _, ok := arg.(*Query); ok {
+			hasSubquery = true
+			break
+		}
+	}
+	if !hasSubquery {
+		return clause, args
+	}
+
+	buf := strmangle.GetBuffer()
+	defer strmangle.PutBuffer(buf)
+	var expanded []interface{}
+
+	n := 0
+	for i := 0; i < len(clause); i++ {
+		if clause[i] == '\\' && i+1 < len(clause) && clause[i+1] == '?' {
+			buf.WriteString(`\?`)
+			i++
+			continue
+		}
+		if clause[i] != '?' || n >= len(args) {
+			buf.WriteByte(clause[i])
+			continue
+		}
+
+		if sub, ok := args[n].(*Query); ok {
+			sql, subArgs := subquerySQL(sub, dialect)
+			fmt.Fprintf(buf, "(%s)", sql)
+			expanded = append(expanded, subArgs...)
+		} else {
+			buf.WriteByte('?')
+			expanded = append(expanded, args[n])
+		}
+		n++
+	}
+	expanded = append(expanded, args[n:]...)
+
+	return buf.String(), expanded
+}
+
 func buildDeleteQuery(q *Query) (*bytes.Buffer, []interface{}) {
 	var args []interface{}
 	buf := strmangle.GetBuffer()
 
+	writeWith(q, buf, &args)
 	buf.WriteString("DELETE FROM ")
-	buf.WriteString(strings.Join(strmangle.IdentQuoteSlice(q.dialect.LQ, q.dialect.RQ, q.from), ", "))
+	buf.WriteString(fromClause(q))
 
-	where, whereArgs := whereClause(q, 1)
+	where, whereArgs := whereClause(q, len(args)+1)
 	if len(whereArgs) != 0 {
 		args = append(args, whereArgs...)
 	}
@@ -144,12 +483,14 @@ func buildDeleteQuery(q *Query) (*bytes.Buffer, []interface{}) {
 
 func buildUpdateQuery(q *Query) (*bytes.Buffer, []interface{}) {
 	buf := strmangle.GetBuffer()
+	var args []interface{}
 
+	writeWith(q, buf, &args)
 	buf.WriteString("UPDATE ")
-	buf.WriteString(strings.Join(strmangle.IdentQuoteSlice(q.dialect.LQ, q.dialect.RQ, q.from), ", "))
+	buf.WriteString(fromClause(q))
 
 	cols := make(sort.StringSlice, len(q.update))
-	var args []interface{}
+	startAt := len(args) + 1
 
 	count := 0
 	for name := range q.update {
@@ -166,7 +507,7 @@ func buildUpdateQuery(q *Query) (*bytes.Buffer, []interface{}) {
 
 	setSlice := make([]string, len(cols))
 	for index, col := range cols {
-		setSlice[index] = fmt.Sprintf("%s = %s", col, strmangle.Placeholders(q.dialect.IndexPlaceholders, 1, index+1, 1))
+		setSlice[index] = fmt.Sprintf("%s = %s", col, strmangle.Placeholders(q.dialect.IndexPlaceholders, 1, startAt+index, 1))
 	}
 	fmt.Fprintf(buf, " SET %s", strings.Join(setSlice, ", "))
 
@@ -189,8 +530,70 @@ func buildUpdateQuery(q *Query) (*bytes.Buffer, []interface{}) {
 	return buf, args
 }
 
+// MaxPlaceholders is the most placeholders Postgres and MySQL accept in a
+// statement. Statements with more arguments need to be split, like chunked
+// queries, see SetChunked.
+const MaxPlaceholders = 65535
+
+// BuildInsertIgnoreQuery builds an insert of rows rows of the whitelist
+// columns, without the rows conflicting with an existing row on any unique
+// key: with ON CONFLICT DO NOTHING, or INSERT IGNORE for MySQL.
+func BuildInsertIgnoreQuery(dia Dialect, modelName string, whitelist []string, rows int) string {
+	cols := strmangle.IdentQuoteSlice(dia.LQ, dia.RQ, whitelist)
+	values := insertValues(dia, len(cols), rows)
+
+	if dia.UseOnDuplicateKey {
+		return fmt.Sprintf("INSERT IGNORE INTO %s (%s) VALUES %s", modelName, strings.Join(cols, ", "), values)
+	}
+	return fmt.Sprintf("INSERT INTO %s (%s) VALUES %s ON CONFLICT DO NOTHING", modelName, strings.Join(cols, ", "), values)
+}
+
+// insertValues returns the placeholders of the values of rows rows of cols
+// columns.
+func insertValues(dia Dialect, cols, rows int) string {
+	values := make([]string, rows)
+	for i := range values {
+		values[i] = "(" + strmangle.Placeholders(dia.IndexPlaceholders, cols, 1+i*cols, 1) + ")"
+	}
+	return strings.Join(values, ",")
+}
+
+// ConflictTarget is the unique index or constraint an upsert conflicts on:
+// either the Columns of a unique index, with the Where predicate of a
+// partial one, or the name of a unique or exclusion Constraint.
+type ConflictTarget struct {
+	Columns    []string
+	Where      string
+	Constraint string
+}
+
+// BuildUpsertQuery builds an upsert statement for the dialect. Rows
+// conflicting with target are updated with the update columns, or left as
+// is if there are none. MySQL conflicts on any unique key, and ignores target
+// and ret.
+func BuildUpsertQuery(dia Dialect, modelName string, target ConflictTarget, update, whitelist, ret []string) string {
+	if dia.UseOnDuplicateKey {
+		return BuildUpsertQueryMySQL(dia, modelName, update, whitelist)
+	}
+	return BuildUpsertQueryPostgresTarget(dia, modelName, len(update) != 0, ret, update, target, whitelist)
+}
+
+// BuildUpsertAllQuery is like BuildUpsertQuery, for an upsert of rows rows
+// in a single statement. Their values are the ones of the whitelist of each
+// row in turn.
+func BuildUpsertAllQuery(dia Dialect, modelName string, target ConflictTarget, update, whitelist []string, rows int) string {
+	if dia.UseOnDuplicateKey {
+		return buildUpsertQueryMySQL(dia, modelName, update, whitelist, rows)
+	}
+	return buildUpsertQueryPostgres(dia, modelName, len(update) != 0, nil, update, target, whitelist, rows)
+}
+
 // BuildUpsertQueryMySQL builds a SQL statement string using the upsertData provided.
 func BuildUpsertQueryMySQL(dia Dialect, modelName string, update, whitelist []string) string {
+	return buildUpsertQueryMySQL(dia, modelName, update, whitelist, 1)
+}
+
+func buildUpsertQueryMySQL(dia Dialect, modelName string, update, whitelist []string, rows int) string {
 	whitelist = strmangle.IdentQuoteSlice(dia.LQ, dia.RQ, whitelist)
 
 	buf := strmangle.GetBuffer()
@@ -204,20 +607,20 @@ func BuildUpsertQueryMySQL(dia Dialect, modelName string, update, whitelist []st
 	if len(update) == 0 {
 		fmt.Fprintf(
 			buf,
-			"INSERT IGNORE INTO %s (%s) VALUES (%s)",
+			"INSERT IGNORE INTO %s (%s) VALUES %s",
 			modelName,
 			fields,
-			strmangle.Placeholders(dia.IndexPlaceholders, len(whitelist), 1, 1),
+			insertValues(dia, len(whitelist), rows),
 		)
 		return buf.String()
 	}
 
 	fmt.Fprintf(
 		buf,
-		"INSERT INTO %s (%s) VALUES (%s) ON DUPLICATE KEY UPDATE ",
+		"INSERT INTO %s (%s) VALUES %s ON DUPLICATE KEY UPDATE ",
 		modelName,
 		fields,
-		strmangle.Placeholders(dia.IndexPlaceholders, len(whitelist), 1, 1),
+		insertValues(dia, len(whitelist), rows),
 	)
 
 	for i, v := range update {
@@ -236,7 +639,21 @@ func BuildUpsertQueryMySQL(dia Dialect, modelName string, update, whitelist []st
 
 // BuildUpsertQueryPostgres builds a SQL statement string using the upsertData provided.
 func BuildUpsertQueryPostgres(dia Dialect, modelName string, updateOnConflict bool, ret, update, conflict, whitelist []string) string {
-	conflict = strmangle.IdentQuoteSlice(dia.LQ, dia.RQ, conflict)
+	var target ConflictTarget
+	if updateOnConflict && len(update) != 0 {
+		target.Columns = conflict
+	}
+	return BuildUpsertQueryPostgresTarget(dia, modelName, updateOnConflict, ret, update, target, whitelist)
+}
+
+// BuildUpsertQueryPostgresTarget is like BuildUpsertQueryPostgres, with a
+// conflict target that can be a partial unique index or a constraint. The
+// target can be empty to do nothing on any conflict.
+func BuildUpsertQueryPostgresTarget(dia Dialect, modelName string, updateOnConflict bool, ret, update []string, target ConflictTarget, whitelist []string) string {
+	return buildUpsertQueryPostgres(dia, modelName, updateOnConflict, ret, update, target, whitelist, 1)
+}
+
+func buildUpsertQueryPostgres(dia Dialect, modelName string, updateOnConflict bool, ret, update []string, target ConflictTarget, whitelist []string, rows int) string {
 	whitelist = strmangle.IdentQuoteSlice(dia.LQ, dia.RQ, whitelist)
 	ret = strmangle.IdentQuoteSlice(dia.LQ, dia.RQ, ret)
 
@@ -245,9 +662,9 @@ func BuildUpsertQueryPostgres(dia Dialect, modelName string, updateOnConflict bo
 
 	fields := "DEFAULT VALUES"
 	if len(whitelist) != 0 {
-		fields = fmt.Sprintf("(%s) VALUES (%s)",
+		fields = fmt.Sprintf("(%s) VALUES %s",
 			strings.Join(whitelist, ", "),
-			strmangle.Placeholders(dia.IndexPlaceholders, len(whitelist), 1, 1))
+			insertValues(dia, len(whitelist), rows))
 	}
 
 	fmt.Fprintf(
@@ -257,12 +674,23 @@ func BuildUpsertQueryPostgres(dia Dialect, modelName string, updateOnConflict bo
 		fields,
 	)
 
+	switch {
+	case target.Constraint != "":
+		fmt.Fprintf(buf, "ON CONSTRAINT %s ", strmangle.IdentQuote(dia.LQ, dia.RQ, target.Constraint))
+	case len(target.Columns) != 0:
+		fmt.Fprintf(buf, "(%s) ", strings.Join(strmangle.IdentQuoteSlice(dia.LQ, dia.RQ, target.Columns), ", "))
+		if target.Where != "" {
+			fmt.Fprintf(buf, "WHERE %s ", target.Where)
+		}
+	}
+
 	if !updateOnConflict || len(update) == 0 {
 		buf.WriteString("DO NOTHING")
 	} else {
-		buf.WriteByte('(')
-		buf.WriteString(strings.Join(conflict, ", "))
-		buf.WriteString(") DO UPDATE SET ")
+		if target.Constraint == "" && len(target.Columns) == 0 {
+			panic("upserts updating on conflict need a conflict target")
+		}
+		buf.WriteString("DO UPDATE SET ")
 
 		for i, v := range update {
 			if i != 0 {
@@ -326,6 +754,63 @@ func BuildUpsertQueryMSSQL(dia Dialect, modelName string, primary, update, inser
 	return buf.String()
 }
 
+// writeAggregate writes an aggregate function expression, like
+// sum("price") AS "total".
+func writeAggregate(q *Query, buf *bytes.Buffer, a aggregate) {
+	fmt.Fprintf(buf, "%s(%s) AS %s", a.fn,
+		strmangle.IdentQuote(q.dialect.LQ, q.dialect.RQ, a.col),
+		strmangle.IdentQuote(q.dialect.LQ, q.dialect.RQ, a.alias),
+	)
+}
+
+// writeWindow writes a window function expression, like
+// row_number() OVER (PARTITION BY a ORDER BY b) AS "rank".
+func writeWindow(q *Query, buf *bytes.Buffer, w window) {
+	buf.WriteString(w.fn)
+	buf.WriteString(" OVER (")
+	if w.partitionBy != "" {
+		buf.WriteString("PARTITION BY ")
+		buf.WriteString(w.partitionBy)
+		if w.orderBy != "" {
+			buf.WriteByte(' ')
+		}
+	}
+	if w.orderBy != "" {
+		buf.WriteString("ORDER BY ")
+		buf.WriteString(w.orderBy)
+	}
+	buf.WriteString(") AS ")
+	buf.WriteString(strmangle.IdentQuote(q.dialect.LQ, q.dialect.RQ, w.alias))
+}
+
+// writeWith writes the WITH clause of the CTEs of the query, if any. It's
+// RECURSIVE if any of them is.
+func writeWith(q *Query, buf *bytes.Buffer, args *[]interface{}) {
+	if len(q.with) == 0 {
+		return
+	}
+
+	buf.WriteString("WITH ")
+	for _, w := range q.with {
+		if w.recursive {
+			buf.WriteString("RECURSIVE ")
+			break
+		}
+	}
+	for i, w := range q.with {
+		if i != 0 {
+			buf.WriteString(", ")
+		}
+		clause := w.clause
+		if q.dialect.IndexPlaceholders {
+			clause, _ = convertQuestionMarks(clause, len(*args)+1)
+		}
+		fmt.Fprintf(buf, "%s AS (%s)", w.name, clause)
+		*args = append(*args, w.args...)
+	}
+	buf.WriteByte(' ')
+}
+
 func writeModifiers(q *Query, buf *bytes.Buffer, args *[]interface{}) {
 	if len(q.groupBy) != 0 {
 		fmt.Fprintf(buf, " GROUP BY %s", strings.Join(q.groupBy, ", "))
@@ -353,8 +838,12 @@ func writeModifiers(q *Query, buf *bytes.Buffer, args *[]interface{}) {
 	}
 
 	if len(q.orderBy) != 0 {
-		buf.WriteString(" ORDER BY ")
-		buf.WriteString(strings.Join(q.orderBy, ", "))
+		orderBy := " ORDER BY " + strings.Join(q.orderBy, ", ")
+		if q.dialect.IndexPlaceholders {
+			orderBy, _ = convertQuestionMarks(orderBy, len(*args)+1)
+		}
+		buf.WriteString(orderBy)
+		*args = append(*args, q.orderArgs...)
 	}
 
 	if !q.dialect.UseTopClause {
@@ -391,12 +880,29 @@ func writeModifiers(q *Query, buf *bytes.Buffer, args *[]interface{}) {
 
 	if len(q.forlock) != 0 {
 		fmt.Fprintf(buf, " FOR %s", q.forlock)
+		if len(q.lockWait) != 0 {
+			fmt.Fprintf(buf, " %s", q.lockWait)
+		}
 	}
 }
 
+// fromClause returns the quoted from statements, with the alias of the table
+// of the model.
+func fromClause(q *Query) string {
+	from := strmangle.IdentQuoteSlice(q.dialect.LQ, q.dialect.RQ, q.from)
+	if q.alias != "" && len(from) != 0 {
+		from[0] += " AS " + strmangle.IdentQuote(q.dialect.LQ, q.dialect.RQ, q.alias)
+	}
+	return strings.Join(from, ", ")
+}
+
 func writeStars(q *Query) []string {
 	cols := make([]string, len(q.from))
 	for i, f := range q.from {
+		if i == 0 && q.alias != "" {
+			cols[i] = fmt.Sprintf(`%s.*`, strmangle.IdentQuote(q.dialect.LQ, q.dialect.RQ, q.alias))
+			continue
+		}
 		toks := strings.Split(f, " ")
 		if len(toks) == 1 {
 			cols[i] = fmt.Sprintf(`%s.*`, strmangle.IdentQuote(q.dialect.LQ, q.dialect.RQ, toks[0]))
@@ -462,8 +968,9 @@ func whereClause(q *Query, startAt int) (string, []interface{}) {
 			buf.WriteString(" AND ")
 		}
 
-		buf.WriteString(fmt.Sprintf("(%s)", where.clause))
-		args = append(args, where.args...)
+		clause, whereArgs := expandSubqueries(q.dialect, where.clause, where.args)
+		buf.WriteString(fmt.Sprintf("(%s)", clause))
+		args = append(args, whereArgs...)
 	}
 
 	var resp string
@@ -502,6 +1009,22 @@ func inClause(q *Query, startAt int) (string, []interface{}) {
 		}
 
 		matches := rgxInClause.FindStringSubmatch(in.clause)
+		if sub, ok := inSubquery(in); ok && matches != nil {
+			// The set is a subquery, its SQL replaces the placeholder.
+			cols := strings.Split(strings.TrimSpace(matches[1]), ",")
+			cols = strmangle.IdentQuoteSlice(q.dialect.LQ, q.dialect.RQ, cols)
+			rightClause, subArgs := expandSubqueries(q.dialect, strings.TrimSpace(matches[2]), []interface{}{sub})
+			if q.dialect.IndexPlaceholders {
+				rightClause, _ = convertQuestionMarks(rightClause, startAt)
+			}
+			buf.WriteString(strings.Join(cols, ","))
+			buf.WriteString(" IN ")
+			buf.WriteString(rightClause)
+			startAt += len(subArgs)
+			args = append(args, subArgs...)
+			continue
+		}
+
 		// If we can't find any matches attempt a simple replace with 1 group.
 		// Clauses that fit this criteria will not be able to contain ? in their
 		// field name side, however if this case is being hit then the regexp
@@ -547,6 +1070,15 @@ func inClause(q *Query, startAt int) (string, []interface{}) {
 	return buf.String(), args
 }
 
+// inSubquery returns the query in's set is made of, if any.
+func inSubquery(in in) (*Query, bool) {
+	if len(in.args) != 1 {
+		return nil, false
+	}
+	sub, ok := in.args[0].(*Query)
+	return sub, ok
+}
+
 // convertInQuestionMarks finds the first unescaped occurrence of ? and swaps it
 // with a list of numbered placeholders, starting at startAt.
 // It uses groupAt to determine how many placeholders should be in each group,
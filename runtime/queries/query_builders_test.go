@@ -89,10 +89,82 @@ func TestBuildQuery(t *testing.T) {
 			},
 			limit: 5,
 		}, []interface{}{2, 3, 1, 4, 5, 6, 7, 8, 9, 10}},
-		{&Query{from: []string{"cats"}, joins: []join{{JoinInner, "dogs d on d.cat_id = cats.id", nil}}}, nil},
-		{&Query{from: []string{"cats c"}, joins: []join{{JoinInner, "dogs d on d.cat_id = cats.id", nil}}}, nil},
-		{&Query{from: []string{"cats as c"}, joins: []join{{JoinInner, "dogs d on d.cat_id = cats.id", nil}}}, nil},
-		{&Query{from: []string{"cats as c", "dogs as d"}, joins: []join{{JoinInner, "dogs d on d.cat_id = cats.id", nil}}}, nil},
+		{&Query{from: []string{"cats"}, joins: []join{{kind: JoinInner, clause: "dogs d on d.cat_id = cats.id"}}}, nil},
+		{&Query{from: []string{"cats c"}, joins: []join{{kind: JoinInner, clause: "dogs d on d.cat_id = cats.id"}}}, nil},
+		{&Query{from: []string{"cats as c"}, joins: []join{{kind: JoinInner, clause: "dogs d on d.cat_id = cats.id"}}}, nil},
+		{&Query{from: []string{"cats as c", "dogs as d"}, joins: []join{{kind: JoinInner, clause: "dogs d on d.cat_id = cats.id"}}}, nil},
+		{&Query{from: []string{"jobs"}, limit: 1, forlock: "UPDATE", lockWait: "SKIP LOCKED"}, nil},
+		{&Query{
+			from: []string{"category"},
+			with: []with{
+				{name: "roots", clause: "SELECT id FROM category WHERE name = ?", args: []interface{}{"books"}},
+				{name: "tree(id)", recursive: true, clause: "SELECT id FROM roots UNION ALL SELECT c.id FROM category c INNER JOIN tree t ON c.parent_id = t.id WHERE c.depth < ?", args: []interface{}{5}},
+			},
+			where: []where{{clause: "id IN (SELECT id FROM tree) AND visible = ?", args: []interface{}{true}}},
+		}, []interface{}{"books", 5, true}},
+		{&Query{
+			from:   []string{"book"},
+			with:   []with{{name: "stale", clause: "SELECT id FROM book WHERE updated_at < ?", args: []interface{}{1}}},
+			update: map[string]interface{}{"archived": true},
+			where:  []where{{clause: "id IN (SELECT id FROM stale) AND owner = ?", args: []interface{}{2}}},
+		}, []interface{}{1, true, 2}},
+		{&Query{
+			from: []string{"book"},
+			windows: []window{
+				{fn: "row_number()", partitionBy: "author_id", orderBy: "created_at DESC", alias: "rank"},
+				{fn: "sum(price)", orderBy: "created_at", alias: "running_total"},
+			},
+		}, nil},
+		{&Query{
+			from:    []string{"book"},
+			where:   []where{{clause: "author_id = ?", args: []interface{}{1}}},
+			orderBy: []string{"title"},
+			limit:   10,
+			setOps: []setOp{
+				{kind: "UNION", query: &Query{from: []string{"book"}, where: []where{{clause: "editor_id = ?", args: []interface{}{2}}}}},
+				{kind: "EXCEPT", query: &Query{from: []string{"book"}, where: []where{{clause: "archived = ?", args: []interface{}{true}}}}},
+			},
+		}, []interface{}{1, 2, true}},
+		{&Query{
+			from:       []string{"book"},
+			selectCols: []string{"id"},
+			count:      true,
+			setOps:     []setOp{{kind: "INTERSECT", query: &Query{from: []string{"review"}, selectCols: []string{"book_id"}}}},
+		}, nil},
+		{&Query{
+			from:  []string{"users"},
+			where: []where{{clause: "active = ? AND EXISTS ?", args: []interface{}{true, &Query{from: []string{"orders"}, where: []where{{clause: "orders.user_id = users.id AND total > ?", args: []interface{}{100}}}}}}},
+			in: []in{
+				{clause: "id in ?", args: []interface{}{&Query{from: []string{"orders"}, selectCols: []string{"user_id"}, where: []where{{clause: "status = ?", args: []interface{}{"paid"}}}}}},
+				{clause: "role in ?", args: []interface{}{"admin", "staff"}},
+			},
+		}, []interface{}{true, 100, "paid", "admin", "staff"}},
+		{&Query{
+			from:   []string{"orders"},
+			delete: true,
+			in:     []in{{clause: "user_id in ?", args: []interface{}{&Query{from: []string{"users"}, selectCols: []string{"id"}, where: []where{{clause: "banned = ?", args: []interface{}{true}}}}}}},
+		}, []interface{}{true}},
+		{&Query{
+			from:       []string{"book"},
+			distinctOn: []string{"author_id"},
+			orderBy:    []string{"\"book\".\"author_id\", created_at DESC NULLS LAST"},
+		}, nil},
+		{&Query{from: []string{"book"}, selectCols: []string{"author_id"}, distinct: true, count: true}, nil},
+		{&Query{
+			from:       []string{"orders"},
+			selectCols: []string{"user_id"},
+			aggregates: []aggregate{{fn: "count", col: "*", alias: "orders"}, {fn: "max", col: "created_at", alias: "last_placed"}},
+			groupBy:    []string{"user_id"},
+			having:     []having{{clause: "count(*) > ?", args: []interface{}{1}}},
+		}, []interface{}{1}},
+		{&Query{from: []string{"orders"}, groupBy: []string{"user_id"}, count: true}, nil},
+		{&Query{
+			from:      []string{"post"},
+			where:     []where{{clause: "search @@ to_tsquery(?)", args: []interface{}{"cat"}}},
+			orderBy:   []string{"ts_rank(search, to_tsquery(?)) DESC", "id"},
+			orderArgs: []interface{}{"cat"},
+			limit:     5,
+		}, []interface{}{"cat", "cat"}},
 	}
 
 	for i, test := range tests {
@@ -486,3 +558,238 @@ func TestWriteAsStatements(t *testing.T) {
 		}
 	}
 }
+
+func TestBuildSetOpColumnMismatch(t *testing.T) {
+	t.Parallel()
+
+	q := &Query{
+		dialect:    &Dialect{LQ: '"', RQ: '"', IndexPlaceholders: true},
+		from:       []string{"book"},
+		selectCols: []string{"id", "title"},
+		setOps:     []setOp{{kind: "UNION", query: &Query{from: []string{"review"}, selectCols: []string{"book_id"}}}},
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("expected a panic for queries selecting a different number of columns")
+		}
+	}()
+	buildQuery(q)
+}
+
+func TestBuildDistinctOnOrderMismatch(t *testing.T) {
+	t.Parallel()
+
+	q := &Query{
+		dialect:    &Dialect{LQ: '"', RQ: '"', IndexPlaceholders: true},
+		from:       []string{"book"},
+		distinctOn: []string{"author_id"},
+		orderBy:    []string{"created_at DESC", "author_id"},
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("expected a panic for ORDER BY not starting with the DISTINCT ON columns")
+		}
+	}()
+	buildQuery(q)
+}
+
+func TestBuildInsertIgnoreQuery(t *testing.T) {
+	t.Parallel()
+
+	postgres := Dialect{LQ: '"', RQ: '"', IndexPlaceholders: true, UseReturning: true}
+	mysql := Dialect{LQ: '`', RQ: '`', UseOnDuplicateKey: true}
+
+	tests := []struct {
+		dia  Dialect
+		rows int
+		want string
+	}{
+		{postgres, 1, `INSERT INTO users ("id", "email") VALUES ($1,$2) ON CONFLICT DO NOTHING`},
+		{postgres, 2, `INSERT INTO users ("id", "email") VALUES ($1,$2),($3,$4) ON CONFLICT DO NOTHING`},
+		{mysql, 2, "INSERT IGNORE INTO users (`id`, `email`) VALUES (?,?),(?,?)"},
+	}
+
+	for i, test := range tests {
+		got := BuildInsertIgnoreQuery(test.dia, "users", []string{"id", "email"}, test.rows)
+		if got != test.want {
+			t.Errorf("[%d] wrong query:\nwant: %s\ngot:  %s", i, test.want, got)
+		}
+	}
+}
+
+func TestBuildUpsertQuery(t *testing.T) {
+	t.Parallel()
+
+	postgres := Dialect{LQ: '"', RQ: '"', IndexPlaceholders: true, UseReturning: true}
+	mysql := Dialect{LQ: '`', RQ: '`', UseOnDuplicateKey: true}
+
+	tests := []struct {
+		dia    Dialect
+		target ConflictTarget
+		update []string
+		want   string
+	}{
+		{postgres, ConflictTarget{Columns: []string{"id"}}, []string{"name"}, `INSERT INTO users ("id", "email", "name") VALUES ($1,$2,$3) ON CONFLICT ("id") DO UPDATE SET "name" = EXCLUDED."name"`},
+		{postgres, ConflictTarget{Columns: []string{"email"}, Where: "deleted_at IS NULL"}, []string{"name"}, `INSERT INTO users ("id", "email", "name") VALUES ($1,$2,$3) ON CONFLICT ("email") WHERE deleted_at IS NULL DO UPDATE SET "name" = EXCLUDED."name"`},
+		{postgres, ConflictTarget{Constraint: "users_email_key"}, nil, `INSERT INTO users ("id", "email", "name") VALUES ($1,$2,$3) ON CONFLICT ON CONSTRAINT "users_email_key" DO NOTHING`},
+		{postgres, ConflictTarget{}, nil, `INSERT INTO users ("id", "email", "name") VALUES ($1,$2,$3) ON CONFLICT DO NOTHING`},
+		{mysql, ConflictTarget{Columns: []string{"email"}}, []string{"name"}, "INSERT INTO users (`id`, `email`, `name`) VALUES (?,?,?) ON DUPLICATE KEY UPDATE `name` = VALUES(`name`)"},
+	}
+
+	for i, test := range tests {
+		got := BuildUpsertQuery(test.dia, "users", test.target, test.update, []string{"id", "email", "name"}, nil)
+		if got != test.want {
+			t.Errorf("[%d] wrong query:\nwant: %s\ngot:  %s", i, test.want, got)
+		}
+	}
+}
+
+func TestBuildUpsertAllQuery(t *testing.T) {
+	t.Parallel()
+
+	postgres := Dialect{LQ: '"', RQ: '"', IndexPlaceholders: true, UseReturning: true}
+	mysql := Dialect{LQ: '`', RQ: '`', UseOnDuplicateKey: true}
+
+	tests := []struct {
+		dia    Dialect
+		update []string
+		rows   int
+		want   string
+	}{
+		{postgres, []string{"name"}, 1, `INSERT INTO users ("id", "name") VALUES ($1,$2) ON CONFLICT ("id") DO UPDATE SET "name" = EXCLUDED."name"`},
+		{postgres, []string{"name"}, 2, `INSERT INTO users ("id", "name") VALUES ($1,$2),($3,$4) ON CONFLICT ("id") DO UPDATE SET "name" = EXCLUDED."name"`},
+		{postgres, nil, 2, `INSERT INTO users ("id", "name") VALUES ($1,$2),($3,$4) ON CONFLICT ("id") DO NOTHING`},
+		{mysql, []string{"name"}, 2, "INSERT INTO users (`id`, `name`) VALUES (?,?),(?,?) ON DUPLICATE KEY UPDATE `name` = VALUES(`name`)"},
+		{mysql, nil, 2, "INSERT IGNORE INTO users (`id`, `name`) VALUES (?,?),(?,?)"},
+	}
+
+	for i, test := range tests {
+		got := BuildUpsertAllQuery(test.dia, "users", ConflictTarget{Columns: []string{"id"}}, test.update, []string{"id", "name"}, test.rows)
+		if got != test.want {
+			t.Errorf("[%d] wrong query:\nwant: %s\ngot:  %s", i, test.want, got)
+		}
+	}
+}
+
+func TestBuildAliasQuery(t *testing.T) {
+	t.Parallel()
+
+	dia := &Dialect{LQ: '"', RQ: '"', IndexPlaceholders: true}
+
+	tests := []struct {
+		q    *Query
+		want string
+	}{
+		{
+			&Query{from: []string{"employees"}, alias: "e"},
+			`SELECT * FROM "employees" AS "e";`,
+		},
+		{
+			&Query{
+				from:  []string{"employees"},
+				alias: "e",
+				joins: []join{{clause: `"employees" AS "m" ON "m"."id" = "e"."manager_id"`}},
+				where: []where{{clause: `"m"."name" = ?`, args: []interface{}{"Ana"}}},
+			},
+			`SELECT "e".* FROM "employees" AS "e" INNER JOIN "employees" AS "m" ON "m"."id" = "e"."manager_id" WHERE ("m"."name" = $1);`,
+		},
+		{
+			&Query{from: []string{"employees"}, alias: "e", delete: true, where: []where{{clause: `"e"."id" = ?`, args: []interface{}{1}}}},
+			`DELETE FROM "employees" AS "e" WHERE ("e"."id" = $1);`,
+		},
+	}
+
+	for i, test := range tests {
+		test.q.dialect = dia
+		got, _ := buildQuery(test.q)
+		if got != test.want {
+			t.Errorf("[%d] wrong query:\nwant: %s\ngot:  %s", i, test.want, got)
+		}
+	}
+
+	q := &Query{dialect: dia, from: []string{"employees"}}
+	if got := TableRef(q, `"employees"`); got != `"employees"` {
+		t.Errorf("got table ref %s without alias", got)
+	}
+	SetAlias(q, "e")
+	if got := TableRef(q, `"employees"`); got != `"e"` {
+		t.Errorf("got table ref %s with alias", got)
+	}
+}
+
+func TestBuildDefaultOrderBy(t *testing.T) {
+	t.Parallel()
+
+	dia := &Dialect{LQ: '"', RQ: '"', IndexPlaceholders: true}
+
+	tests := []struct {
+		q    *Query
+		want string
+	}{
+		{
+			&Query{from: []string{"posts"}, defOrderBy: "created_at DESC, id DESC"},
+			`SELECT * FROM "posts" ORDER BY created_at DESC, id DESC;`,
+		},
+		{
+			&Query{from: []string{"posts"}, orderBy: []string{"title"}, defOrderBy: "created_at DESC, id DESC"},
+			`SELECT * FROM "posts" ORDER BY title;`,
+		},
+		{
+			&Query{from: []string{"posts"}, count: true, defOrderBy: "created_at DESC, id DESC"},
+			`SELECT COUNT(*) FROM "posts";`,
+		},
+		{
+			&Query{from: []string{"posts"}, selectCols: []string{"author_id"}, groupBy: []string{"author_id"}, defOrderBy: "created_at DESC, id DESC"},
+			`SELECT "author_id" FROM "posts" GROUP BY author_id;`,
+		},
+	}
+
+	for i, test := range tests {
+		test.q.dialect = dia
+		got, _ := buildQuery(test.q)
+		if got != test.want {
+			t.Errorf("[%d] wrong query:\nwant: %s\ngot:  %s", i, test.want, got)
+		}
+	}
+}
+
+func TestBuildLimitPerQuery(t *testing.T) {
+	t.Parallel()
+
+	dia := &Dialect{LQ: '"', RQ: '"', IndexPlaceholders: true}
+
+	tests := []struct {
+		q    *Query
+		want string
+	}{
+		{
+			&Query{from: []string{"comments AS f"}, limitPer: []string{`"f"."post_id"`}},
+			`SELECT * FROM comments AS f;`,
+		},
+		{
+			&Query{
+				from:       []string{"comments AS f"},
+				selectCols: []string{"f.*"},
+				where:      []where{{clause: "deleted = ?", args: []interface{}{false}}},
+				orderBy:    []string{"created_at DESC"},
+				limit:      10,
+				limitPer:   []string{`"f"."post_id"`},
+			},
+			`SELECT * FROM (SELECT "f".*, ROW_NUMBER() OVER (PARTITION BY "f"."post_id" ORDER BY created_at DESC) AS "bunny_rn" FROM comments AS f WHERE (deleted = $1)) AS "limited" WHERE "bunny_rn" > 0 AND "bunny_rn" <= 10 ORDER BY "bunny_rn";`,
+		},
+		{
+			&Query{from: []string{"comments AS f"}, offset: 5, limitPer: []string{`"f"."post_id"`}},
+			`SELECT * FROM (SELECT *, ROW_NUMBER() OVER (PARTITION BY "f"."post_id") AS "bunny_rn" FROM comments AS f) AS "limited" WHERE "bunny_rn" > 5 ORDER BY "bunny_rn";`,
+		},
+	}
+
+	for i, test := range tests {
+		test.q.dialect = dia
+		got, _ := buildQuery(test.q)
+		if got != test.want {
+			t.Errorf("[%d] wrong query:\nwant: %s\ngot:  %s", i, test.want, got)
+		}
+	}
+}
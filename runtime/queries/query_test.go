@@ -1,8 +1,11 @@
 package queries
 
 import (
+	"context"
+	"errors"
 	"reflect"
 	"testing"
+	"time"
 )
 
 func TestSetLimit(t *testing.T) {
@@ -29,6 +32,92 @@ func TestSetOffset(t *testing.T) {
 	}
 }
 
+func TestSetTimeout(t *testing.T) {
+	t.Parallel()
+
+	q := &Query{}
+	ctx, cancel := q.timeoutContext(context.Background())
+	cancel()
+	if _, ok := ctx.Deadline(); ok {
+		t.Error("Expected no deadline without a timeout")
+	}
+
+	SetTimeout(q, time.Minute)
+	ctx, cancel = q.timeoutContext(context.Background())
+	defer cancel()
+	if _, ok := ctx.Deadline(); !ok {
+		t.Error("Expected a deadline")
+	}
+}
+
+type orgKey struct{}
+
+func TestSetContextMods(t *testing.T) {
+	t.Parallel()
+
+	q := &Query{
+		from:    []string{"thing"},
+		dialect: &Dialect{LQ: '"', RQ: '"', IndexPlaceholders: true},
+	}
+	AppendWhere(q, "a = ?", 1)
+	SetContextMods(q, []func(ctx context.Context, q *Query){
+		func(ctx context.Context, q *Query) {
+			AppendWhere(q, "org_id = ?", ctx.Value(orgKey{}))
+		},
+	})
+
+	for _, org := range []int{5, 7} {
+		q2 := q.withContextMods(context.WithValue(context.Background(), orgKey{}, org))
+		sql, args := buildQuery(q2)
+		if expect := `SELECT * FROM "thing" WHERE (a = $1) AND (org_id = $2);`; sql != expect {
+			t.Errorf("Expected %s, got %s", expect, sql)
+		}
+		if !reflect.DeepEqual(args, []interface{}{1, org}) {
+			t.Errorf("Invalid args, got %#v", args)
+		}
+	}
+	if len(q.where) != 1 || q.rawSQL.sql != "" {
+		t.Errorf("Expected the query to be left as is, got %#v", q)
+	}
+}
+
+func TestContextWhere(t *testing.T) {
+	t.Parallel()
+
+	dialect := &Dialect{LQ: '"', RQ: '"', IndexPlaceholders: true}
+	mods := []func(ctx context.Context, q *Query){
+		func(ctx context.Context, q *Query) {
+			AppendWhere(q, "org_id = ?", ctx.Value(orgKey{}))
+		},
+		func(ctx context.Context, q *Query) {
+			AppendWhere(q, "deleted = ?", false)
+		},
+	}
+
+	ctx := context.WithValue(context.Background(), orgKey{}, 5)
+	clause, args, err := ContextWhere(ctx, dialect, mods, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expect := " AND (org_id = $3) AND (deleted = $4)"; clause != expect {
+		t.Errorf("Expected %s, got %s", expect, clause)
+	}
+	if !reflect.DeepEqual(args, []interface{}{5, false}) {
+		t.Errorf("Invalid args, got %#v", args)
+	}
+
+	if clause, args, err := ContextWhere(ctx, dialect, nil, 1); clause != "" || args != nil || err != nil {
+		t.Errorf("Expected nothing without mods, got %q, %#v, %v", clause, args, err)
+	}
+
+	join := func(ctx context.Context, q *Query) {
+		AppendInnerJoin(q, "org on org.id = thing.org_id")
+	}
+	if _, _, err := ContextWhere(ctx, dialect, append(mods, join), 1); err == nil {
+		t.Error("Expected an error for a join")
+	}
+}
+
 func TestSetSQL(t *testing.T) {
 	t.Parallel()
 
@@ -344,3 +433,24 @@ func TestAppendInnerJoin(t *testing.T) {
 		t.Errorf("Got invalid innerJoin on string: %#v", q.joins)
 	}
 }
+
+func TestSetError(t *testing.T) {
+	errUnknown := errors.New("unknown relationship")
+	q := &Query{}
+	SetError(q, errUnknown)
+	SetError(q, errors.New("other"))
+
+	ctx := context.Background()
+	if _, err := q.Query(ctx); err != errUnknown {
+		t.Errorf("Query: expected %v, got %v", errUnknown, err)
+	}
+	if _, err := q.Exec(ctx); err != errUnknown {
+		t.Errorf("Exec: expected %v, got %v", errUnknown, err)
+	}
+	if err := q.ScanRow(ctx); err != errUnknown {
+		t.Errorf("ScanRow: expected %v, got %v", errUnknown, err)
+	}
+	if err := q.QueryRow(ctx).Scan(); err != errUnknown {
+		t.Errorf("QueryRow: expected %v, got %v", errUnknown, err)
+	}
+}
@@ -24,6 +24,14 @@ var (
 type MappedField struct {
 	Path        uint64
 	ParentValid *MappedField
+	// Binary fields are written with BinaryValue.
+	Binary bool
+	// Encrypted fields are written with bunny.Encrypted, and decrypted
+	// when they're read.
+	Encrypted     bool
+	Deterministic bool
+	// PII fields are written with bunny.Redact.
+	PII bool
 }
 
 // Identifies what kind of object we're binding to
@@ -53,6 +61,10 @@ const (
 //     of the inner fields.
 //   - If the ",null:valid_column_name" option is specified in addition to ",bind", the SQL boolean column
 //     "valid_column_name" is used to tell whether the nested struct is valid (not null) or not (null).
+//   - The ",binary" option writes the field with BinaryValue, for columns storing its bytes.
+//   - The ",encrypted" option encrypts the field in its bytea column, see bunny.Encrypted,
+//     with deterministic encryption if the ",deterministic" option is specified too.
+//   - The ",pii" option writes the field with bunny.Redact, so it's redacted in the query logs.
 func Bind(rows *sql.Rows, obj interface{}) error {
 	structType, sliceType, singular, err := bindChecks(obj)
 	if err != nil {
@@ -72,22 +84,128 @@ func (q *Query) Bind(ctx context.Context, obj interface{}) error {
 		return err
 	}
 
-	rows, err := q.Query(ctx)
-	if err != nil {
-		return errors.Errorf("bind failed to execute query: %w", err)
+	return q.bindWith(ctx, obj, bkind, func(rows *sql.Rows) error {
+		return bind(rows, obj, structType, sliceType, bkind)
+	})
+}
+
+// bindWith runs the query, binds its rows with bindRows and eager loads the
+// relationships of obj.
+func (q *Query) bindWith(ctx context.Context, obj interface{}, bkind bindKind, bindRows func(rows *sql.Rows) error) error {
+	ctx = q.shardContext(ctx)
+	q = q.withContextMods(ctx)
+	return bunny.AtomicTenant(ctx, func(ctx context.Context) error {
+		if err := q.queryRows(ctx, bindRows); err != nil {
+			return err
+		}
+
+		if len(q.load) != 0 {
+			return eagerLoad(ctx, q.load, q.loadMods, obj, bkind)
+		}
+
+		return nil
+	})
+}
+
+// BindEach executes the query and calls fn for each returned row, bound into
+// a new value of typ, which must be a pointer to a struct type.
+//
+// Rows are scanned one at a time as fn is called, instead of loading the whole
+// result set in memory like Bind does. If fn returns an error, iteration stops
+// and the error is returned. Eager loading is not supported.
+func (q *Query) BindEach(ctx context.Context, typ reflect.Type, fn func(obj interface{}) error) error {
+	if typ.Kind() != reflect.Ptr || typ.Elem().Kind() != reflect.Struct {
+		return errors.Errorf("type should be *Type but was %q", typ.String())
 	}
-	defer rows.Close()
-	if res := bind(rows, obj, structType, sliceType, bkind); res != nil {
-		return res
+	if len(q.load) != 0 {
+		return errors.New("eager loading is not supported by BindEach")
 	}
+	structType := typ.Elem()
 
-	if len(q.load) != 0 {
-		return eagerLoad(ctx, q.load, obj, bkind)
+	ctx = q.shardContext(ctx)
+	q = q.withContextMods(ctx)
+	return bunny.AtomicTenant(ctx, func(ctx context.Context) error {
+		return q.bindEach(ctx, structType, fn)
+	})
+}
+
+// BindShards executes the query in every shard with bunny.FanOut, and
+// appends the rows of all of them to obj, which must be a pointer to a
+// slice. The shard key of the query is ignored, and its ordering, limit and
+// offset apply to the rows of each shard.
+func (q *Query) BindShards(ctx context.Context, obj interface{}) error {
+	ptr := reflect.ValueOf(obj)
+	if ptr.Kind() != reflect.Ptr || ptr.Elem().Kind() != reflect.Slice {
+		return errors.Errorf("obj type should be *[]Type or *[]*Type but was %q", reflect.TypeOf(obj).String())
 	}
 
+	q2 := *q
+	q2.shardKey = nil
+	// Build the query once, since the shards run it concurrently.
+	buildQuery(&q2)
+
+	var mu sync.Mutex
+	return bunny.FanOut(ctx, func(ctx context.Context) error {
+		res := reflect.New(ptr.Elem().Type())
+		if err := q2.Bind(ctx, res.Interface()); err != nil {
+			return err
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		ptr.Elem().Set(reflect.AppendSlice(ptr.Elem(), res.Elem()))
+		return nil
+	})
+}
+
+func (q *Query) bindEach(ctx context.Context, structType reflect.Type, fn func(obj interface{}) error) error {
+	return q.queryRows(ctx, func(rows *sql.Rows) error {
+		cols, err := rows.Columns()
+		if err != nil {
+			return errors.Errorf("bind failed to get field names: %w", err)
+		}
+		mapping, err := cachedBindMapping(structType, cols)
+		if err != nil {
+			return err
+		}
+
+		for rows.Next() {
+			obj := reflect.New(structType)
+			if err := rows.Scan(PtrsFromMapping(reflect.Indirect(obj), mapping)...); err != nil {
+				return errors.Errorf("failed to bind pointers to obj: %w", err)
+			}
+			if err := fn(obj.Interface()); err != nil {
+				return err
+			}
+		}
+
+		return rows.Err()
+	})
+}
+
+// queryRows runs the query and binds its rows with bindRows, within the
+// query timeout, once per chunk if it's chunked. Eager loads are separate
+// queries, each with their own timeout.
+func (q *Query) queryRows(ctx context.Context, bindRows func(rows *sql.Rows) error) error {
+	ctx, cancel := q.timeoutContext(ctx)
+	defer cancel()
+
+	for _, chunk := range q.chunks() {
+		if err := chunk.queryChunk(ctx, bindRows); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
+func (q *Query) queryChunk(ctx context.Context, bindRows func(rows *sql.Rows) error) error {
+	rows, err := q.Query(ctx)
+	if err != nil {
+		return errors.Errorf("bind failed to execute query: %w", err)
+	}
+	defer rows.Close()
+	return bindRows(rows)
+}
+
 // bindChecks resolves information about the bind target, and errors if it's not an object
 // we can bind to.
 func bindChecks(obj interface{}) (structType reflect.Type, sliceType reflect.Type, bkind bindKind, err error) {
@@ -155,35 +273,9 @@ func bind(rows *sql.Rows, obj interface{}, structType, sliceType reflect.Type, b
 		ptrSlice = reflect.Indirect(reflect.ValueOf(obj))
 	}
 
-	var strMapping map[string]MappedField
-	var sok bool
-	var mapping []MappedField
-	var ok bool
-
-	typStr := structType.String()
-
-	mapKey := makeCacheKey(typStr, cols)
-	mut.RLock()
-	mapping, ok = bindingMaps[mapKey]
-	if !ok {
-		if strMapping, sok = structMaps[typStr]; !sok {
-			strMapping = MakeStructMapping(structType)
-		}
-	}
-	mut.RUnlock()
-
-	if !ok {
-		mapping, err = BindMapping(structType, strMapping, cols)
-		if err != nil {
-			return err
-		}
-
-		mut.Lock()
-		if !sok {
-			structMaps[typStr] = strMapping
-		}
-		bindingMaps[mapKey] = mapping
-		mut.Unlock()
+	mapping, err := cachedBindMapping(structType, cols)
+	if err != nil {
+		return err
 	}
 
 	var oneStruct reflect.Value
@@ -233,6 +325,44 @@ func bind(rows *sql.Rows, obj interface{}, structType, sliceType reflect.Type, b
 	return nil
 }
 
+// cachedBindMapping returns the mapping of cols to the fields of structType,
+// caching it for subsequent queries returning the same columns.
+func cachedBindMapping(structType reflect.Type, cols []string) ([]MappedField, error) {
+	var strMapping map[string]MappedField
+	var sok bool
+	var mapping []MappedField
+	var ok bool
+	var err error
+
+	typStr := structType.String()
+
+	mapKey := makeCacheKey(typStr, cols)
+	mut.RLock()
+	mapping, ok = bindingMaps[mapKey]
+	if !ok {
+		if strMapping, sok = structMaps[typStr]; !sok {
+			strMapping = MakeStructMapping(structType)
+		}
+	}
+	mut.RUnlock()
+
+	if !ok {
+		mapping, err = BindMapping(structType, strMapping, cols)
+		if err != nil {
+			return nil, err
+		}
+
+		mut.Lock()
+		if !sok {
+			structMaps[typStr] = strMapping
+		}
+		bindingMaps[mapKey] = mapping
+		mut.Unlock()
+	}
+
+	return mapping, nil
+}
+
 // BindMapping creates a mapping that helps look up the pointer for the
 // field given.
 func BindMapping(typ reflect.Type, mapping map[string]MappedField, cols []string) ([]MappedField, error) {
@@ -266,6 +396,9 @@ func PtrsFromMapping(val reflect.Value, mapping []MappedField) []interface{} {
 	ptrs := make([]interface{}, len(mapping))
 	for i, m := range mapping {
 		ptrs[i] = ptrFromMapping(val, m, true)
+		if m.Encrypted {
+			ptrs[i] = &decryptScan{dest: ptrs[i]}
+		}
 	}
 	return ptrs
 }
@@ -276,10 +409,41 @@ func ValuesFromMapping(val reflect.Value, mapping []MappedField) []interface{} {
 	ptrs := make([]interface{}, len(mapping))
 	for i, m := range mapping {
 		ptrs[i] = ptrFromMapping(val, m, false)
+		if m.Binary {
+			ptrs[i] = BinaryValue(ptrs[i])
+		}
+		if m.Encrypted {
+			ptrs[i] = bunny.Encrypted{Plaintext: ptrs[i], Deterministic: m.Deterministic}
+		}
+		if m.PII {
+			ptrs[i] = bunny.Redact(ptrs[i])
+		}
 	}
 	return ptrs
 }
 
+// BinaryValue returns v as written to the columns storing it as its bytes,
+// like UUIDs stored in bytea columns: the result of its Bytes method, or nil
+// if v is a null type which is not valid. The generated code converts the
+// values of these fields, other query arguments need to be converted with
+// BinaryValue.
+func BinaryValue(v interface{}) interface{} {
+	if b, ok := v.(interface{ Bytes() []byte }); ok {
+		return b.Bytes()
+	}
+	// Null types are a struct with the value and a Valid flag.
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Struct && rv.NumField() == 2 {
+		if valid := rv.FieldByName("Valid"); valid.IsValid() && valid.Kind() == reflect.Bool {
+			if !valid.Bool() {
+				return nil
+			}
+			return BinaryValue(rv.Field(0).Interface())
+		}
+	}
+	return v
+}
+
 type ignoreNullScan struct {
 	dest interface{}
 }
@@ -292,6 +456,23 @@ func (v *ignoreNullScan) Scan(value interface{}) error {
 	return convert.Assign(v.dest, value)
 }
 
+// decryptScan scans the values of encrypted columns into dest.
+type decryptScan struct {
+	dest interface{}
+}
+
+// Scan implements the Scanner interface.
+func (v *decryptScan) Scan(value interface{}) error {
+	if b, ok := value.([]byte); ok {
+		plaintext, err := bunny.Decrypt(b)
+		if err != nil {
+			return err
+		}
+		value = plaintext
+	}
+	return convert.Assign(v.dest, value)
+}
+
 // ptrFromMapping expects to be passed an addressable struct that it's looking
 // for things on.
 func ptrFromMapping(val reflect.Value, mapping MappedField, addressOf bool) interface{} {
@@ -336,7 +517,7 @@ func ptrFromMapping(val reflect.Value, mapping MappedField, addressOf bool) inte
 			val = reflect.Indirect(val)
 			if !val.IsValid() {
 				var nothing interface{}
-				return reflect.ValueOf(&nothing)
+				return &nothing
 			}
 		}
 	}
@@ -403,17 +584,25 @@ func makeStructMappingHelper(typ reflect.Type, prefix string, current MappedFiel
 		}
 
 		fieldMaps[name] = MappedField{
-			Path:        current.Path | uint64(i+1)<<depth,
-			ParentValid: current.ParentValid,
+			Path:          current.Path | uint64(i+1)<<depth,
+			ParentValid:   current.ParentValid,
+			Binary:        tag.binary,
+			Encrypted:     tag.encrypted,
+			Deterministic: tag.deterministic,
+			PII:           tag.pii,
 		}
 	}
 }
 
 type bunnyTag struct {
-	present bool
-	name    string
-	bind    bool
-	null    string
+	present       bool
+	name          string
+	bind          bool
+	null          string
+	binary        bool
+	encrypted     bool
+	deterministic bool
+	pii           bool
 }
 
 func getBunnyTag(field reflect.StructField) (bunnyTag, error) {
@@ -434,6 +623,14 @@ func getBunnyTag(field reflect.StructField) (bunnyTag, error) {
 	for _, flag := range parts[1:] {
 		if flag == "bind" {
 			res.bind = true
+		} else if flag == "binary" {
+			res.binary = true
+		} else if flag == "encrypted" {
+			res.encrypted = true
+		} else if flag == "deterministic" {
+			res.deterministic = true
+		} else if flag == "pii" {
+			res.pii = true
 		} else if strings.HasPrefix(flag, "null:") {
 			res.null = strings.TrimPrefix(flag, "null:")
 		} else {
@@ -444,6 +641,9 @@ func getBunnyTag(field reflect.StructField) (bunnyTag, error) {
 	if len(res.null) != 0 && !res.bind {
 		return bunnyTag{}, fmt.Errorf("Invalid flags in bunny tag in field '%s': null requires bind to be set", field.Name)
 	}
+	if res.deterministic && !res.encrypted {
+		return bunnyTag{}, fmt.Errorf("Invalid flags in bunny tag in field '%s': deterministic requires encrypted to be set", field.Name)
+	}
 
 	return res, nil
 }
@@ -8,6 +8,7 @@ import (
 	"reflect"
 	"testing"
 
+	"github.com/sqlbunny/errors"
 	"github.com/sqlbunny/sqlbunny/runtime/bunny"
 	"gopkg.in/DATA-DOG/go-sqlmock.v2"
 )
@@ -349,6 +350,121 @@ func TestValuesFromMapping(t *testing.T) {
 	}
 }
 
+type binaryID [2]byte
+
+func (b binaryID) Bytes() []byte {
+	return b[:]
+}
+
+type nullBinaryID struct {
+	ID    binaryID
+	Valid bool
+}
+
+func TestValuesFromMappingBinary(t *testing.T) {
+	t.Parallel()
+
+	type thing struct {
+		ID     binaryID     `bunny:"id,binary"`
+		Parent nullBinaryID `bunny:"parent,binary"`
+		Prev   nullBinaryID `bunny:"prev,binary"`
+	}
+
+	val := reflect.ValueOf(thing{ID: binaryID{1, 2}, Parent: nullBinaryID{binaryID{3, 4}, true}})
+	mapping, err := BindMapping(val.Type(), MakeStructMapping(val.Type()), []string{"id", "parent", "prev"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := ValuesFromMapping(val, mapping)
+	want := []interface{}{[]byte{1, 2}, []byte{3, 4}, nil}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %#v, got %#v", want, got)
+	}
+}
+
+func TestValuesFromMappingPII(t *testing.T) {
+	t.Parallel()
+
+	type thing struct {
+		ID    int    `bunny:"id"`
+		Email string `bunny:"email,pii"`
+	}
+
+	val := reflect.ValueOf(thing{ID: 1, Email: "pat@example.com"})
+	mapping, err := BindMapping(val.Type(), MakeStructMapping(val.Type()), []string{"id", "email"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := ValuesFromMapping(val, mapping)
+	if s := fmt.Sprint(got); s != "[1 "+bunny.RedactedText+"]" {
+		t.Errorf("expected the email to be redacted, got %s", s)
+	}
+	if v, err := got[1].(driver.Valuer).Value(); v != "pat@example.com" || err != nil {
+		t.Errorf("expected the email to be written, got %#v, %v", v, err)
+	}
+}
+
+func TestBindEncrypted(t *testing.T) {
+	bunny.SetKeyProvider(bunny.LocalKeys{Current: "k1", Keys: map[string][]byte{"k1": make([]byte, 32)}})
+	defer bunny.SetKeyProvider(nil)
+
+	type thing struct {
+		ID    int            `bunny:"id"`
+		Email string         `bunny:"email,encrypted,deterministic"`
+		Note  sql.NullString `bunny:"note,encrypted"`
+		Prev  sql.NullString `bunny:"prev,encrypted"`
+	}
+
+	val := reflect.ValueOf(thing{ID: 1, Email: "pat@example.com", Note: sql.NullString{String: "secret", Valid: true}})
+	columns := []string{"id", "email", "note", "prev"}
+	mapping, err := BindMapping(val.Type(), MakeStructMapping(val.Type()), columns)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var values []driver.Value
+	for _, v := range ValuesFromMapping(val, mapping) {
+		if e, ok := v.(bunny.Encrypted); ok {
+			if v, err = e.Value(); err != nil {
+				t.Fatal(err)
+			}
+		}
+		values = append(values, v)
+	}
+	if b, ok := values[1].([]byte); !ok || string(b) == "pat@example.com" {
+		t.Errorf("expected the email to be encrypted, got %#v", values[1])
+	}
+	if values[3] != nil {
+		t.Errorf("expected the null prev to stay null, got %#v", values[3])
+	}
+	lookup, err := bunny.Encrypted{Plaintext: "pat@example.com", Deterministic: true}.Value()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(lookup, values[1]) {
+		t.Error("expected deterministic encryption to encrypt equal values to equal bytes")
+	}
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	mock.ExpectQuery(`SELECT \* FROM "thing";`).WillReturnRows(sqlmock.NewRows(columns).AddRow(values...))
+
+	var got thing
+	query := &Query{
+		from:    []string{"thing"},
+		dialect: &Dialect{LQ: '"', RQ: '"', IndexPlaceholders: true},
+	}
+	if err := query.Bind(dbToContext(db), &got); err != nil {
+		t.Fatal(err)
+	}
+	if want := val.Interface().(thing); got != want {
+		t.Errorf("expected %#v, got %#v", want, got)
+	}
+}
+
 func TestPtrsFromMapping(t *testing.T) {
 	t.Parallel()
 
@@ -400,6 +516,7 @@ func TestGetBunnyTag(t *testing.T) {
 		Age         string `bunny:"age,null:agenull"`
 		Nose        string
 		Fail        string `bunny:"fail,invalidflag"`
+		ID          string `bunny:"id,binary"`
 	}
 
 	var structFields []reflect.StructField
@@ -417,6 +534,7 @@ func TestGetBunnyTag(t *testing.T) {
 	structFields = append(structFields, removeOk(typ.FieldByName("Age")))
 	structFields = append(structFields, removeOk(typ.FieldByName("Nose")))
 	structFields = append(structFields, removeOk(typ.FieldByName("Fail")))
+	structFields = append(structFields, removeOk(typ.FieldByName("ID")))
 
 	expect := []*bunnyTag{
 		{present: true, name: "test_one", bind: true},
@@ -426,6 +544,7 @@ func TestGetBunnyTag(t *testing.T) {
 		nil,
 		{present: false},
 		nil,
+		{present: true, name: "id", binary: true},
 	}
 	for i, s := range structFields {
 		tag, err := getBunnyTag(s)
@@ -793,3 +912,220 @@ func TestBind_NullBind(t *testing.T) {
 		t.Error(err)
 	}
 }
+
+func TestBind_Raw(t *testing.T) {
+	t.Parallel()
+
+	type address struct {
+		City string `bunny:"city"`
+	}
+	testResults := []*struct {
+		ID      int    `bunny:"id"`
+		Name    string `bunny:"name"`
+		Address struct {
+			Address address
+			Valid   bool
+		} `bunny:"address__,bind,null:address"`
+	}{}
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Error(err)
+	}
+
+	ret := sqlmock.NewRows([]string{"id", "name", "address", "address__city", "book_count"})
+	ret.AddRow(driver.Value(int64(1)), driver.Value("a"), driver.Value(true), driver.Value("x"), driver.Value(int64(3)))
+	ret.AddRow(driver.Value(int64(2)), driver.Value("b"), driver.Value(false), driver.Value(nil), driver.Value(int64(0)))
+	mock.ExpectQuery(`SELECT u\.\*, count\(b\.id\) AS book_count FROM "user" u`).WithArgs(1).WillReturnRows(ret)
+
+	ctx := dbToContext(db)
+	err = Raw(`SELECT u.*, count(b.id) AS book_count FROM "user" u LEFT JOIN book b ON b.author_id = u.id WHERE b.status = $1 GROUP BY u.id`, 1).Bind(ctx, &testResults)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(testResults) != 2 {
+		t.Fatal("wrong number of results:", len(testResults))
+	}
+	if r := testResults[0]; r.ID != 1 || r.Name != "a" || !r.Address.Valid || r.Address.Address.City != "x" {
+		t.Errorf("wrong result: %+v", r)
+	}
+	if r := testResults[1]; r.ID != 2 || r.Name != "b" || r.Address.Valid {
+		t.Errorf("wrong result: %+v", r)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestBindEach(t *testing.T) {
+	t.Parallel()
+
+	type row struct {
+		ID int `bunny:"id"`
+	}
+
+	query := &Query{
+		dialect: &Dialect{LQ: '"', RQ: '"', IndexPlaceholders: true},
+		from:    []string{"fun"},
+	}
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Error(err)
+	}
+
+	ret := sqlmock.NewRows([]string{"id"})
+	ret.AddRow(driver.Value(int64(10)))
+	ret.AddRow(driver.Value(int64(11)))
+	ret.AddRow(driver.Value(int64(12)))
+	mock.ExpectQuery(`SELECT \* FROM "fun";`).WillReturnRows(ret)
+
+	ctx := dbToContext(db)
+	var got []*row
+	errStop := fmt.Errorf("stop")
+	err = query.BindEach(ctx, reflect.TypeOf(&row{}), func(obj interface{}) error {
+		got = append(got, obj.(*row))
+		if len(got) == 2 {
+			return errStop
+		}
+		return nil
+	})
+	if err != errStop {
+		t.Errorf("expected stop error, got %v", err)
+	}
+
+	if len(got) != 2 || got[0].ID != 10 || got[1].ID != 11 {
+		t.Errorf("wrong results: %+v %+v", got[0], got[1])
+	}
+	if got[0] == got[1] {
+		t.Error("rows should be bound into distinct objects")
+	}
+}
+
+func TestBindShards(t *testing.T) {
+	t.Parallel()
+
+	type row struct {
+		ID int `bunny:"id"`
+	}
+
+	query := &Query{
+		dialect:  &Dialect{LQ: '"', RQ: '"', IndexPlaceholders: true},
+		from:     []string{"fun"},
+		sharded:  true,
+		shardKey: 1,
+	}
+
+	var dbs []bunny.DB
+	for i := 0; i < 2; i++ {
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatal(err)
+		}
+		mock.ExpectQuery(`SELECT \* FROM "fun";`).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(driver.Value(int64(i))))
+		dbs = append(dbs, db)
+	}
+	ctx := bunny.ContextWithShards(context.Background(), &bunny.Shards{DBs: dbs})
+
+	var got []*row
+	if err := query.BindShards(ctx, &got); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[0].ID+got[1].ID != 1 {
+		t.Errorf("expected the rows of both shards, got %d rows", len(got))
+	}
+
+	query.shardKey = nil
+	if err := query.Bind(ctx, &got); !errors.Is(err, bunny.ErrNoShardKey) {
+		t.Errorf("expected ErrNoShardKey, got %v", err)
+	}
+}
+
+func TestBind_Window(t *testing.T) {
+	t.Parallel()
+
+	type book struct {
+		ID     int    `bunny:"id"`
+		Author string `bunny:"author_id"`
+	}
+	var results []*struct {
+		book `bunny:",bind"`
+		Rank int `bunny:"rank"`
+	}
+
+	query := &Query{
+		from:    []string{"book"},
+		windows: []window{{fn: "row_number()", partitionBy: "author_id", orderBy: "id", alias: "rank"}},
+		dialect: &Dialect{LQ: '"', RQ: '"', IndexPlaceholders: true},
+	}
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ret := sqlmock.NewRows([]string{"id", "author_id", "rank"})
+	ret.AddRow(driver.Value(int64(1)), driver.Value("pat"), driver.Value(int64(1)))
+	ret.AddRow(driver.Value(int64(2)), driver.Value("pat"), driver.Value(int64(2)))
+	mock.ExpectQuery(`SELECT \*, row_number\(\) OVER \(PARTITION BY author_id ORDER BY id\) AS "rank" FROM "book";`).WillReturnRows(ret)
+
+	if err := query.Bind(dbToContext(db), &results); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(results) != 2 {
+		t.Fatalf("expected 2 results, got %d", len(results))
+	}
+	if r := results[1]; r.ID != 2 || r.Author != "pat" || r.Rank != 2 {
+		t.Errorf("wrong result: %+v", *r)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestBind_Aggregate(t *testing.T) {
+	t.Parallel()
+
+	var results []struct {
+		UserID string `bunny:"user_id"`
+		Orders int64  `bunny:"orders"`
+		Total  int64  `bunny:"total"`
+	}
+
+	query := &Query{
+		from:       []string{"orders"},
+		selectCols: []string{"user_id"},
+		aggregates: []aggregate{{fn: "count", col: "*", alias: "orders"}, {fn: "sum", col: "amount", alias: "total"}},
+		groupBy:    []string{"user_id"},
+		dialect:    &Dialect{LQ: '"', RQ: '"', IndexPlaceholders: true},
+	}
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ret := sqlmock.NewRows([]string{"user_id", "orders", "total"})
+	ret.AddRow(driver.Value("pat"), driver.Value(int64(2)), driver.Value(int64(30)))
+	ret.AddRow(driver.Value("sam"), driver.Value(int64(1)), driver.Value(int64(5)))
+	mock.ExpectQuery(`SELECT "user_id", count\(\*\) AS "orders", sum\("amount"\) AS "total" FROM "orders" GROUP BY user_id;`).WillReturnRows(ret)
+
+	if err := query.Bind(dbToContext(db), &results); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(results) != 2 {
+		t.Fatalf("expected 2 results, got %d", len(results))
+	}
+	if r := results[0]; r.UserID != "pat" || r.Orders != 2 || r.Total != 30 {
+		t.Errorf("wrong result: %+v", r)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,143 @@
+package queries
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/sqlbunny/errors"
+	"github.com/sqlbunny/sqlbunny/runtime/bunny"
+)
+
+// Scanner binds the rows of queries into structs of type T without
+// reflection, with the scanRow function generated for each model. It's the
+// counterpart of Bind for the finishers of the generated queries, and binds
+// the same columns: the ones the struct has no field for are discarded.
+type Scanner[T any] struct {
+	columns map[string]int
+	scanRow func(o *T, rows *sql.Rows, cols []int) error
+}
+
+// NewScanner returns a Scanner of the columns of a model. scanRow scans the
+// current row of rows into o, with cols the indexes of the columns of the
+// row in columns, or -1 for the columns to discard.
+func NewScanner[T any](columns []string, scanRow func(o *T, rows *sql.Rows, cols []int) error) *Scanner[T] {
+	s := &Scanner[T]{
+		columns: make(map[string]int, len(columns)),
+		scanRow: scanRow,
+	}
+	for i, c := range columns {
+		s.columns[c] = i
+	}
+	return s
+}
+
+// indexes returns the indexes of the columns of rows in the columns of the
+// scanner.
+func (s *Scanner[T]) indexes(rows *sql.Rows) ([]int, error) {
+	cols, err := rows.Columns()
+	if err != nil {
+		return nil, errors.Errorf("bind failed to get field names: %w", err)
+	}
+	res := make([]int, len(cols))
+	for i, c := range cols {
+		j, ok := s.columns[c]
+		if !ok {
+			j = -1
+		}
+		res[i] = j
+	}
+	return res, nil
+}
+
+// One executes the query and binds its single row into o, like Bind. It
+// returns sql.ErrNoRows if there's no row, and bunny.ErrMultipleRows if
+// there's more than one.
+func (s *Scanner[T]) One(ctx context.Context, q *Query, o *T) error {
+	return q.bindWith(ctx, o, kindStruct, func(rows *sql.Rows) error {
+		cols, err := s.indexes(rows)
+		if err != nil {
+			return err
+		}
+
+		foundOne := false
+		for rows.Next() {
+			if foundOne {
+				return bunny.ErrMultipleRows
+			}
+			foundOne = true
+			if err := s.scanRow(o, rows, cols); err != nil {
+				return errors.Errorf("failed to bind pointers to obj: %w", err)
+			}
+		}
+		if err := rows.Err(); err != nil {
+			return err
+		}
+		if !foundOne {
+			return sql.ErrNoRows
+		}
+		return nil
+	})
+}
+
+// All executes the query and appends its rows to the slice pointed to by o,
+// like Bind.
+func (s *Scanner[T]) All(ctx context.Context, q *Query, o *[]*T) error {
+	return q.bindWith(ctx, o, kindPtrSliceStruct, func(rows *sql.Rows) error {
+		cols, err := s.indexes(rows)
+		if err != nil {
+			return err
+		}
+
+		for rows.Next() {
+			obj := new(T)
+			if err := s.scanRow(obj, rows, cols); err != nil {
+				return errors.Errorf("failed to bind pointers to obj: %w", err)
+			}
+			*o = append(*o, obj)
+		}
+		return rows.Err()
+	})
+}
+
+// Each executes the query and calls fn for each returned row, like BindEach.
+func (s *Scanner[T]) Each(ctx context.Context, q *Query, fn func(o *T) error) error {
+	if len(q.load) != 0 {
+		return errors.New("eager loading is not supported by Each")
+	}
+
+	ctx = q.shardContext(ctx)
+	q = q.withContextMods(ctx)
+	return bunny.AtomicTenant(ctx, func(ctx context.Context) error {
+		return q.queryRows(ctx, func(rows *sql.Rows) error {
+			cols, err := s.indexes(rows)
+			if err != nil {
+				return err
+			}
+
+			for rows.Next() {
+				obj := new(T)
+				if err := s.scanRow(obj, rows, cols); err != nil {
+					return errors.Errorf("failed to bind pointers to obj: %w", err)
+				}
+				if err := fn(obj); err != nil {
+					return err
+				}
+			}
+			return rows.Err()
+		})
+	})
+}
+
+// IgnoreNull returns a scan destination writing to dest, which converts
+// NULLs to the zero value instead of failing. The generated scanRow
+// functions use it for the columns of the fields of nullable structs, like
+// Bind.
+func IgnoreNull(dest interface{}) interface{} {
+	return &ignoreNullScan{dest: dest}
+}
+
+// Decrypted returns a scan destination writing the decrypted values of an
+// encrypted column to dest, see bunny.Encrypted.
+func Decrypted(dest interface{}) interface{} {
+	return &decryptScan{dest: dest}
+}
@@ -0,0 +1,146 @@
+package queries
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/sqlbunny/sqlbunny/runtime/bunny"
+	"gopkg.in/DATA-DOG/go-sqlmock.v2"
+)
+
+type scanThing struct {
+	ID   int
+	Name string
+	Home struct {
+		City  string
+		Valid bool
+	}
+}
+
+func (o *scanThing) scanRow(rows *sql.Rows, cols []int) error {
+	dests := make([]interface{}, len(cols))
+	for i, c := range cols {
+		switch c {
+		case 0:
+			dests[i] = &o.ID
+		case 1:
+			dests[i] = &o.Name
+		case 2:
+			dests[i] = IgnoreNull(&o.Home.City)
+		case 3:
+			dests[i] = &o.Home.Valid
+		default:
+			dests[i] = new(interface{})
+		}
+	}
+	return rows.Scan(dests...)
+}
+
+var scanThingScanner = NewScanner([]string{"id", "name", "home__city", "home"}, (*scanThing).scanRow)
+
+func TestScannerAll(t *testing.T) {
+	t.Parallel()
+
+	query := &Query{
+		from:    []string{"thing"},
+		dialect: &Dialect{LQ: '"', RQ: '"', IndexPlaceholders: true},
+	}
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ret := sqlmock.NewRows([]string{"name", "extra", "id", "home__city", "home"})
+	ret.AddRow("pat", 1, int64(35), "Paris", true)
+	ret.AddRow("sam", 2, int64(12), nil, false)
+	mock.ExpectQuery(`SELECT \* FROM "thing";`).WillReturnRows(ret)
+
+	var res []*scanThing
+	if err := scanThingScanner.All(dbToContext(db), query, &res); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(res) != 2 {
+		t.Fatalf("expected 2 rows, got %d", len(res))
+	}
+	if o := res[0]; o.ID != 35 || o.Name != "pat" || o.Home.City != "Paris" || !o.Home.Valid {
+		t.Errorf("wrong first row: %+v", o)
+	}
+	if o := res[1]; o.ID != 12 || o.Name != "sam" || o.Home.City != "" || o.Home.Valid {
+		t.Errorf("wrong second row: %+v", o)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestScannerOne(t *testing.T) {
+	t.Parallel()
+
+	query := &Query{
+		from:    []string{"thing"},
+		dialect: &Dialect{LQ: '"', RQ: '"', IndexPlaceholders: true},
+	}
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mock.ExpectQuery(`SELECT \* FROM "thing";`).WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(int64(35), "pat"))
+	mock.ExpectQuery(`SELECT \* FROM "thing";`).WillReturnRows(sqlmock.NewRows([]string{"id", "name"}))
+	mock.ExpectQuery(`SELECT \* FROM "thing";`).WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(int64(1), "a").AddRow(int64(2), "b"))
+
+	ctx := dbToContext(db)
+	var o scanThing
+	if err := scanThingScanner.One(ctx, query, &o); err != nil {
+		t.Fatal(err)
+	}
+	if o.ID != 35 || o.Name != "pat" {
+		t.Errorf("wrong row: %+v", o)
+	}
+	if err := scanThingScanner.One(ctx, query, &scanThing{}); err != sql.ErrNoRows {
+		t.Errorf("expected sql.ErrNoRows, got %v", err)
+	}
+	if err := scanThingScanner.One(ctx, query, &scanThing{}); err != bunny.ErrMultipleRows {
+		t.Errorf("expected bunny.ErrMultipleRows, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestScannerEach(t *testing.T) {
+	t.Parallel()
+
+	query := &Query{
+		from:    []string{"thing"},
+		dialect: &Dialect{LQ: '"', RQ: '"', IndexPlaceholders: true},
+	}
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mock.ExpectQuery(`SELECT \* FROM "thing";`).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(int64(1)).AddRow(int64(2)))
+
+	sum := 0
+	err = scanThingScanner.Each(dbToContext(db), query, func(o *scanThing) error {
+		sum += o.ID
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sum != 3 {
+		t.Errorf("expected the rows to sum 3, got %d", sum)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
@@ -1,10 +1,18 @@
 package schema
 
-import "github.com/sqlbunny/sqlbunny/runtime/strmangle"
+import (
+	"fmt"
+	"strings"
+
+	"github.com/sqlbunny/sqlbunny/runtime/strmangle"
+)
 
 type Enum struct {
 	Name    string
 	Choices []string
+	// Text stores the values by name in text columns, restricted to the
+	// choices by a CHECK constraint, instead of by index in integer columns.
+	Text bool
 
 	Extendable
 }
@@ -30,10 +38,33 @@ func (e *Enum) GoTypeNullField() string {
 }
 
 func (e *Enum) SQLType() SQLType {
+	if e.Text {
+		return SQLType{
+			Type:      "text",
+			ZeroValue: sqlString(e.Choices[0]),
+		}
+	}
 	return SQLType{
 		Type:      "integer",
 		ZeroValue: "0",
 	}
 }
 
+// Check returns the expression of the CHECK constraint of the column of a
+// text enum, or "" if the enum is stored as integers.
+func (e *Enum) Check(column string) string {
+	if !e.Text {
+		return ""
+	}
+	choices := make([]string, len(e.Choices))
+	for i, c := range e.Choices {
+		choices[i] = sqlString(c)
+	}
+	return fmt.Sprintf("\"%s\" IN (%s)", column, strings.Join(choices, ", "))
+}
+
+func sqlString(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
 var _ BaseType = &Enum{}
@@ -0,0 +1,160 @@
+package schema
+
+import "sort"
+
+// ExportVersion is the version of the Export format. It changes only when
+// fields are removed or change meaning, new fields can be added.
+const ExportVersion = 1
+
+// Export is the stable JSON form of a schema, for the tools reading the
+// schema without the Go packages. Types and models are sorted by name.
+type Export struct {
+	Version int           `json:"version"`
+	Types   []ExportType  `json:"types"`
+	Models  []ExportModel `json:"models"`
+}
+
+// ExportType is a type of an Export.
+type ExportType struct {
+	Name string `json:"name"`
+	// Kind is "base", "enum", "struct", "timestamp", "uuid" or "id".
+	Kind    string        `json:"kind"`
+	GoType  string        `json:"go_type"`
+	SQLType string        `json:"sql_type,omitempty"`
+	Choices []string      `json:"choices,omitempty"`
+	Fields  []ExportField `json:"fields,omitempty"`
+}
+
+// ExportField is a field of a model or a struct of an Export.
+type ExportField struct {
+	Name     string            `json:"name"`
+	Type     string            `json:"type"`
+	Nullable bool              `json:"nullable"`
+	Tags     map[string]string `json:"tags,omitempty"`
+}
+
+// ExportColumn is a column of the table of a model of an Export.
+type ExportColumn struct {
+	Name     string `json:"name"`
+	SQLType  string `json:"sql_type"`
+	Nullable bool   `json:"nullable"`
+	PII      bool   `json:"pii,omitempty"`
+	// DBDefault is the SQL expression of the default value set by the
+	// database on inserts, if any.
+	DBDefault string `json:"db_default,omitempty"`
+}
+
+// ExportForeignKey is a foreign key of a model of an Export.
+type ExportForeignKey struct {
+	Columns        []string `json:"columns"`
+	ForeignModel   string   `json:"foreign_model"`
+	ForeignColumns []string `json:"foreign_columns"`
+}
+
+// ExportModel is a model of an Export. Keys are lists of column names.
+type ExportModel struct {
+	Name           string             `json:"name"`
+	Fields         []ExportField      `json:"fields"`
+	Columns        []ExportColumn     `json:"columns"`
+	PrimaryKey     []string           `json:"primary_key"`
+	Indexes        [][]string         `json:"indexes,omitempty"`
+	Uniques        [][]string         `json:"uniques,omitempty"`
+	UniqueIndexes  [][]string         `json:"unique_indexes,omitempty"`
+	ForeignKeys    []ExportForeignKey `json:"foreign_keys,omitempty"`
+	DefaultScope   string             `json:"default_scope,omitempty"`
+	DefaultOrderBy string             `json:"default_order_by,omitempty"`
+	ShardKey       string             `json:"shard_key,omitempty"`
+}
+
+// Export returns the stable form of the schema.
+func (s *Schema) Export() *Export {
+	e := &Export{
+		Version: ExportVersion,
+		Types:   []ExportType{},
+		Models:  []ExportModel{},
+	}
+
+	for _, t := range s.Types {
+		et := ExportType{
+			Name:   t.GetName(),
+			Kind:   "base",
+			GoType: t.GoType().String(),
+		}
+		if bt, ok := t.(BaseType); ok {
+			et.SQLType = bt.SQLType().Type
+		}
+		switch t := t.(type) {
+		case *Enum:
+			et.Kind = "enum"
+			et.Choices = t.Choices
+		case *Struct:
+			et.Kind = "struct"
+			et.Fields = exportFields(t.Fields)
+		case *Timestamp:
+			et.Kind = "timestamp"
+		case *UUID:
+			et.Kind = "uuid"
+		case *ID:
+			et.Kind = "id"
+		}
+		e.Types = append(e.Types, et)
+	}
+	sort.Slice(e.Types, func(i, j int) bool {
+		return e.Types[i].Name < e.Types[j].Name
+	})
+
+	for _, m := range s.Models {
+		em := ExportModel{
+			Name:           m.Name,
+			Fields:         exportFields(m.Fields),
+			Columns:        []ExportColumn{},
+			DefaultScope:   m.DefaultScope,
+			DefaultOrderBy: m.DefaultOrderBy,
+			ShardKey:       m.ShardKey.SQLName(),
+		}
+		for _, c := range m.Columns() {
+			em.Columns = append(em.Columns, ExportColumn{Name: c.Name, SQLType: c.SQLType, Nullable: c.Nullable, PII: c.PII, DBDefault: c.DBDefault})
+		}
+		if m.PrimaryKey != nil {
+			em.PrimaryKey = sqlNameAll(m.PrimaryKey.Fields)
+		}
+		for _, i := range m.Indexes {
+			em.Indexes = append(em.Indexes, indexColumns(i))
+		}
+		for _, u := range m.Uniques {
+			if u.Index {
+				em.UniqueIndexes = append(em.UniqueIndexes, sqlNameAll(u.Fields))
+			} else {
+				em.Uniques = append(em.Uniques, sqlNameAll(u.Fields))
+			}
+		}
+		for _, f := range m.ForeignKeys {
+			em.ForeignKeys = append(em.ForeignKeys, ExportForeignKey{
+				Columns:        sqlNameAll(f.LocalFields),
+				ForeignModel:   f.ForeignModel,
+				ForeignColumns: sqlNameAll(f.ForeignFields),
+			})
+		}
+		e.Models = append(e.Models, em)
+	}
+	sort.Slice(e.Models, func(i, j int) bool {
+		return e.Models[i].Name < e.Models[j].Name
+	})
+
+	return e
+}
+
+func exportFields(fields []*Field) []ExportField {
+	res := make([]ExportField, len(fields))
+	for i, f := range fields {
+		res[i] = ExportField{
+			Name:     f.Name,
+			Type:     f.Type.GetName(),
+			Nullable: f.Nullable,
+		}
+		if len(f.Tags) != 0 {
+			res[i].Tags = f.Tags
+		}
+	}
+	return res
+}
@@ -0,0 +1,167 @@
+package schema
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Diff returns the changes of the types and models from e to to, one per
+// line, like "model book: added column cost bigint".
+func (e *Export) Diff(to *Export) []string {
+	var res []string
+
+	types := map[string]ExportType{}
+	for _, t := range e.Types {
+		types[t.Name] = t
+	}
+	newTypes := map[string]bool{}
+	for _, t := range to.Types {
+		newTypes[t.Name] = true
+		old, ok := types[t.Name]
+		if !ok {
+			res = append(res, fmt.Sprintf("added type %s (%s)", t.Name, t.Kind))
+			continue
+		}
+		res = append(res, diffType(old, t)...)
+	}
+	for _, t := range e.Types {
+		if !newTypes[t.Name] {
+			res = append(res, fmt.Sprintf("removed type %s", t.Name))
+		}
+	}
+
+	models := map[string]ExportModel{}
+	for _, m := range e.Models {
+		models[m.Name] = m
+	}
+	newModels := map[string]bool{}
+	for _, m := range to.Models {
+		newModels[m.Name] = true
+		old, ok := models[m.Name]
+		if !ok {
+			res = append(res, fmt.Sprintf("added model %s", m.Name))
+			continue
+		}
+		res = append(res, diffModel(old, m)...)
+	}
+	for _, m := range e.Models {
+		if !newModels[m.Name] {
+			res = append(res, fmt.Sprintf("removed model %s", m.Name))
+		}
+	}
+
+	return res
+}
+
+func diffType(a, b ExportType) []string {
+	var res []string
+	change := func(what, from, to string) {
+		if from != to {
+			res = append(res, fmt.Sprintf("type %s: %s changed from %s to %s", a.Name, what, from, to))
+		}
+	}
+	change("kind", a.Kind, b.Kind)
+	change("Go type", a.GoType, b.GoType)
+	change("SQL type", a.SQLType, b.SQLType)
+	change("choices", strings.Join(a.Choices, ", "), strings.Join(b.Choices, ", "))
+	return res
+}
+
+func describeColumn(c ExportColumn) string {
+	s := c.Name + " " + c.SQLType
+	if c.Nullable {
+		s += " null"
+	}
+	if c.DBDefault != "" {
+		s += " default " + c.DBDefault
+	}
+	return s
+}
+
+func diffModel(a, b ExportModel) []string {
+	var res []string
+	add := func(format string, args ...interface{}) {
+		res = append(res, fmt.Sprintf("model %s: ", a.Name)+fmt.Sprintf(format, args...))
+	}
+
+	columns := map[string]ExportColumn{}
+	for _, c := range a.Columns {
+		columns[c.Name] = c
+	}
+	newColumns := map[string]bool{}
+	for _, c := range b.Columns {
+		newColumns[c.Name] = true
+		old, ok := columns[c.Name]
+		if !ok {
+			add("added column %s", describeColumn(c))
+		} else if old != c {
+			add("changed column %s to %s", describeColumn(old), describeColumn(c))
+		}
+	}
+	for _, c := range a.Columns {
+		if !newColumns[c.Name] {
+			add("removed column %s", c.Name)
+		}
+	}
+
+	if pa, pb := strings.Join(a.PrimaryKey, ", "), strings.Join(b.PrimaryKey, ", "); pa != pb {
+		add("changed primary key from (%s) to (%s)", pa, pb)
+	}
+
+	diffKeys := func(kind string, a, b [][]string) {
+		keys := func(l [][]string) map[string]bool {
+			m := map[string]bool{}
+			for _, k := range l {
+				m[strings.Join(k, ", ")] = true
+			}
+			return m
+		}
+		ka, kb := keys(a), keys(b)
+		for _, k := range b {
+			if desc := strings.Join(k, ", "); !ka[desc] {
+				add("added %s (%s)", kind, desc)
+			}
+		}
+		for _, k := range a {
+			if desc := strings.Join(k, ", "); !kb[desc] {
+				add("removed %s (%s)", kind, desc)
+			}
+		}
+	}
+	diffKeys("index", a.Indexes, b.Indexes)
+	diffKeys("unique", a.Uniques, b.Uniques)
+	diffKeys("unique index", a.UniqueIndexes, b.UniqueIndexes)
+
+	describeFK := func(f ExportForeignKey) string {
+		return fmt.Sprintf("(%s) references %s (%s)", strings.Join(f.Columns, ", "), f.ForeignModel, strings.Join(f.ForeignColumns, ", "))
+	}
+	fks := func(l []ExportForeignKey) map[string]bool {
+		m := map[string]bool{}
+		for _, f := range l {
+			m[describeFK(f)] = true
+		}
+		return m
+	}
+	fa, fb := fks(a.ForeignKeys), fks(b.ForeignKeys)
+	for _, f := range b.ForeignKeys {
+		if desc := describeFK(f); !fa[desc] {
+			add("added foreign key %s", desc)
+		}
+	}
+	for _, f := range a.ForeignKeys {
+		if desc := describeFK(f); !fb[desc] {
+			add("removed foreign key %s", desc)
+		}
+	}
+
+	if a.DefaultScope != b.DefaultScope {
+		add("changed default scope from %q to %q", a.DefaultScope, b.DefaultScope)
+	}
+	if a.DefaultOrderBy != b.DefaultOrderBy {
+		add("changed default order by from %q to %q", a.DefaultOrderBy, b.DefaultOrderBy)
+	}
+	if a.ShardKey != b.ShardKey {
+		add("changed shard key from %q to %q", a.ShardKey, b.ShardKey)
+	}
+	return res
+}
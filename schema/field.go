@@ -1,11 +1,72 @@
 package schema
 
+// Encryption is the encryption at rest of the values of a field.
+type Encryption int
+
+const (
+	// EncryptionNone stores the values in the column of their type.
+	EncryptionNone Encryption = iota
+	// EncryptionRandomized encrypts the values with random nonces in a
+	// bytea column, see bunny.Encrypted.
+	EncryptionRandomized
+	// EncryptionDeterministic encrypts equal values to equal bytes in a
+	// bytea column, so it can be compared for equality.
+	EncryptionDeterministic
+)
+
+// Anonymization is how the values of a column are replaced in the dumps of
+// the "dump" command, for non-production environments.
+type Anonymization int
+
+const (
+	// AnonymizationNone dumps the values as is.
+	AnonymizationNone Anonymization = iota
+	// AnonymizationHash replaces the values by their salted hash, so equal
+	// values, like the ones of foreign keys, stay equal.
+	AnonymizationHash
+	// AnonymizationFake replaces the values by fake values of their type,
+	// derived from their salted hash.
+	AnonymizationFake
+	// AnonymizationNull replaces the values by NULL.
+	AnonymizationNull
+)
+
+// String returns the name of the anonymization in the schema files.
+func (a Anonymization) String() string {
+	switch a {
+	case AnonymizationHash:
+		return "hash"
+	case AnonymizationFake:
+		return "fake"
+	case AnonymizationNull:
+		return "null"
+	}
+	return ""
+}
+
 // Field holds information about a database field.
 // Types are Go types, converted by TranslateFieldType.
 type Field struct {
 	Name     string
 	Type     Type
 	Nullable bool
+	// Encryption encrypts the values of the field at rest.
+	Encryption Encryption
+	// PII marks the values of the field as personally identifiable
+	// information, redacted by the generated String methods and in the
+	// query arguments passed to the logger.
+	PII bool
+	// DBDefault is the SQL expression of the default value of the column,
+	// like "now()", which the database sets instead of the generated inserts.
+	DBDefault string
+	// Immutable fields are written by inserts only, never by the generated
+	// updates.
+	Immutable bool
+	// RenamedFrom is the previous name of the field, whose columns the
+	// migrations rename, see ModelColumn.RenamedFrom.
+	RenamedFrom string
+	// Anonymization replaces the values of the field in dumps.
+	Anonymization Anonymization
 
 	Tags Tags
 
@@ -20,6 +81,17 @@ func (f *Field) GenerateTags() string {
 			if f.Nullable {
 				f.Tags["bunny"] += ",null:" + f.Name
 			}
+		} else if f.IsBinary() {
+			f.Tags["bunny"] += ",binary"
+		}
+		switch f.Encryption {
+		case EncryptionRandomized:
+			f.Tags["bunny"] += ",encrypted"
+		case EncryptionDeterministic:
+			f.Tags["bunny"] += ",encrypted,deterministic"
+		}
+		if f.PII && !f.IsStruct() {
+			f.Tags["bunny"] += ",pii"
 		}
 	}
 	if _, ok := f.Tags["json"]; !ok {
@@ -38,6 +110,13 @@ func (f *Field) IsStruct() bool {
 	return ok
 }
 
+// IsBinary reports whether the values of the field are written as their
+// bytes.
+func (f *Field) IsBinary() bool {
+	t, ok := f.Type.(BinaryType)
+	return ok && t.Binary()
+}
+
 func (f *Field) GoType() GoType {
 	if f.Nullable {
 		return f.Type.(NullableType).GoTypeNull()
@@ -45,6 +124,20 @@ func (f *Field) GoType() GoType {
 	return f.Type.GoType()
 }
 
+// HasPII reports whether any of the fields, or of the fields of their
+// struct types, is PII.
+func HasPII(fields []*Field) bool {
+	for _, f := range fields {
+		if f.PII {
+			return true
+		}
+		if t, ok := f.Type.(*Struct); ok && HasPII(t.Fields) {
+			return true
+		}
+	}
+	return false
+}
+
 // FieldNames of the fields.
 func FieldNames(fields []*Field) []string {
 	names := make([]string, len(fields))
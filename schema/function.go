@@ -0,0 +1,50 @@
+package schema
+
+// Function is a SQL function or procedure created by the migrations, with a
+// generated Go caller.
+type Function struct {
+	Name string
+	Args []*FunctionArg
+	// Returns is the type of the result of the function, or nil if it has
+	// none or returns the rows of ReturnsModel.
+	Returns     BaseType
+	ReturnsNull bool
+	// ReturnsModel is the model of the rows returned by the function, or
+	// nil.
+	ReturnsModel *Model
+	Procedure    bool
+	Language     string
+	Volatility   string
+	Body         string
+	// DependsOn are the models whose tables the function uses.
+	DependsOn []string
+}
+
+// FunctionArg is an argument of a function.
+type FunctionArg struct {
+	Name string
+	Type BaseType
+}
+
+// SQLArgs returns the arguments of the function, "name type" each.
+func (f *Function) SQLArgs() []string {
+	var res []string
+	for _, a := range f.Args {
+		res = append(res, a.Name+" "+a.Type.SQLType().Type)
+	}
+	return res
+}
+
+// SQLReturns returns the result type of the function, or "" if it has none.
+func (f *Function) SQLReturns() string {
+	switch {
+	case f.Procedure:
+		return ""
+	case f.ReturnsModel != nil:
+		return "SETOF \"" + f.ReturnsModel.Name + "\""
+	case f.Returns != nil:
+		return f.Returns.SQLType().Type
+	default:
+		return "void"
+	}
+}
@@ -0,0 +1,63 @@
+package schema
+
+import "github.com/sqlbunny/sqlbunny/runtime/strmangle"
+
+// IDStorage is the column type storing an ID.
+type IDStorage int
+
+const (
+	// IDUUID stores random IDs in uuid columns.
+	IDUUID IDStorage = iota
+	// IDBigint stores IDs in bigint columns.
+	IDBigint
+)
+
+// ID is an ID type stored in the column type of Storage, whose external
+// form is its bytes prefixed with Prefix, like "user_kwy423jvf962v3ghe0xa4np90g".
+// Model is the model whose primary key it is, which declares Prefix.
+type ID struct {
+	Name    string
+	Model   string
+	Prefix  string
+	Storage IDStorage
+
+	Extendable
+}
+
+func (t *ID) GetName() string {
+	return t.Name
+}
+
+func (t *ID) GoType() GoType {
+	return GoType{
+		Name: strmangle.TitleCase(t.Name),
+	}
+}
+
+func (t *ID) GoTypeNull() GoType {
+	return GoType{
+		Pkg:  "github.com/sqlbunny/sqlbunny/types/null",
+		Name: "Val",
+		Args: []GoType{t.GoType()},
+	}
+}
+
+func (t *ID) GoTypeNullField() string {
+	return "Val"
+}
+
+func (t *ID) SQLType() SQLType {
+	if t.Storage == IDBigint {
+		return SQLType{
+			Type:      "bigint",
+			ZeroValue: "0",
+		}
+	}
+	return SQLType{
+		Type:      "uuid",
+		ZeroValue: "'00000000-0000-0000-0000-000000000000'",
+	}
+}
+
+var _ BaseType = &ID{}
+var _ NullableType = &ID{}
@@ -8,11 +8,25 @@ type PrimaryKey struct {
 // Index represents an index in a database
 type Index struct {
 	Fields []Path
+	// Options are the SQL after each field in the index, like its operator
+	// class, or nil if no field has any.
+	Options []string
+	Storage *Storage
+}
+
+// Storage represents the storage of a table or index: its storage
+// parameters, like fillfactor, and its tablespace.
+type Storage struct {
+	Params     map[string]string
+	Tablespace string
 }
 
 // Unique represents a unique constraint in a database
 type Unique struct {
 	Fields []Path
+	// Index is set if the uniqueness is enforced by a unique index instead
+	// of a UNIQUE constraint.
+	Index bool
 }
 
 // ForeignKey represents a foreign key constraint in a database
@@ -21,3 +35,67 @@ type ForeignKey struct {
 	ForeignModel  string
 	ForeignFields []Path
 }
+
+// Trigger represents a trigger on the table of a model, running Function,
+// a call like "set_updated_at()", or the PL/pgSQL function with the Body.
+type Trigger struct {
+	Name         string
+	Timing       string
+	Events       []string
+	UpdateFields []Path
+	Statement    bool
+	When         string
+	Function     string
+	Body         string
+}
+
+// Policy represents a row-level security policy on the table of a model.
+type Policy struct {
+	Name        string
+	Restrictive bool
+	Command     string
+	Roles       []string
+	Using       string
+	WithCheck   string
+}
+
+// Grant represents the privileges of a role on the table of a model.
+type Grant struct {
+	Role       string
+	Privileges []string
+}
+
+// ForeignTable represents a foreign table, whose rows are in a remote table
+// read and written through the foreign data wrapper of Server.
+type ForeignTable struct {
+	Server  string
+	Options map[string]string
+	Columns []*ForeignColumn
+}
+
+// ForeignColumn maps a field of a foreign table to the column RemoteName
+// of the remote table.
+type ForeignColumn struct {
+	Field      Path
+	RemoteName string
+}
+
+// View represents a view, whose rows are the rows of Query. DependsOn are
+// the models it reads.
+//
+// If Table is set, the writes to the view are done on the table of the model
+// Table by INSTEAD OF triggers, with the columns mapped by Columns.
+type View struct {
+	Query       string
+	CheckOption string
+	DependsOn   []string
+	Table       string
+	Columns     []*ViewColumn
+}
+
+// ViewColumn maps a field of a view to the column TableColumn of the table
+// written by its triggers.
+type ViewColumn struct {
+	Field       Path
+	TableColumn string
+}
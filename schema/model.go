@@ -14,6 +14,39 @@ type Model struct {
 
 	IsJoinModel bool
 
+	// DefaultScope is a WHERE clause filtering the rows of the generated
+	// queries, like "deleted_at IS NULL".
+	DefaultScope string
+
+	// DefaultOrderBy is an ORDER BY clause ordering the rows of the
+	// generated queries without one, like "created_at DESC, id DESC".
+	DefaultOrderBy string
+
+	// ShardKey is the field whose value selects the shard storing the rows
+	// of the model, or nil if the model isn't sharded.
+	ShardKey Path
+
+	// Storage is the storage of the table of the model, or nil.
+	Storage *Storage
+
+	Triggers []*Trigger
+
+	// RowLevelSecurity enables the row-level security of the table, with
+	// the Policies. ForceRowLevelSecurity applies them to its owner too.
+	RowLevelSecurity      bool
+	ForceRowLevelSecurity bool
+	Policies              []*Policy
+
+	Grants []*Grant
+
+	// ForeignTable makes the table of the model a foreign table, or is nil.
+	ForeignTable *ForeignTable
+	// View makes the table of the model a view, or is nil.
+	View *View
+	// History is the model of the table recording the row versions of the
+	// model, or nil.
+	History *Model
+
 	Relationships []*Relationship
 
 	Table *schema.Table
@@ -21,6 +54,12 @@ type Model struct {
 	Extendable
 }
 
+// HasTable reports whether the table of the model is a plain table, and not
+// a foreign table or a view.
+func (m *Model) HasTable() bool {
+	return m.ForeignTable == nil && m.View == nil
+}
+
 // FindField by path. Returns nil if not found.
 func (m *Model) FindField(path Path) *Field {
 	if len(path) == 0 {
@@ -80,3 +119,70 @@ func isSubset(a, b []Path) bool {
 	}
 	return true
 }
+
+// ModelColumn is a column of the table of a model.
+type ModelColumn struct {
+	Name     string
+	SQLType  string
+	Nullable bool
+	// PII is set if the column stores PII, of a PII field or of a field of
+	// a PII struct field.
+	PII bool
+	// DBDefault is the DBDefault of the field of the column.
+	DBDefault string
+	// Check is the expression of the CHECK constraint of the column, set
+	// for the columns of text enums.
+	Check string
+	// Immutable is set if the column is of an Immutable field.
+	Immutable bool
+	// RenamedFrom is the previous name of the column, if its field or the
+	// struct field it's in was renamed.
+	RenamedFrom string
+	// Anonymization is the Anonymization of the field of the column.
+	Anonymization Anonymization
+}
+
+// Columns returns the columns of the table of the model, in the order of
+// its fields. Struct fields have a column for each of their fields, and a
+// boolean column if they're nullable.
+func (m *Model) Columns() []ModelColumn {
+	var res []ModelColumn
+	var walk func(f *Field, prefix, oldPrefix Path, forceNullable bool, pii bool, immutable bool)
+	walk = func(f *Field, prefix, oldPrefix Path, forceNullable bool, pii bool, immutable bool) {
+		path := appendPath(prefix, f.Name)
+		oldPath := appendPath(oldPrefix, f.Name)
+		if f.RenamedFrom != "" {
+			oldPath = appendPath(oldPrefix, f.RenamedFrom)
+		}
+		var renamedFrom string
+		if oldPath.SQLName() != path.SQLName() {
+			renamedFrom = oldPath.SQLName()
+		}
+		pii = pii || f.PII
+		immutable = immutable || f.Immutable
+		switch t := f.Type.(type) {
+		case *Struct:
+			for _, f2 := range t.Fields {
+				walk(f2, path, oldPath, forceNullable || f.Nullable, pii, immutable)
+			}
+			if f.Nullable {
+				res = append(res, ModelColumn{Name: path.SQLName(), SQLType: "boolean", Nullable: forceNullable, Immutable: immutable, RenamedFrom: renamedFrom})
+			}
+		case BaseType:
+			sqlType := t.SQLType().Type
+			var check string
+			if e, ok := t.(*Enum); ok {
+				check = e.Check(path.SQLName())
+			}
+			if f.Encryption != EncryptionNone {
+				sqlType = "bytea"
+				check = ""
+			}
+			res = append(res, ModelColumn{Name: path.SQLName(), SQLType: sqlType, Nullable: f.Nullable || forceNullable, PII: pii, DBDefault: f.DBDefault, Check: check, Immutable: immutable, RenamedFrom: renamedFrom, Anonymization: f.Anonymization})
+		}
+	}
+	for _, f := range m.Fields {
+		walk(f, nil, nil, false, false, false)
+	}
+	return res
+}
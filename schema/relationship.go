@@ -1,5 +1,7 @@
 package schema
 
+import "sort"
+
 // Relationship describes a relationship between two models
 type Relationship struct {
 	// Name is the relationship name. It must be unique per model.
@@ -26,3 +28,60 @@ type Relationship struct {
 	ForeignOrderBy string
 	Autogenerated  bool
 }
+
+// DependentRelationships returns the relationships of m to the rows depending
+// on its rows, which must be deleted before them: the rows of the models with
+// a foreign key to m, and the rows of the join models of its relationships
+// through join models, as relationships to the join models. It's computed from
+// the relationships set by CalculateRelationships, sorted by name.
+func (s *Schema) DependentRelationships(m *Model) []*Relationship {
+	var res []*Relationship
+	add := func(r *Relationship) {
+		for _, r2 := range res {
+			if r2.ForeignModel == r.ForeignModel && pathsEqual(r2.ForeignFields, r.ForeignFields) {
+				return
+			}
+		}
+		res = append(res, r)
+	}
+
+	for _, r := range m.Relationships {
+		if r.IsJoinModel {
+			add(&Relationship{
+				Name:          r.Name,
+				ForeignModel:  r.JoinModel,
+				ToMany:        true,
+				LocalFields:   r.LocalFields,
+				ForeignFields: r.JoinLocalFields,
+			})
+			continue
+		}
+		fm := s.Models[r.ForeignModel]
+		if fm == nil {
+			continue
+		}
+		for _, fk := range fm.ForeignKeys {
+			if fk.ForeignModel == m.Name && pathsEqual(fk.LocalFields, r.ForeignFields) && pathsEqual(fk.ForeignFields, r.LocalFields) {
+				add(r)
+				break
+			}
+		}
+	}
+
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Name < res[j].Name
+	})
+	return res
+}
+
+func pathsEqual(a, b []Path) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !a[i].Equals(b[i]) {
+			return false
+		}
+	}
+	return true
+}
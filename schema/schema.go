@@ -11,13 +11,28 @@ type Schema struct {
 	Types  map[string]Type
 	Models map[string]*Model
 
+	// RawSQL are the raw SQL items, in definition order.
+	RawSQL []*RawSQL
+
+	// Functions are the SQL functions and procedures, by name.
+	Functions map[string]*Function
+
 	Extendable
 }
 
+// RawSQL is raw SQL run by migrations, with the SQL undoing it and the
+// models it depends on.
+type RawSQL struct {
+	Up        string
+	Down      string
+	DependsOn []string
+}
+
 func New() *Schema {
 	return &Schema{
-		Types:  make(map[string]Type),
-		Models: make(map[string]*Model),
+		Types:     make(map[string]Type),
+		Models:    make(map[string]*Model),
+		Functions: make(map[string]*Function),
 	}
 }
 
@@ -149,9 +164,10 @@ func pluralIf(s string, plural bool) string {
 func localRelationshipName(f *ForeignKey, m1, m2 *Model) string {
 	if len(f.LocalFields) == 1 {
 		c := strings.Join(f.LocalFields[0], "_")
-		// If the local field is an identifier (like "id", "uuid"...) don't use it as relationship name.
-		if !isIdentifier(c) {
-			c = trimSuffixes(c)
+		// If the local field is an identifier (like "id", "uuid"...) or has
+		// the name of the referenced field don't use it as relationship name.
+		if !isIdentifier(c) && c != referencedField(f) {
+			c = trimKeySuffixes(f, c)
 			return clean(c)
 		}
 	}
@@ -161,11 +177,11 @@ func localRelationshipName(f *ForeignKey, m1, m2 *Model) string {
 func foreignRelationshipName(f *ForeignKey, m1, m2 *Model) string {
 	if len(f.LocalFields) == 1 {
 		c := strings.Join(f.LocalFields[0], "_")
-		c = trimSuffixes(c)
+		c = trimKeySuffixes(f, c)
 		if strings.HasPrefix(m1.Name, m2.Name+"_") {
 			return clean(strings.TrimPrefix(m1.Name, m2.Name+"_"))
 		}
-		if c == m2.Name {
+		if c == m2.Name || c == referencedField(f) {
 			return clean(m1.Name)
 		}
 
@@ -198,6 +214,26 @@ func trimSuffixes(str string) string {
 	return str
 }
 
+// referencedField returns the name of the field referenced by a single
+// field foreign key, or "".
+func referencedField(f *ForeignKey) string {
+	if len(f.ForeignFields) != 1 {
+		return ""
+	}
+	return strings.Join(f.ForeignFields[0], "_")
+}
+
+// trimKeySuffixes trims the identifier suffixes from the name of the local
+// field of a foreign key, and the name of the field it references, like
+// "_email" from "owner_email".
+func trimKeySuffixes(f *ForeignKey, str string) string {
+	str = trimSuffixes(str)
+	if ref := referencedField(f); ref != "" && !isIdentifier(ref) {
+		str = strings.TrimSuffix(str, "_"+ref)
+	}
+	return str
+}
+
 func isIdentifier(str string) bool {
 	for _, s := range identifiers {
 		if s == str {
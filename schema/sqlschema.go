@@ -2,6 +2,8 @@ package schema
 
 import (
 	"fmt"
+	"hash/fnv"
+	"sort"
 	"strings"
 
 	"github.com/sqlbunny/sqlschema/schema"
@@ -22,10 +24,80 @@ func sqlNameAll(paths []Path) []string {
 	return res
 }
 
+// indexColumns returns the columns of the index, each followed by its
+// options, like "name varchar_pattern_ops". The migration dialects split them
+// again where they quote the column names.
+func indexColumns(i *Index) []string {
+	res := sqlNameAll(i.Fields)
+	for j, o := range i.Options {
+		if o != "" {
+			res[j] += " " + o
+		}
+	}
+	return res
+}
+
+// MaxIdentifierLength is the maximum length in bytes of Postgres
+// identifiers. Longer identifiers are truncated by Postgres.
+const MaxIdentifierLength = 63
+
 func makeName(model string, columns []Path, suffix string) string {
 	// Triple underscore because column names can have double underscores
 	// if they belong to a struct.
-	return fmt.Sprintf("%s___%s___%s", model, strings.Join(sqlNameAll(columns), "___"), suffix)
+	return truncateName(fmt.Sprintf("%s___%s___%s", model, strings.Join(sqlNameAll(columns), "___"), suffix))
+}
+
+// truncateName truncates names longer than MaxIdentifierLength, keeping its
+// start and replacing the rest with a hash of the whole name, so names
+// truncated by Postgres don't collide. Names that fit are kept as is.
+func truncateName(name string) string {
+	if len(name) <= MaxIdentifierLength {
+		return name
+	}
+	hash := nameHash(name)
+	return name[:MaxIdentifierLength-len(hash)-1] + "_" + hash
+}
+
+func nameHash(name string) string {
+	h := fnv.New32a()
+	h.Write([]byte(name))
+	return fmt.Sprintf("%08x", h.Sum32())
+}
+
+// IndexName returns the name of the index of the model.
+func (m *Model) IndexName(i *Index) string {
+	return makeName(m.Name, i.Fields, "idx")
+}
+
+// UniqueName returns the name of the UNIQUE constraint or unique index
+// enforcing u.
+func (m *Model) UniqueName(u *Unique) string {
+	if u.Index {
+		return UniqueIndexName(m.Name, u.Fields)
+	}
+	return makeName(m.Name, u.Fields, "key")
+}
+
+// UniqueIndexSuffix ends the names of unique indexes, which the migration
+// dialects create with CREATE UNIQUE INDEX since the indexes of the SQL
+// schema have no unique flag.
+const UniqueIndexSuffix = "___uidx"
+
+// UniqueIndexName returns the name of the unique index on the columns of
+// the model. Unlike other names, it keeps its suffix when truncated.
+func UniqueIndexName(model string, columns []Path) string {
+	name := fmt.Sprintf("%s___%s", model, strings.Join(sqlNameAll(columns), "___"))
+	if len(name)+len(UniqueIndexSuffix) > MaxIdentifierLength {
+		hash := nameHash(name + UniqueIndexSuffix)
+		name = name[:MaxIdentifierLength-len(UniqueIndexSuffix)-len(hash)-1] + "_" + hash
+	}
+	return name + UniqueIndexSuffix
+}
+
+// CheckName returns the name of the CHECK constraint of the column of the
+// model.
+func (m *Model) CheckName(column string) string {
+	return truncateName(m.Name + "_" + column + "_check")
 }
 
 func (s *Schema) SQLSchema() *schema.Database {
@@ -49,18 +121,29 @@ func (s *Schema) SQLSchema() *schema.Database {
 		}
 
 		for _, f := range m.Indexes {
-			t.Indexes[makeName(m.Name, f.Fields, "idx")] = &schema.Index{
-				Columns: sqlNameAll(f.Fields),
+			t.Indexes[m.IndexName(f)] = &schema.Index{
+				Columns: indexColumns(f),
 			}
 		}
 
 		for _, f := range m.Uniques {
-			t.Uniques[makeName(m.Name, f.Fields, "key")] = &schema.Unique{
+			if f.Index {
+				t.Indexes[m.UniqueName(f)] = &schema.Index{
+					Columns: sqlNameAll(f.Fields),
+				}
+				continue
+			}
+			t.Uniques[m.UniqueName(f)] = &schema.Unique{
 				Columns: sqlNameAll(f.Fields),
 			}
 		}
 
 		for _, f := range m.ForeignKeys {
+			// Foreign keys can't reference foreign tables or views, they're
+			// only used by the relationships.
+			if m2, ok := s.Models[f.ForeignModel]; ok && !m2.HasTable() {
+				continue
+			}
 			t.ForeignKeys[makeName(m.Name, f.LocalFields, "fkey")] = &schema.ForeignKey{
 				ForeignTable:   f.ForeignModel,
 				LocalColumns:   sqlNameAll(f.LocalFields),
@@ -72,6 +155,124 @@ func (s *Schema) SQLSchema() *schema.Database {
 	return d
 }
 
+// ViewTriggerNames are the names of the triggers of ViewTriggers.
+var ViewTriggerNames = []string{"view_insert", "view_update", "view_delete"}
+
+// ViewTriggers returns the INSTEAD OF triggers writing the rows of the view
+// of the model to the table of its View.Table, or nil if it has none. They
+// write the columns of the table of the model, set by SQLSchema.
+func (m *Model) ViewTriggers() []*Trigger {
+	if m.View == nil || m.View.Table == "" {
+		return nil
+	}
+	mapped := make(map[string]string)
+	for _, c := range m.View.Columns {
+		mapped[c.Field.SQLName()] = c.TableColumn
+	}
+	tableColumn := func(c string) string {
+		if tc, ok := mapped[c]; ok {
+			return fmt.Sprintf("\"%s\"", tc)
+		}
+		return fmt.Sprintf("\"%s\"", c)
+	}
+
+	names := make([]string, 0, len(m.Table.Columns))
+	for c := range m.Table.Columns {
+		names = append(names, c)
+	}
+	sort.Strings(names)
+	var columns, values, sets, where []string
+	for _, c := range names {
+		columns = append(columns, tableColumn(c))
+		values = append(values, fmt.Sprintf("NEW.\"%s\"", c))
+		sets = append(sets, fmt.Sprintf("%s = NEW.\"%s\"", tableColumn(c), c))
+	}
+	for _, c := range sqlNameAll(m.PrimaryKey.Fields) {
+		where = append(where, fmt.Sprintf("%s = OLD.\"%s\"", tableColumn(c), c))
+	}
+
+	table := fmt.Sprintf("\"%s\"", m.View.Table)
+	returning := fmt.Sprintf("RETURNING %s INTO %s", strings.Join(columns, ", "), strings.Join(values, ", "))
+	notFound := "IF NOT FOUND THEN\n\tRETURN NULL;\nEND IF;\n"
+	return []*Trigger{
+		{
+			Name:   ViewTriggerNames[0],
+			Timing: "INSTEAD OF",
+			Events: []string{"INSERT"},
+			Body:   fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s) %s;\nRETURN NEW;", table, strings.Join(columns, ", "), strings.Join(values, ", "), returning),
+		},
+		{
+			Name:   ViewTriggerNames[1],
+			Timing: "INSTEAD OF",
+			Events: []string{"UPDATE"},
+			Body:   fmt.Sprintf("UPDATE %s SET %s WHERE %s %s;\n%sRETURN NEW;", table, strings.Join(sets, ", "), strings.Join(where, " AND "), returning, notFound),
+		},
+		{
+			Name:   ViewTriggerNames[2],
+			Timing: "INSTEAD OF",
+			Events: []string{"DELETE"},
+			Body:   fmt.Sprintf("DELETE FROM %s WHERE %s;\n%sRETURN OLD;", table, strings.Join(where, " AND "), notFound),
+		},
+	}
+}
+
+// HistoryTriggerName is the name of the trigger of HistoryTriggers.
+const HistoryTriggerName = "history"
+
+// HistoryValidFrom and HistoryValidTo are the fields of history models with
+// the times a row version became current and stopped being current, which
+// is null for the current version.
+const (
+	HistoryValidFrom = "valid_from"
+	HistoryValidTo   = "valid_to"
+)
+
+// HistoryTriggers returns the trigger recording the row versions of the
+// model in the table of its History, or nil if it has none. Every write
+// closes the current version of the row and inserts the new one, replacing
+// the versions made current by the same transaction, so a transaction only
+// records the last version of a row.
+func (m *Model) HistoryTriggers() []*Trigger {
+	if m.History == nil {
+		return nil
+	}
+
+	names := make([]string, 0, len(m.Table.Columns))
+	for c := range m.Table.Columns {
+		names = append(names, c)
+	}
+	sort.Strings(names)
+	var columns, values, where []string
+	for _, c := range names {
+		columns = append(columns, fmt.Sprintf("\"%s\"", c))
+		values = append(values, fmt.Sprintf("NEW.\"%s\"", c))
+	}
+	for _, c := range sqlNameAll(m.PrimaryKey.Fields) {
+		where = append(where, fmt.Sprintf("\"%s\" = OLD.\"%s\"", c, c))
+	}
+
+	table := fmt.Sprintf("\"%s\"", m.History.Name)
+	current := fmt.Sprintf("%s AND \"%s\" IS NULL", strings.Join(where, " AND "), HistoryValidTo)
+	return []*Trigger{
+		{
+			Name:   HistoryTriggerName,
+			Timing: "AFTER",
+			Events: []string{"INSERT", "UPDATE", "DELETE"},
+			Body: fmt.Sprintf("IF TG_OP <> 'INSERT' THEN\n"+
+				"\tDELETE FROM %s WHERE %s AND \"%s\" = now();\n"+
+				"\tUPDATE %s SET \"%s\" = now() WHERE %s;\n"+
+				"END IF;\n"+
+				"IF TG_OP <> 'DELETE' THEN\n"+
+				"\tINSERT INTO %s (%s, \"%s\") VALUES (%s, now());\n"+
+				"END IF;\n"+
+				"RETURN NULL;",
+				table, current, HistoryValidFrom,
+				table, HistoryValidTo, current,
+				table, strings.Join(columns, ", "), HistoryValidFrom, strings.Join(values, ", ")),
+		},
+	}
+}
+
 func doCalcFields(m *Model, t *schema.Table, f *Field, forceNullable bool, prefix Path) {
 	switch ty := f.Type.(type) {
 	case *Struct:
@@ -96,14 +297,22 @@ func doCalcFields(m *Model, t *schema.Table, f *Field, forceNullable bool, prefi
 		}
 	case BaseType:
 		nullable := f.Nullable || forceNullable
+		sqlType := ty.SQLType()
+		if f.Encryption != EncryptionNone {
+			// Encrypted values have no zero value.
+			sqlType = SQLType{Type: "bytea"}
+		}
 		var def string
 		if !nullable {
-			def = ty.SQLType().ZeroValue
+			def = sqlType.ZeroValue
+		}
+		if f.DBDefault != "" {
+			def = f.DBDefault
 		}
 
 		colName := appendPath(prefix, f.Name).SQLName()
 		t.Columns[colName] = &schema.Column{
-			Type:     ty.SQLType().Type,
+			Type:     sqlType.Type,
 			Default:  def,
 			Nullable: nullable,
 		}
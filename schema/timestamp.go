@@ -0,0 +1,62 @@
+package schema
+
+import (
+	"fmt"
+
+	"github.com/sqlbunny/sqlbunny/runtime/strmangle"
+)
+
+// Timestamp is a time type with its own storage options, generated as a
+// time.Time wrapper normalizing the values it scans and writes.
+type Timestamp struct {
+	Name string
+
+	// TZ stores the values as timestamptz instead of timestamp.
+	TZ bool
+	// Precision is the number of fractional digits of the seconds, or -1
+	// for the database default.
+	Precision int
+	// Location is the IANA name of the location of the values.
+	Location string
+
+	Extendable
+}
+
+func (t *Timestamp) GetName() string {
+	return t.Name
+}
+
+func (t *Timestamp) GoType() GoType {
+	return GoType{
+		Name: strmangle.TitleCase(t.Name),
+	}
+}
+
+func (t *Timestamp) GoTypeNull() GoType {
+	return GoType{
+		Pkg:  "github.com/sqlbunny/sqlbunny/types/null",
+		Name: "Val",
+		Args: []GoType{t.GoType()},
+	}
+}
+
+func (t *Timestamp) GoTypeNullField() string {
+	return "Val"
+}
+
+func (t *Timestamp) SQLType() SQLType {
+	typ, zero := "timestamp", "'0001-01-01 00:00:00'"
+	if t.TZ {
+		typ, zero = "timestamptz", "'0001-01-01 00:00:00+00'"
+	}
+	if t.Precision >= 0 {
+		typ = fmt.Sprintf("%s(%d)", typ, t.Precision)
+	}
+	return SQLType{
+		Type:      typ,
+		ZeroValue: zero,
+	}
+}
+
+var _ BaseType = &Timestamp{}
+var _ NullableType = &Timestamp{}
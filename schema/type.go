@@ -23,9 +23,20 @@ type NullableType interface {
 	GoTypeNullField() string
 }
 
+// BinaryType is a type whose Go values are written as the bytes returned
+// by their Bytes method, see queries.BinaryValue.
+type BinaryType interface {
+	Type
+
+	Binary() bool
+}
+
 type GoType struct {
 	Pkg  string
 	Name string
+
+	// Args are the type arguments of generic types.
+	Args []GoType
 }
 
 type BaseTypeNotNullable struct {
@@ -83,3 +94,20 @@ func (t *BaseTypeNullable) SQLType() SQLType {
 
 var _ BaseType = &BaseTypeNullable{}
 var _ BaseType = &BaseTypeNotNullable{}
+
+// String returns the qualified name of the type, like
+// "github.com/sqlbunny/sqlbunny/types/null.Val[int64]".
+func (t GoType) String() string {
+	s := t.Name
+	if t.Pkg != "" {
+		s = t.Pkg + "." + s
+	}
+	if len(t.Args) != 0 {
+		args := make([]string, len(t.Args))
+		for i, a := range t.Args {
+			args[i] = a.String()
+		}
+		s += "[" + strings.Join(args, ", ") + "]"
+	}
+	return s
+}
@@ -0,0 +1,71 @@
+package schema
+
+// UUIDStorage is the column type storing a UUID.
+type UUIDStorage int
+
+const (
+	// UUIDNative stores UUIDs in uuid columns.
+	UUIDNative UUIDStorage = iota
+	// UUIDBytea stores the 16 bytes of UUIDs in bytea columns.
+	UUIDBytea
+	// UUIDText stores UUIDs in text columns, in their canonical form.
+	UUIDText
+)
+
+// UUID is a uuid.UUID type, stored in the column type of Storage.
+type UUID struct {
+	Name    string
+	Storage UUIDStorage
+
+	Extendable
+}
+
+func (t *UUID) GetName() string {
+	return t.Name
+}
+
+func (t *UUID) GoType() GoType {
+	return GoType{
+		Pkg:  "github.com/gofrs/uuid",
+		Name: "UUID",
+	}
+}
+
+func (t *UUID) GoTypeNull() GoType {
+	return GoType{
+		Pkg:  "github.com/gofrs/uuid",
+		Name: "NullUUID",
+	}
+}
+
+func (t *UUID) GoTypeNullField() string {
+	return "UUID"
+}
+
+func (t *UUID) SQLType() SQLType {
+	switch t.Storage {
+	case UUIDBytea:
+		return SQLType{
+			Type:      "bytea",
+			ZeroValue: `'\x00000000000000000000000000000000'`,
+		}
+	case UUIDText:
+		return SQLType{
+			Type:      "text",
+			ZeroValue: "'00000000-0000-0000-0000-000000000000'",
+		}
+	}
+	return SQLType{
+		Type:      "uuid",
+		ZeroValue: "'00000000-0000-0000-0000-000000000000'",
+	}
+}
+
+// Binary reports whether the values are written as their bytes.
+func (t *UUID) Binary() bool {
+	return t.Storage == UUIDBytea
+}
+
+var _ BaseType = &UUID{}
+var _ NullableType = &UUID{}
+var _ BinaryType = &UUID{}
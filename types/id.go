@@ -0,0 +1,32 @@
+package types
+
+import (
+	"encoding/base32"
+	"fmt"
+	"strings"
+)
+
+// idEncoding is the lowercase Crockford base32 alphabet, without the
+// letters that are easy to mistake for others.
+var idEncoding = base32.NewEncoding("0123456789abcdefghjkmnpqrstvwxyz").WithPadding(base32.NoPadding)
+
+// FormatID returns the external form of the bytes of an ID of the type with
+// prefix, like "user_kwy423jvf962v3ghe0xa4np90g".
+func FormatID(prefix string, id []byte) string {
+	return prefix + "_" + idEncoding.EncodeToString(id)
+}
+
+// ParseID parses s, the external form of an ID of the type with prefix,
+// into the bytes of id. It fails if s has another prefix, or if it doesn't
+// hold exactly len(id) bytes.
+func ParseID(prefix string, s string, id []byte) error {
+	if !strings.HasPrefix(s, prefix+"_") {
+		return fmt.Errorf("invalid %s ID '%s': it must start with '%s_'", prefix, s, prefix)
+	}
+	b, err := idEncoding.DecodeString(s[len(prefix)+1:])
+	if err != nil || len(b) != len(id) {
+		return fmt.Errorf("invalid %s ID '%s'", prefix, s)
+	}
+	copy(id, b)
+	return nil
+}
@@ -0,0 +1,41 @@
+package types
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestFormatID(t *testing.T) {
+	t.Parallel()
+
+	id := []byte{0x01, 0x8f, 0x2c, 0x00, 0xff, 0x10, 0x42, 0x7e}
+	s := FormatID("user", id)
+	if s != "user_067jr07z2117w" {
+		t.Errorf("Expected %q, got %q", "user_067jr07z2117w", s)
+	}
+
+	parsed := make([]byte, len(id))
+	if err := ParseID("user", s, parsed); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(parsed, id) {
+		t.Errorf("Expected %v, got %v", id, parsed)
+	}
+}
+
+func TestParseIDInvalid(t *testing.T) {
+	t.Parallel()
+
+	tests := []string{
+		"org_067jr07z2117w",
+		"user067jr07z2117w",
+		"user_067jr07z2117",
+		"user_067jr07z2117wz0",
+		"user_067jr07z2117!",
+	}
+	for i, s := range tests {
+		if err := ParseID("user", s, make([]byte, 8)); err == nil {
+			t.Errorf("%d: expected %q to be invalid", i, s)
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package null
+
+import (
+	"encoding"
+	"flag"
+)
+
+// TextValue is a value with a text form, like the null types.
+type TextValue interface {
+	encoding.TextMarshaler
+	encoding.TextUnmarshaler
+}
+
+// Flag returns a flag.Value setting v from its text form, so null types can
+// be command line flags:
+//
+//	var limit null.Int
+//	flag.Var(null.Flag(&limit), "limit", "maximum number of rows")
+//
+// A flag set to "" is null. flag.TextVar works the same way.
+func Flag(v TextValue) flag.Value {
+	return textFlag{v}
+}
+
+type textFlag struct {
+	v TextValue
+}
+
+func (f textFlag) String() string {
+	if f.v == nil {
+		return ""
+	}
+	text, err := f.v.MarshalText()
+	if err != nil {
+		return ""
+	}
+	return string(text)
+}
+
+func (f textFlag) Set(s string) error {
+	return f.v.UnmarshalText([]byte(s))
+}
@@ -0,0 +1,47 @@
+package null
+
+import (
+	"flag"
+	"io/ioutil"
+	"testing"
+	"time"
+)
+
+func TestFlag(t *testing.T) {
+	var (
+		limit Int
+		name  String
+		since Time
+		price Val[cents]
+	)
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(ioutil.Discard)
+	fs.Var(Flag(&limit), "limit", "")
+	fs.Var(Flag(&name), "name", "")
+	fs.Var(Flag(&since), "since", "")
+	fs.Var(Flag(&price), "price", "")
+
+	err := fs.Parse([]string{"-limit", "10", "-name", "", "-since", "2012-12-21T21:21:21Z", "-price", "1250"})
+	maybePanic(err)
+
+	if !limit.Valid || limit.Int != 10 {
+		t.Errorf("expected limit 10, got %+v", limit)
+	}
+	if name.Valid {
+		t.Errorf("expected a null name, got %+v", name)
+	}
+	if !since.Valid || !since.Time.Equal(time.Date(2012, 12, 21, 21, 21, 21, 0, time.UTC)) {
+		t.Errorf("expected since 2012-12-21T21:21:21Z, got %+v", since)
+	}
+	if !price.Valid || price.Val != 1250 {
+		t.Errorf("expected price 1250, got %+v", price)
+	}
+	if s := fs.Lookup("limit").Value.String(); s != "10" {
+		t.Errorf("expected the flag to print 10, got %q", s)
+	}
+
+	if err := fs.Parse([]string{"-limit", "ten"}); err == nil {
+		t.Error("expected invalid values to fail")
+	}
+}
@@ -0,0 +1,133 @@
+package null
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"encoding"
+	"encoding/json"
+	"fmt"
+
+	"github.com/sqlbunny/sqlbunny/types/null/convert"
+)
+
+// Val is a nullable T. It supports SQL and JSON serialization, so custom
+// types don't need a hand-written nullable wrapper. T is scanned with its
+// Scan method if it has one, and converted like database/sql does otherwise.
+type Val[T any] struct {
+	Val   T
+	Valid bool
+}
+
+// ValFrom creates a new Val that will never be blank.
+func ValFrom[T any](v T) Val[T] {
+	return NewVal(v, true)
+}
+
+// ValFromPtr creates a new Val that will be null if v is nil.
+func ValFromPtr[T any](v *T) Val[T] {
+	if v == nil {
+		var zero T
+		return NewVal(zero, false)
+	}
+	return NewVal(*v, true)
+}
+
+// NewVal creates a new Val
+func NewVal[T any](v T, valid bool) Val[T] {
+	return Val[T]{
+		Val:   v,
+		Valid: valid,
+	}
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (v *Val[T]) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, NullBytes) {
+		var zero T
+		v.Val = zero
+		v.Valid = false
+		return nil
+	}
+
+	if err := json.Unmarshal(data, &v.Val); err != nil {
+		return err
+	}
+
+	v.Valid = true
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler.
+func (v Val[T]) MarshalJSON() ([]byte, error) {
+	if !v.Valid {
+		return NullBytes, nil
+	}
+	return json.Marshal(v.Val)
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler. T is parsed with its
+// UnmarshalText method if it has one, and converted like in Scan otherwise.
+func (v *Val[T]) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		v.Valid = false
+		return nil
+	}
+	var err error
+	if u, ok := interface{}(&v.Val).(encoding.TextUnmarshaler); ok {
+		err = u.UnmarshalText(text)
+	} else {
+		err = convert.Assign(&v.Val, string(text))
+	}
+	v.Valid = err == nil
+	return err
+}
+
+// MarshalText implements encoding.TextMarshaler. T is formatted with its
+// MarshalText method if it has one, and with fmt otherwise.
+func (v Val[T]) MarshalText() ([]byte, error) {
+	if !v.Valid {
+		return nil, nil
+	}
+	if m, ok := interface{}(v.Val).(encoding.TextMarshaler); ok {
+		return m.MarshalText()
+	}
+	return []byte(fmt.Sprint(v.Val)), nil
+}
+
+// SetValid changes this Val's value and also sets it to be non-null.
+func (v *Val[T]) SetValid(n T) {
+	v.Val = n
+	v.Valid = true
+}
+
+// Ptr returns a pointer to this Val's value, or a nil pointer if this Val is null.
+func (v Val[T]) Ptr() *T {
+	if !v.Valid {
+		return nil
+	}
+	return &v.Val
+}
+
+// IsZero returns true for null values, for potential future omitempty support.
+func (v Val[T]) IsZero() bool {
+	return !v.Valid
+}
+
+// Scan implements the Scanner interface.
+func (v *Val[T]) Scan(value interface{}) error {
+	if value == nil {
+		var zero T
+		v.Val, v.Valid = zero, false
+		return nil
+	}
+	v.Valid = true
+	return convert.Assign(&v.Val, value)
+}
+
+// Value implements the driver Valuer interface.
+func (v Val[T]) Value() (driver.Value, error) {
+	if !v.Valid {
+		return nil, nil
+	}
+	return driver.DefaultParameterConverter.ConvertValue(v.Val)
+}
@@ -0,0 +1,126 @@
+package null
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+type cents int64
+
+type upper string
+
+func (u *upper) Scan(value interface{}) error {
+	s, ok := value.(string)
+	if !ok {
+		return errors.New("not a string")
+	}
+	*u = upper(strings.ToUpper(s))
+	return nil
+}
+
+func TestValFrom(t *testing.T) {
+	v := ValFrom(cents(0))
+	if !v.Valid || v.Val != 0 {
+		t.Error("ValFrom(0)", "is invalid, but should be valid")
+	}
+
+	null := ValFromPtr[cents](nil)
+	if null.Valid {
+		t.Error("ValFromPtr(nil)", "is valid, but should be invalid")
+	}
+	if null.Ptr() != nil {
+		t.Error("Ptr() of a null Val should be nil")
+	}
+}
+
+func TestValScan(t *testing.T) {
+	var v Val[cents]
+	err := v.Scan(int64(1250))
+	maybePanic(err)
+	if !v.Valid || v.Val != 1250 {
+		t.Errorf("expected 1250, got %+v", v)
+	}
+
+	err = v.Scan(nil)
+	maybePanic(err)
+	if v.Valid || v.Val != 0 {
+		t.Errorf("expected null, got %+v", v)
+	}
+
+	var u Val[upper]
+	err = u.Scan("abc")
+	maybePanic(err)
+	if !u.Valid || u.Val != "ABC" {
+		t.Errorf("expected the Scan method of the value to be used, got %+v", u)
+	}
+}
+
+func TestValValue(t *testing.T) {
+	value, err := ValFrom(cents(1250)).Value()
+	maybePanic(err)
+	if value != int64(1250) {
+		t.Errorf("expected int64 1250, got %#v", value)
+	}
+
+	value, err = Val[cents]{}.Value()
+	maybePanic(err)
+	if value != nil {
+		t.Errorf("expected nil, got %#v", value)
+	}
+}
+
+func TestValJSON(t *testing.T) {
+	data, err := json.Marshal(ValFrom(cents(1250)))
+	maybePanic(err)
+	assertJSONEquals(t, data, "1250", "non-empty json marshal")
+
+	data, err = json.Marshal(Val[cents]{})
+	maybePanic(err)
+	assertJSONEquals(t, data, "null", "null json marshal")
+
+	var v Val[cents]
+	err = json.Unmarshal([]byte("1250"), &v)
+	maybePanic(err)
+	if !v.Valid || v.Val != 1250 {
+		t.Errorf("expected 1250, got %+v", v)
+	}
+
+	err = json.Unmarshal(nullJSON, &v)
+	maybePanic(err)
+	if v.Valid {
+		t.Errorf("expected null, got %+v", v)
+	}
+}
+
+func TestValText(t *testing.T) {
+	text, err := ValFrom(cents(1250)).MarshalText()
+	maybePanic(err)
+	if string(text) != "1250" {
+		t.Errorf("expected 1250, got %q", text)
+	}
+
+	text, err = ValFrom(Int64From(7)).MarshalText()
+	maybePanic(err)
+	if string(text) != "7" {
+		t.Errorf("expected the MarshalText method of the value to be used, got %q", text)
+	}
+
+	var v Val[cents]
+	err = v.UnmarshalText([]byte("1250"))
+	maybePanic(err)
+	if !v.Valid || v.Val != 1250 {
+		t.Errorf("expected 1250, got %+v", v)
+	}
+
+	err = v.UnmarshalText(nil)
+	maybePanic(err)
+	if v.Valid {
+		t.Errorf("expected null, got %+v", v)
+	}
+
+	if err := v.UnmarshalText([]byte("abc")); err == nil || v.Valid {
+		t.Errorf("expected an error and null, got %v and %+v", err, v)
+	}
+}